@@ -0,0 +1,146 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command netconf runs NETCONF operations against devices.  Connection
+// defaults are read from ~/.netconf.yaml, or the file named by
+// NETCONF_CONFIG, and from the NETCONF_* environment variables, and can be
+// overridden with flags.
+//
+//	netconf -host r1 -user ops get-config -source candidate
+//	netconf -host r1 exec '<get-software-information/>'
+//	netconf -insecure matrix -inventory devices.yaml -format csv
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Juniper/go-netconf/netconf"
+)
+
+// command is a subcommand run with the connection settings and the
+// arguments following its name.
+type command struct {
+	usage string
+	run   func(ctx context.Context, s *netconf.Settings, args []string) error
+}
+
+var commands = map[string]*command{
+	"get-config": {usage: "[-source datastore] [-filter subtree]", run: getConfig},
+	"exec":       {usage: "rpc", run: execRPC},
+	"matrix":     {usage: "[-inventory file] [-tags tag,...] [-format json|csv] [-workers n] [device ...]", run: matrix},
+}
+
+func main() {
+	settings, err := netconf.LoadSettings()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("netconf", flag.ExitOnError)
+	flags.Usage = func() { usage(flags) }
+	flags.StringVar(&settings.Host, "host", settings.Host, "device to connect to")
+	flags.IntVar(&settings.Port, "port", settings.Port, "port to connect to")
+	flags.StringVar(&settings.Username, "user", settings.Username, "SSH username")
+	flags.StringVar(&settings.KeyFile, "key", settings.KeyFile, "SSH private key file")
+	flags.StringVar(&settings.KnownHosts, "known-hosts", settings.KnownHosts, "known_hosts file, ~/.ssh/known_hosts by default")
+	flags.BoolVar(&settings.InsecureIgnoreHostKey, "insecure", settings.InsecureIgnoreHostKey, "do not check host keys")
+	flags.StringVar(&settings.Profile, "profile", settings.Profile, "vendor profile")
+	flags.DurationVar(&settings.Timeout, "timeout", settings.Timeout, "session establishment timeout")
+	flags.DurationVar(&settings.RPCTimeout, "rpc-timeout", settings.RPCTimeout, "timeout of each reply")
+	flags.Parse(os.Args[1:])
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	cmd := commands[flags.Arg(0)]
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "netconf: unknown command %q\n", flags.Arg(0))
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(context.Background(), settings, flags.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage(flags *flag.FlagSet) {
+	out := flags.Output()
+	fmt.Fprintln(out, "usage: netconf [flags] command [arguments]")
+	fmt.Fprintln(out, "\ncommands:")
+	for _, name := range commandNames() {
+		fmt.Fprintf(out, "  %s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(out, "\nflags:")
+	flags.PrintDefaults()
+}
+
+func commandNames() []string {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dial establishes a session to the host of the settings.
+func dial(ctx context.Context, s *netconf.Settings) (*netconf.Session, error) {
+	if s.Host == "" {
+		return nil, fmt.Errorf("netconf: no host, set -host or %s", netconf.EnvHost)
+	}
+	opts, err := s.Options()
+	if err != nil {
+		return nil, err
+	}
+	return netconf.DialContext(ctx, s.Target(), opts...)
+}
+
+func getConfig(ctx context.Context, s *netconf.Settings, args []string) error {
+	flags := flag.NewFlagSet("get-config", flag.ExitOnError)
+	source := flags.String("source", "running", "datastore to read")
+	filter := flags.String("filter", "", "subtree filter")
+	flags.Parse(args)
+
+	session, err := dial(ctx, s)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	method := netconf.MethodGetConfig(*source)
+	if *filter != "" {
+		method = netconf.MethodGetConfigFilter(*source, netconf.SubtreeFilter(*filter))
+	}
+	reply, err := session.ExecContext(ctx, method)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Println(string(reply.Data))
+	return err
+}
+
+func execRPC(ctx context.Context, s *netconf.Settings, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: netconf exec rpc")
+	}
+	session, err := dial(ctx, s)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	reply, err := session.ExecContext(ctx, netconf.RawMethod(args[0]))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Println(string(reply.Data))
+	return err
+}
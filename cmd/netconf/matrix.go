@@ -0,0 +1,81 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Juniper/go-netconf/netconf"
+)
+
+// matrix writes the capability matrix of the devices named as arguments,
+// or of those of an inventory.  Devices that cannot be read are listed on
+// stderr and make the command fail once the matrix is written.
+func matrix(ctx context.Context, s *netconf.Settings, args []string) error {
+	flags := flag.NewFlagSet("matrix", flag.ExitOnError)
+	inventory := flags.String("inventory", "", "inventory file of the devices")
+	tags := flags.String("tags", "", "comma separated tags the inventory devices must carry")
+	format := flags.String("format", "json", "output format, json or csv")
+	workers := flags.Int("workers", 0, "devices read in parallel")
+	flags.Parse(args)
+
+	if *format != "json" && *format != "csv" {
+		return fmt.Errorf("netconf: unknown format %q", *format)
+	}
+	var devices []*netconf.Device
+	if *inventory != "" {
+		inv, err := netconf.LoadInventory(*inventory)
+		if err != nil {
+			return err
+		}
+		var selected []string
+		if *tags != "" {
+			selected = strings.Split(*tags, ",")
+		}
+		devices = inv.Select(selected...)
+	}
+	for _, address := range flags.Args() {
+		devices = append(devices, &netconf.Device{Name: address, Address: address, Port: s.Port})
+	}
+	if len(devices) == 0 && s.Host != "" {
+		devices = append(devices, &netconf.Device{Name: s.Host, Address: s.Host, Port: s.Port})
+	}
+	if len(devices) == 0 {
+		return fmt.Errorf("netconf: no devices, name them or set -inventory")
+	}
+
+	opts, err := s.Options()
+	if err != nil {
+		return err
+	}
+	client := netconf.NewClient(opts...)
+	fleet := &netconf.Fleet{
+		Devices: devices,
+		Dial: func(ctx context.Context, d *netconf.Device) (*netconf.Session, error) {
+			return client.DialDevice(ctx, d)
+		},
+		Workers: *workers,
+	}
+	m, report := fleet.CapabilityMatrix(ctx)
+	if *format == "csv" {
+		err = m.WriteCSV(os.Stdout)
+	} else {
+		err = m.WriteJSON(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+	if !report.OK() {
+		report.WriteTable(os.Stderr)
+		return fmt.Errorf("netconf: %d of %d devices failed", len(report.Results)-report.Count(netconf.StatusOK), len(report.Results))
+	}
+	return nil
+}
@@ -111,7 +111,7 @@ func main() {
 	}
 	var q SystemInformation
 
-	err = xml.Unmarshal([]byte(reply.RawReply), &q)
+	err = xml.Unmarshal(reply.RawReply, &q)
 	if err != nil {
 		log.Fatal(err)
 	}
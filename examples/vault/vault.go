@@ -0,0 +1,103 @@
+// Go NETCONF Client - Example
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This example fetches device credentials from the KV version 2 secrets
+// engine of HashiCorp Vault, using VAULT_ADDR and VAULT_TOKEN.  The secret
+// of a device is read from secret/data/netconf/<credential>, or
+// secret/data/netconf/<device> if the device names no credential, and holds
+// the keys "username", "password" and optionally "private_key".
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Juniper/go-netconf/netconf"
+)
+
+// VaultCredentials is a netconf.CredentialProvider reading secrets from
+// Vault.
+type VaultCredentials struct {
+	Addr   string
+	Token  string
+	Mount  string
+	Prefix string
+	Client *http.Client
+}
+
+// GetCredentials implements netconf.CredentialProvider.
+func (v *VaultCredentials) GetCredentials(ctx context.Context, d *netconf.Device) (*netconf.Credentials, error) {
+	name := d.Credential
+	if name == "" {
+		name = d.Name
+	}
+	url := fmt.Sprintf("%s/v1/%s/data/%s/%s", v.Addr, v.Mount, v.Prefix, name)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Vault-Token", v.Token)
+
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault: %s: %s", url, resp.Status)
+	}
+
+	var secret struct {
+		Data struct {
+			Data struct {
+				Username   string `json:"username"`
+				Password   string `json:"password"`
+				PrivateKey string `json:"private_key"`
+			} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, err
+	}
+	s := secret.Data.Data
+	creds := &netconf.Credentials{Username: s.Username, Password: s.Password}
+	if s.PrivateKey != "" {
+		creds.PrivateKey = []byte(s.PrivateKey)
+	}
+	return creds, nil
+}
+
+func main() {
+	vault := &VaultCredentials{
+		Addr:   os.Getenv("VAULT_ADDR"),
+		Token:  os.Getenv("VAULT_TOKEN"),
+		Mount:  "secret",
+		Prefix: "netconf",
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+	client := netconf.NewClient(
+		netconf.WithCredentialProvider(vault),
+		netconf.WithTimeout(30*time.Second),
+	)
+
+	s, err := client.DialDevice(context.Background(), &netconf.Device{Name: "r1", Address: "1.1.1.1"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer s.Close()
+
+	reply, err := s.Exec(netconf.MethodGetConfig("running"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Reply: %+v", reply)
+}
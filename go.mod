@@ -5,4 +5,5 @@ go 1.12
 require (
 	github.com/google/go-cmp v0.5.1
 	golang.org/x/crypto v0.17.0
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,67 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// splitTarget splits a dial target into host and port, the port being empty
+// if target has none.  The host may be a name, an IPv4 address or an IPv6
+// literal, bracketed or not, with a zone such as fe80::1%mgmt.  The %25
+// escape of the zone used in URIs (RFC 6874) is accepted too.  A bare IPv6
+// literal cannot carry a port.
+func splitTarget(target string) (host, port string) {
+	target = strings.TrimSpace(target)
+	if strings.HasPrefix(target, "[") {
+		if i := strings.LastIndexByte(target, ']'); i > 0 {
+			host, rest := target[1:i], target[i+1:]
+			if strings.HasPrefix(rest, ":") {
+				port = rest[1:]
+			}
+			return unescapeZone(host), port
+		}
+	}
+	if strings.Count(target, ":") > 1 {
+		return unescapeZone(target), ""
+	}
+	if i := strings.LastIndexByte(target, ':'); i >= 0 {
+		return target[:i], target[i+1:]
+	}
+	return target, ""
+}
+
+// unescapeZone turns the zone escape %25 of an IPv6 literal into %.
+func unescapeZone(host string) string {
+	i := strings.Index(host, "%25")
+	if i < 0 || i+3 == len(host) || net.ParseIP(host[:i]) == nil {
+		return host
+	}
+	return host[:i] + "%" + host[i+3:]
+}
+
+// withDefaultPort returns target as host:port, adding port if target has
+// none and bracketing IPv6 literals.
+func withDefaultPort(target string, port int) string {
+	host, p := splitTarget(target)
+	if p == "" {
+		p = strconv.Itoa(port)
+	}
+	return net.JoinHostPort(host, p)
+}
+
+// targetHost returns the host of target without the zone of an IPv6
+// literal, as used to verify certificates and look up credentials.
+func targetHost(target string) string {
+	host, _ := splitTarget(target)
+	if i := strings.IndexByte(host, '%'); i >= 0 && strings.Contains(host, ":") {
+		host = host[:i]
+	}
+	return host
+}
@@ -0,0 +1,56 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import "testing"
+
+func TestSplitTarget(t *testing.T) {
+	tt := []struct {
+		target string
+		host   string
+		port   string
+		bare   string
+	}{
+		{"r1", "r1", "", "r1"},
+		{"r1:22", "r1", "22", "r1"},
+		{"10.0.0.1:830", "10.0.0.1", "830", "10.0.0.1"},
+		{"2001:db8::1", "2001:db8::1", "", "2001:db8::1"},
+		{"[2001:db8::1]", "2001:db8::1", "", "2001:db8::1"},
+		{"[2001:db8::1]:830", "2001:db8::1", "830", "2001:db8::1"},
+		{"fe80::1%mgmt", "fe80::1%mgmt", "", "fe80::1"},
+		{"[fe80::1%mgmt]:830", "fe80::1%mgmt", "830", "fe80::1"},
+		{"[fe80::1%25eth0]:830", "fe80::1%eth0", "830", "fe80::1"},
+		{" [fe80::1%eth0.100] ", "fe80::1%eth0.100", "", "fe80::1"},
+	}
+	for _, tc := range tt {
+		host, port := splitTarget(tc.target)
+		if host != tc.host || port != tc.port {
+			t.Errorf("%q: got %q, %q, expected %q, %q", tc.target, host, port, tc.host, tc.port)
+		}
+		if got := targetHost(tc.target); got != tc.bare {
+			t.Errorf("%q: got host %q, expected %q", tc.target, got, tc.bare)
+		}
+	}
+}
+
+func TestWithDefaultPort(t *testing.T) {
+	tt := []struct {
+		target   string
+		expected string
+	}{
+		{"r1", "r1:830"},
+		{"r1:22", "r1:22"},
+		{"fe80::1%mgmt", "[fe80::1%mgmt]:830"},
+		{"[fe80::1%mgmt]", "[fe80::1%mgmt]:830"},
+		{"[fe80::1%25mgmt]:22", "[fe80::1%mgmt]:22"},
+	}
+	for _, tc := range tt {
+		if got := withDefaultPort(tc.target, sshDefaultPort); got != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.target, got, tc.expected)
+		}
+	}
+}
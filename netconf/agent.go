@@ -0,0 +1,23 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"io"
+	"os"
+)
+
+// DialSSHAgent connects to the running SSH agent.  The agent listening on
+// SSH_AUTH_SOCK is used if the variable is set.  On Windows, where it may
+// also name a named pipe, the Windows OpenSSH agent and Pageant are tried
+// otherwise.  The connection is used with agent.NewClient.
+func DialSSHAgent() (io.ReadWriteCloser, error) {
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		return dialAgentSocket(sock)
+	}
+	return dialSystemAgent()
+}
@@ -0,0 +1,24 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+// +build !windows
+
+package netconf
+
+import (
+	"errors"
+	"io"
+	"net"
+)
+
+func dialAgentSocket(path string) (io.ReadWriteCloser, error) {
+	return net.Dial("unix", path)
+}
+
+func dialSystemAgent() (io.ReadWriteCloser, error) {
+	return nil, errors.New("netconf: no SSH agent, SSH_AUTH_SOCK not set")
+}
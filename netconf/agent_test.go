@@ -0,0 +1,74 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+// +build !windows
+
+package netconf
+
+import (
+	"crypto/ed25519"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func TestDialSSHAgent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sock := filepath.Join(dir, "agent.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	_, key, _ := ed25519.GenerateKey(nil)
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: key, Comment: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go agent.ServeAgent(keyring, c)
+		}
+	}()
+
+	defer os.Setenv("SSH_AUTH_SOCK", os.Getenv("SSH_AUTH_SOCK"))
+	os.Setenv("SSH_AUTH_SOCK", sock)
+	config, err := SSHConfigPubKeyAgent("admin")
+	if err != nil {
+		t.Fatalf("SSHConfigPubKeyAgent failed: %v", err)
+	}
+	if config.User != "admin" || len(config.Auth) != 1 {
+		t.Errorf("unexpected config %+v", config)
+	}
+
+	c, err := DialSSHAgent()
+	if err != nil {
+		t.Fatalf("DialSSHAgent failed: %v", err)
+	}
+	defer c.Close()
+	if keys, err := agent.NewClient(c).List(); err != nil || len(keys) != 1 || keys[0].Comment != "test" {
+		t.Errorf("got keys %v, %v, expected the test key", keys, err)
+	}
+
+	os.Setenv("SSH_AUTH_SOCK", "")
+	if _, err := DialSSHAgent(); err == nil {
+		t.Error("expected an error without SSH_AUTH_SOCK")
+	}
+}
@@ -0,0 +1,160 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+// openSSHAgentPipe is the named pipe of the Windows OpenSSH agent service.
+const openSSHAgentPipe = `\\.\pipe\openssh-ssh-agent`
+
+// Pageant protocol constants, from PuTTY's windows/pageant.c.
+const (
+	pageantCopyDataID = 0x804e50ba
+	pageantMaxMessage = 8192
+	wmCopyData        = 0x004a
+)
+
+var (
+	user32          = syscall.NewLazyDLL("user32.dll")
+	kernel32        = syscall.NewLazyDLL("kernel32.dll")
+	procFindWindow  = user32.NewProc("FindWindowW")
+	procSendMessage = user32.NewProc("SendMessageW")
+	procMoveMemory  = kernel32.NewProc("RtlMoveMemory")
+
+	pageantRequests uint32
+)
+
+func dialAgentSocket(path string) (io.ReadWriteCloser, error) {
+	if strings.HasPrefix(path, `\\.\pipe\`) {
+		return os.OpenFile(path, os.O_RDWR, 0)
+	}
+	// Windows 10 supports unix domain sockets, as used by e.g. WSL agents.
+	return net.Dial("unix", path)
+}
+
+func dialSystemAgent() (io.ReadWriteCloser, error) {
+	pipe, err := os.OpenFile(openSSHAgentPipe, os.O_RDWR, 0)
+	if err == nil {
+		return pipe, nil
+	}
+	if !pageantRunning() {
+		return nil, fmt.Errorf("netconf: no SSH agent, neither OpenSSH agent nor Pageant running: %v", err)
+	}
+	return &pageantConn{}, nil
+}
+
+func pageantWindow() uintptr {
+	name, _ := syscall.UTF16PtrFromString("Pageant")
+	hwnd, _, _ := procFindWindow.Call(uintptr(unsafe.Pointer(name)), uintptr(unsafe.Pointer(name)))
+	return hwnd
+}
+
+func pageantRunning() bool {
+	return procFindWindow.Find() == nil && pageantWindow() != 0
+}
+
+// pageantConn speaks the agent protocol to Pageant, which takes requests
+// through shared memory announced by a WM_COPYDATA message rather than over
+// a stream.  Writes are buffered until a whole request was written; its
+// response is then read back.
+type pageantConn struct {
+	req  []byte
+	resp []byte
+}
+
+func (c *pageantConn) Write(p []byte) (int, error) {
+	c.req = append(c.req, p...)
+	if len(c.req) < 4 {
+		return len(p), nil
+	}
+	n := 4 + int(binary.BigEndian.Uint32(c.req))
+	if n > pageantMaxMessage {
+		c.req = nil
+		return 0, errors.New("netconf: SSH agent request too large for Pageant")
+	}
+	if len(c.req) < n {
+		return len(p), nil
+	}
+	resp, err := pageantQuery(c.req[:n])
+	c.req = append([]byte(nil), c.req[n:]...)
+	if err != nil {
+		return 0, err
+	}
+	c.resp = append(c.resp, resp...)
+	return len(p), nil
+}
+
+func (c *pageantConn) Read(p []byte) (int, error) {
+	if len(c.resp) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, c.resp)
+	c.resp = c.resp[n:]
+	return n, nil
+}
+
+func (c *pageantConn) Close() error {
+	return nil
+}
+
+// copyDataStruct is the COPYDATASTRUCT of WM_COPYDATA.
+type copyDataStruct struct {
+	dwData uintptr
+	cbData uint32
+	lpData uintptr
+}
+
+// pageantQuery sends a request, including its length prefix, to Pageant and
+// returns the response.
+func pageantQuery(req []byte) ([]byte, error) {
+	hwnd := pageantWindow()
+	if hwnd == 0 {
+		return nil, errors.New("netconf: Pageant not running")
+	}
+
+	name := fmt.Sprintf("PageantRequest%08x%08x", os.Getpid(), atomic.AddUint32(&pageantRequests, 1))
+	wname, _ := syscall.UTF16PtrFromString(name)
+	mapping, err := syscall.CreateFileMapping(syscall.InvalidHandle, nil, syscall.PAGE_READWRITE, 0, pageantMaxMessage, wname)
+	if err != nil {
+		return nil, fmt.Errorf("netconf: Pageant shared memory: %v", err)
+	}
+	defer syscall.CloseHandle(mapping)
+	view, err := syscall.MapViewOfFile(mapping, syscall.FILE_MAP_WRITE, 0, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("netconf: Pageant shared memory: %v", err)
+	}
+	defer syscall.UnmapViewOfFile(view)
+
+	procMoveMemory.Call(view, uintptr(unsafe.Pointer(&req[0])), uintptr(len(req)))
+	// Pageant expects the name of the mapping as an ANSI string.
+	ansi := append([]byte(name), 0)
+	cds := copyDataStruct{dwData: pageantCopyDataID, cbData: uint32(len(ansi)), lpData: uintptr(unsafe.Pointer(&ansi[0]))}
+	if ret, _, _ := procSendMessage.Call(hwnd, wmCopyData, 0, uintptr(unsafe.Pointer(&cds))); ret == 0 {
+		return nil, errors.New("netconf: Pageant refused the request")
+	}
+
+	var size [4]byte
+	procMoveMemory.Call(uintptr(unsafe.Pointer(&size[0])), view, 4)
+	n := 4 + int(binary.BigEndian.Uint32(size[:]))
+	if n > pageantMaxMessage {
+		return nil, errors.New("netconf: invalid Pageant response")
+	}
+	resp := make([]byte, n)
+	procMoveMemory.Call(uintptr(unsafe.Pointer(&resp[0])), view, uintptr(n))
+	return resp, nil
+}
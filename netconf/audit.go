@@ -0,0 +1,307 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Directions of audit records.
+const (
+	AuditRequest = "request"
+	AuditReply   = "reply"
+)
+
+// AuditRecord is an entry of an AuditLog.  Each record holds the hash of the
+// previous one, so altering, removing or reordering records breaks the
+// chain, see VerifyAuditLog.
+type AuditRecord struct {
+	Seq       uint64    `json:"seq"`
+	Time      time.Time `json:"time"`
+	Device    string    `json:"device,omitempty"`
+	Direction string    `json:"direction"`
+	Operation string    `json:"operation,omitempty"`
+	Data      string    `json:"data,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	PrevHash  string    `json:"prev_hash"`
+	Hash      string    `json:"hash"`
+}
+
+// hash returns the hash of the record, computed over its JSON encoding
+// without the hash itself.
+func (r AuditRecord) hash() (string, error) {
+	r.Hash = ""
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// AuditLog writes a tamper-evident transcript of RPCs as JSON lines, each
+// record chained to the previous one by its hash.  It is safe for
+// concurrent use.
+type AuditLog struct {
+	// Device, if set, is recorded with every record.
+	Device string
+	// Redactor, if set, masks secrets in the data and errors recorded.
+	Redactor *Redactor
+
+	mu   sync.Mutex
+	w    io.Writer
+	seq  uint64
+	prev string
+}
+
+// NewAuditLog returns a log starting a new chain on w.
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{w: w}
+}
+
+// ResumeAuditLog returns a log appending to w the chain read from r, the
+// existing content of the log.  The existing records are verified first.
+func ResumeAuditLog(w io.Writer, r io.Reader) (*AuditLog, error) {
+	l := NewAuditLog(w)
+	last, _, err := verifyAuditLog(r)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil {
+		l.seq, l.prev = last.Seq, last.Hash
+	}
+	return l, nil
+}
+
+// Record appends a record to the log.
+func (l *AuditLog) Record(direction, operation string, data []byte, rpcErr error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r := AuditRecord{
+		Seq:       l.seq + 1,
+		Time:      time.Now().UTC(),
+		Device:    l.Device,
+		Direction: direction,
+		Operation: operation,
+		Data:      string(l.Redactor.Redact(data)),
+		PrevHash:  l.prev,
+	}
+	if rpcErr != nil {
+		r.Error = l.Redactor.RedactString(rpcErr.Error())
+	}
+	hash, err := r.hash()
+	if err != nil {
+		return err
+	}
+	r.Hash = hash
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := l.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	l.seq, l.prev = r.Seq, r.Hash
+	return nil
+}
+
+// Interceptor returns an interceptor recording every request and its reply.
+// The RPC fails if its request cannot be recorded, so nothing is sent
+// unaudited.  Streamed requests are copied into the record while they are
+// written, see auditStreamLimit, and recorded before the message is
+// completed.
+func (l *AuditLog) Interceptor() Interceptor {
+	return func(ctx context.Context, methods []RPCMethod, invoke Invoker) (*RPCReply, error) {
+		var op string
+		if len(methods) > 0 {
+			op = methodName(methods[0])
+		}
+		methods = append([]RPCMethod(nil), methods...)
+		var last *auditedMethod
+		for i, m := range methods {
+			mw, ok := m.(MethodWriter)
+			if _, named := m.(interface{ methodName() string }); ok && named {
+				// Marshaling would consume a streamed request.
+				last = &auditedMethod{MethodWriter: mw, name: methodName(m), hash: sha256.New()}
+				methods[i] = last
+			}
+		}
+
+		var once sync.Once
+		var recordErr error
+		record := func() error {
+			once.Do(func() {
+				var req strings.Builder
+				for _, m := range methods {
+					if a, ok := m.(*auditedMethod); ok {
+						req.WriteString(a.String())
+						continue
+					}
+					req.WriteString(m.MarshalMethod())
+				}
+				if err := l.Record(AuditRequest, op, []byte(req.String()), nil); err != nil {
+					recordErr = fmt.Errorf("netconf: audit log: %v", err)
+				}
+			})
+			return recordErr
+		}
+		if last == nil {
+			if err := record(); err != nil {
+				return nil, err
+			}
+		} else {
+			last.done = record
+		}
+
+		reply, err := invoke(ctx, methods)
+		if last != nil {
+			// Record requests that were never written, too.
+			if rerr := record(); rerr != nil && err == nil {
+				err = rerr
+			}
+		}
+		var data []byte
+		if reply != nil {
+			data = reply.RawReply
+		}
+		if aerr := l.Record(AuditReply, op, data, err); aerr != nil && err == nil {
+			err = fmt.Errorf("netconf: audit log: %v", aerr)
+		}
+		return reply, err
+	}
+}
+
+// auditStreamLimit bounds the part of a streamed request copied into its
+// audit record.  Longer requests are recorded truncated, along with their
+// size and hash.
+const auditStreamLimit = 1 << 20
+
+// auditedMethod copies a streamed method into its audit record while it is
+// written.  Once the last streamed method of a request has been written,
+// done records the request; an error aborts the message.
+type auditedMethod struct {
+	MethodWriter
+	name string
+	done func() error
+
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	hash hash.Hash
+	size int64
+}
+
+func (m *auditedMethod) methodName() string {
+	return m.name
+}
+
+// WriteMethod writes the method to w, copying it.
+func (m *auditedMethod) WriteMethod(w io.Writer) error {
+	if err := m.MethodWriter.WriteMethod(io.MultiWriter(w, m)); err != nil {
+		return err
+	}
+	if m.done != nil {
+		return m.done()
+	}
+	return nil
+}
+
+// Write copies p.
+func (m *auditedMethod) Write(p []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hash.Write(p)
+	m.size += int64(len(p))
+	if room := auditStreamLimit - m.buf.Len(); room < len(p) {
+		m.buf.Write(p[:room])
+	} else {
+		m.buf.Write(p)
+	}
+	return len(p), nil
+}
+
+// String returns the method written so far.
+func (m *auditedMethod) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.size <= int64(m.buf.Len()) {
+		return m.buf.String()
+	}
+	return fmt.Sprintf("%s<!-- truncated, %d bytes, sha256 %x -->", m.buf.String(), m.size, m.hash.Sum(nil))
+}
+
+// AuditChainError reports a broken audit log chain.
+type AuditChainError struct {
+	// Line is the line of the offending record, starting at 1.
+	Line int
+	Msg  string
+}
+
+func (e *AuditChainError) Error() string {
+	return fmt.Sprintf("netconf: audit log line %d: %s", e.Line, e.Msg)
+}
+
+// VerifyAuditLog checks the chain of the audit log read from r and returns
+// the number of records.  A broken chain is reported with an
+// *AuditChainError.  Verification proves the records were not altered after
+// they were written, unless the whole log from the altered record on was
+// rewritten; keep the hash of the last record elsewhere to detect that.
+func VerifyAuditLog(r io.Reader) (int, error) {
+	_, n, err := verifyAuditLog(r)
+	return n, err
+}
+
+func verifyAuditLog(r io.Reader) (*AuditRecord, int, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 64<<20)
+	var last *AuditRecord
+	line, n := 0, 0
+	for sc.Scan() {
+		line++
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+		rec := &AuditRecord{}
+		if err := json.Unmarshal(sc.Bytes(), rec); err != nil {
+			return nil, n, &AuditChainError{Line: line, Msg: err.Error()}
+		}
+
+		var prevSeq uint64
+		var prevHash string
+		if last != nil {
+			prevSeq, prevHash = last.Seq, last.Hash
+		}
+		switch {
+		case rec.Seq != prevSeq+1:
+			return nil, n, &AuditChainError{Line: line, Msg: fmt.Sprintf("sequence %d follows %d", rec.Seq, prevSeq)}
+		case rec.PrevHash != prevHash:
+			return nil, n, &AuditChainError{Line: line, Msg: "previous hash mismatch"}
+		}
+		hash, err := rec.hash()
+		if err != nil {
+			return nil, n, err
+		}
+		if hash != rec.Hash {
+			return nil, n, &AuditChainError{Line: line, Msg: "record hash mismatch"}
+		}
+		last = rec
+		n++
+	}
+	return last, n, sc.Err()
+}
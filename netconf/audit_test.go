@@ -0,0 +1,116 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewAuditLog(&buf)
+	log.Device = "r1"
+
+	s, _ := newScriptedSession(nil, replyOK, replyError("lock-denied"))
+	s.Interceptors = []Interceptor{log.Interceptor()}
+	if _, err := s.Exec(MethodGetConfig("running")); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if _, err := s.Exec(MethodLock("candidate")); err == nil {
+		t.Fatal("expected rpc-error")
+	}
+
+	n, err := VerifyAuditLog(bytes.NewReader(buf.Bytes()))
+	if err != nil || n != 4 {
+		t.Fatalf("VerifyAuditLog returned %d, %v", n, err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for i, want := range []string{`"direction":"request","operation":"get-config"`, `"direction":"reply"`, `"operation":"lock"`, `"error":`} {
+		if !strings.Contains(lines[i], want) || !strings.Contains(lines[i], `"device":"r1"`) {
+			t.Errorf("record %d: expected %s in %s", i+1, want, lines[i])
+		}
+	}
+
+	// Resuming continues the chain.
+	resumed, err := ResumeAuditLog(&buf, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ResumeAuditLog failed: %v", err)
+	}
+	if err := resumed.Record(AuditRequest, "commit", []byte("<commit/>"), nil); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if n, err := VerifyAuditLog(bytes.NewReader(buf.Bytes())); err != nil || n != 5 {
+		t.Fatalf("VerifyAuditLog after resume returned %d, %v", n, err)
+	}
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	tt := []struct {
+		name  string
+		lines []string
+		line  int
+	}{
+		{"altered data", replaceLine(lines, 1, strings.Replace(lines[1], "ok", "no", 1)), 2},
+		{"removed record", append(append([]string(nil), lines[:2]...), lines[3:]...), 3},
+		{"reordered records", append([]string{lines[1], lines[0]}, lines[2:]...), 1},
+		{"truncated head", lines[1:], 1},
+		{"garbage", replaceLine(lines, 4, "{"), 5},
+	}
+	for _, tc := range tt {
+		_, err := VerifyAuditLog(strings.NewReader(strings.Join(tc.lines, "\n")))
+		var cerr *AuditChainError
+		if !errors.As(err, &cerr) || cerr.Line != tc.line {
+			t.Errorf("%s: expected chain error at line %d, got %v", tc.name, tc.line, err)
+		}
+	}
+	if _, err := ResumeAuditLog(&buf, strings.NewReader(strings.Join(lines[1:], "\n"))); err == nil {
+		t.Error("expected ResumeAuditLog to reject a broken chain")
+	}
+}
+
+func TestAuditLogStreamedRequest(t *testing.T) {
+	var buf bytes.Buffer
+	s, trans := newScriptedSession(nil, replyOK)
+	s.Interceptors = []Interceptor{NewAuditLog(&buf).Interceptor()}
+	if _, err := s.Exec(MethodEditConfigReader("candidate", strings.NewReader("<system/>"))); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if len(trans.sent) != 1 || !strings.Contains(trans.sent[0], "<config><system/></config>") {
+		t.Errorf("streamed configuration consumed by the audit log: %q", trans.sent)
+	}
+	if !strings.Contains(buf.String(), `"operation":"edit-config"`) || !strings.Contains(buf.String(), `\u003cconfig\u003e\u003csystem/\u003e\u003c/config\u003e`) {
+		t.Errorf("streamed configuration not recorded: %s", buf.String())
+	}
+
+	var m auditedMethod
+	m.hash = sha256.New()
+	big := strings.Repeat("x", auditStreamLimit+10)
+	m.Write([]byte(big))
+	if got := m.String(); !strings.HasPrefix(got, big[:auditStreamLimit]+"<!-- truncated, 1048586 bytes, sha256 ") {
+		t.Errorf("unexpected truncated record %q", got[auditStreamLimit:])
+	}
+}
+
+func TestAuditLogStreamedRequestFailure(t *testing.T) {
+	s, trans := newScriptedSession(nil, replyOK)
+	s.Interceptors = []Interceptor{NewAuditLog(failingWriter{}).Interceptor()}
+	if _, err := s.Exec(MethodEditConfigReader("candidate", strings.NewReader("<system/>"))); err == nil {
+		t.Fatal("expected the unrecorded request to fail")
+	}
+	if len(trans.sent) != 0 {
+		t.Errorf("unaudited request sent: %q", trans.sent)
+	}
+}
+
+func replaceLine(lines []string, i int, line string) []string {
+	out := append([]string(nil), lines...)
+	out[i] = line
+	return out
+}
@@ -0,0 +1,107 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// BandwidthLimiter caps the rate of bytes written to connections, so large
+// configuration pushes over shared low-bandwidth management links do not
+// starve other traffic.  A single limiter may be shared by the connections
+// using the same link.
+type BandwidthLimiter struct {
+	rate  float64
+	burst int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewBandwidthLimiter returns a limiter allowing bytesPerSecond on average
+// and bursts of up to burst bytes.  A burst below 1 selects a tenth of a
+// second worth of bytes, but at least 512.
+func NewBandwidthLimiter(bytesPerSecond, burst int) *BandwidthLimiter {
+	if burst < 1 {
+		burst = bytesPerSecond / 10
+		if burst < 512 {
+			burst = 512
+		}
+	}
+	return &BandwidthLimiter{rate: float64(bytesPerSecond), burst: burst, tokens: float64(burst)}
+}
+
+// reserve takes n bytes, at most the burst, from the bucket and returns how
+// long to wait before writing them.
+func (l *BandwidthLimiter) reserve(n int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// Writer returns a writer passing data to w at the rate of the limiter.
+func (l *BandwidthLimiter) Writer(w io.Writer) io.Writer {
+	return &throttledWriter{w: w, l: l}
+}
+
+// Conn returns a connection whose writes are throttled by the limiter.
+func (l *BandwidthLimiter) Conn(c net.Conn) net.Conn {
+	return &throttledConn{Conn: c, w: throttledWriter{w: c, l: l}}
+}
+
+type throttledWriter struct {
+	w io.Writer
+	l *BandwidthLimiter
+}
+
+// Write writes p in pieces of at most the burst size, waiting for the
+// bucket to allow each.
+func (t *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := len(p)
+		if n > t.l.burst {
+			n = t.l.burst
+		}
+		if d := t.l.reserve(n); d > 0 {
+			time.Sleep(d)
+		}
+		m, err := t.w.Write(p[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+type throttledConn struct {
+	net.Conn
+	w throttledWriter
+}
+
+func (c *throttledConn) Write(p []byte) (int, error) {
+	return c.w.Write(p)
+}
@@ -0,0 +1,53 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestBandwidthLimiter(t *testing.T) {
+	l := NewBandwidthLimiter(20000, 1000)
+	var a, b bytes.Buffer
+	wa, wb := l.Writer(&a), l.Writer(&b)
+
+	start := time.Now()
+	// The burst is free, the remaining 4000 bytes take 200ms.
+	if n, err := wa.Write(make([]byte, 3000)); n != 3000 || err != nil {
+		t.Fatalf("Write returned %d, %v", n, err)
+	}
+	if n, err := wb.Write(make([]byte, 2000)); n != 2000 || err != nil {
+		t.Fatalf("Write returned %d, %v", n, err)
+	}
+	elapsed := time.Since(start)
+	if elapsed < 180*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("expected shared limit to take about 200ms, took %s", elapsed)
+	}
+	if a.Len() != 3000 || b.Len() != 2000 {
+		t.Errorf("unexpected lengths %d and %d", a.Len(), b.Len())
+	}
+
+	if l := NewBandwidthLimiter(1000, 0); l.burst != 512 {
+		t.Errorf("unexpected default burst %d", l.burst)
+	}
+}
+
+func TestDialBandwidthLimit(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+
+	s, err := Dial(srv.Addr(), WithSSHConfig(testSSHConfig()), WithBandwidthLimit(NewBandwidthLimiter(1<<20, 0)))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer s.Close()
+	if _, err := s.Exec(MethodGetConfig("running")); err != nil {
+		t.Errorf("Exec failed: %v", err)
+	}
+}
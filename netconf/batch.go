@@ -0,0 +1,140 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"strings"
+)
+
+// ConfigBatch accumulates configuration fragments, possibly of different
+// modules, and merges them into a single <config> tree so that a change set
+// can be applied with one edit-config.
+//
+// Elements with the same name and attributes are merged into one.  List
+// entries are told apart by the keys given in ListKeys, or by their <name>
+// child.  Elements holding a value are treated as leaf-list entries:
+// entries with the same value are merged, others are kept side by side.
+type ConfigBatch struct {
+	// ListKeys maps the local name of list elements to the names of their
+	// key leaves, see DiffOptions.
+	ListKeys map[string][]string
+
+	nodes []*Node
+}
+
+// NewConfigBatch returns an empty batch.
+func NewConfigBatch() *ConfigBatch {
+	return &ConfigBatch{}
+}
+
+// Add parses a configuration fragment and merges it into the batch.  A
+// <config> or <data> wrapper element around the fragment is ignored.
+func (b *ConfigBatch) Add(fragment string) error {
+	root, err := configRoot([]byte(fragment))
+	if err != nil {
+		return err
+	}
+	b.AddNode(root.Children...)
+	return nil
+}
+
+// AddNode merges copies of the given top-level elements into the batch.
+func (b *ConfigBatch) AddNode(nodes ...*Node) {
+	for _, n := range nodes {
+		b.nodes = b.merge(b.nodes, n.Clone())
+	}
+}
+
+// Nodes returns the top-level elements of the merged configuration.
+func (b *ConfigBatch) Nodes() []*Node {
+	return b.nodes
+}
+
+// Empty reports whether nothing was added to the batch.
+func (b *ConfigBatch) Empty() bool {
+	return len(b.nodes) == 0
+}
+
+// String returns the merged configuration without <config> wrapper.
+func (b *ConfigBatch) String() string {
+	var s strings.Builder
+	for _, n := range b.nodes {
+		s.WriteString(n.String())
+	}
+	return s.String()
+}
+
+// EditConfig returns an edit-config request of the target datastore
+// carrying the merged configuration.
+func (b *ConfigBatch) EditConfig(target string) RawMethod {
+	return MethodEditConfig(target, b.String())
+}
+
+// merge adds n to siblings, merging it with a matching sibling if there is
+// one.
+func (b *ConfigBatch) merge(siblings []*Node, n *Node) []*Node {
+	for _, s := range siblings {
+		if !b.same(s, n) {
+			continue
+		}
+		if n.IsLeaf() {
+			return siblings
+		}
+		for _, c := range n.Children {
+			s.Children = b.merge(s.Children, c)
+		}
+		return siblings
+	}
+	return append(siblings, n)
+}
+
+// same reports whether x and y denote the same configuration node.
+func (b *ConfigBatch) same(x, y *Node) bool {
+	if nodeName(x) != nodeName(y) || !sameAttrs(x, y) {
+		return false
+	}
+	if x.IsLeaf() && y.IsLeaf() {
+		return x.Value() == y.Value()
+	}
+	// An empty element matches a container of the same name.
+	if x.IsLeaf() || y.IsLeaf() {
+		return x.Value() == "" && y.Value() == ""
+	}
+
+	keys, ok := b.ListKeys[x.XMLName.Local]
+	if !ok {
+		keys = []string{"name"}
+	}
+	for _, k := range keys {
+		xk, yk := x.Child(k), y.Child(k)
+		if (xk == nil) != (yk == nil) {
+			return false
+		}
+		if xk != nil && xk.Value() != yk.Value() {
+			return false
+		}
+	}
+	return true
+}
+
+// sameAttrs reports whether a and b carry the same attributes, ignoring
+// namespace declarations.
+func sameAttrs(a, b *Node) bool {
+	return attrsWithin(a, b) && attrsWithin(b, a)
+}
+
+func attrsWithin(a, b *Node) bool {
+	for _, attr := range a.Attrs {
+		if attr.Name.Space == xmlnsPrefix || (attr.Name.Space == "" && attr.Name.Local == xmlnsPrefix) {
+			continue
+		}
+		if v, ok := b.Attr(attr.Name.Space, attr.Name.Local); !ok || v != attr.Value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConfigBatch(t *testing.T) {
+	tt := []struct {
+		name      string
+		listKeys  map[string][]string
+		fragments []string
+		expected  string
+	}{
+		{
+			name: "parents",
+			fragments: []string{
+				`<config><system><host-name>r1</host-name></system></config>`,
+				`<system><services/></system>`,
+				`<system><services><ssh/></services></system>`,
+				`<system><services><netconf/></services></system>`,
+			},
+			expected: `<system><host-name>r1</host-name><services><ssh/><netconf/></services></system>`,
+		},
+		{
+			name: "namespaces",
+			fragments: []string{
+				`<interfaces xmlns="urn:a"><interface><name>ge-0</name><mtu>1500</mtu></interface></interfaces>`,
+				`<system xmlns="urn:b"><host-name>r1</host-name></system>`,
+				`<interfaces xmlns="urn:a"><interface><name>ge-0</name><enabled/></interface><interface><name>ge-1</name></interface></interfaces>`,
+			},
+			expected: `<interfaces xmlns="urn:a"><interface><name>ge-0</name><mtu>1500</mtu><enabled/></interface><interface><name>ge-1</name></interface></interfaces>` +
+				`<system xmlns="urn:b"><host-name>r1</host-name></system>`,
+		},
+		{
+			name:     "keys",
+			listKeys: map[string][]string{"route": {"prefix"}},
+			fragments: []string{
+				`<routes><route><prefix>10/8</prefix><hop>a</hop></route></routes>`,
+				`<routes><route><prefix>10/8</prefix><metric>5</metric></route><route><prefix>11/8</prefix></route></routes>`,
+			},
+			expected: `<routes><route><prefix>10/8</prefix><hop>a</hop><metric>5</metric></route><route><prefix>11/8</prefix></route></routes>`,
+		},
+		{
+			name: "leaf-list",
+			fragments: []string{
+				`<dns><server>1.1.1.1</server></dns>`,
+				`<dns><server>1.1.1.1</server><server>8.8.8.8</server></dns>`,
+			},
+			expected: `<dns><server>1.1.1.1</server><server>8.8.8.8</server></dns>`,
+		},
+		{
+			name: "attributes",
+			fragments: []string{
+				`<system><syslog operation="delete"/></system>`,
+				`<system><syslog><host>a</host></syslog></system>`,
+			},
+			expected: `<system><syslog operation="delete"/><syslog><host>a</host></syslog></system>`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewConfigBatch()
+			b.ListKeys = tc.listKeys
+			for _, f := range tc.fragments {
+				if err := b.Add(f); err != nil {
+					t.Fatalf("Add failed: %v", err)
+				}
+			}
+			if diff := cmp.Diff(tc.expected, b.String()); diff != "" {
+				t.Errorf("unexpected configuration (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigBatchInvalid(t *testing.T) {
+	b := NewConfigBatch()
+	if err := b.Add("<system>"); err == nil {
+		t.Error("expected error for malformed fragment")
+	}
+	if !b.Empty() {
+		t.Error("expected batch to stay empty")
+	}
+}
@@ -0,0 +1,85 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	b := NewCircuitBreaker(2, 20*time.Millisecond)
+	fail := func() error { return errors.New("boom") }
+
+	b.Do(fail)
+	if b.State() != BreakerClosed {
+		t.Fatalf("breaker opened after a single failure")
+	}
+	b.Do(fail)
+	if b.State() != BreakerOpen {
+		t.Fatalf("breaker not opened after threshold, state %s", b.State())
+	}
+	if err := b.Allow(); err != ErrCircuitOpen {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected half-open probe to be allowed, got %v", err)
+	}
+	if err := b.Allow(); err != ErrCircuitOpen {
+		t.Fatalf("expected a single probe, got %v", err)
+	}
+	b.Failure()
+	if b.State() != BreakerOpen {
+		t.Fatalf("failed probe did not reopen breaker, state %s", b.State())
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if err := b.Do(func() error { return nil }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.State() != BreakerClosed {
+		t.Fatalf("successful probe did not close breaker, state %s", b.State())
+	}
+}
+
+func TestFleetBreaker(t *testing.T) {
+	f := newFleetTest("unreachable")
+	f.BreakerThreshold = 1
+	f.BreakerCooldown = time.Hour
+
+	job := func(ctx context.Context, s *Session, r *DeviceResult) error { return nil }
+	if r := f.Run(context.Background(), job).Results[0]; r.Status != StatusFailed {
+		t.Errorf("first run: got status %s, expected failed", r.Status)
+	}
+	if r := f.Run(context.Background(), job).Results[0]; r.Status != StatusSkipped {
+		t.Errorf("second run: got status %s, expected skipped", r.Status)
+	}
+
+	tt := []struct {
+		err  error
+		open bool
+	}{
+		{errors.New("validation failed"), false},
+		{&RPCError{Severity: "error", Tag: "in-use"}, false},
+		{&TransportError{Op: "read", Err: io.EOF}, true},
+		{&TimeoutError{Op: "read"}, true},
+	}
+	for _, tc := range tt {
+		f := newFleetTest("r1")
+		f.BreakerThreshold = 1
+		f.BreakerCooldown = time.Hour
+		f.Run(context.Background(), func(ctx context.Context, s *Session, r *DeviceResult) error { return tc.err })
+		if open := f.deviceBreaker(f.Devices[0]).State() == BreakerOpen; open != tc.open {
+			t.Errorf("%v: got breaker open %v, expected %v", tc.err, open, tc.open)
+		}
+	}
+}
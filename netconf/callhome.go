@@ -0,0 +1,247 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Call Home ports assigned by RFC 8071.
+const (
+	CallHomeSSHPort = 4334
+	CallHomeTLSPort = 4335
+)
+
+// DefaultCallHomeHandshakeTimeout bounds the session setup of Call Home
+// connections if CallHomeListener.HandshakeTimeout is not set.
+const DefaultCallHomeHandshakeTimeout = 30 * time.Second
+
+// CallHomeListener accepts NETCONF Call Home connections (RFC 8071), where
+// the device connects to the client.  The client then acts as SSH or TLS
+// client on the accepted connection, depending on which of SSHConfig and
+// TLSConfig is set.
+//
+// Devices are identified by the fingerprint of their TLS certificate or SSH
+// host key, which is looked up in Inventory before the session is handed to
+// the application.  Connections of unknown devices are rejected.  If
+// Inventory is nil TLSConfig.RootCAs or SSHConfig.HostKeyCallback must
+// authenticate the devices instead, connections being rejected without.
+type CallHomeListener struct {
+	Listener  net.Listener
+	SSHConfig *ssh.ClientConfig
+	TLSConfig *tls.Config
+	Inventory *Inventory
+	// OnReject, if set, is called for each connection that was rejected.
+	OnReject func(addr net.Addr, err error)
+	// HandshakeTimeout bounds the TLS or SSH handshake and the hello
+	// exchange of each connection, DefaultCallHomeHandshakeTimeout if zero.
+	// Connections are set up one at a time, so a device that stalls holds
+	// up the others until it is rejected.
+	HandshakeTimeout time.Duration
+}
+
+// CallHomeSession is a session established by a device calling home.
+type CallHomeSession struct {
+	*Session
+	// Device is the inventory entry of the device, nil without inventory.
+	Device *Device
+	// Fingerprint of the device's certificate or host key.
+	Fingerprint string
+}
+
+// ListenCallHomeTLS listens for TLS Call Home connections on addr, e.g.
+// ":4335".
+func ListenCallHomeTLS(addr string, config *tls.Config, inv *Inventory) (*CallHomeListener, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &CallHomeListener{Listener: l, TLSConfig: config, Inventory: inv}, nil
+}
+
+// ListenCallHomeSSH listens for SSH Call Home connections on addr, e.g.
+// ":4334".
+func ListenCallHomeSSH(addr string, config *ssh.ClientConfig, inv *Inventory) (*CallHomeListener, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &CallHomeListener{Listener: l, SSHConfig: config, Inventory: inv}, nil
+}
+
+// Accept waits for the next device to call home and returns its session.
+// Connections that fail to set up a session are rejected and Accept keeps
+// waiting; only errors of the listener itself are returned.
+func (l *CallHomeListener) Accept() (*CallHomeSession, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		timeout := l.HandshakeTimeout
+		if timeout <= 0 {
+			timeout = DefaultCallHomeHandshakeTimeout
+		}
+		conn.SetDeadline(time.Now().Add(timeout))
+		s, err := l.setup(conn)
+		if err == nil {
+			conn.SetDeadline(time.Time{})
+			return s, nil
+		}
+		conn.Close()
+		if l.OnReject != nil {
+			l.OnReject(conn.RemoteAddr(), err)
+		}
+	}
+}
+
+// Close stops listening.  Established sessions are not affected.
+func (l *CallHomeListener) Close() error {
+	return l.Listener.Close()
+}
+
+func (l *CallHomeListener) setup(conn net.Conn) (*CallHomeSession, error) {
+	switch {
+	case l.TLSConfig != nil:
+		return l.setupTLS(conn)
+	case l.SSHConfig != nil:
+		return l.setupSSH(conn)
+	}
+	return nil, fmt.Errorf("netconf: call home listener has neither TLS nor SSH config")
+}
+
+func (l *CallHomeListener) setupTLS(conn net.Conn) (*CallHomeSession, error) {
+	// The device's address is not known in advance, so its certificate is
+	// verified without host name and identified by its fingerprint.
+	config := l.TLSConfig.Clone()
+	roots := config.RootCAs
+	config.InsecureSkipVerify = true
+	config.VerifyPeerCertificate = func(raw [][]byte, _ [][]*x509.Certificate) error {
+		return verifyCallHomeChain(raw, roots, l.Inventory == nil)
+	}
+
+	tc := tls.Client(conn, config)
+	if err := tc.Handshake(); err != nil {
+		return nil, err
+	}
+	fp := CertificateFingerprint(tc.ConnectionState().PeerCertificates[0])
+
+	d, err := l.identify(fp)
+	if err != nil {
+		return nil, err
+	}
+	s, err := newSession(NewTransportConn(tc))
+	if err != nil {
+		return nil, err
+	}
+	return &CallHomeSession{Session: s, Device: d, Fingerprint: fp}, nil
+}
+
+func (l *CallHomeListener) setupSSH(conn net.Conn) (*CallHomeSession, error) {
+	config := *l.SSHConfig
+	var fp string
+	config.HostKeyCallback = func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		fp = ssh.FingerprintSHA256(key)
+		if l.SSHConfig.HostKeyCallback != nil {
+			return l.SSHConfig.HostKeyCallback(hostname, remote, key)
+		}
+		if l.Inventory == nil {
+			return fmt.Errorf("netconf: no inventory or host key callback to authenticate device")
+		}
+		return nil
+	}
+
+	c, chans, reqs, err := ssh.NewClientConn(conn, conn.RemoteAddr().String(), &config)
+	if err != nil {
+		return nil, err
+	}
+	d, err := l.identify(fp)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	t := &TransportSSH{sshClient: ssh.NewClient(c, chans, reqs)}
+	if err := t.setupSession(); err != nil {
+		t.Close()
+		return nil, err
+	}
+	s, err := newSession(t)
+	if err != nil {
+		t.Close()
+		return nil, err
+	}
+	return &CallHomeSession{Session: s, Device: d, Fingerprint: fp}, nil
+}
+
+// identify maps a fingerprint to its inventory entry.
+func (l *CallHomeListener) identify(fp string) (*Device, error) {
+	if l.Inventory == nil {
+		return nil, nil
+	}
+	d := l.Inventory.DeviceByFingerprint(fp)
+	if d == nil {
+		return nil, fmt.Errorf("netconf: unknown device with fingerprint %s", fp)
+	}
+	return d, nil
+}
+
+// verifyCallHomeChain verifies the device certificate against roots.
+// Without roots the certificate is accepted unless roots are required, as
+// the device is then authenticated by its fingerprint alone.
+func verifyCallHomeChain(raw [][]byte, roots *x509.CertPool, required bool) error {
+	if len(raw) == 0 {
+		return fmt.Errorf("netconf: device presented no certificate")
+	}
+	if roots == nil {
+		if required {
+			return fmt.Errorf("netconf: no inventory or root CAs to authenticate device")
+		}
+		return nil
+	}
+
+	certs := make([]*x509.Certificate, len(raw))
+	for i, der := range raw {
+		c, err := x509.ParseCertificate(der)
+		if err != nil {
+			return err
+		}
+		certs[i] = c
+	}
+	opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+	for _, c := range certs[1:] {
+		opts.Intermediates.AddCert(c)
+	}
+	_, err := certs[0].Verify(opts)
+	return err
+}
+
+// CertificateFingerprint returns the hex encoded SHA-256 fingerprint of a
+// certificate, as used to identify calling home devices.
+func CertificateFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeFingerprint canonicalizes hex fingerprints.  SSH fingerprints
+// are base64 encoded and compared as is.
+func normalizeFingerprint(fp string) string {
+	if strings.HasPrefix(fp, "SHA256:") {
+		return fp
+	}
+	return strings.ToLower(strings.Replace(fp, ":", "", -1))
+}
@@ -0,0 +1,161 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// callHome connects to addr as a TLS Call Home device presenting cert.
+func callHome(t *testing.T, addr string, cert tls.Certificate) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	go serveTestNETCONF(tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}}))
+}
+
+func TestCallHomeTLS(t *testing.T) {
+	ca := newTestCA(t)
+	known := ca.tlsCert(t, "edge1", 20)
+	unknown := ca.tlsCert(t, "rogue", 21)
+	leaf, err := x509.ParseCertificate(known.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inv := &Inventory{Devices: []*Device{
+		{Name: "edge1", Address: "192.0.2.1", Fingerprint: strings.ToUpper(CertificateFingerprint(leaf))},
+	}}
+	l, err := ListenCallHomeTLS("127.0.0.1:0", &tls.Config{RootCAs: ca.pool}, inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	rejected := make(chan error, 1)
+	l.OnReject = func(addr net.Addr, err error) { rejected <- err }
+
+	// Connections are accepted in order, so the unknown device is rejected
+	// before the known one is accepted.
+	callHome(t, l.Listener.Addr().String(), unknown)
+	callHome(t, l.Listener.Addr().String(), known)
+
+	s, err := l.Accept()
+	if err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	defer s.Close()
+
+	if err := <-rejected; !strings.Contains(err.Error(), "unknown device") {
+		t.Errorf("unexpected rejection %v", err)
+	}
+	if s.Device == nil || s.Device.Name != "edge1" {
+		t.Fatalf("expected device edge1, got %+v", s.Device)
+	}
+	if _, err := s.Exec(MethodGetConfig("running")); err != nil {
+		t.Errorf("Exec failed: %v", err)
+	}
+}
+
+func TestCallHomeHandshakeTimeout(t *testing.T) {
+	ca := newTestCA(t)
+	known := ca.tlsCert(t, "edge1", 20)
+	l, err := ListenCallHomeTLS("127.0.0.1:0", &tls.Config{RootCAs: ca.pool}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	l.HandshakeTimeout = 50 * time.Millisecond
+	rejected := make(chan error, 1)
+	l.OnReject = func(addr net.Addr, err error) { rejected <- err }
+
+	// A device that connects but never speaks must not block the others.
+	stalled, err := net.Dial("tcp", l.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stalled.Close()
+	callHome(t, l.Listener.Addr().String(), known)
+
+	accepted := make(chan *CallHomeSession, 1)
+	go func() {
+		s, err := l.Accept()
+		if err != nil {
+			t.Errorf("Accept failed: %v", err)
+		}
+		accepted <- s
+	}()
+	select {
+	case s := <-accepted:
+		if s != nil {
+			s.Close()
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Accept blocked by a stalled connection")
+	}
+	if err := <-rejected; err == nil {
+		t.Error("expected the stalled connection to be rejected")
+	}
+}
+
+func TestCallHomeSSHHostKey(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+
+	tt := []struct {
+		name     string
+		callback ssh.HostKeyCallback
+		accepted bool
+	}{
+		{name: "no inventory or callback"},
+		{name: "callback", callback: ssh.InsecureIgnoreHostKey(), accepted: true},
+	}
+	for _, tc := range tt {
+		l, err := ListenCallHomeSSH("127.0.0.1:0", &ssh.ClientConfig{User: "test", HostKeyCallback: tc.callback}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rejected := make(chan error, 1)
+		l.OnReject = func(addr net.Addr, err error) { rejected <- err }
+		conn, err := net.Dial("tcp", l.Listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		go srv.serveConn(conn)
+
+		accepted := make(chan *CallHomeSession, 1)
+		go func() {
+			s, _ := l.Accept()
+			accepted <- s
+		}()
+		select {
+		case s := <-accepted:
+			if !tc.accepted || s == nil {
+				t.Errorf("%s: got session %v, expected accepted %v", tc.name, s, tc.accepted)
+			}
+			if s != nil {
+				s.Close()
+			}
+		case err := <-rejected:
+			if tc.accepted || !strings.Contains(err.Error(), "host key callback") {
+				t.Errorf("%s: unexpected rejection %v", tc.name, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: no session accepted or rejected", tc.name)
+		}
+		l.Close()
+	}
+}
@@ -0,0 +1,204 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"time"
+)
+
+// commitRedialInterval is the time between attempts to redial a failed
+// session to confirm or cancel a persistent confirmed commit.
+const commitRedialInterval = time.Second
+
+// CandidateSession wraps a Session to work with the candidate datastore.
+type CandidateSession struct {
+	*Session
+	// DiffOptions is used by Compare.
+	DiffOptions *DiffOptions
+	// Redial, if set, is used by CommitConfirmed to open a new session to
+	// the device if the session fails before the commit is confirmed.
+	Redial func(ctx context.Context) (*Session, error)
+}
+
+// CommitVerifyError is returned by CommitConfirmed if the verification of
+// the commit failed.
+type CommitVerifyError struct {
+	// Err is the error of the verification.
+	Err error
+	// Cancelled reports whether the commit was cancelled.  Otherwise the
+	// device rolls back once the confirm timeout expires.
+	Cancelled bool
+}
+
+func (e *CommitVerifyError) Error() string {
+	if e.Cancelled {
+		return "netconf: commit verification failed, rolled back: " + e.Err.Error()
+	}
+	return "netconf: commit verification failed, rolling back on timeout: " + e.Err.Error()
+}
+
+func (e *CommitVerifyError) Unwrap() error {
+	return e.Err
+}
+
+// NewCandidateSession returns a CandidateSession for s.  It fails if the
+// server does not support the candidate datastore.
+func NewCandidateSession(s *Session) (*CandidateSession, error) {
+	if err := s.requireCapability("candidate datastore", CapabilityCandidate); err != nil {
+		return nil, err
+	}
+	return &CandidateSession{Session: s}, nil
+}
+
+// Lock locks the candidate datastore.
+func (c *CandidateSession) Lock(ctx context.Context) error {
+	_, err := c.ExecContext(ctx, MethodLock("candidate"))
+	return err
+}
+
+// Unlock unlocks the candidate datastore.
+func (c *CandidateSession) Unlock(ctx context.Context) error {
+	_, err := c.ExecContext(ctx, MethodUnlock("candidate"))
+	return err
+}
+
+// Load merges config into the candidate datastore.
+func (c *CandidateSession) Load(ctx context.Context, config string) error {
+	_, err := c.ExecContext(ctx, MethodEditConfig("candidate", config))
+	return err
+}
+
+// Validate validates the content of the candidate datastore.
+func (c *CandidateSession) Validate(ctx context.Context) error {
+	if err := c.requireCapability("validate", CapabilityValidate); err != nil {
+		return err
+	}
+	_, err := c.ExecContext(ctx, MethodValidate("candidate"))
+	return err
+}
+
+// Compare returns the differences between the running and the candidate
+// datastore.
+func (c *CandidateSession) Compare(ctx context.Context) (*Delta, error) {
+	running, err := c.ExecContext(ctx, MethodGetConfig("running"))
+	if err != nil {
+		return nil, err
+	}
+	candidate, err := c.ExecContext(ctx, MethodGetConfig("candidate"))
+	if err != nil {
+		return nil, err
+	}
+	return Diff(running.Data, candidate.Data, c.DiffOptions)
+}
+
+// Commit commits the candidate datastore to running.
+func (c *CandidateSession) Commit(ctx context.Context) error {
+	_, err := c.ExecContext(ctx, MethodCommit())
+	return err
+}
+
+// ConfirmedCommit commits the candidate datastore to running, reverting
+// the commit unless it is confirmed with Commit within timeout.
+func (c *CandidateSession) ConfirmedCommit(ctx context.Context, timeout time.Duration) error {
+	if err := c.requireCapability("confirmed-commit", CapabilityConfirmedCommit); err != nil {
+		return err
+	}
+	_, err := c.ExecContext(ctx, MethodConfirmedCommit(timeout))
+	return err
+}
+
+// CommitConfirmed commits the candidate datastore to running with a confirmed
+// commit and runs verify, e.g. a reachability probe.  If verify succeeds the
+// commit is confirmed.  Otherwise it is cancelled, so the device rolls back,
+// and a *CommitVerifyError is returned.  A commit that is not confirmed
+// within timeout, 600 seconds if zero, is rolled back by the device.
+//
+// On servers announcing :confirmed-commit:1.1 the commit is persistent, so
+// if the session fails during verification, e.g. because the change cut it,
+// the commit is confirmed over a new session opened with Redial, which then
+// replaces the session of c.  Without Redial, or with :confirmed-commit:1.0,
+// the device rolls back if the session fails.
+func (c *CandidateSession) CommitConfirmed(ctx context.Context, timeout time.Duration, verify func() error) error {
+	if err := c.requireCapability("confirmed-commit", CapabilityConfirmedCommit); err != nil {
+		return err
+	}
+	if timeout <= 0 {
+		timeout = 600 * time.Second
+	}
+
+	var persist string
+	commit := MethodConfirmedCommit(timeout)
+	if c.persistentCommit() {
+		persist = uuid()
+		commit = MethodPersistConfirmedCommit(timeout, persist)
+	}
+	if _, err := c.ExecContext(ctx, commit); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+
+	if err := verify(); err != nil {
+		// Roll back right away rather than on timeout, if possible.
+		cancelErr := c.finishCommit(ctx, MethodCancelCommit(persist), persist, deadline)
+		return &CommitVerifyError{Err: err, Cancelled: cancelErr == nil}
+	}
+	confirm := MethodCommit()
+	if persist != "" {
+		confirm = MethodConfirmCommit(persist)
+	}
+	return c.finishCommit(ctx, confirm, persist, deadline)
+}
+
+// persistentCommit reports whether the server supports persistent confirmed
+// commits.  Unlike HasCapability only :confirmed-commit:1.1 counts.
+func (c *CandidateSession) persistentCommit() bool {
+	for _, uri := range c.ServerCapabilities {
+		if capabilityBase(uri) == CapabilityConfirmedCommit {
+			return true
+		}
+	}
+	return false
+}
+
+// finishCommit sends m, confirming or cancelling a confirmed commit.  If the
+// session fails and the commit is persistent, m is sent over a new session
+// opened with Redial, retrying until deadline.
+func (c *CandidateSession) finishCommit(ctx context.Context, m RPCMethod, persist string, deadline time.Time) error {
+	_, err := c.ExecContext(ctx, m)
+	if err == nil || isRPCError(err) || persist == "" || c.Redial == nil {
+		return err
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	for {
+		s, dialErr := c.Redial(ctx)
+		if dialErr == nil {
+			if _, err = s.ExecContext(ctx, m); err == nil || isRPCError(err) {
+				c.Session.Close()
+				c.Session = s
+				return err
+			}
+			s.Close()
+		} else {
+			err = dialErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(commitRedialInterval):
+		}
+	}
+}
+
+// Discard reverts the candidate datastore to the running configuration.
+func (c *CandidateSession) Discard(ctx context.Context) error {
+	_, err := c.ExecContext(ctx, MethodDiscardChanges())
+	return err
+}
@@ -0,0 +1,193 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewCandidateSession(t *testing.T) {
+	s, _ := newScriptedSession([]string{CapabilityBase10})
+	if _, err := NewCandidateSession(s); !errors.Is(err, ErrCapabilityUnsupported) {
+		t.Errorf("expected ErrCapabilityUnsupported without :candidate, got %v", err)
+	}
+}
+
+func TestCandidateSessionCapabilities(t *testing.T) {
+	s, trans := newScriptedSession([]string{CapabilityCandidate})
+	c, err := NewCandidateSession(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var capErr *CapabilityError
+	if err := c.Validate(context.Background()); !errors.As(err, &capErr) || capErr.Capability != CapabilityValidate {
+		t.Errorf("expected missing :validate, got %v", err)
+	}
+	if err := c.ConfirmedCommit(context.Background(), time.Minute); !errors.As(err, &capErr) || capErr.Capability != CapabilityConfirmedCommit {
+		t.Errorf("expected missing :confirmed-commit, got %v", err)
+	}
+	if _, err := s.PartialLock(context.Background(), "/interfaces"); !errors.Is(err, ErrCapabilityUnsupported) {
+		t.Errorf("expected missing :partial-lock, got %v", err)
+	}
+	if len(trans.sent) != 0 {
+		t.Errorf("expected nothing to be sent, got %d requests", len(trans.sent))
+	}
+}
+
+func TestConfirmedCommit(t *testing.T) {
+	s, trans := newScriptedSession([]string{CapabilityCandidate, "urn:ietf:params:netconf:capability:confirmed-commit:1.0"}, replyOK)
+	c, err := NewCandidateSession(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.ConfirmedCommit(context.Background(), 2*time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(trans.sent[0], "<commit><confirmed/><confirm-timeout>120</confirm-timeout></commit>") {
+		t.Errorf("unexpected request: %s", trans.sent[0])
+	}
+}
+
+func TestPartialLock(t *testing.T) {
+	s, trans := newScriptedSession([]string{CapabilityPartialLock},
+		`<rpc-reply><lock-id>7</lock-id><locked-node>/if:interfaces</locked-node></rpc-reply>`,
+		replyOK,
+	)
+	lock, err := s.PartialLock(context.Background(), "/if:interfaces[name='a<b']")
+	if err != nil {
+		t.Fatalf("PartialLock failed: %v", err)
+	}
+	if diff := cmp.Diff(&PartialLock{ID: 7, LockedNodes: []string{"/if:interfaces"}}, lock); diff != "" {
+		t.Errorf("lock mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(trans.sent[0], "<select>/if:interfaces[name=&#39;a&lt;b&#39;]</select>") {
+		t.Errorf("unexpected request: %s", trans.sent[0])
+	}
+
+	if err := s.PartialUnlock(context.Background(), lock); err != nil {
+		t.Fatalf("PartialUnlock failed: %v", err)
+	}
+	if !strings.Contains(trans.sent[1], "<lock-id>7</lock-id>") {
+		t.Errorf("unexpected request: %s", trans.sent[1])
+	}
+}
+
+func TestCandidateSessionCompare(t *testing.T) {
+	s, trans := newScriptedSession([]string{CapabilityCandidate},
+		`<rpc-reply><data><system><host-name>r1</host-name></system></data></rpc-reply>`,
+		`<rpc-reply><data><system><host-name>r2</host-name></system></data></rpc-reply>`,
+	)
+	c, err := NewCandidateSession(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	delta, err := c.Compare(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{`~ /system/host-name: "r1" -> "r2"`}
+	if got := changeStrings(delta.Changes); !cmp.Equal(got, expected) {
+		t.Errorf("unexpected changes:\n%s", cmp.Diff(expected, got))
+	}
+	if ops := trans.operations(); !cmp.Equal(ops, []string{"get-config", "get-config"}) {
+		t.Errorf("unexpected operations: %v", ops)
+	}
+}
+
+func TestCommitConfirmed(t *testing.T) {
+	caps := []string{CapabilityCandidate, CapabilityConfirmedCommit}
+	errProbe := errors.New("unreachable")
+	persist := regexp.MustCompile(`<persist>([^<]+)</persist>`)
+
+	tt := []struct {
+		name     string
+		caps     []string
+		replies  []string
+		verify   error
+		redial   bool
+		expected []string
+		// cancelled is the Cancelled field of the expected CommitVerifyError.
+		cancelled bool
+	}{
+		{
+			name: "confirmed", caps: caps, replies: []string{replyOK, replyOK},
+			expected: []string{"<commit><confirmed/><confirm-timeout>60</confirm-timeout><persist>ID</persist></commit>", "<commit><persist-id>ID</persist-id></commit>"},
+		},
+		{
+			name: "verification failed", caps: caps, replies: []string{replyOK, replyOK}, verify: errProbe,
+			expected:  []string{"<persist>ID</persist>", "<cancel-commit><persist-id>ID</persist-id></cancel-commit>"},
+			cancelled: true,
+		},
+		{
+			name: "not persistent", caps: []string{CapabilityCandidate, "urn:ietf:params:netconf:capability:confirmed-commit:1.0"},
+			replies:  []string{replyOK, replyOK},
+			expected: []string{"<commit><confirmed/><confirm-timeout>60</confirm-timeout></commit>", "<commit/>"},
+		},
+		{
+			name: "redialled", caps: caps, replies: []string{replyOK}, redial: true,
+			expected: []string{"<persist>ID</persist>", "<commit><persist-id>ID</persist-id></commit>", "<commit><persist-id>ID</persist-id></commit>"},
+		},
+		{
+			name: "session lost", caps: caps, replies: []string{replyOK}, verify: errProbe,
+			expected: []string{"<persist>ID</persist>", "<cancel-commit><persist-id>ID</persist-id></cancel-commit>"},
+		},
+	}
+
+	for _, tc := range tt {
+		s, trans := newScriptedSession(tc.caps, tc.replies...)
+		c, err := NewCandidateSession(s)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		sent := func() []string { return trans.sent }
+		var redialled *Session
+		if tc.redial {
+			c.Redial = func(ctx context.Context) (*Session, error) {
+				var redialTrans *scriptedTransport
+				redialled, redialTrans = newScriptedSession(tc.caps, replyOK)
+				sent = func() []string { return append(trans.sent, redialTrans.sent...) }
+				return redialled, nil
+			}
+		}
+
+		err = c.CommitConfirmed(context.Background(), time.Minute, func() error { return tc.verify })
+		var verifyErr *CommitVerifyError
+		if tc.verify == nil && err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		} else if tc.verify != nil && (!errors.As(err, &verifyErr) || !errors.Is(err, tc.verify) || verifyErr.Cancelled != tc.cancelled) {
+			t.Errorf("%s: got %v, expected a CommitVerifyError with Cancelled %v", tc.name, err, tc.cancelled)
+		}
+		if tc.redial && c.Session != redialled {
+			t.Errorf("%s: expected the redialled session to replace the session", tc.name)
+		}
+
+		requests := sent()
+		if len(requests) != len(tc.expected) {
+			t.Errorf("%s: got %d requests, expected %d: %v", tc.name, len(requests), len(tc.expected), requests)
+			continue
+		}
+		var id string
+		if m := persist.FindStringSubmatch(requests[0]); m != nil {
+			id = m[1]
+		}
+		for i, expected := range tc.expected {
+			if expected = strings.Replace(expected, "ID", id, -1); !strings.Contains(requests[i], expected) {
+				t.Errorf("%s: request %d is %s, expected %s", tc.name, i, requests[i], expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Capability URIs defined by RFC 6241 and companion RFCs.
+const (
+	CapabilityBase10          = "urn:ietf:params:netconf:base:1.0"
+	CapabilityBase11          = "urn:ietf:params:netconf:base:1.1"
+	CapabilityWritableRunning = "urn:ietf:params:netconf:capability:writable-running:1.0"
+	CapabilityCandidate       = "urn:ietf:params:netconf:capability:candidate:1.0"
+	CapabilityConfirmedCommit = "urn:ietf:params:netconf:capability:confirmed-commit:1.1"
+	CapabilityRollbackOnError = "urn:ietf:params:netconf:capability:rollback-on-error:1.0"
+	CapabilityValidate        = "urn:ietf:params:netconf:capability:validate:1.1"
+	CapabilityStartup         = "urn:ietf:params:netconf:capability:startup:1.0"
+	CapabilityURL             = "urn:ietf:params:netconf:capability:url:1.0"
+	CapabilityXPath           = "urn:ietf:params:netconf:capability:xpath:1.0"
+	CapabilityNotification    = "urn:ietf:params:netconf:capability:notification:1.0"
+	CapabilityInterleave      = "urn:ietf:params:netconf:capability:interleave:1.0"
+	CapabilityPartialLock     = "urn:ietf:params:netconf:capability:partial-lock:1.0"
+	CapabilityWithDefaults    = "urn:ietf:params:netconf:capability:with-defaults:1.0"
+)
+
+// HasCapability reports whether the server announced the capability.  Any
+// parameters of the announced capability are ignored, and for versioned
+// capabilities such as :validate:1.1 an announcement of an earlier version
+// (:validate:1.0) is also accepted.
+func (s *Session) HasCapability(uri string) bool {
+	return s.Capabilities().Has(uri)
+}
+
+// capabilityBase strips the parameters from a capability URI.
+func capabilityBase(uri string) string {
+	uri = strings.TrimSpace(uri)
+	if i := strings.IndexByte(uri, '?'); i >= 0 {
+		uri = uri[:i]
+	}
+	return uri
+}
+
+// ErrCapabilityUnsupported matches errors returned for operations the
+// server did not announce support for, see CapabilityError.
+var ErrCapabilityUnsupported = errors.New("netconf: capability not supported")
+
+// CapabilityError is returned, before anything is sent, for operations
+// requiring a capability the server did not announce.
+type CapabilityError struct {
+	Operation  string
+	Capability string
+}
+
+func (e *CapabilityError) Error() string {
+	return fmt.Sprintf("netconf: %s requires capability %s, not announced by the server", e.Operation, e.Capability)
+}
+
+// Is reports whether target is ErrCapabilityUnsupported.
+func (e *CapabilityError) Is(target error) bool {
+	return target == ErrCapabilityUnsupported
+}
+
+// requireCapability returns a *CapabilityError if the server did not
+// announce uri, needed for op.
+func (s *Session) requireCapability(op, uri string) error {
+	if s.HasCapability(uri) {
+		return nil
+	}
+	return &CapabilityError{Operation: op, Capability: uri}
+}
+
+// HelloError lists the problems found in a server hello.
+type HelloError struct {
+	Problems []string
+}
+
+func (e *HelloError) Error() string {
+	return "netconf: invalid server hello: " + strings.Join(e.Problems, "; ")
+}
+
+// ValidateHello checks the server hello thoroughly: it must carry a
+// session-id, announce a supported base capability exactly once and
+// announce all of the required capabilities.  All problems found are
+// reported in a single *HelloError.
+func (s *Session) ValidateHello(required ...string) error {
+	var problems []string
+	if s.SessionID <= 0 {
+		problems = append(problems, "missing session-id")
+	}
+
+	bases := make(map[string]int)
+	for _, c := range s.ServerCapabilities {
+		if c = capabilityBase(c); c == CapabilityBase10 || c == CapabilityBase11 {
+			bases[c]++
+		}
+	}
+	if len(bases) == 0 {
+		problems = append(problems, "no supported base capability")
+	}
+	for _, base := range []string{CapabilityBase10, CapabilityBase11} {
+		if bases[base] > 1 {
+			problems = append(problems, fmt.Sprintf("duplicate capability %s", base))
+		}
+	}
+
+	for _, c := range required {
+		if !s.HasCapability(c) {
+			problems = append(problems, fmt.Sprintf("missing capability %s", c))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &HelloError{Problems: problems}
+	}
+	return nil
+}
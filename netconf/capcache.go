@@ -0,0 +1,198 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Module is a YANG module announced in the server's capabilities.
+type Module struct {
+	Name       string
+	Namespace  string
+	Revision   string
+	Features   []string
+	Deviations []string
+}
+
+// Capabilities holds the capabilities announced by a server along with the
+// data derived from them.
+type Capabilities struct {
+	URIs []string
+	// Modules lists the YANG modules announced as capabilities, sorted by
+	// name.
+	Modules []Module
+
+	bases map[string]bool
+}
+
+// ParseCapabilities derives the capability set and module list from the
+// capability URIs announced in a hello message.
+func ParseCapabilities(uris []string) *Capabilities {
+	c := &Capabilities{
+		URIs:  append([]string(nil), uris...),
+		bases: make(map[string]bool, len(uris)),
+	}
+	for _, uri := range uris {
+		c.bases[capabilityBase(uri)] = true
+
+		i := strings.IndexByte(uri, '?')
+		if i < 0 {
+			continue
+		}
+		query, err := url.ParseQuery(strings.TrimSpace(uri[i+1:]))
+		if err != nil || query.Get("module") == "" {
+			continue
+		}
+		c.Modules = append(c.Modules, Module{
+			Name:       query.Get("module"),
+			Namespace:  capabilityBase(uri),
+			Revision:   query.Get("revision"),
+			Features:   splitList(query.Get("features")),
+			Deviations: splitList(query.Get("deviations")),
+		})
+	}
+	sort.Slice(c.Modules, func(i, j int) bool {
+		return c.Modules[i].Name < c.Modules[j].Name
+	})
+	return c
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// Has reports whether the capability was announced, see
+// Session.HasCapability.
+func (c *Capabilities) Has(uri string) bool {
+	uri = capabilityBase(uri)
+	if c.bases[uri] {
+		return true
+	}
+	return strings.HasSuffix(uri, ":1.1") && strings.Contains(uri, ":capability:") &&
+		c.bases[strings.TrimSuffix(uri, "1.1")+"1.0"]
+}
+
+// Module returns the announced module with the given name, or nil.
+func (c *Capabilities) Module(name string) *Module {
+	i := sort.Search(len(c.Modules), func(i int) bool {
+		return c.Modules[i].Name >= name
+	})
+	if i < len(c.Modules) && c.Modules[i].Name == name {
+		return &c.Modules[i]
+	}
+	return nil
+}
+
+// HasFeature reports whether the module was announced with the feature
+// enabled.
+func (c *Capabilities) HasFeature(module, feature string) bool {
+	m := c.Module(module)
+	if m == nil {
+		return false
+	}
+	for _, f := range m.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// sameURIs reports whether the capabilities were derived from uris.
+func (c *Capabilities) sameURIs(uris []string) bool {
+	if len(c.URIs) != len(uris) {
+		return false
+	}
+	for i := range uris {
+		if c.URIs[i] != uris[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Capabilities returns the capabilities announced by the server.  They are
+// derived once per session, or taken from a CapabilityCache with
+// CapabilityCache.Load.
+func (s *Session) Capabilities() *Capabilities {
+	if s.capabilities == nil || !s.capabilities.sameURIs(s.ServerCapabilities) {
+		s.capabilities = ParseCapabilities(s.ServerCapabilities)
+	}
+	return s.capabilities
+}
+
+// CapabilityCache keeps the capabilities derived for devices across
+// sessions, keyed by device and software version, so reconnects and pooled
+// sessions need not derive them again.  It is safe for concurrent use.
+type CapabilityCache struct {
+	mu      sync.Mutex
+	entries map[capabilityKey]*Capabilities
+}
+
+type capabilityKey struct {
+	device  string
+	version string
+}
+
+// NewCapabilityCache returns an empty cache.
+func NewCapabilityCache() *CapabilityCache {
+	return &CapabilityCache{entries: make(map[capabilityKey]*Capabilities)}
+}
+
+// Load sets the capabilities of session s, which is connected to device
+// running the given software version, from the cache.  They are derived and
+// stored if the cache holds none, or if the server announced different
+// capabilities than cached.
+func (c *CapabilityCache) Load(s *Session, device, version string) *Capabilities {
+	key := capabilityKey{device, version}
+
+	c.mu.Lock()
+	caps := c.entries[key]
+	c.mu.Unlock()
+
+	if caps == nil || !caps.sameURIs(s.ServerCapabilities) {
+		caps = ParseCapabilities(s.ServerCapabilities)
+		c.mu.Lock()
+		c.entries[key] = caps
+		c.mu.Unlock()
+	}
+	s.capabilities = caps
+	return caps
+}
+
+// Invalidate drops the cached capabilities of device for all software
+// versions.
+func (c *CapabilityCache) Invalidate(device string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.entries {
+		if k.device == device {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// Purge drops all cached capabilities.
+func (c *CapabilityCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[capabilityKey]*Capabilities)
+}
+
+// Len returns the number of cached entries.
+func (c *CapabilityCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
@@ -0,0 +1,93 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+var testModuleCapabilities = []string{
+	CapabilityBase11,
+	"urn:ietf:params:netconf:capability:validate:1.0",
+	"urn:ietf:params:xml:ns:yang:ietf-interfaces?module=ietf-interfaces&revision=2018-02-20&features=arbitrary-names,pre-provisioning",
+	"http://openconfig.net/yang/bgp?module=openconfig-bgp&revision=2019-05-28&deviations=vendor-bgp-dev",
+	"urn:ietf:params:netconf:capability:with-defaults:1.0?basic-mode=explicit",
+}
+
+func TestParseCapabilities(t *testing.T) {
+	caps := ParseCapabilities(testModuleCapabilities)
+
+	expected := []Module{
+		{
+			Name:      "ietf-interfaces",
+			Namespace: "urn:ietf:params:xml:ns:yang:ietf-interfaces",
+			Revision:  "2018-02-20",
+			Features:  []string{"arbitrary-names", "pre-provisioning"},
+		},
+		{
+			Name:       "openconfig-bgp",
+			Namespace:  "http://openconfig.net/yang/bgp",
+			Revision:   "2019-05-28",
+			Deviations: []string{"vendor-bgp-dev"},
+		},
+	}
+	if diff := cmp.Diff(expected, caps.Modules, cmpopts.SortSlices(func(a, b Module) bool { return a.Name < b.Name })); diff != "" {
+		t.Errorf("modules mismatch (-want +got):\n%s", diff)
+	}
+
+	for uri, want := range map[string]bool{
+		CapabilityValidate:     true,
+		CapabilityWithDefaults: true,
+		CapabilityCandidate:    false,
+	} {
+		if got := caps.Has(uri); got != want {
+			t.Errorf("Has(%s) = %v, expected %v", uri, got, want)
+		}
+	}
+	if !caps.HasFeature("ietf-interfaces", "pre-provisioning") || caps.HasFeature("ietf-interfaces", "other") {
+		t.Error("unexpected feature lookup result")
+	}
+	if caps.Module("missing") != nil {
+		t.Error("expected no module")
+	}
+}
+
+func TestCapabilityCache(t *testing.T) {
+	cache := NewCapabilityCache()
+	s1 := &Session{ServerCapabilities: testModuleCapabilities}
+	s2 := &Session{ServerCapabilities: testModuleCapabilities}
+
+	first := cache.Load(s1, "r1", "20.4R1")
+	if got := cache.Load(s2, "r1", "20.4R1"); got != first {
+		t.Error("expected cached capabilities to be reused")
+	}
+	if s2.Capabilities() != first {
+		t.Error("expected session to use cached capabilities")
+	}
+
+	if got := cache.Load(&Session{ServerCapabilities: testModuleCapabilities}, "r1", "21.1R1"); got == first {
+		t.Error("expected new software version to derive capabilities again")
+	}
+
+	changed := &Session{ServerCapabilities: []string{CapabilityBase10}}
+	if got := cache.Load(changed, "r1", "20.4R1"); got == first || got.Has(CapabilityBase11) {
+		t.Error("expected changed capabilities to replace the cached entry")
+	}
+
+	cache.Invalidate("r1")
+	if cache.Len() != 0 {
+		t.Errorf("expected empty cache, got %d entries", cache.Len())
+	}
+	cache.Load(s1, "r2", "")
+	cache.Purge()
+	if cache.Len() != 0 {
+		t.Errorf("expected empty cache after purge, got %d entries", cache.Len())
+	}
+}
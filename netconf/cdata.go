@@ -0,0 +1,37 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"strings"
+)
+
+// CDATA returns s as CDATA section for embedding literal blocks such as
+// scripts, banners or certificates into a configuration.  Occurrences of
+// "]]>" in s are split across sections so any content survives, except that
+// XML parsers turn carriage returns into line feeds.  Use EscapeText for
+// content where they matter.
+func CDATA(s string) string {
+	return "<![CDATA[" + strings.Replace(s, "]]>", "]]]]><![CDATA[>", -1) + "]]>"
+}
+
+// EscapeText returns s escaped for use as character data or attribute
+// value.  Unlike xml.EscapeText line breaks and tabs are kept as they are.
+func EscapeText(s string) string {
+	return attrEscaper.Replace(s)
+}
+
+// attrEscaper escapes character data including quotes, so its output is
+// also valid within attribute values.
+var attrEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&#34;",
+	"'", "&#39;",
+	"\r", "&#xD;",
+)
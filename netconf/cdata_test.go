@@ -0,0 +1,56 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"testing"
+)
+
+func TestLiteralRoundTrip(t *testing.T) {
+	tt := []struct {
+		name    string
+		literal string
+		escapes []func(string) string
+	}{
+		{"plain", "Authorized access only", []func(string) string{CDATA, EscapeText}},
+		{"markup", "<b>no</b> & \"quotes\" 'too'", []func(string) string{CDATA, EscapeText}},
+		{"terminator", "a]]>b]]>]]>", []func(string) string{CDATA, EscapeText}},
+		{"multiline", "line 1\n\tline 2\nline 3\n", []func(string) string{CDATA, EscapeText}},
+		// Parsers normalize line breaks in CDATA sections.
+		{"carriage return", "line 1\r\nline 2", []func(string) string{EscapeText}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, enc := range tc.escapes {
+				n, err := ParseNode([]byte("<message>" + enc(tc.literal) + "</message>"))
+				if err != nil {
+					t.Fatalf("ParseNode failed: %v", err)
+				}
+				if n.Text != tc.literal {
+					t.Errorf("literal changed: (want %q, got %q)", tc.literal, n.Text)
+				}
+				if again, err := ParseNode([]byte(n.String())); err != nil || again.Text != tc.literal {
+					t.Errorf("literal changed on re-encoding: %q, %v", again.Text, err)
+				}
+			}
+		})
+	}
+}
+
+func TestEscapeTextAttribute(t *testing.T) {
+	literal := `a"b'c<&>`
+	n, err := ParseNode([]byte(`<x v="` + EscapeText(literal) + `" w='` + EscapeText(literal) + `'/>`))
+	if err != nil {
+		t.Fatalf("ParseNode failed: %v", err)
+	}
+	for _, name := range []string{"v", "w"} {
+		if v, _ := n.Attr("", name); v != literal {
+			t.Errorf("attribute %s changed: (want %q, got %q)", name, literal, v)
+		}
+	}
+}
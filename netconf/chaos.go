@@ -0,0 +1,353 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrInjectedFault is returned for the faults injected by a Chaos.  Once a
+// connection failed this way, all further reads and writes fail too.
+var ErrInjectedFault = errors.New("netconf: injected fault")
+
+// Chaos injects faults into NETCONF connections, for testing the retry and
+// reconnect handling of applications without flaky links.  Probabilities
+// range from 0 to 1 and apply to each read and write.  A Chaos may be
+// shared by several connections; its fields must not be changed once it
+// is in use.
+type Chaos struct {
+	// Latency delays every read and write, plus a random time of up to
+	// Jitter.
+	Latency time.Duration
+	Jitter  time.Duration
+	// PartialWrite is the probability of a write being cut short, after
+	// which the connection is closed, leaving a truncated message.
+	PartialWrite float64
+	// Disconnect is the probability of the connection being closed.
+	Disconnect float64
+	// DisconnectAfter, if set, closes the connection once that many bytes
+	// have been read and written.
+	DisconnectAfter int64
+	// CorruptChunk is the probability of each chunk header (RFC 6242) in the
+	// data being corrupted, so that the peer reports a framing error.  It
+	// has no effect with end-of-message framing.
+	CorruptChunk float64
+	// Seed makes the faults reproducible.  Zero seeds from the time.
+	Seed int64
+
+	mu    sync.Mutex
+	rand  *rand.Rand
+	stats ChaosStats
+}
+
+// ChaosStats counts the faults injected by a Chaos.
+type ChaosStats struct {
+	Delays        int
+	PartialWrites int
+	Disconnects   int
+	CorruptChunks int
+	BytesRead     int64
+	BytesWritten  int64
+}
+
+// WithChaos injects the faults of c into the sessions dialled.  They are
+// injected above SSH and TLS, into the NETCONF messages.
+func WithChaos(c *Chaos) Option {
+	return func(cfg *SessionConfig) { cfg.Chaos = c }
+}
+
+// Stats returns the faults injected so far.
+func (c *Chaos) Stats() ChaosStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Transport injects faults into t and returns it.  The byte stream of the
+// transports of this package is modified in place, other transports are
+// wrapped and only see latency and disconnects.
+func (c *Chaos) Transport(t Transport) Transport {
+	if b, ok := t.(interface{ basicIO() *transportBasicIO }); ok {
+		bio := b.basicIO()
+		bio.ReadWriteCloser = c.ReadWriteCloser(bio.ReadWriteCloser)
+		return t
+	}
+	return &chaosTransport{Transport: t, chaos: c}
+}
+
+// ReadWriteCloser returns rwc with faults injected.  Read and write
+// deadlines are passed on if rwc supports them.
+func (c *Chaos) ReadWriteCloser(rwc io.ReadWriteCloser) io.ReadWriteCloser {
+	return &chaosConn{rwc: rwc, chaos: c}
+}
+
+// Conn returns conn with faults injected.
+func (c *Chaos) Conn(conn net.Conn) net.Conn {
+	return &chaosNetConn{Conn: conn, cc: chaosConn{rwc: conn, chaos: c}}
+}
+
+// Listener returns a listener injecting faults into the connections it
+// accepts, e.g. to make a Server or Simulator flaky.
+func (c *Chaos) Listener(l net.Listener) net.Listener {
+	return &chaosListener{Listener: l, chaos: c}
+}
+
+// chance reports whether an event of probability p happens.
+func (c *Chaos) chance(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.random().Float64() < p
+}
+
+// intn returns a random number in [0, n).
+func (c *Chaos) intn(n int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.random().Intn(n)
+}
+
+func (c *Chaos) random() *rand.Rand {
+	if c.rand == nil {
+		seed := c.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		c.rand = rand.New(rand.NewSource(seed))
+	}
+	return c.rand
+}
+
+// delay sleeps for the latency and jitter.
+func (c *Chaos) delay() {
+	d := c.Latency
+	if c.Jitter > 0 {
+		c.mu.Lock()
+		d += time.Duration(c.random().Int63n(int64(c.Jitter)))
+		c.mu.Unlock()
+	}
+	if d <= 0 {
+		return
+	}
+	c.count(func(s *ChaosStats) { s.Delays++ })
+	time.Sleep(d)
+}
+
+func (c *Chaos) count(fn func(s *ChaosStats)) {
+	c.mu.Lock()
+	fn(&c.stats)
+	c.mu.Unlock()
+}
+
+// corruptChunks corrupts the chunk headers in p by giving their size a
+// leading zero.
+func (c *Chaos) corruptChunks(p []byte) {
+	for i := 0; i+2 < len(p); i++ {
+		if p[i] != '\n' || p[i+1] != '#' || p[i+2] < '1' || p[i+2] > '9' {
+			continue
+		}
+		if c.chance(c.CorruptChunk) {
+			p[i+2] = '0'
+			c.count(func(s *ChaosStats) { s.CorruptChunks++ })
+		}
+	}
+}
+
+// chaosConn injects faults into a byte stream.
+type chaosConn struct {
+	rwc   io.ReadWriteCloser
+	chaos *Chaos
+
+	mu     sync.Mutex
+	bytes  int64
+	failed error
+}
+
+// fail closes the connection with an injected fault.
+func (cc *chaosConn) fail(fault string) error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.failed == nil {
+		cc.failed = fmt.Errorf("%w: %s", ErrInjectedFault, fault)
+		cc.rwc.Close()
+		cc.chaos.count(func(s *ChaosStats) { s.Disconnects++ })
+	}
+	return cc.failed
+}
+
+// check injects a disconnect or returns the earlier fault, if any.
+func (cc *chaosConn) check() error {
+	cc.mu.Lock()
+	err := cc.failed
+	cc.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if cc.chaos.chance(cc.chaos.Disconnect) {
+		return cc.fail("disconnect")
+	}
+	return nil
+}
+
+// transferred counts n bytes and reports whether DisconnectAfter was
+// exceeded.
+func (cc *chaosConn) transferred(n int) bool {
+	cc.mu.Lock()
+	cc.bytes += int64(n)
+	exceeded := cc.chaos.DisconnectAfter > 0 && cc.bytes >= cc.chaos.DisconnectAfter
+	cc.mu.Unlock()
+	return exceeded
+}
+
+// limit shortens n to the bytes left before DisconnectAfter.
+func (cc *chaosConn) limit(n int) int {
+	if cc.chaos.DisconnectAfter <= 0 {
+		return n
+	}
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if left := cc.chaos.DisconnectAfter - cc.bytes; int64(n) > left {
+		return int(left)
+	}
+	return n
+}
+
+func (cc *chaosConn) Read(p []byte) (int, error) {
+	cc.chaos.delay()
+	if err := cc.check(); err != nil {
+		return 0, err
+	}
+	n, err := cc.rwc.Read(p[:cc.limit(len(p))])
+	if cc.chaos.CorruptChunk > 0 {
+		cc.chaos.corruptChunks(p[:n])
+	}
+	cc.chaos.count(func(s *ChaosStats) { s.BytesRead += int64(n) })
+	if cc.transferred(n) {
+		cc.fail("disconnect after limit")
+	}
+	return n, err
+}
+
+func (cc *chaosConn) Write(p []byte) (int, error) {
+	cc.chaos.delay()
+	if err := cc.check(); err != nil {
+		return 0, err
+	}
+
+	data := p
+	if cc.chaos.CorruptChunk > 0 {
+		data = append([]byte(nil), p...)
+		cc.chaos.corruptChunks(data)
+	}
+	partial := len(data)
+	if len(data) > 1 && cc.chaos.chance(cc.chaos.PartialWrite) {
+		partial = 1 + cc.chaos.intn(len(data)-1)
+		cc.chaos.count(func(s *ChaosStats) { s.PartialWrites++ })
+	}
+	if l := cc.limit(partial); l < partial {
+		partial = l
+	}
+
+	n, err := cc.rwc.Write(data[:partial])
+	cc.chaos.count(func(s *ChaosStats) { s.BytesWritten += int64(n) })
+	exceeded := cc.transferred(n)
+	if err != nil {
+		return n, err
+	}
+	switch {
+	case partial < len(data) && !exceeded:
+		return n, cc.fail("partial write")
+	case partial < len(data):
+		return n, cc.fail("disconnect after limit")
+	case exceeded:
+		cc.fail("disconnect after limit")
+	}
+	return n, nil
+}
+
+func (cc *chaosConn) Close() error {
+	return cc.rwc.Close()
+}
+
+func (cc *chaosConn) SetReadDeadline(d time.Time) error {
+	if c, ok := cc.rwc.(interface{ SetReadDeadline(time.Time) error }); ok {
+		return c.SetReadDeadline(d)
+	}
+	return errDeadlineUnsupported
+}
+
+func (cc *chaosConn) SetWriteDeadline(d time.Time) error {
+	if c, ok := cc.rwc.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		return c.SetWriteDeadline(d)
+	}
+	return errDeadlineUnsupported
+}
+
+type chaosNetConn struct {
+	net.Conn
+	cc chaosConn
+}
+
+func (c *chaosNetConn) Read(p []byte) (int, error)  { return c.cc.Read(p) }
+func (c *chaosNetConn) Write(p []byte) (int, error) { return c.cc.Write(p) }
+
+type chaosListener struct {
+	net.Listener
+	chaos *Chaos
+}
+
+func (l *chaosListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return l.chaos.Conn(conn), nil
+}
+
+// chaosTransport injects latency and disconnects into the messages of a
+// transport whose byte stream is not accessible.
+type chaosTransport struct {
+	Transport
+	chaos *Chaos
+
+	mu     sync.Mutex
+	failed error
+}
+
+func (t *chaosTransport) inject() error {
+	t.chaos.delay()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.failed == nil && t.chaos.chance(t.chaos.Disconnect) {
+		t.failed = fmt.Errorf("%w: disconnect", ErrInjectedFault)
+		t.Transport.Close()
+		t.chaos.count(func(s *ChaosStats) { s.Disconnects++ })
+	}
+	return t.failed
+}
+
+func (t *chaosTransport) Send(data []byte) error {
+	if err := t.inject(); err != nil {
+		return err
+	}
+	return t.Transport.Send(data)
+}
+
+func (t *chaosTransport) Receive() ([]byte, error) {
+	if err := t.inject(); err != nil {
+		return nil, err
+	}
+	return t.Transport.Receive()
+}
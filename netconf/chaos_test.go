@@ -0,0 +1,155 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+// bufferConn is an in-memory connection reading from and writing to a
+// buffer.
+type bufferConn struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *bufferConn) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestChaosPartialWrite(t *testing.T) {
+	chaos := &Chaos{PartialWrite: 1, Seed: 1}
+	buf := &bufferConn{}
+	conn := chaos.ReadWriteCloser(buf)
+
+	msg := []byte("<rpc><get/></rpc>")
+	n, err := conn.Write(msg)
+	if !errors.Is(err, ErrInjectedFault) || n == 0 || n >= len(msg) {
+		t.Fatalf("got %d bytes written, error %v, expected a partial write", n, err)
+	}
+	if buf.Len() != n || !buf.closed {
+		t.Errorf("got %d bytes in the buffer, closed %v, expected %d and closed", buf.Len(), buf.closed, n)
+	}
+	if _, err := conn.Write(msg); !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("expected writes after the fault to fail, got %v", err)
+	}
+	if stats := chaos.Stats(); stats.PartialWrites != 1 || stats.Disconnects != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestChaosDisconnectAfter(t *testing.T) {
+	chaos := &Chaos{DisconnectAfter: 5}
+	conn := chaos.ReadWriteCloser(&bufferConn{Buffer: *bytes.NewBufferString("0123456789")})
+
+	p := make([]byte, 8)
+	if n, err := conn.Read(p); n != 5 || err != nil {
+		t.Fatalf("got %d bytes, error %v, expected 5 bytes", n, err)
+	}
+	if _, err := conn.Read(p); !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("expected the read past the limit to fail, got %v", err)
+	}
+}
+
+func TestChaosCorruptChunk(t *testing.T) {
+	chaos := &Chaos{CorruptChunk: 1}
+	buf := &bufferConn{}
+	f := NewFramer(chaos.ReadWriteCloser(buf), buf)
+	f.SetFraming(FramingChunked)
+	buf.WriteString("\n#5\nhello\n##\n")
+
+	var ferr *FramingError
+	if _, err := f.ReadFrame(); !errors.As(err, &ferr) {
+		t.Errorf("expected a framing error, got %v", err)
+	}
+	if stats := chaos.Stats(); stats.CorruptChunks != 1 {
+		t.Errorf("got %d corrupted chunks, expected 1", stats.CorruptChunks)
+	}
+
+	// The data written is copied before it is corrupted.
+	msg := []byte("\n#5\nhello\n##\n")
+	chaos.ReadWriteCloser(buf).Write(msg)
+	if string(msg) != "\n#5\nhello\n##\n" {
+		t.Errorf("the written data was modified: %q", msg)
+	}
+}
+
+func TestChaosLatency(t *testing.T) {
+	chaos := &Chaos{Latency: 20 * time.Millisecond, Jitter: 10 * time.Millisecond}
+	client, server := net.Pipe()
+	defer server.Close()
+	conn := chaos.Conn(client)
+	defer conn.Close()
+	go io.Copy(ioutil.Discard, server)
+
+	start := time.Now()
+	if _, err := conn.Write([]byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("write took %v, expected at least 20ms", d)
+	}
+	if err := conn.SetDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Errorf("SetDeadline failed: %v", err)
+	}
+}
+
+func TestWithChaos(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+
+	s, err := Dial(srv.Addr(), WithSSHConfig(testSSHConfig()), WithChaos(&Chaos{Latency: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	if _, err := s.Exec(MethodGetConfig("running")); err != nil {
+		t.Errorf("Exec with latency failed: %v", err)
+	}
+	s.Close()
+}
+
+func TestChaosCorruptChunkSession(t *testing.T) {
+	srv, addr := newTestServer(t)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chaos := &Chaos{CorruptChunk: 1}
+	s := NewSession(chaos.Transport(NewTransportConn(conn)))
+	defer s.Close()
+	if s.Framing() != FramingChunked {
+		t.Fatalf("got %s framing, expected chunked", s.Framing())
+	}
+	if _, err := s.Exec(MethodGetConfig("running")); !errors.Is(err, ErrTransportBroken) {
+		t.Errorf("expected the server to drop the session, got %v", err)
+	}
+	if chaos.Stats().CorruptChunks == 0 {
+		t.Error("expected chunk headers to be corrupted")
+	}
+}
+
+func TestChaosTransport(t *testing.T) {
+	s, trans := newScriptedSession(nil, replyOK)
+	s.Transport = (&Chaos{Disconnect: 1}).Transport(s.Transport)
+
+	_, err := s.Exec(MethodGetConfig("running"))
+	if !errors.Is(err, ErrInjectedFault) || !errors.Is(err, ErrTransportBroken) {
+		t.Errorf("expected an injected transport fault, got %v", err)
+	}
+	if !trans.closed || len(trans.sent) != 0 {
+		t.Errorf("expected the transport to be closed before sending, got closed %v and %d sent", trans.closed, len(trans.sent))
+	}
+}
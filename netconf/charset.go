@@ -0,0 +1,99 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// CharsetReader returns a reader converting input from charset to UTF-8.
+// Its signature matches xml.Decoder's CharsetReader, so converters such as
+// charset.NewReaderLabel from golang.org/x/net/html/charset can be plugged in
+// to support charsets like GB2312.
+type CharsetReader func(charset string, input io.Reader) (io.Reader, error)
+
+// encodingRE matches the encoding declared by an XML declaration.
+var encodingRE = regexp.MustCompile(`<\?xml[^>]*?encoding\s*=\s*["']([A-Za-z0-9._:-]+)["']`)
+
+// declarationScan bounds how far into a reply an XML declaration is looked
+// for.
+const declarationScan = 1024
+
+// toUTF8 converts a reply to UTF-8.  The charset is taken from the XML
+// declaration, or is fallback if the reply declares none and is not valid
+// UTF-8.  Latin-1 and ASCII are converted without a CharsetReader.  The
+// declaration of a converted reply is updated to announce UTF-8.
+func toUTF8(data []byte, fallback string, convert CharsetReader) ([]byte, error) {
+	head := data
+	if len(head) > declarationScan {
+		head = head[:declarationScan]
+	}
+
+	var charset string
+	if loc := encodingRE.FindSubmatchIndex(head); loc != nil {
+		charset = string(head[loc[2]:loc[3]])
+	}
+	if charset == "" || isUTF8Charset(charset) {
+		if fallback == "" || utf8.Valid(data) {
+			return data, nil
+		}
+		charset = fallback
+	}
+
+	var out []byte
+	switch {
+	case isLatin1Charset(charset):
+		out = latin1ToUTF8(data)
+	case convert != nil:
+		r, err := convert(charset, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if out, err = ioutil.ReadAll(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("netconf: unsupported charset %q", charset)
+	}
+
+	if loc := encodingRE.FindSubmatchIndex(out); loc != nil && loc[2] < declarationScan {
+		out = append(out[:loc[2]:loc[2]], append([]byte("UTF-8"), out[loc[3]:]...)...)
+	}
+	return out, nil
+}
+
+func isUTF8Charset(charset string) bool {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8":
+		return true
+	}
+	return false
+}
+
+func isLatin1Charset(charset string) bool {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "iso_8859-1", "iso8859-1", "latin1", "latin-1", "l1", "us-ascii", "ascii":
+		return true
+	}
+	return false
+}
+
+// latin1ToUTF8 converts ISO-8859-1 to UTF-8.  Every byte maps to the code
+// point of the same value.
+func latin1ToUTF8(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for _, b := range data {
+		out = append(out, string(rune(b))...)
+	}
+	return out
+}
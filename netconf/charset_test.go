@@ -0,0 +1,86 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestToUTF8(t *testing.T) {
+	// convert stands in for a real converter.
+	convert := func(charset string, input io.Reader) (io.Reader, error) {
+		var b bytes.Buffer
+		b.ReadFrom(input)
+		return strings.NewReader(strings.Replace(b.String(), "\xd6\xd0", "中", -1)), nil
+	}
+
+	tt := []struct {
+		name     string
+		input    string
+		fallback string
+		convert  CharsetReader
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:  "utf8",
+			input: "<?xml version=\"1.0\" encoding=\"UTF-8\"?><d>café</d>",
+			want:  "<?xml version=\"1.0\" encoding=\"UTF-8\"?><d>café</d>",
+		},
+		{
+			name:  "latin1",
+			input: "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><d>caf\xe9</d>",
+			want:  "<?xml version=\"1.0\" encoding=\"UTF-8\"?><d>café</d>",
+		},
+		{
+			name:     "fallback",
+			input:    "<d>caf\xe9</d>",
+			fallback: "latin1",
+			want:     "<d>café</d>",
+		},
+		{
+			name:    "plugged",
+			input:   "<?xml version='1.0' encoding='gb2312'?><d>\xd6\xd0</d>",
+			convert: convert,
+			want:    "<?xml version='1.0' encoding='UTF-8'?><d>中</d>",
+		},
+		{
+			name:    "unsupported",
+			input:   "<?xml version='1.0' encoding='gb2312'?><d>x</d>",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := toUTF8([]byte(tc.input), tc.fallback, tc.convert)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.wantErr && string(got) != tc.want {
+				t.Errorf("toUTF8(%q) = %q, want %q", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSessionLatin1Reply(t *testing.T) {
+	reply := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n" +
+		"<rpc-reply xmlns=\"urn:ietf:params:xml:ns:netconf:base:1.0\"><data><d>caf\xe9</d></data></rpc-reply>"
+	s, _ := newScriptedSession(nil, reply)
+
+	r, err := s.Exec(MethodGetConfig("running"))
+	if err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if r.Data.String() != "<data><d>café</d></data>" {
+		t.Errorf("unexpected data %q", r.Data)
+	}
+}
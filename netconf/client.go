@@ -0,0 +1,84 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"fmt"
+)
+
+// Client holds the settings shared by the sessions of an application, such
+// as SSH configuration, timeouts, interceptors and loggers, and establishes
+// sessions to many devices with them.  It is safe for concurrent use once
+// configured.
+type Client struct {
+	// Options are applied to every session, before the options passed to
+	// Dial.
+	Options []Option
+	// Inventory, if set, resolves device names passed to DialDevice.
+	Inventory *Inventory
+}
+
+// NewClient returns a client applying opts to all its sessions.
+func NewClient(opts ...Option) *Client {
+	return &Client{Options: opts}
+}
+
+// Config returns the configuration of a session dialled with opts.
+func (c *Client) Config(opts ...Option) *SessionConfig {
+	config := NewSessionConfig(c.Options...)
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// Dial establishes a session to target with the client's defaults and
+// opts.
+func (c *Client) Dial(ctx context.Context, target string, opts ...Option) (*Session, error) {
+	return c.Config(opts...).DialContext(ctx, target)
+}
+
+// DialDevice establishes a session to d.  The profile, port and username of
+// the device take precedence over the client's defaults, and the credentials
+// from a CredentialProvider over both.
+func (c *Client) DialDevice(ctx context.Context, d *Device, opts ...Option) (*Session, error) {
+	config := c.Config(opts...)
+	if d.Profile != "" {
+		p := LookupProfile(d.Profile)
+		if p == nil {
+			return nil, fmt.Errorf("netconf: device %s: unknown profile %q", d.Name, d.Profile)
+		}
+		config.Profile = p
+	}
+	if d.Username != "" && config.SSHConfig != nil {
+		ssh := *config.SSHConfig
+		ssh.User = d.Username
+		config.SSHConfig = &ssh
+	}
+	return config.DialDevice(ctx, d)
+}
+
+// DialName establishes a session to the named device of the client's
+// inventory.
+func (c *Client) DialName(ctx context.Context, name string, opts ...Option) (*Session, error) {
+	if c.Inventory == nil {
+		return nil, fmt.Errorf("%w %q", ErrUnknownDevice, name)
+	}
+	d := c.Inventory.Device(name)
+	if d == nil {
+		return nil, fmt.Errorf("%w %q", ErrUnknownDevice, name)
+	}
+	return c.DialDevice(ctx, d, opts...)
+}
+
+// Pool returns a session pool for the devices of inv dialled by the client.
+func (c *Client) Pool(inv *Inventory) *Pool {
+	return NewPool(inv, func(ctx context.Context, d *Device) (*Session, error) {
+		return c.DialDevice(ctx, d)
+	})
+}
@@ -0,0 +1,76 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+	host, portStr, _ := net.SplitHostPort(srv.Addr())
+	port, _ := strconv.Atoi(portStr)
+
+	var mu sync.Mutex
+	var ops []string
+	c := NewClient(
+		WithSSHConfig(testSSHConfig()),
+		WithTimeout(5*time.Second),
+		WithInterceptors(MetricsInterceptor(func(op string, d time.Duration, err error) {
+			mu.Lock()
+			ops = append(ops, op)
+			mu.Unlock()
+		})),
+	)
+	c.Inventory = &Inventory{Devices: []*Device{
+		{Name: "r1", Address: host, Port: port, Username: "ops"},
+		{Name: "r2", Address: host, Port: port, Profile: "missing"},
+	}}
+	ctx := context.Background()
+
+	s, err := c.DialName(ctx, "r1")
+	if err != nil {
+		t.Fatalf("DialName failed: %v", err)
+	}
+	defer s.Close()
+	if _, err := s.Exec(MethodGetConfig("running")); err != nil {
+		t.Errorf("Exec failed: %v", err)
+	}
+
+	pool := c.Pool(c.Inventory)
+	defer pool.Close()
+	err = pool.Do(ctx, "r1", func(s *Session) error {
+		_, err := s.ExecContext(ctx, MethodCommit())
+		return err
+	})
+	if err != nil {
+		t.Errorf("pool Do failed: %v", err)
+	}
+
+	mu.Lock()
+	if len(ops) != 2 || ops[0] != "get-config" || ops[1] != "commit" {
+		t.Errorf("unexpected recorded operations %v", ops)
+	}
+	mu.Unlock()
+
+	if _, err := c.DialName(ctx, "r3"); !errors.Is(err, ErrUnknownDevice) {
+		t.Errorf("expected ErrUnknownDevice, got %v", err)
+	}
+	if _, err := c.DialName(ctx, "r2"); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+	if c.Config().SSHConfig.User != "test" {
+		t.Error("expected device username not to leak into the client defaults")
+	}
+}
@@ -0,0 +1,302 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ComplianceRule is an assertion about a configuration.  Exactly one of
+// MustContain, MustNotContain and Path is set.
+type ComplianceRule struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	// MustContain is an XML subtree the configuration must contain.  As with
+	// subtree filters, elements of the configuration missing from the
+	// subtree are ignored and leaves with content must have that value.
+	MustContain string `yaml:"must_contain,omitempty"`
+	// MustNotContain is an XML subtree the configuration must not contain.
+	MustNotContain string `yaml:"must_not_contain,omitempty"`
+	// Path selects the nodes the value constraints apply to, see
+	// Node.Select.  Without constraints the path must select a node.
+	Path string `yaml:"path,omitempty"`
+	// Pattern, if set, is a regular expression the whole value of every
+	// selected node must match.
+	Pattern string `yaml:"pattern,omitempty"`
+	// Values, if set, lists the values allowed for selected nodes.
+	Values []string `yaml:"values,omitempty"`
+	// Optional passes the rule if Path selects no nodes.
+	Optional bool `yaml:"optional,omitempty"`
+}
+
+// compiledRule is a rule prepared for evaluation.
+type compiledRule struct {
+	*ComplianceRule
+	path    []xpathStep
+	pattern *regexp.Regexp
+	subtree []*Node
+}
+
+// compile checks the rule and prepares it for evaluation.
+func (r *ComplianceRule) compile() (*compiledRule, error) {
+	set := 0
+	for _, s := range []string{r.MustContain, r.MustNotContain, r.Path} {
+		if s != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("rule %s: exactly one of must_contain, must_not_contain and path must be set", r.Name)
+	}
+
+	c := &compiledRule{ComplianceRule: r}
+	var err error
+	switch {
+	case r.Path != "":
+		if c.path, err = parseXPath(r.Path); err != nil {
+			return nil, fmt.Errorf("rule %s: %v", r.Name, err)
+		}
+		if r.Pattern != "" {
+			if c.pattern, err = regexp.Compile("^(?:" + r.Pattern + ")$"); err != nil {
+				return nil, fmt.Errorf("rule %s: %v", r.Name, err)
+			}
+		}
+	default:
+		if c.subtree, err = ParseNodes([]byte(r.MustContain + r.MustNotContain)); err != nil {
+			return nil, fmt.Errorf("rule %s: %v", r.Name, err)
+		}
+		if len(c.subtree) == 0 {
+			return nil, fmt.Errorf("rule %s: empty subtree", r.Name)
+		}
+	}
+	return c, nil
+}
+
+// ComplianceResult is the outcome of a rule.
+type ComplianceResult struct {
+	Rule   string `json:"rule"`
+	Passed bool   `json:"passed"`
+	// Message explains why the rule failed.
+	Message string `json:"message,omitempty"`
+}
+
+// ComplianceReport holds the results of the rules for a device.
+type ComplianceReport struct {
+	Device  string             `json:"device"`
+	Results []ComplianceResult `json:"results"`
+}
+
+// Passed reports whether every rule passed.
+func (r *ComplianceReport) Passed() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed returns the results of the rules that failed.
+func (r *ComplianceReport) Failed() []ComplianceResult {
+	var failed []ComplianceResult
+	for _, res := range r.Results {
+		if !res.Passed {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+func (r *ComplianceReport) String() string {
+	var b strings.Builder
+	for _, res := range r.Results {
+		if res.Passed {
+			fmt.Fprintf(&b, "PASS %s\n", res.Rule)
+		} else {
+			fmt.Fprintf(&b, "FAIL %s: %s\n", res.Rule, res.Message)
+		}
+	}
+	return b.String()
+}
+
+// ComplianceChecker evaluates rules against the configuration of devices.
+type ComplianceChecker struct {
+	Rules []ComplianceRule `yaml:"rules"`
+	// Source is the datastore to check, "running" if empty.
+	Source string `yaml:"source,omitempty"`
+}
+
+// LoadComplianceRules reads a checker from a YAML or JSON file.
+func LoadComplianceRules(path string) (*ComplianceChecker, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cc, err := ParseComplianceRules(buf)
+	if err != nil {
+		return nil, fmt.Errorf("compliance rules %s: %v", path, err)
+	}
+	return cc, nil
+}
+
+// ParseComplianceRules parses a document holding the rules and source of a
+// checker, and checks the rules.
+func ParseComplianceRules(data []byte) (*ComplianceChecker, error) {
+	cc := new(ComplianceChecker)
+	if err := yaml.UnmarshalStrict(data, cc); err != nil {
+		return nil, err
+	}
+	for i := range cc.Rules {
+		if cc.Rules[i].Name == "" {
+			return nil, fmt.Errorf("rule #%d has no name", i)
+		}
+		if _, err := cc.Rules[i].compile(); err != nil {
+			return nil, err
+		}
+	}
+	return cc, nil
+}
+
+// Evaluate evaluates the rules against a configuration, such as the data
+// of a get-config reply.
+func (cc *ComplianceChecker) Evaluate(config []byte) ([]ComplianceResult, error) {
+	rules := make([]*compiledRule, len(cc.Rules))
+	for i := range cc.Rules {
+		r, err := cc.Rules[i].compile()
+		if err != nil {
+			return nil, err
+		}
+		rules[i] = r
+	}
+	root, err := configRoot(config)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ComplianceResult, 0, len(rules))
+	for _, r := range rules {
+		res := ComplianceResult{Rule: r.Name}
+		res.Message = r.evaluate(root)
+		res.Passed = res.Message == ""
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// evaluate returns why the rule fails for the configuration below root, or
+// "" if it passes.
+func (r *compiledRule) evaluate(root *Node) string {
+	switch {
+	case r.MustContain != "":
+		for _, want := range r.subtree {
+			if !containsSubtree(root.Children, want) {
+				return "missing " + want.String()
+			}
+		}
+	case r.MustNotContain != "":
+		for _, unwanted := range r.subtree {
+			if !containsSubtree(root.Children, unwanted) {
+				return ""
+			}
+		}
+		return "contains " + strings.TrimSpace(r.MustNotContain)
+	default:
+		nodes := evalXPath([]*Node{root}, r.path)
+		if len(nodes) == 0 {
+			if r.Optional {
+				return ""
+			}
+			return r.Path + " selects no nodes"
+		}
+		for _, n := range nodes {
+			v := n.Value()
+			if r.pattern != nil && !r.pattern.MatchString(v) {
+				return fmt.Sprintf("%s: value %q does not match %s", r.Path, v, r.Pattern)
+			}
+			if len(r.Values) > 0 && !containsString(r.Values, v) {
+				return fmt.Sprintf("%s: value %q is not one of %s", r.Path, v, strings.Join(r.Values, ", "))
+			}
+		}
+	}
+	return ""
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// containsSubtree reports whether one of nodes matches want: it has the
+// same name, and namespace if want has one, and every child of want
+// matches one of its children.  Leaves of want with content must have the
+// same value.
+func containsSubtree(nodes []*Node, want *Node) bool {
+	for _, n := range nodes {
+		if n.XMLName.Local != want.XMLName.Local {
+			continue
+		}
+		if want.XMLName.Space != "" && n.XMLName.Space != want.XMLName.Space {
+			continue
+		}
+		if want.IsLeaf() {
+			if want.Value() == "" || n.Value() == want.Value() {
+				return true
+			}
+			continue
+		}
+		matched := true
+		for _, c := range want.Children {
+			if !containsSubtree(n.Children, c) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Check retrieves the configuration of device over s and evaluates the
+// rules against it.
+func (cc *ComplianceChecker) Check(ctx context.Context, s *Session, device string) (*ComplianceReport, error) {
+	source := cc.Source
+	if source == "" {
+		source = "running"
+	}
+	reply, err := s.ExecContext(ctx, MethodGetConfig(source))
+	if err != nil {
+		return nil, err
+	}
+	results, err := cc.Evaluate(reply.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &ComplianceReport{Device: device, Results: results}, nil
+}
+
+// Job returns a FleetJob that checks each device for compliance.  Devices
+// failing a rule are reported as failed with the results as diff.
+func (cc *ComplianceChecker) Job() FleetJob {
+	return func(ctx context.Context, s *Session, r *DeviceResult) error {
+		report, err := cc.Check(ctx, s, r.Device)
+		if err != nil {
+			return err
+		}
+		if failed := report.Failed(); len(failed) > 0 {
+			r.Diff = report.String()
+			return fmt.Errorf("compliance: %d of %d rules failed", len(failed), len(report.Results))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,114 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const complianceConfig = `<data><system xmlns="urn:example:system">
+<host-name>r1</host-name>
+<services><ssh><protocol-version>v2</protocol-version></ssh><telnet/></services>
+<ntp><server><name>10.0.0.1</name></server><server><name>192.0.2.9</name></server></ntp>
+</system></data>`
+
+func TestComplianceEvaluate(t *testing.T) {
+	cc, err := ParseComplianceRules([]byte(`
+rules:
+- name: ssh-v2
+  must_contain: <system xmlns="urn:example:system"><services><ssh><protocol-version>v2</protocol-version></ssh></services></system>
+- name: ssh-v1
+  must_contain: <system><services><ssh><protocol-version>v1</protocol-version></ssh></services></system>
+- name: wrong-namespace
+  must_contain: <system xmlns="urn:example:other"/>
+- name: no-telnet
+  must_not_contain: <system><services><telnet/></services></system>
+- name: no-ftp
+  must_not_contain: <system><services><ftp/></services></system>
+- name: host-name
+  path: /system/host-name
+  pattern: '[a-z][0-9]+'
+- name: ntp-servers
+  path: /system/ntp/server/name
+  values: [10.0.0.1, 10.0.0.2]
+- name: syslog
+  path: /system/syslog
+- name: syslog-hosts
+  path: /system/syslog/host
+  pattern: '10\..*'
+  optional: true
+`))
+	if err != nil {
+		t.Fatalf("ParseComplianceRules failed: %v", err)
+	}
+
+	results, err := cc.Evaluate([]byte(complianceConfig))
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	expected := []ComplianceResult{
+		{Rule: "ssh-v2", Passed: true},
+		{Rule: "ssh-v1", Message: "missing <system><services><ssh><protocol-version>v1</protocol-version></ssh></services></system>"},
+		{Rule: "wrong-namespace", Message: `missing <system xmlns="urn:example:other"/>`},
+		{Rule: "no-telnet", Message: "contains <system><services><telnet/></services></system>"},
+		{Rule: "no-ftp", Passed: true},
+		{Rule: "host-name", Passed: true},
+		{Rule: "ntp-servers", Message: `/system/ntp/server/name: value "192.0.2.9" is not one of 10.0.0.1, 10.0.0.2`},
+		{Rule: "syslog", Message: "/system/syslog selects no nodes"},
+		{Rule: "syslog-hosts", Passed: true},
+	}
+	if diff := cmp.Diff(expected, results); diff != "" {
+		t.Errorf("results mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseComplianceRulesErrors(t *testing.T) {
+	tt := []struct {
+		name string
+		doc  string
+	}{
+		{"noName", "rules: [{path: /a}]"},
+		{"noAssertion", "rules: [{name: a}]"},
+		{"twoAssertions", "rules: [{name: a, path: /a, must_contain: <a/>}]"},
+		{"badPath", "rules: [{name: a, path: '/a['}]"},
+		{"badPattern", "rules: [{name: a, path: /a, pattern: '('}]"},
+		{"badSubtree", "rules: [{name: a, must_contain: <a>}]"},
+		{"unknownField", "rules: [{name: a, path: /a, regexp: x}]"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseComplianceRules([]byte(tc.doc)); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func TestComplianceJob(t *testing.T) {
+	cc := &ComplianceChecker{Rules: []ComplianceRule{
+		{Name: "host-name", Path: "/system/host-name", Values: []string{"r1"}},
+		{Name: "no-telnet", MustNotContain: "<system><services><telnet/></services></system>"},
+	}}
+	s, trans := newScriptedSession(nil, `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0">`+complianceConfig+`</rpc-reply>`)
+
+	r := &DeviceResult{Device: "r1"}
+	err := cc.Job()(context.Background(), s, r)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 rules failed") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	expected := "PASS host-name\nFAIL no-telnet: contains <system><services><telnet/></services></system>\n"
+	if diff := cmp.Diff(expected, r.Diff); diff != "" {
+		t.Errorf("diff mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(trans.sent[0], "<get-config><source><running/></source></get-config>") {
+		t.Errorf("unexpected request %s", trans.sent[0])
+	}
+}
@@ -0,0 +1,72 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io/ioutil"
+)
+
+// Compression enables transparent decompression of <data> payloads that a
+// device sends as base64 encoded gzip.  There is no standard for this, so
+// devices announce it with a vendor capability.
+//
+// Compression of the SSH transport itself cannot be negotiated, since
+// golang.org/x/crypto/ssh only implements the "none" compression method.
+type Compression struct {
+	// Capability announced by servers that compress <data> payloads.
+	// Decompression is only attempted on sessions whose server announced
+	// it.  An empty Capability attempts decompression on every session.
+	Capability string
+}
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// enabled reports whether compressed payloads are expected on s.
+func (c *Compression) enabled(s *Session) bool {
+	return c != nil && (c.Capability == "" || s.HasCapability(c.Capability))
+}
+
+// decompressData replaces base64 encoded gzip content of the <data> element
+// in data, the inner XML of an rpc-reply, with its decompressed content.
+// Data that is not compressed is returned unchanged.
+func decompressData(data RawXML) (RawXML, error) {
+	start := bytes.Index(data, []byte("<data"))
+	if start < 0 {
+		return data, nil
+	}
+	open := bytes.IndexByte(data[start:], '>')
+	end := bytes.LastIndex(data, []byte("</data>"))
+	if open < 0 || data[start+open-1] == '/' || end < start+open {
+		return data, nil
+	}
+	open += start + 1
+
+	encoded := bytes.Join(bytes.Fields(data[open:end]), nil)
+	raw := make([]byte, base64.StdEncoding.DecodedLen(len(encoded)))
+	n, err := base64.StdEncoding.Decode(raw, encoded)
+	if err != nil || !bytes.HasPrefix(raw[:n], gzipMagic) {
+		return data, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(raw[:n]))
+	if err != nil {
+		return nil, err
+	}
+	plain, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(RawXML, 0, open+len(plain)+len(data)-end)
+	out = append(out, data[:open]...)
+	out = append(out, plain...)
+	return append(out, data[end:]...), nil
+}
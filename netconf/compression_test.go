@@ -0,0 +1,70 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"testing"
+)
+
+func gzipBase64(s string) string {
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	zw.Write([]byte(s))
+	zw.Close()
+	return base64.StdEncoding.EncodeToString(b.Bytes())
+}
+
+func TestSessionCompression(t *testing.T) {
+	const capability = "http://example.com/netconf/gzip-data"
+	reply := `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><data>` +
+		gzipBase64("<system><host-name>r1</host-name></system>") + `</data></rpc-reply>`
+
+	tt := []struct {
+		name         string
+		capabilities []string
+		compression  *Compression
+		want         string
+	}{
+		{
+			name:         "announced",
+			capabilities: []string{capability},
+			compression:  &Compression{Capability: capability},
+			want:         "<data><system><host-name>r1</host-name></system></data>",
+		},
+		{
+			name:        "notAnnounced",
+			compression: &Compression{Capability: capability},
+			want:        "<data>" + gzipBase64("<system><host-name>r1</host-name></system>") + "</data>",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s, _ := newScriptedSession(tc.capabilities, reply)
+			s.Compression = tc.compression
+
+			r, err := s.Exec(MethodGetConfig("running"))
+			if err != nil {
+				t.Fatalf("Exec failed: %v", err)
+			}
+			if r.Data.String() != tc.want {
+				t.Errorf("unexpected data %q", r.Data)
+			}
+		})
+	}
+}
+
+func TestDecompressDataPlain(t *testing.T) {
+	for _, data := range []string{"<ok/>", "<data/>", "<data>abcd</data>", "<data><a/></data>"} {
+		if got, err := decompressData(RawXML(data)); err != nil || got.String() != data {
+			t.Errorf("decompressData(%q) = %q, %v", data, got, err)
+		}
+	}
+}
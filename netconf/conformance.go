@@ -0,0 +1,342 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SchemaViolation is an element of data that does not conform to a Schema.
+type SchemaViolation struct {
+	// Path is the instance path of the element, with the keys of list
+	// entries, e.g. /interfaces/interface[name='eth0']/mtu.
+	Path    string
+	Message string
+}
+
+func (v SchemaViolation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// SchemaValidationError is returned by SchemaInterceptor for replies that
+// do not conform to the schema.
+type SchemaValidationError struct {
+	Operation  string
+	Violations []SchemaViolation
+}
+
+func (e *SchemaValidationError) Error() string {
+	msg := fmt.Sprintf("netconf: %s reply does not conform to schema: %s", e.Operation, e.Violations[0])
+	if len(e.Violations) > 1 {
+		msg += fmt.Sprintf(" and %d more", len(e.Violations)-1)
+	}
+	return msg
+}
+
+// Validate checks data, the content of a <data> or <config> element or the
+// element itself, against the schema.  It reports elements the schema does
+// not define, leaves holding values not valid for their type and list
+// entries missing keys.  Mandatory nodes and constraints are not checked,
+// as replies often hold only part of the data.
+func (sc *Schema) Validate(data []byte) ([]SchemaViolation, error) {
+	root, err := configRoot(data)
+	if err != nil {
+		return nil, err
+	}
+	return sc.ValidateNodes(root.Children), nil
+}
+
+// ValidateNodes checks top-level data nodes against the schema, see
+// Validate.
+func (sc *Schema) ValidateNodes(nodes []*Node) []SchemaViolation {
+	var violations []SchemaViolation
+	for _, n := range nodes {
+		violations = sc.validate(sc.Root, n, "", violations)
+	}
+	return violations
+}
+
+func (sc *Schema) validate(parent *SchemaNode, n *Node, path string, violations []SchemaViolation) []SchemaViolation {
+	path += "/" + n.XMLName.Local
+	var s *SchemaNode
+	for _, c := range parent.Children {
+		if c.Name == n.XMLName.Local && (n.XMLName.Space == "" || c.Namespace == n.XMLName.Space) {
+			s = c
+			break
+		}
+	}
+	if s == nil {
+		msg := "unknown element"
+		if n.XMLName.Space != "" {
+			msg += " in namespace " + n.XMLName.Space
+		}
+		return append(violations, SchemaViolation{Path: path, Message: msg})
+	}
+
+	switch s.Keyword {
+	case "leaf", "leaf-list":
+		if len(n.Children) > 0 {
+			return append(violations, SchemaViolation{Path: path, Message: "unexpected child elements of " + s.Keyword})
+		}
+		if t := s.Statement.Sub("type"); t != nil {
+			if msg := sc.checkValue(t, s.def, n.Text, 0); msg != "" {
+				violations = append(violations, SchemaViolation{Path: path, Message: fmt.Sprintf("invalid value %q: %s", n.Text, msg)})
+			}
+		}
+		return violations
+	case "list":
+		var keys []string
+		for _, k := range s.Keys {
+			if c := n.Child(k); c != nil {
+				keys = append(keys, fmt.Sprintf("[%s=%s]", k, xpathLiteral(c.Text)))
+			} else {
+				violations = append(violations, SchemaViolation{Path: path, Message: "missing key " + k})
+			}
+		}
+		path += strings.Join(keys, "")
+	}
+	for _, c := range n.Children {
+		violations = sc.validate(s, c, path, violations)
+	}
+	return violations
+}
+
+// xpathLiteral quotes s as an XPath string literal.
+func xpathLiteral(s string) string {
+	if strings.Contains(s, "'") {
+		return `"` + s + `"`
+	}
+	return "'" + s + "'"
+}
+
+// yangIntegers are the ranges of the integer built-in types.
+var yangIntegers = map[string][2]float64{
+	"int8":   {math.MinInt8, math.MaxInt8},
+	"int16":  {math.MinInt16, math.MaxInt16},
+	"int32":  {math.MinInt32, math.MaxInt32},
+	"int64":  {math.MinInt64, math.MaxInt64},
+	"uint8":  {0, math.MaxUint8},
+	"uint16": {0, math.MaxUint16},
+	"uint32": {0, math.MaxUint32},
+	"uint64": {0, math.MaxUint64},
+}
+
+// checkValue checks value against the type statement t written in module
+// m, returning what is wrong with it or "".  Derived types are resolved
+// through their typedefs; leafref, identityref and instance-identifier
+// values, and types that cannot be resolved, are accepted.
+func (sc *Schema) checkValue(t *YANGStatement, m *yangModule, value string, depth int) string {
+	if depth > 32 {
+		return ""
+	}
+	prefix, name := splitPrefix(t.Argument)
+	if b, ok := yangIntegers[name]; ok && prefix == "" {
+		if name[0] == 'u' {
+			if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+				return "not a " + name
+			}
+		} else if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return "not a " + name
+		}
+		// The value was parsed as a 64-bit integer already, only the
+		// bounds of smaller types need checking.
+		if f, _ := strconv.ParseFloat(value, 64); name != "int64" && name != "uint64" && (f < b[0] || f > b[1]) {
+			return "out of range for " + name
+		}
+		return checkRange(t, "range", value, 0)
+	}
+
+	switch {
+	case prefix != "":
+	case name == "string":
+		if msg := checkRange(t, "length", value, utf8.RuneCountInString(value)); msg != "" {
+			return msg
+		}
+		return checkPatterns(t, value)
+	case name == "boolean":
+		if value != "true" && value != "false" {
+			return "not a boolean"
+		}
+		return ""
+	case name == "empty":
+		if value != "" {
+			return "empty leaf with a value"
+		}
+		return ""
+	case name == "decimal64":
+		if _, err := strconv.ParseFloat(value, 64); err != nil || strings.ContainsAny(value, "eE") {
+			return "not a decimal64"
+		}
+		if fd := t.Sub("fraction-digits"); fd != nil {
+			if i := strings.IndexByte(value, '.'); i >= 0 {
+				if digits, _ := strconv.Atoi(fd.Argument); len(value)-i-1 > digits {
+					return "more than " + fd.Argument + " fraction digits"
+				}
+			}
+		}
+		return checkRange(t, "range", value, 0)
+	case name == "enumeration":
+		for _, e := range t.Substatements {
+			if e.Keyword == "enum" && e.Argument == value {
+				return ""
+			}
+		}
+		return "not an enumeration value"
+	case name == "bits":
+		for _, bit := range strings.Fields(value) {
+			found := false
+			for _, b := range t.Substatements {
+				if b.Keyword == "bit" && b.Argument == bit {
+					found = true
+				}
+			}
+			if !found {
+				return "unknown bit " + bit
+			}
+		}
+		return ""
+	case name == "binary":
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+		if err != nil {
+			return "not base64"
+		}
+		return checkRange(t, "length", value, len(decoded))
+	case name == "union":
+		for _, member := range t.Substatements {
+			if member.Keyword == "type" && sc.checkValue(member, m, value, depth+1) == "" {
+				return ""
+			}
+		}
+		return "matches no member of union"
+	case name == "leafref" || name == "identityref" || name == "instance-identifier":
+		return ""
+	}
+
+	// A derived type: check the typedef and then the restrictions added
+	// to it.
+	tm := prefixModule(sc.modules, m, prefix)
+	if tm == nil || tm.typedefs[name] == nil {
+		return ""
+	}
+	if base := tm.typedefs[name].Sub("type"); base != nil {
+		if msg := sc.checkValue(base, tm, value, depth+1); msg != "" {
+			return msg
+		}
+	}
+	if msg := checkRange(t, "range", value, 0); msg != "" {
+		return msg
+	}
+	if msg := checkRange(t, "length", value, utf8.RuneCountInString(value)); msg != "" {
+		return msg
+	}
+	return checkPatterns(t, value)
+}
+
+// checkRange checks a value against the range or length restriction of t,
+// e.g. "1..10 | 20..max".  Ranges are checked against the numeric value,
+// lengths against n.
+func checkRange(t *YANGStatement, keyword, value string, n int) string {
+	r := t.Sub(keyword)
+	if r == nil {
+		return ""
+	}
+	v := float64(n)
+	if keyword == "range" {
+		var err error
+		if v, err = strconv.ParseFloat(value, 64); err != nil {
+			return ""
+		}
+	}
+	for _, part := range strings.Split(r.Argument, "|") {
+		bounds := strings.SplitN(part, "..", 2)
+		lo, ok := rangeBound(bounds[0], math.Inf(-1))
+		if !ok {
+			return ""
+		}
+		hi := lo
+		if len(bounds) == 2 {
+			if hi, ok = rangeBound(bounds[1], math.Inf(1)); !ok {
+				return ""
+			}
+		}
+		if v >= lo && v <= hi {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%s not within %s", keyword, strings.TrimSpace(r.Argument))
+}
+
+// rangeBound parses a bound of a range, def standing for min or max.
+func rangeBound(s string, def float64) (float64, bool) {
+	switch s = strings.TrimSpace(s); s {
+	case "min", "max":
+		return def, true
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
+
+// checkPatterns checks value against the patterns of t.  Patterns that are
+// not valid Go regular expressions are ignored.
+func checkPatterns(t *YANGStatement, value string) string {
+	for _, p := range t.Substatements {
+		if p.Keyword != "pattern" {
+			continue
+		}
+		re, err := regexp.Compile(`^(?:` + p.Argument + `)$`)
+		if err != nil {
+			continue
+		}
+		invert := false
+		if mod := p.Sub("modifier"); mod != nil && mod.Argument == "invert-match" {
+			invert = true
+		}
+		if re.MatchString(value) == invert {
+			return "does not match pattern " + p.Argument
+		}
+	}
+	return ""
+}
+
+// SchemaInterceptor returns an interceptor validating the data of get,
+// get-config and get-data replies against sc.  Replies that do not conform
+// are passed to handle with their violations, and the RPC fails with the
+// error handle returns.  If handle is nil, the RPC fails with a
+// *SchemaValidationError.
+func SchemaInterceptor(sc *Schema, handle func(ctx context.Context, reply *RPCReply, violations []SchemaViolation) error) Interceptor {
+	return func(ctx context.Context, methods []RPCMethod, invoke Invoker) (*RPCReply, error) {
+		reply, err := invoke(ctx, methods)
+		if err != nil || len(methods) != 1 {
+			return reply, err
+		}
+		switch op := methodName(methods[0]); op {
+		case "get", "get-config", "get-data":
+			violations, err := sc.Validate(reply.Data)
+			if err != nil || len(violations) == 0 {
+				return reply, nil
+			}
+			if handle == nil {
+				return reply, &SchemaValidationError{Operation: op, Violations: violations}
+			}
+			return reply, handle(ctx, reply, violations)
+		}
+		return reply, nil
+	}
+}
+
+// WithSchemaValidation validates the replies of the session against sc, see
+// SchemaInterceptor.
+func WithSchemaValidation(sc *Schema, handle func(ctx context.Context, reply *RPCReply, violations []SchemaViolation) error) Option {
+	return WithInterceptors(SchemaInterceptor(sc, handle))
+}
@@ -0,0 +1,163 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testYANGTypes = `module example-types {
+  namespace "urn:example:types";
+  prefix t;
+
+  typedef percent {
+    type uint8 { range "0..100"; }
+  }
+  typedef hostname {
+    type string { length "1..16"; pattern '[a-z][a-z0-9-]*'; }
+  }
+
+  container system {
+    leaf hostname { type hostname; }
+    leaf load { type t:percent { range "0..90"; } }
+    leaf enabled { type boolean; }
+    leaf mode { type enumeration { enum active; enum standby; } }
+    leaf timeout { type union { type uint16; type enumeration { enum never; } } }
+    leaf ratio { type decimal64 { fraction-digits 2; } }
+    leaf flags { type bits { bit up; bit running; } }
+    leaf offset { type int8; }
+    leaf debug { type empty; }
+    leaf-list server { type string; }
+    list user {
+      key name;
+      leaf name { type string; }
+      leaf uid { type uint32; }
+    }
+  }
+}
+`
+
+func newTypesSchema(t *testing.T) *Schema {
+	module, err := ParseYANG([]byte(testYANGTypes))
+	if err != nil {
+		t.Fatalf("ParseYANG failed: %v", err)
+	}
+	sc, err := NewSchema(module)
+	if err != nil {
+		t.Fatalf("NewSchema failed: %v", err)
+	}
+	return sc
+}
+
+func TestSchemaValidate(t *testing.T) {
+	sc := newTypesSchema(t)
+
+	tt := []struct {
+		name     string
+		data     string
+		expected []SchemaViolation
+	}{
+		{
+			name: "valid",
+			data: `<data><system xmlns="urn:example:types"><hostname>r1</hostname><load>42</load><enabled>true</enabled>` +
+				`<mode>standby</mode><timeout>never</timeout><ratio>0.25</ratio><flags>up running</flags>` +
+				`<offset>-5</offset><debug/><server>a</server><server>b</server>` +
+				`<user><name>bob</name><uid>1000</uid></user></system></data>`,
+		},
+		{
+			name: "unknown elements",
+			data: `<system xmlns="urn:example:types"><hostnmae>r1</hostnmae></system><system xmlns="urn:example:other"/>`,
+			expected: []SchemaViolation{
+				{Path: "/system/hostnmae", Message: "unknown element in namespace urn:example:types"},
+				{Path: "/system", Message: "unknown element in namespace urn:example:other"},
+			},
+		},
+		{
+			name: "derived types",
+			data: `<system><hostname>R1</hostname><load>95</load></system><system><load>101</load><hostname>router-with-a-long-name</hostname></system>`,
+			expected: []SchemaViolation{
+				{Path: "/system/hostname", Message: `invalid value "R1": does not match pattern [a-z][a-z0-9-]*`},
+				{Path: "/system/load", Message: `invalid value "95": range not within 0..90`},
+				{Path: "/system/load", Message: `invalid value "101": range not within 0..100`},
+				{Path: "/system/hostname", Message: `invalid value "router-with-a-long-name": length not within 1..16`},
+			},
+		},
+		{
+			name: "built-in types",
+			data: `<system><enabled>yes</enabled><mode>down</mode><timeout>soon</timeout><ratio>0.125</ratio>` +
+				`<flags>up down</flags><offset>200</offset><debug>1</debug></system>`,
+			expected: []SchemaViolation{
+				{Path: "/system/enabled", Message: `invalid value "yes": not a boolean`},
+				{Path: "/system/mode", Message: `invalid value "down": not an enumeration value`},
+				{Path: "/system/timeout", Message: `invalid value "soon": matches no member of union`},
+				{Path: "/system/ratio", Message: `invalid value "0.125": more than 2 fraction digits`},
+				{Path: "/system/flags", Message: `invalid value "up down": unknown bit down`},
+				{Path: "/system/offset", Message: `invalid value "200": out of range for int8`},
+				{Path: "/system/debug", Message: `invalid value "1": empty leaf with a value`},
+			},
+		},
+		{
+			name: "lists",
+			data: `<system><user><uid>x</uid></user><user><name>bob</name><uid>-1</uid></user><enabled><x/></enabled></system>`,
+			expected: []SchemaViolation{
+				{Path: "/system/user", Message: "missing key name"},
+				{Path: "/system/user/uid", Message: `invalid value "x": not a uint32`},
+				{Path: "/system/user[name='bob']/uid", Message: `invalid value "-1": not a uint32`},
+				{Path: "/system/enabled", Message: "unexpected child elements of leaf"},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		violations, err := sc.Validate([]byte(tc.data))
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+			continue
+		}
+		if diff := cmp.Diff(tc.expected, violations); diff != "" {
+			t.Errorf("%s: violations mismatch (-expected +got):\n%s", tc.name, diff)
+		}
+	}
+}
+
+func TestSchemaInterceptor(t *testing.T) {
+	sc := newTypesSchema(t)
+	reply := `<rpc-reply message-id="1"><data><system xmlns="urn:example:types"><load>high</load></system></data></rpc-reply>`
+
+	s, _ := newScriptedSession(nil, reply, reply, replyOK)
+	s.Interceptors = []Interceptor{SchemaInterceptor(sc, nil)}
+	_, err := s.ExecContext(context.Background(), MethodGetConfig("running"))
+	var validationErr *SchemaValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("got %v, expected a SchemaValidationError", err)
+	}
+	expected := `netconf: get-config reply does not conform to schema: /system/load: invalid value "high": not a uint8`
+	if err.Error() != expected {
+		t.Errorf("got %q, expected %q", err, expected)
+	}
+
+	var reported []SchemaViolation
+	s.Interceptors = []Interceptor{SchemaInterceptor(sc, func(ctx context.Context, reply *RPCReply, violations []SchemaViolation) error {
+		reported = violations
+		return nil
+	})}
+	if _, err := s.ExecContext(context.Background(), MethodGet("subtree", "<system/>")); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if len(reported) != 1 {
+		t.Errorf("got %v, expected one reported violation", reported)
+	}
+
+	// Other operations are not validated.
+	if _, err := s.ExecContext(context.Background(), MethodCommit()); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !plan9
+// +build !plan9
+
+package netconf
+
+import (
+	"errors"
+	"syscall"
+)
+
+// connReset reports whether err tells of a connection reset or closed by
+// the peer.
+func connReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,14 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+// connReset reports whether err tells of a connection reset or closed by
+// the peer.  Plan 9 has no error numbers for either, so a reset connection
+// is only recognised by the EOF that follows.
+func connReset(err error) bool {
+	return false
+}
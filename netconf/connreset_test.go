@@ -0,0 +1,25 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !plan9
+// +build !plan9
+
+package netconf
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+)
+
+func TestConnReset(t *testing.T) {
+	for _, err := range []error{syscall.ECONNRESET, syscall.EPIPE, fmt.Errorf("writing: %w", syscall.EPIPE)} {
+		if !errors.Is(transportError("write", false, err), ErrSessionClosed) {
+			t.Errorf("expected %v to match ErrSessionClosed", err)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Credentials authenticate a session to a device.  Only the fields that are
+// set are used; the others are taken from the session configuration.
+type Credentials struct {
+	Username string
+	Password string
+	// PrivateKey is a PEM encoded SSH private key, decrypted with
+	// Passphrase if encrypted.
+	PrivateKey []byte
+	Passphrase string
+	// Certificate is the client certificate of NETCONF over TLS.
+	Certificate *tls.Certificate
+}
+
+// CredentialProvider supplies the credentials of a device when a session to
+// it is dialled, so that secrets can be kept in a store such as Vault or a
+// KMS rather than in the configuration of the application.
+type CredentialProvider interface {
+	GetCredentials(ctx context.Context, d *Device) (*Credentials, error)
+}
+
+// StaticCredentials is a CredentialProvider holding credentials in memory.
+// It looks up the Credential name of the device, then the device name, then
+// the empty string as a default.
+type StaticCredentials map[string]*Credentials
+
+// GetCredentials implements CredentialProvider.
+func (c StaticCredentials) GetCredentials(ctx context.Context, d *Device) (*Credentials, error) {
+	if creds, ok := c[d.Credential]; ok && d.Credential != "" {
+		return creds, nil
+	}
+	if creds, ok := c[d.Name]; ok {
+		return creds, nil
+	}
+	if creds, ok := c[""]; ok {
+		return creds, nil
+	}
+	return nil, fmt.Errorf("netconf: no credentials for device %s", d.Name)
+}
+
+// WithCredentialProvider fetches the credentials of every session from p
+// when it is dialled.
+func WithCredentialProvider(p CredentialProvider) Option {
+	return func(c *SessionConfig) { c.Credentials = p }
+}
+
+// DialDevice establishes a session to d with the configuration and the
+// credentials of the device, if a CredentialProvider is set.  The addresses
+// of the device are tried in order, see DialAny.
+func (c *SessionConfig) DialDevice(ctx context.Context, d *Device) (*Session, error) {
+	c, err := c.resolveCredentials(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	return c.DialAny(ctx, d.Targets()...)
+}
+
+// resolveCredentials returns the configuration with the credentials of d
+// from the CredentialProvider applied.
+func (c *SessionConfig) resolveCredentials(ctx context.Context, d *Device) (*SessionConfig, error) {
+	if c.Credentials == nil {
+		return c, nil
+	}
+	creds, err := c.Credentials.GetCredentials(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	config, err := c.withCredentials(creds)
+	if err != nil {
+		return nil, fmt.Errorf("netconf: device %s: %v", d.Name, err)
+	}
+	return config, nil
+}
+
+// targetDevice returns the device fetched from the CredentialProvider for a
+// session dialled by address.
+func targetDevice(target string) *Device {
+	host, _ := splitTarget(target)
+	return &Device{Name: host, Address: host}
+}
+
+// withCredentials returns a copy of the configuration authenticating with
+// creds.  Host keys are checked against ~/.ssh/known_hosts unless the SSH
+// configuration has a HostKeyCallback.
+func (c *SessionConfig) withCredentials(creds *Credentials) (*SessionConfig, error) {
+	config := *c
+	config.Credentials = nil
+
+	if creds.Certificate != nil {
+		if config.TLSConfig == nil {
+			return nil, fmt.Errorf("client certificate without TLS configuration")
+		}
+		tc := config.TLSConfig.Clone()
+		tc.Certificates = []tls.Certificate{*creds.Certificate}
+		config.TLSConfig = tc
+	}
+
+	var auth []ssh.AuthMethod
+	if creds.PrivateKey != nil {
+		var signer ssh.Signer
+		var err error
+		if creds.Passphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(creds.PrivateKey, []byte(creds.Passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(creds.PrivateKey)
+		}
+		if err != nil {
+			return nil, err
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if creds.Password != "" {
+		auth = append(auth, ssh.Password(creds.Password))
+	}
+	if len(auth) == 0 && creds.Username == "" {
+		return &config, nil
+	}
+
+	sc := &ssh.ClientConfig{}
+	if config.SSHConfig != nil {
+		*sc = *config.SSHConfig
+	}
+	if sc.HostKeyCallback == nil {
+		sc.HostKeyCallback = checkKnownHosts
+	}
+	if creds.Username != "" {
+		sc.User = creds.Username
+	}
+	sc.Auth = append(auth, sc.Auth...)
+	config.SSHConfig = sc
+	return &config, nil
+}
+
+// checkKnownHosts is the HostKeyCallback of the SSH configurations built
+// without one: the host key must be listed in ~/.ssh/known_hosts.  Host
+// keys are only left unchecked if asked for with ssh.InsecureIgnoreHostKey.
+func checkKnownHosts(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("netconf: cannot check host key: %v", err)
+	}
+	callback, err := knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+	if err != nil {
+		return fmt.Errorf("netconf: cannot check host key: %v", err)
+	}
+	return callback(hostname, remote, key)
+}
@@ -0,0 +1,163 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestStaticCredentials(t *testing.T) {
+	admin := &Credentials{Username: "admin"}
+	ops := &Credentials{Username: "ops"}
+	def := &Credentials{Username: "default"}
+	tt := []struct {
+		creds    StaticCredentials
+		device   *Device
+		expected *Credentials
+	}{
+		{StaticCredentials{"core": admin, "r1": ops}, &Device{Name: "r1", Credential: "core"}, admin},
+		{StaticCredentials{"core": admin, "r1": ops}, &Device{Name: "r1", Credential: "edge"}, ops},
+		{StaticCredentials{"r1": ops, "": def}, &Device{Name: "r2"}, def},
+		{StaticCredentials{"r1": ops}, &Device{Name: "r2"}, nil},
+	}
+	for _, tc := range tt {
+		got, err := tc.creds.GetCredentials(context.Background(), tc.device)
+		if tc.expected == nil {
+			if err == nil {
+				t.Errorf("%s: expected error", tc.device.Name)
+			}
+			continue
+		}
+		if err != nil || got != tc.expected {
+			t.Errorf("%s: got %v (%v), expected %v", tc.device.Name, got, err, tc.expected)
+		}
+	}
+}
+
+func TestWithCredentials(t *testing.T) {
+	c := NewSessionConfig(WithSSHConfig(testSSHConfig()), WithPassword("test", "stored"))
+	config, err := c.withCredentials(&Credentials{Username: "ops", Password: "secret"})
+	if err != nil {
+		t.Fatalf("withCredentials failed: %v", err)
+	}
+	if config.SSHConfig.User != "ops" || len(config.SSHConfig.Auth) != 2 || config.SSHConfig.HostKeyCallback == nil {
+		t.Errorf("unexpected SSH config %+v", config.SSHConfig)
+	}
+	if c.SSHConfig.User != "test" || len(c.SSHConfig.Auth) != 1 {
+		t.Errorf("original SSH config modified: %+v", c.SSHConfig)
+	}
+
+	if _, err := c.withCredentials(&Credentials{PrivateKey: []byte("not a key")}); err == nil {
+		t.Error("expected error for invalid private key")
+	}
+	if _, err := c.withCredentials(&Credentials{Certificate: &tls.Certificate{}}); err == nil {
+		t.Error("expected error for certificate without TLS configuration")
+	}
+	cert := &tls.Certificate{Certificate: [][]byte{[]byte("cert")}}
+	config, err = NewSessionConfig(WithTLSConfig(&tls.Config{})).withCredentials(&Credentials{Certificate: cert})
+	if err != nil || len(config.TLSConfig.Certificates) != 1 {
+		t.Errorf("client certificate not applied: %v", err)
+	}
+}
+
+type recordingCredentials struct {
+	mu      sync.Mutex
+	devices []string
+}
+
+func (r *recordingCredentials) GetCredentials(ctx context.Context, d *Device) (*Credentials, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.devices = append(r.devices, d.Name)
+	return &Credentials{Username: "ops", Password: "secret"}, nil
+}
+
+func TestClientCredentialProvider(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+	host, portStr, _ := net.SplitHostPort(srv.Addr())
+	port, _ := strconv.Atoi(portStr)
+
+	creds := &recordingCredentials{}
+	c := NewClient(WithSSHConfig(testSSHConfig()), WithCredentialProvider(creds), WithTimeout(5*time.Second))
+	c.Inventory = &Inventory{Devices: []*Device{{Name: "r1", Address: host, Port: port}}}
+	ctx := context.Background()
+
+	pool := c.Pool(c.Inventory)
+	defer pool.Close()
+	err := pool.Do(ctx, "r1", func(s *Session) error {
+		_, err := s.ExecContext(ctx, MethodGetConfig("running"))
+		return err
+	})
+	if err != nil {
+		t.Errorf("pool Do failed: %v", err)
+	}
+
+	s, err := c.Dial(ctx, srv.Addr())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	s.Close()
+
+	creds.mu.Lock()
+	defer creds.mu.Unlock()
+	if len(creds.devices) != 2 || creds.devices[0] != "r1" || creds.devices[1] != host {
+		t.Errorf("unexpected credential lookups %v", creds.devices)
+	}
+}
+
+func TestCredentialsKnownHosts(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+	home, err := ioutil.TempDir("", "netconf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+
+	creds := StaticCredentials{"": {Username: "ops"}}
+	if _, err := Dial(srv.Addr(), WithCredentialProvider(creds), WithTimeout(5*time.Second)); err == nil || !strings.Contains(err.Error(), "known_hosts") {
+		t.Errorf("got %v, expected an error without known_hosts", err)
+	}
+
+	var hostKey ssh.PublicKey
+	config := testSSHConfig()
+	config.HostKeyCallback = func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		hostKey = key
+		return nil
+	}
+	s, err := Dial(srv.Addr(), WithSSHConfig(config), WithTimeout(5*time.Second))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	s.Close()
+	os.Mkdir(filepath.Join(home, ".ssh"), 0700)
+	line := knownhosts.Line([]string{knownhosts.Normalize(srv.Addr())}, hostKey) + "\n"
+	if err := ioutil.WriteFile(filepath.Join(home, ".ssh", "known_hosts"), []byte(line), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s, err = Dial(srv.Addr(), WithCredentialProvider(creds), WithTimeout(5*time.Second))
+	if err != nil {
+		t.Fatalf("Dial with known host failed: %v", err)
+	}
+	s.Close()
+}
@@ -0,0 +1,206 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrDeviceSuppressed is returned for devices suppressed by a Dampener.
+var ErrDeviceSuppressed = errors.New("netconf: device suppressed")
+
+// Dampener defaults.
+const (
+	DefaultDampeningThreshold  = 3
+	DefaultDampeningWindow     = time.Minute
+	DefaultDampeningQuarantine = 30 * time.Second
+	DefaultMaxQuarantine       = 30 * time.Minute
+)
+
+// DampeningEvent reports that a device was suppressed or reinstated.
+type DampeningEvent struct {
+	Device     string
+	Suppressed bool
+	// Flaps is the number of failures that led to the suppression.
+	Flaps int
+	// Until is the end of the quarantine of a suppressed device.
+	Until time.Time
+	// Err is the failure that suppressed the device.
+	Err error
+}
+
+func (e DampeningEvent) String() string {
+	if !e.Suppressed {
+		return fmt.Sprintf("device %s reinstated", e.Device)
+	}
+	return fmt.Sprintf("device %s suppressed until %s after %d failures: %v",
+		e.Device, e.Until.Format(time.RFC3339), e.Flaps, e.Err)
+}
+
+// Dampener suppresses devices that keep failing, so that a broken device
+// does not cause a storm of reconnections.  A device failing Threshold times
+// within Window is quarantined; the quarantine doubles with every further
+// suppression, up to MaxQuarantine, and starts over once the device ran for
+// MaxQuarantine without being suppressed.  It is safe for concurrent use;
+// the zero value uses the defaults.
+type Dampener struct {
+	// Threshold is the number of failures that suppress a device,
+	// DefaultDampeningThreshold if zero.
+	Threshold int
+	// Window is the time the failures are counted in,
+	// DefaultDampeningWindow if zero.
+	Window time.Duration
+	// Quarantine is the first suppression, DefaultDampeningQuarantine if
+	// zero.
+	Quarantine time.Duration
+	// MaxQuarantine caps the suppressions, DefaultMaxQuarantine if zero.
+	MaxQuarantine time.Duration
+	// OnEvent, if set, is called when a device is suppressed or reinstated.
+	// Reinstatement is noticed by the first Allow after the quarantine.
+	OnEvent func(e DampeningEvent)
+
+	mu      sync.Mutex
+	devices map[string]*dampState
+}
+
+type dampState struct {
+	failures     []time.Time
+	suppressions int
+	suppressed   bool
+	until        time.Time
+}
+
+// Allow returns an error wrapping ErrDeviceSuppressed if device must not be
+// connected to.
+func (d *Dampener) Allow(device string) error {
+	d.mu.Lock()
+	st := d.devices[device]
+	if st == nil || !st.suppressed {
+		d.mu.Unlock()
+		return nil
+	}
+	if until := st.until; time.Now().Before(until) {
+		d.mu.Unlock()
+		return fmt.Errorf("%w %q until %s", ErrDeviceSuppressed, device, until.Format(time.RFC3339))
+	}
+	st.suppressed = false
+	st.failures = nil
+	d.mu.Unlock()
+
+	d.emit(DampeningEvent{Device: device})
+	return nil
+}
+
+// Failure records a failed connection or session of device, suppressing it
+// once the threshold is reached.
+func (d *Dampener) Failure(device string, err error) {
+	now := time.Now()
+	d.mu.Lock()
+	if d.devices == nil {
+		d.devices = make(map[string]*dampState)
+	}
+	st := d.devices[device]
+	if st == nil {
+		st = &dampState{}
+		d.devices[device] = st
+	}
+	if st.suppressed {
+		d.mu.Unlock()
+		return
+	}
+	if st.suppressions > 0 && now.Sub(st.until) >= d.maxQuarantine() {
+		st.suppressions = 0
+	}
+
+	window := d.Window
+	if window <= 0 {
+		window = DefaultDampeningWindow
+	}
+	recent := st.failures[:0]
+	for _, t := range st.failures {
+		if now.Sub(t) < window {
+			recent = append(recent, t)
+		}
+	}
+	st.failures = append(recent, now)
+
+	threshold := d.Threshold
+	if threshold <= 0 {
+		threshold = DefaultDampeningThreshold
+	}
+	if len(st.failures) < threshold {
+		d.mu.Unlock()
+		return
+	}
+	quarantine := d.quarantine(st.suppressions)
+	st.suppressions++
+	st.suppressed = true
+	st.until = now.Add(quarantine)
+	e := DampeningEvent{Device: device, Suppressed: true, Flaps: len(st.failures), Until: st.until, Err: err}
+	d.mu.Unlock()
+
+	d.emit(e)
+}
+
+// Suppressed returns the end of the quarantine of device, and whether it is
+// suppressed.
+func (d *Dampener) Suppressed(device string) (time.Time, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	st := d.devices[device]
+	if st == nil || !st.suppressed || !time.Now().Before(st.until) {
+		return time.Time{}, false
+	}
+	return st.until, true
+}
+
+// quarantine returns the quarantine after n earlier suppressions.
+func (d *Dampener) quarantine(n int) time.Duration {
+	q := d.Quarantine
+	if q <= 0 {
+		q = DefaultDampeningQuarantine
+	}
+	max := d.maxQuarantine()
+	for i := 0; i < n && q < max; i++ {
+		q *= 2
+	}
+	if q > max {
+		q = max
+	}
+	return q
+}
+
+func (d *Dampener) maxQuarantine() time.Duration {
+	if d.MaxQuarantine <= 0 {
+		return DefaultMaxQuarantine
+	}
+	return d.MaxQuarantine
+}
+
+func (d *Dampener) emit(e DampeningEvent) {
+	if d.OnEvent != nil {
+		d.OnEvent(e)
+	}
+}
+
+// flapped reports whether err, the error a session ended its use with,
+// shows the connection to the device failed.
+func flapped(err error) bool {
+	var timeout *TimeoutError
+	var framing *FramingError
+	return errors.Is(err, ErrTransportBroken) || errors.As(err, &timeout) || errors.As(err, &framing)
+}
+
+// dialFailed reports whether err, the error of a dial, is a failure of the
+// device rather than a cancellation by the caller.
+func dialFailed(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() == nil
+}
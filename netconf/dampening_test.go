@@ -0,0 +1,108 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDampener(t *testing.T) {
+	var mu sync.Mutex
+	var events []DampeningEvent
+	d := &Dampener{
+		Threshold:     2,
+		Quarantine:    20 * time.Millisecond,
+		MaxQuarantine: 50 * time.Millisecond,
+		OnEvent: func(e DampeningEvent) {
+			mu.Lock()
+			events = append(events, e)
+			mu.Unlock()
+		},
+	}
+	boom := errors.New("boom")
+
+	for i, expected := range []time.Duration{20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond} {
+		d.Failure("r1", boom)
+		if err := d.Allow("r1"); err != nil {
+			t.Fatalf("suppression %d: device suppressed after a single failure: %v", i, err)
+		}
+		start := time.Now()
+		d.Failure("r1", boom)
+		until, ok := d.Suppressed("r1")
+		if !ok || until.Sub(start) < expected || until.Sub(start) > expected+10*time.Millisecond {
+			t.Fatalf("suppression %d: got %s, %v, expected a quarantine of %s", i, until.Sub(start), ok, expected)
+		}
+		if err := d.Allow("r1"); !errors.Is(err, ErrDeviceSuppressed) {
+			t.Fatalf("suppression %d: got %v, expected ErrDeviceSuppressed", i, err)
+		}
+		if err := d.Allow("r2"); err != nil {
+			t.Fatalf("suppression %d: other device suppressed: %v", i, err)
+		}
+
+		time.Sleep(time.Until(until) + time.Millisecond)
+		if err := d.Allow("r1"); err != nil {
+			t.Fatalf("suppression %d: device not reinstated: %v", i, err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 6 {
+		t.Fatalf("got events %v, expected 3 suppressions and reinstatements", events)
+	}
+	for i, e := range events {
+		if e.Device != "r1" || e.Suppressed != (i%2 == 0) {
+			t.Errorf("unexpected event %d: %s", i, e)
+		}
+		if e.Suppressed && (e.Flaps != 2 || e.Err != boom) {
+			t.Errorf("unexpected suppression %d: %s", i, e)
+		}
+	}
+}
+
+func TestPoolDampening(t *testing.T) {
+	p, dials := newTestPool()
+	p.Dampener = &Dampener{Threshold: 2, Quarantine: time.Hour}
+	ctx := context.Background()
+	commit := func(s *Session) error {
+		_, err := s.ExecContext(ctx, MethodCommit())
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := p.Do(ctx, "r1", commit); err == nil || errors.Is(err, ErrDeviceSuppressed) {
+			t.Fatalf("attempt %d: got %v, expected the session to fail", i, err)
+		}
+	}
+	if err := p.Do(ctx, "r1", commit); !errors.Is(err, ErrDeviceSuppressed) {
+		t.Errorf("got %v, expected ErrDeviceSuppressed", err)
+	}
+	if *dials != 2 {
+		t.Errorf("got %d dials, expected 2", *dials)
+	}
+}
+
+func TestFleetDampening(t *testing.T) {
+	f := newFleetTest("unreachable", "ok")
+	f.Dampener = &Dampener{Threshold: 1, Quarantine: time.Hour}
+
+	job := func(ctx context.Context, s *Session, r *DeviceResult) error { return nil }
+	if r := f.Run(context.Background(), job).Results[0]; r.Status != StatusFailed {
+		t.Errorf("first run: got status %s, expected failed", r.Status)
+	}
+	report := f.Run(context.Background(), job)
+	if r := report.Results[0]; r.Status != StatusSkipped {
+		t.Errorf("second run: got status %s, expected skipped", r.Status)
+	}
+	if r := report.Results[1]; r.Status != StatusOK {
+		t.Errorf("second run: got status %s for the healthy device, expected ok", r.Status)
+	}
+}
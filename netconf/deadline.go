@@ -0,0 +1,112 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// Deadlines bound the time a single RPC may spend writing its request and
+// waiting for its reply.  Zero values disable the corresponding bound.
+type Deadlines struct {
+	Write time.Duration
+	Read  time.Duration
+}
+
+// TimeoutError is returned when writing a request or reading a reply
+// exceeded its deadline.  The session's framing state is unknown afterwards,
+// so it is closed and further RPCs fail with ErrSessionAbandoned.
+type TimeoutError struct {
+	// Op is "write" or "read".
+	Op string
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("netconf: %s deadline exceeded", e.Op)
+}
+
+// Timeout implements net.Error.
+func (e *TimeoutError) Timeout() bool { return true }
+
+// Temporary implements net.Error.
+func (e *TimeoutError) Temporary() bool { return true }
+
+var errDeadlineUnsupported = errors.New("netconf: transport does not support deadlines")
+
+// deadlineTransport is implemented by transports that can enforce deadlines
+// natively, such as those running over a net.Conn.
+type deadlineTransport interface {
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+}
+
+type deadlinesKey struct{}
+
+// WithDeadlines returns a context that overrides the session's Deadlines for
+// RPCs executed with it.
+func WithDeadlines(ctx context.Context, d Deadlines) context.Context {
+	return context.WithValue(ctx, deadlinesKey{}, d)
+}
+
+// deadlines returns the deadlines applying to an RPC executed with ctx.
+func (s *Session) deadlines(ctx context.Context) Deadlines {
+	if d, ok := ctx.Value(deadlinesKey{}).(Deadlines); ok {
+		return d
+	}
+	return s.Deadlines
+}
+
+// withDeadline runs the transport operation fn within d or the deadline of
+// ctx, whichever is earlier.  Transports supporting deadlines enforce them
+// natively, others are closed once the deadline passes.  Either way the
+// session is abandoned on timeout, as the late reply is still to come.
+func (s *Session) withDeadline(ctx context.Context, op string, d time.Duration, fn func() error) error {
+	var deadline time.Time
+	if d > 0 {
+		deadline = time.Now().Add(d)
+	}
+	if cd, ok := ctx.Deadline(); ok && (deadline.IsZero() || cd.Before(deadline)) {
+		deadline = cd
+	}
+	if deadline.IsZero() {
+		return fn()
+	}
+
+	if dt, ok := s.Transport.(deadlineTransport); ok {
+		set := dt.SetReadDeadline
+		if op == "write" {
+			set = dt.SetWriteDeadline
+		}
+		if err := set(deadline); err == nil {
+			err := fn()
+			set(time.Time{})
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				s.abandoned = true
+				s.Transport.Close()
+				return &TimeoutError{Op: op}
+			}
+			return err
+		}
+	}
+
+	var fired int32
+	timer := time.AfterFunc(time.Until(deadline), func() {
+		atomic.StoreInt32(&fired, 1)
+		s.Transport.Close()
+	})
+	err := fn()
+	if !timer.Stop() && atomic.LoadInt32(&fired) == 1 {
+		s.abandoned = true
+		return &TimeoutError{Op: op}
+	}
+	return err
+}
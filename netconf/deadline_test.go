@@ -0,0 +1,75 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// silentTransport accepts requests but never replies until it is closed.
+type silentTransport struct {
+	scriptedTransport
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (t *silentTransport) Receive() ([]byte, error) {
+	<-t.done
+	return nil, io.EOF
+}
+
+func (t *silentTransport) Close() error {
+	t.closeOnce.Do(func() { close(t.done) })
+	return nil
+}
+
+func TestDeadlineWatchdog(t *testing.T) {
+	trans := &silentTransport{done: make(chan struct{})}
+	s := &Session{Transport: trans, Deadlines: Deadlines{Read: 20 * time.Millisecond}}
+
+	_, err := s.Exec(MethodGetConfig("running"))
+	if te, ok := err.(*TimeoutError); !ok || te.Op != "read" {
+		t.Fatalf("expected read timeout, got %v", err)
+	}
+	if _, err := s.Exec(MethodGetConfig("running")); err != ErrSessionAbandoned {
+		t.Errorf("got %v after the timeout, expected ErrSessionAbandoned", err)
+	}
+}
+
+func TestDeadlineOverride(t *testing.T) {
+	trans := &silentTransport{done: make(chan struct{})}
+	s := &Session{Transport: trans, Deadlines: Deadlines{Read: time.Hour}}
+
+	ctx := WithDeadlines(context.Background(), Deadlines{Read: 20 * time.Millisecond})
+	if _, err := s.ExecContext(ctx, MethodGetConfig("running")); err == nil {
+		t.Fatalf("expected timeout")
+	}
+}
+
+func TestDeadlineNative(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	go io.Copy(io.Discard, server)
+
+	var trans transportTest
+	trans.ReadWriteCloser = client
+	s := &Session{Transport: &trans, Deadlines: Deadlines{Read: 20 * time.Millisecond}}
+
+	_, err := s.Exec(MethodGetConfig("running"))
+	if te, ok := err.(*TimeoutError); !ok || te.Op != "read" {
+		t.Fatalf("expected read timeout, got %v", err)
+	}
+	if _, err := s.Exec(MethodGetConfig("running")); err != ErrSessionAbandoned {
+		t.Errorf("got %v after the timeout, expected ErrSessionAbandoned", err)
+	}
+}
@@ -0,0 +1,67 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import "fmt"
+
+// Namespaces of the with-defaults capability (RFC 6243).
+const (
+	WithDefaultsNamespace = "urn:ietf:params:xml:ns:yang:ietf-netconf-with-defaults"
+	// DefaultAttrNamespace is the namespace of the default attribute tagging
+	// default values in report-all-tagged mode.
+	DefaultAttrNamespace = "urn:ietf:params:xml:ns:netconf:default:1.0"
+)
+
+// With-defaults retrieval modes.
+const (
+	WithDefaultsReportAll       = "report-all"
+	WithDefaultsReportAllTagged = "report-all-tagged"
+	WithDefaultsTrim            = "trim"
+	WithDefaultsExplicit        = "explicit"
+)
+
+// MethodGetConfigWithDefaults files a NETCONF get-config request with the
+// given with-defaults mode.  filter may be nil.
+func MethodGetConfigWithDefaults(source string, filter *Filter, mode string) RawMethod {
+	var f string
+	if filter != nil {
+		f = filter.String()
+	}
+	return RawMethod(fmt.Sprintf(`<get-config><source><%s/></source>%s<with-defaults xmlns="%s">%s</with-defaults></get-config>`,
+		source, f, WithDefaultsNamespace, EscapeText(mode)))
+}
+
+// isTaggedDefault reports whether the leaf carries wd:default="true".
+func isTaggedDefault(n *Node) bool {
+	v, ok := n.Attr(DefaultAttrNamespace, "default")
+	return ok && (v == "true" || v == "1")
+}
+
+// trimDefaults removes the leaves below n that hold their default value,
+// and the containers left empty.  path is the schema path of n.
+func (o *DiffOptions) trimDefaults(n *Node, path string) {
+	kept := n.Children[:0]
+	for _, c := range n.Children {
+		cpath := path + "/" + c.XMLName.Local
+		if c.IsLeaf() {
+			if o.TaggedDefaults && isTaggedDefault(c) {
+				continue
+			}
+			if def, ok := o.Defaults[cpath]; ok && c.Value() == def {
+				continue
+			}
+		} else {
+			o.trimDefaults(c, cpath)
+			// A container holding nothing but defaults is as good as absent.
+			if len(c.Children) == 0 {
+				continue
+			}
+		}
+		kept = append(kept, c)
+	}
+	n.Children = kept
+}
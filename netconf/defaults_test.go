@@ -0,0 +1,68 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffDefaults(t *testing.T) {
+	running := `<data xmlns:wd="urn:ietf:params:xml:ns:netconf:default:1.0"><interfaces>
+<interface><name>ge-0</name><mtu>1500</mtu><enabled wd:default="true">true</enabled></interface>
+<interface><name>ge-1</name><mtu>9000</mtu></interface>
+</interfaces><system><timeout wd:default="true">30</timeout></system></data>`
+	intended := `<data><interfaces>
+<interface><name>ge-0</name></interface>
+<interface><name>ge-1</name><mtu>1500</mtu></interface>
+</interfaces></data>`
+
+	tt := []struct {
+		name     string
+		opts     *DiffOptions
+		expected []string
+	}{
+		{
+			name: "naive",
+			opts: &DiffOptions{},
+			expected: []string{
+				"- /interfaces/interface[name='ge-0']/mtu",
+				"- /interfaces/interface[name='ge-0']/enabled",
+				`~ /interfaces/interface[name='ge-1']/mtu: "9000" -> "1500"`,
+				"- /system",
+			},
+		},
+		{
+			name: "defaults",
+			opts: &DiffOptions{
+				Defaults:       map[string]string{"/interfaces/interface/mtu": "1500"},
+				TaggedDefaults: true,
+			},
+			expected: []string{
+				"- /interfaces/interface[name='ge-1']/mtu",
+			},
+		},
+	}
+	for _, tc := range tt {
+		delta, err := Diff([]byte(running), []byte(intended), tc.opts)
+		if err != nil {
+			t.Fatalf("%s: Diff failed: %v", tc.name, err)
+		}
+		if diff := cmp.Diff(tc.expected, changeStrings(delta.Changes)); diff != "" {
+			t.Errorf("%s: changes mismatch (-want +got):\n%s", tc.name, diff)
+		}
+	}
+}
+
+func TestMethodGetConfigWithDefaults(t *testing.T) {
+	got := MethodGetConfigWithDefaults("running", nil, WithDefaultsReportAllTagged).MarshalMethod()
+	expected := `<get-config><source><running/></source><with-defaults xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-with-defaults">report-all-tagged</with-defaults></get-config>`
+	if got != expected {
+		t.Errorf("got %s, expected %s", got, expected)
+	}
+}
@@ -0,0 +1,55 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"net"
+)
+
+// DialFunc opens the connection a session is established over, such as
+// net.Dialer.DialContext.  network is "tcp".
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// WithDialFunc opens the TCP connections of NETCONF over SSH and TLS with
+// dial, e.g. to bind them to a VRF, mark them or connect through a proxy.
+// Tests can use it to dial fakes.
+func WithDialFunc(dial DialFunc) Option {
+	return func(c *SessionConfig) { c.DialFunc = dial }
+}
+
+// WithDialer opens the TCP connections with d, e.g. to set the local
+// address or a Control function setting socket options.
+func WithDialer(d *net.Dialer) Option {
+	return WithDialFunc(d.DialContext)
+}
+
+// WithResolver looks up host names with r instead of the default resolver
+// when the addresses of a name are tried one by one, see WithResolveAll and
+// WithHappyEyeballs.  Otherwise names are resolved by the dial function.
+func WithResolver(r *net.Resolver) Option {
+	return func(c *SessionConfig) { c.Resolver = r }
+}
+
+// dialFunc returns the function opening connections.
+func (c *SessionConfig) dialFunc() DialFunc {
+	if c.DialFunc != nil {
+		return c.DialFunc
+	}
+	if c.SocketOptions != nil {
+		return socketDialFunc(c.SocketOptions)
+	}
+	var d net.Dialer
+	return d.DialContext
+}
+
+func (c *SessionConfig) resolver() *net.Resolver {
+	if c.Resolver != nil {
+		return c.Resolver
+	}
+	return net.DefaultResolver
+}
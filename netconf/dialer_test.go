@@ -0,0 +1,59 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithDialFunc(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+
+	var dialled []string
+	dial := func(ctx context.Context, network, address string) (net.Conn, error) {
+		dialled = append(dialled, network+" "+address)
+		var d net.Dialer
+		return d.DialContext(ctx, network, srv.Addr())
+	}
+
+	s, err := Dial("r1.example", WithSSHConfig(testSSHConfig()), WithDialFunc(dial))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	s.Close()
+
+	trans := &TransportSSH{DialFunc: dial}
+	if err := trans.Dial("[fe80::1%mgmt]", testSSHConfig()); err != nil {
+		t.Fatalf("TransportSSH.Dial failed: %v", err)
+	}
+	trans.Close()
+
+	expected := []string{"tcp r1.example:830", "tcp [fe80::1%mgmt]:830"}
+	if diff := cmp.Diff(expected, dialled); diff != "" {
+		t.Errorf("dials mismatch (-expected +got):\n%s", diff)
+	}
+}
+
+func TestWithDialer(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+
+	local := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}
+	s, err := Dial(srv.Addr(), WithSSHConfig(testSSHConfig()), WithDialer(&net.Dialer{LocalAddr: local}))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer s.Close()
+	if s.Address != srv.Addr() {
+		t.Errorf("got address %s, expected %s", s.Address, srv.Addr())
+	}
+}
@@ -0,0 +1,359 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ChangeType describes how a subtree differs between two configurations.
+type ChangeType string
+
+// Possible change types.
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+	// ChangeMoved is reported for entries of ordered lists whose position
+	// changed.
+	ChangeMoved ChangeType = "moved"
+)
+
+// Change is a single difference between two configuration trees.
+type Change struct {
+	Type ChangeType
+	// Path locates the subtree, e.g. /interfaces/interface[name='ge-0/0/0'].
+	Path string
+	// Old and New hold the subtree on either side.  Old is nil for added and
+	// New is nil for removed subtrees.
+	Old *Node
+	New *Node
+}
+
+func (c Change) String() string {
+	switch c.Type {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s", c.Path)
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s", c.Path)
+	case ChangeMoved:
+		return fmt.Sprintf("> %s", c.Path)
+	}
+	if c.Old.IsLeaf() && c.New.IsLeaf() {
+		return fmt.Sprintf("~ %s: %q -> %q", c.Path, c.Old.Value(), c.New.Value())
+	}
+	return fmt.Sprintf("~ %s", c.Path)
+}
+
+// DiffOptions tunes how configuration trees are matched.  The hints
+// normally come from the YANG modules describing the configuration.
+type DiffOptions struct {
+	// ListKeys maps the local name of list elements to the names of their
+	// key leaves, e.g. "interface": {"name"}.  Elements without a hint
+	// are keyed by their <name> child if they have one, and if repeated
+	// otherwise by value for leaf-lists, or by position.
+	ListKeys map[string][]string
+	// Ordered holds the local names of "ordered-by user" lists and
+	// leaf-lists, whose entry order is significant.  The order of all
+	// other elements is ignored.
+	Ordered map[string]bool
+	// Paths, if set, restricts the result to changes below these paths.
+	// Subtrees added or removed above a path are reported from the path
+	// down.
+	Paths []string
+	// Defaults maps the schema paths of leaves, e.g.
+	// /interfaces/interface/mtu, to their default values, see YANGDefaults.
+	// A leaf holding its default value is compared as if it were absent.
+	Defaults map[string]string
+	// TaggedDefaults compares leaves tagged as defaults, as returned with
+	// the report-all-tagged with-defaults mode, as if they were absent.
+	TaggedDefaults bool
+}
+
+// Delta is the result of comparing two configurations.
+type Delta struct {
+	Changes []Change
+}
+
+// Empty reports whether the configurations were equal.
+func (d *Delta) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// String renders the delta in a human readable form, including the XML of
+// added and removed subtrees.
+func (d *Delta) String() string {
+	var b strings.Builder
+	for _, c := range d.Changes {
+		b.WriteString(c.String())
+		b.WriteByte('\n')
+		switch {
+		case c.Type == ChangeAdded:
+			writeIndented(&b, c.New.String())
+		case c.Type == ChangeRemoved:
+			writeIndented(&b, c.Old.String())
+		case c.Type == ChangeChanged && !(c.Old.IsLeaf() && c.New.IsLeaf()):
+			writeIndented(&b, "old: "+c.Old.String())
+			writeIndented(&b, "new: "+c.New.String())
+		}
+	}
+	return b.String()
+}
+
+func writeIndented(b *strings.Builder, s string) {
+	for _, line := range strings.Split(s, "\n") {
+		b.WriteString("    ")
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+}
+
+type jsonChange struct {
+	Type ChangeType `json:"type"`
+	Path string     `json:"path"`
+	Old  string     `json:"old,omitempty"`
+	New  string     `json:"new,omitempty"`
+}
+
+// MarshalJSON renders the delta as a list of changes.  Leaf values are
+// given as text, subtrees as XML.
+func (d *Delta) MarshalJSON() ([]byte, error) {
+	out := make([]jsonChange, 0, len(d.Changes))
+	for _, c := range d.Changes {
+		out = append(out, jsonChange{
+			Type: c.Type,
+			Path: c.Path,
+			Old:  jsonNode(c.Old),
+			New:  jsonNode(c.New),
+		})
+	}
+	return json.Marshal(out)
+}
+
+func jsonNode(n *Node) string {
+	switch {
+	case n == nil:
+		return ""
+	case n.IsLeaf():
+		return n.Value()
+	}
+	return n.String()
+}
+
+// Diff structurally compares two configurations.  A <data> or <config>
+// wrapper element around either configuration is ignored.  opts may be nil.
+func Diff(a, b []byte, opts *DiffOptions) (*Delta, error) {
+	if opts == nil {
+		opts = &DiffOptions{}
+	}
+
+	aRoot, err := configRoot(a)
+	if err != nil {
+		return nil, err
+	}
+	bRoot, err := configRoot(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Defaults) > 0 || opts.TaggedDefaults {
+		opts.trimDefaults(aRoot, "")
+		opts.trimDefaults(bRoot, "")
+	}
+
+	changes := opts.diff(nil, aRoot, bRoot, "")
+	if len(opts.Paths) == 0 {
+		return &Delta{Changes: changes}, nil
+	}
+
+	var filtered []Change
+	for _, c := range changes {
+		filtered = opts.within(filtered, c)
+	}
+	return &Delta{Changes: filtered}, nil
+}
+
+// within appends the part of c lying within the Paths to changes.  An added
+// or removed ancestor of a path is descended into, so that the subtrees at
+// the path are reported; other changes of an ancestor are kept whole.
+func (o *DiffOptions) within(changes []Change, c Change) []Change {
+	ancestor := false
+	for _, p := range o.Paths {
+		if pathWithin(c.Path, p) {
+			return append(changes, c)
+		}
+		ancestor = ancestor || pathWithin(p, c.Path)
+	}
+	if !ancestor {
+		return changes
+	}
+
+	n := c.New
+	switch c.Type {
+	case ChangeRemoved:
+		n = c.Old
+	case ChangeAdded:
+	default:
+		return append(changes, c)
+	}
+	keys, byKey := o.keyChildren(n)
+	for _, k := range keys {
+		child := Change{Type: c.Type, Path: c.Path + k.path}
+		if c.Type == ChangeAdded {
+			child.New = byKey[k.key]
+		} else {
+			child.Old = byKey[k.key]
+		}
+		changes = o.within(changes, child)
+	}
+	return changes
+}
+
+// diff compares the children of a and b and appends the differences to
+// changes.  The roots themselves are assumed to match.
+func (o *DiffOptions) diff(changes []Change, a, b *Node, path string) []Change {
+	if a.IsLeaf() && b.IsLeaf() {
+		if a.Value() != b.Value() {
+			changes = append(changes, Change{Type: ChangeChanged, Path: path, Old: a, New: b})
+		}
+		return changes
+	}
+	// An empty element is compared as a container without children, a leaf
+	// with a value against a container is a change of the whole subtree.
+	if (a.IsLeaf() && a.Value() != "") || (b.IsLeaf() && b.Value() != "") {
+		return append(changes, Change{Type: ChangeChanged, Path: path, Old: a, New: b})
+	}
+
+	aKeys, aByKey := o.keyChildren(a)
+	bKeys, bByKey := o.keyChildren(b)
+
+	for _, k := range aKeys {
+		ac := aByKey[k.key]
+		bc, ok := bByKey[k.key]
+		if !ok {
+			changes = append(changes, Change{Type: ChangeRemoved, Path: path + k.path, Old: ac})
+			continue
+		}
+		changes = o.diff(changes, ac, bc, path+k.path)
+	}
+
+	for _, k := range bKeys {
+		if _, ok := aByKey[k.key]; !ok {
+			changes = append(changes, Change{Type: ChangeAdded, Path: path + k.path, New: bByKey[k.key]})
+		}
+	}
+
+	return o.moves(changes, path, aKeys, bKeys, aByKey, bByKey)
+}
+
+// moves reports entries of ordered lists present on both sides whose
+// relative order differs.
+func (o *DiffOptions) moves(changes []Change, path string, aKeys, bKeys []childKey, aByKey, bByKey map[string]*Node) []Change {
+	if len(o.Ordered) == 0 {
+		return changes
+	}
+
+	common := func(keys []childKey, other map[string]*Node, name string) []childKey {
+		var out []childKey
+		for _, k := range keys {
+			if _, ok := other[k.key]; ok && k.name == name {
+				out = append(out, k)
+			}
+		}
+		return out
+	}
+
+	done := make(map[string]bool)
+	for _, k := range aKeys {
+		if !o.Ordered[aByKey[k.key].XMLName.Local] || done[k.name] {
+			continue
+		}
+		done[k.name] = true
+
+		aOrder := common(aKeys, bByKey, k.name)
+		bOrder := common(bKeys, aByKey, k.name)
+		for i := range aOrder {
+			if aOrder[i].key != bOrder[i].key {
+				changes = append(changes, Change{
+					Type: ChangeMoved,
+					Path: path + bOrder[i].path,
+					Old:  aByKey[bOrder[i].key],
+					New:  bByKey[bOrder[i].key],
+				})
+			}
+		}
+	}
+	return changes
+}
+
+// childKey identifies a child element among its siblings.
+type childKey struct {
+	name string
+	key  string
+	path string
+}
+
+// keyChildren assigns an identity to each child of n.
+func (o *DiffOptions) keyChildren(n *Node) ([]childKey, map[string]*Node) {
+	count := make(map[string]int)
+	for _, c := range n.Children {
+		count[nodeName(c)]++
+	}
+
+	var keys []childKey
+	byKey := make(map[string]*Node)
+	seen := make(map[string]int)
+	for _, c := range n.Children {
+		name := nodeName(c)
+		seg := "/" + c.XMLName.Local
+		if leaves, ok := o.ListKeys[c.XMLName.Local]; ok {
+			for _, leaf := range leaves {
+				val := ""
+				if key := c.Child(leaf); key != nil {
+					val = key.Value()
+				}
+				seg += fmt.Sprintf("[%s=%s]", leaf, quoteXPath(val))
+			}
+		} else if key := c.Child("name"); key != nil && key.IsLeaf() {
+			// Entries are keyed by name even when alone, so that the path
+			// of an entry does not change as others are added.
+			seg += fmt.Sprintf("[name=%s]", quoteXPath(key.Value()))
+		} else if count[name] > 1 {
+			if c.IsLeaf() {
+				seg += fmt.Sprintf("[.=%s]", quoteXPath(c.Value()))
+			} else {
+				seg += fmt.Sprintf("[%d]", seen[name]+1)
+			}
+		}
+		k := childKey{name: name, key: name + seg, path: seg}
+		if _, dup := byKey[k.key]; dup {
+			seen[k.key]++
+			k.key = fmt.Sprintf("%s#%d", k.key, seen[k.key])
+		}
+		seen[name]++
+		keys = append(keys, k)
+		byKey[k.key] = c
+	}
+	return keys, byKey
+}
+
+// nodeName returns the namespace qualified name of a node.
+func nodeName(n *Node) string {
+	if n.XMLName.Space == "" {
+		return n.XMLName.Local
+	}
+	return "{" + n.XMLName.Space + "}" + n.XMLName.Local
+}
+
+// quoteXPath quotes s as an XPath string literal.
+func quoteXPath(s string) string {
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	return `"` + s + `"`
+}
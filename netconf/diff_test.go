@@ -0,0 +1,213 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func changeStrings(changes []Change) []string {
+	var out []string
+	for _, c := range changes {
+		out = append(out, c.String())
+	}
+	return out
+}
+
+func TestCompareConfigs(t *testing.T) {
+	tt := []struct {
+		name     string
+		a, b     string
+		paths    []string
+		expected []string
+	}{
+		{
+			name:     "equal",
+			a:        `<data><system><host-name>r1</host-name></system></data>`,
+			b:        `<data><system>  <host-name> r1 </host-name>  </system></data>`,
+			expected: nil,
+		},
+		{
+			name: "leafchange",
+			a:    `<data><system><host-name>r1</host-name></system></data>`,
+			b:    `<data><system><host-name>r2</host-name></system></data>`,
+			expected: []string{
+				`~ /system/host-name: "r1" -> "r2"`,
+			},
+		},
+		{
+			name: "listentries",
+			a: `<interfaces>
+  <interface><name>ge-0</name><mtu>1500</mtu></interface>
+  <interface><name>ge-1</name><mtu>1500</mtu></interface>
+</interfaces>`,
+			b: `<interfaces>
+  <interface><name>ge-1</name><mtu>9000</mtu></interface>
+  <interface><name>ge-2</name></interface>
+</interfaces>`,
+			expected: []string{
+				`- /interfaces/interface[name='ge-0']`,
+				`~ /interfaces/interface[name='ge-1']/mtu: "1500" -> "9000"`,
+				`+ /interfaces/interface[name='ge-2']`,
+			},
+		},
+		{
+			name: "secondentry",
+			a:    `<interfaces><interface><name>ge-0</name><mtu>1500</mtu></interface></interfaces>`,
+			b: `<interfaces><interface><name>ge-0</name><mtu>1500</mtu></interface>
+  <interface><name>ge-1</name></interface></interfaces>`,
+			expected: []string{
+				`+ /interfaces/interface[name='ge-1']`,
+			},
+		},
+		{
+			name: "leaflist",
+			a:    `<dns><server>1.1.1.1</server><server>8.8.8.8</server></dns>`,
+			b:    `<dns><server>8.8.8.8</server><server>9.9.9.9</server></dns>`,
+			expected: []string{
+				`- /dns/server[.='1.1.1.1']`,
+				`+ /dns/server[.='9.9.9.9']`,
+			},
+		},
+		{
+			name:     "emptycontainer",
+			a:        `<data/>`,
+			b:        `<data><system/></data>`,
+			expected: []string{`+ /system`},
+		},
+		{
+			name:  "paths",
+			a:     `<data><system><host-name>r1</host-name></system><snmp><community>a</community></snmp></data>`,
+			b:     `<data><system><host-name>r2</host-name></system><snmp><community>b</community></snmp></data>`,
+			paths: []string{"/snmp"},
+			expected: []string{
+				`~ /snmp/community: "a" -> "b"`,
+			},
+		},
+		{
+			name:  "pathsancestor",
+			a:     `<data><snmp><community>a</community></snmp></data>`,
+			b:     `<data><system><host-name>r2</host-name><services><ssh/></services></system></data>`,
+			paths: []string{"/system/services", "/snmp/community"},
+			expected: []string{
+				`- /snmp/community`,
+				`+ /system/services`,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			changes, err := CompareConfigs([]byte(tc.a), []byte(tc.b), tc.paths...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := changeStrings(changes); !cmp.Equal(got, tc.expected) {
+				t.Errorf("unexpected changes:\n%s", cmp.Diff(tc.expected, got))
+			}
+		})
+	}
+}
+
+func TestDiffOptions(t *testing.T) {
+	a := `<policy>
+  <term><id>1</id><action>accept</action></term>
+  <term><id>2</id><action>reject</action></term>
+</policy>`
+	b := `<policy>
+  <term><id>2</id><action>reject</action></term>
+  <term><id>1</id><action>discard</action></term>
+</policy>`
+
+	tt := []struct {
+		name     string
+		opts     *DiffOptions
+		expected []string
+	}{
+		{
+			name: "positional",
+			opts: nil,
+			expected: []string{
+				`~ /policy/term[1]/id: "1" -> "2"`,
+				`~ /policy/term[1]/action: "accept" -> "reject"`,
+				`~ /policy/term[2]/id: "2" -> "1"`,
+				`~ /policy/term[2]/action: "reject" -> "discard"`,
+			},
+		},
+		{
+			name: "keyed",
+			opts: &DiffOptions{ListKeys: map[string][]string{"term": {"id"}}},
+			expected: []string{
+				`~ /policy/term[id='1']/action: "accept" -> "discard"`,
+			},
+		},
+		{
+			name: "ordered",
+			opts: &DiffOptions{
+				ListKeys: map[string][]string{"term": {"id"}},
+				Ordered:  map[string]bool{"term": true},
+			},
+			expected: []string{
+				`~ /policy/term[id='1']/action: "accept" -> "discard"`,
+				`> /policy/term[id='2']`,
+				`> /policy/term[id='1']`,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			delta, err := Diff([]byte(a), []byte(b), tc.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := changeStrings(delta.Changes); !cmp.Equal(got, tc.expected) {
+				t.Errorf("unexpected changes:\n%s", cmp.Diff(tc.expected, got))
+			}
+		})
+	}
+}
+
+func TestDeltaRender(t *testing.T) {
+	delta, err := Diff(
+		[]byte(`<system><host-name>r1</host-name></system>`),
+		[]byte(`<system><host-name>r2</host-name><ntp><server>10.0.0.1</server></ntp></system>`),
+		nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	text := delta.String()
+	if !strings.Contains(text, "+ /system/ntp\n    <ntp><server>10.0.0.1</server></ntp>\n") {
+		t.Errorf("unexpected text rendering:\n%s", text)
+	}
+
+	out, err := json.Marshal(delta)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `[{"type":"changed","path":"/system/host-name","old":"r1","new":"r2"},{"type":"added","path":"/system/ntp","new":"\u003cntp\u003e\u003cserver\u003e10.0.0.1\u003c/server\u003e\u003c/ntp\u003e"}]`
+	if string(out) != expected {
+		t.Errorf("unexpected json rendering:\nwant %s\n got %s", expected, out)
+	}
+}
+
+func TestNodeString(t *testing.T) {
+	input := `<config xmlns="urn:ietf:params:xml:ns:netconf:base:1.0" xmlns:junos="http://xml.juniper.net/junos/*/junos"><system junos:changed="x"><host-name>a &amp; b</host-name><empty/></system><foo xmlns="urn:foo"/></config>`
+
+	n, err := ParseNode([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := n.String(); got != input {
+		t.Errorf("unexpected round trip:\nwant %s\n got %s", input, got)
+	}
+}
@@ -0,0 +1,137 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DriftReport lists how the configuration of a device differs from its
+// golden configuration.
+type DriftReport struct {
+	Device string
+	// Golden is the time the golden configuration was stored.
+	Golden  time.Time
+	Changes []Change
+}
+
+// Drifted reports whether any difference was found.
+func (r *DriftReport) Drifted() bool {
+	return len(r.Changes) > 0
+}
+
+func (r *DriftReport) String() string {
+	var b strings.Builder
+	for _, c := range r.Changes {
+		b.WriteString(c.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// DriftChecker compares the configuration of devices against the golden
+// configurations held in a SnapshotStore.
+type DriftChecker struct {
+	Golden SnapshotStore
+	// Source is the datastore to check, "running" if empty.
+	Source string
+	// Paths, if set, restricts the comparison to the subtrees below these
+	// paths, e.g. /configuration/system.
+	Paths []string
+	// Options, if set, provides list hints for the comparison.  Its Paths
+	// are ignored in favour of the checker's.
+	Options *DiffOptions
+}
+
+// Check retrieves the configuration of device over s and compares it with
+// the device's golden configuration.
+func (dc *DriftChecker) Check(ctx context.Context, s *Session, device string) (*DriftReport, error) {
+	golden, err := dc.Golden.Latest(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	if golden == nil {
+		return nil, fmt.Errorf("no golden configuration for %s", device)
+	}
+
+	source := dc.Source
+	if source == "" {
+		source = "running"
+	}
+	reply, err := s.ExecContext(ctx, MethodGetConfig(source))
+	if err != nil {
+		return nil, err
+	}
+
+	var opts DiffOptions
+	if dc.Options != nil {
+		opts = *dc.Options
+	}
+	opts.Paths = dc.Paths
+
+	delta, err := Diff(golden.Config, reply.Data, &opts)
+	if err != nil {
+		return nil, err
+	}
+	return &DriftReport{Device: device, Golden: golden.Time, Changes: delta.Changes}, nil
+}
+
+// Job returns a FleetJob that checks each device for drift.  Devices that
+// drifted are reported as failed with the changes as diff.
+func (dc *DriftChecker) Job() FleetJob {
+	return func(ctx context.Context, s *Session, r *DeviceResult) error {
+		report, err := dc.Check(ctx, s, r.Device)
+		if err != nil {
+			return err
+		}
+		if report.Drifted() {
+			r.Diff = report.String()
+			return fmt.Errorf("configuration drift: %d changes", len(report.Changes))
+		}
+		return nil
+	}
+}
+
+// CompareConfigs structurally compares two configurations, optionally
+// limited to the subtrees below paths.  It is a shorthand for Diff without
+// list hints.
+func CompareConfigs(a, b []byte, paths ...string) ([]Change, error) {
+	delta, err := Diff(a, b, &DiffOptions{Paths: paths})
+	if err != nil {
+		return nil, err
+	}
+	return delta.Changes, nil
+}
+
+// configRoot parses a configuration into a synthetic root node holding its
+// top-level elements.
+func configRoot(data []byte) (*Node, error) {
+	nodes, err := ParseNodes(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 1 {
+		switch nodes[0].XMLName.Local {
+		case "data", "config":
+			nodes = nodes[0].Children
+		}
+	}
+	return &Node{Children: nodes}, nil
+}
+
+// pathWithin reports whether path equals prefix or lies below it.
+func pathWithin(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	rest := path[len(prefix):]
+	return rest == "" || rest[0] == '/' || rest[0] == '['
+}
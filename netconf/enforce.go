@@ -0,0 +1,235 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// EnforceMethod selects how an Enforcer restores the golden configuration.
+type EnforceMethod string
+
+// Enforcement methods.
+const (
+	// EnforceEditConfig replaces the configuration with an edit-config
+	// using the replace default operation.
+	EnforceEditConfig EnforceMethod = "edit-config"
+	// EnforceCopyConfig replaces the configuration with a copy-config
+	// holding the golden configuration inline.
+	EnforceCopyConfig EnforceMethod = "copy-config"
+)
+
+// Remediation records the drift of a device found by an Enforcer and
+// whether it was corrected.
+type Remediation struct {
+	Device string
+	Time   time.Time
+	// Golden is the time the golden configuration was stored.
+	Golden  time.Time
+	Changes []Change
+	Method  EnforceMethod
+	Target  string
+	// Applied reports whether the golden configuration was applied.
+	Applied bool
+	// Skipped gives the reason the drift was not corrected, if it was not
+	// attempted.
+	Skipped string
+	// Err is the error applying the golden configuration.
+	Err error
+}
+
+func (r *Remediation) String() string {
+	msg := fmt.Sprintf("%s: %d changes from golden configuration of %s", r.Device, len(r.Changes), r.Golden.Format(time.RFC3339))
+	switch {
+	case r.Err != nil:
+		return msg + fmt.Sprintf(", %s of %s failed: %v", r.Method, r.Target, r.Err)
+	case r.Applied:
+		return msg + fmt.Sprintf(", restored by %s of %s", r.Method, r.Target)
+	default:
+		return msg + ", not corrected: " + r.Skipped
+	}
+}
+
+// Enforcer detects configuration drift of a fleet against the golden
+// configurations held in a SnapshotStore and optionally restores the golden
+// configuration of devices that drifted.
+type Enforcer struct {
+	// DriftChecker detects drift.  Its Paths only limit the detection, the
+	// whole golden configuration is applied.
+	DriftChecker
+	Fleet *Fleet
+	// Interval between enforcement runs.
+	Interval time.Duration
+	// Remediate enables correcting drift.  Otherwise drift is only
+	// reported.
+	Remediate bool
+	// Method is the way drift is corrected, EnforceEditConfig if empty.
+	Method EnforceMethod
+	// Target is the datastore the golden configuration is applied to.  If
+	// empty the candidate is used, followed by a commit, on servers
+	// announcing :candidate and Source otherwise.
+	Target string
+	// Confirm, if set, is called before correcting a drift.  The drift is
+	// only corrected if it returns true.
+	Confirm func(ctx context.Context, r *Remediation) (bool, error)
+	// Transaction tunes the lock, validate and commit sequence around the
+	// correction.  Its Replace field is ignored.
+	Transaction TransactionOptions
+	// Logger, if set, receives a message for every drift found.
+	Logger Logger
+	// OnRemediation, if set, is called for every drift found once it was
+	// handled.
+	OnRemediation func(r *Remediation)
+}
+
+// EnforceDevice checks device for drift over s and corrects it if enabled.
+// It returns nil if the device has not drifted.  An error applying the
+// golden configuration is recorded in the returned Remediation.
+func (e *Enforcer) EnforceDevice(ctx context.Context, s *Session, device string) (*Remediation, error) {
+	report, err := e.Check(ctx, s, device)
+	if err != nil {
+		return nil, err
+	}
+	if !report.Drifted() {
+		return nil, nil
+	}
+
+	r := &Remediation{
+		Device:  device,
+		Time:    time.Now(),
+		Golden:  report.Golden,
+		Changes: report.Changes,
+		Method:  e.Method,
+		Target:  e.Target,
+	}
+	if r.Method == "" {
+		r.Method = EnforceEditConfig
+	}
+	if r.Target == "" {
+		r.Target = e.Source
+		if s.HasCapability(CapabilityCandidate) {
+			r.Target = "candidate"
+		} else if r.Target == "" {
+			r.Target = "running"
+		}
+	}
+	defer e.log(r)
+
+	if !e.Remediate {
+		r.Skipped = "remediation disabled"
+		return r, nil
+	}
+	if e.Confirm != nil {
+		ok, err := e.Confirm(ctx, r)
+		if err != nil {
+			r.Err = err
+			return r, nil
+		}
+		if !ok {
+			r.Skipped = "not confirmed"
+			return r, nil
+		}
+	}
+
+	golden, err := e.Golden.Latest(ctx, device)
+	if err != nil {
+		r.Err = err
+		return r, nil
+	}
+	config := configContent(golden.Config)
+	opts := e.Transaction
+	opts.Replace = true
+	switch r.Method {
+	case EnforceEditConfig:
+		r.Err = s.EditTransaction(ctx, r.Target, string(config), &opts)
+	case EnforceCopyConfig:
+		r.Err = s.transaction(ctx, r.Target, MethodCopyConfigData(string(config), r.Target), &opts)
+	default:
+		r.Err = fmt.Errorf("unknown enforcement method %q", r.Method)
+	}
+	r.Applied = r.Err == nil
+	return r, nil
+}
+
+func (e *Enforcer) log(r *Remediation) {
+	if e.Logger != nil {
+		e.Logger.Printf("netconf: enforce %s", r)
+	}
+	if e.OnRemediation != nil {
+		e.OnRemediation(r)
+	}
+}
+
+// Job returns a FleetJob that enforces the golden configuration of each
+// device.  Devices that drifted are reported with the changes as diff, and
+// as failed unless the drift was corrected.
+func (e *Enforcer) Job() FleetJob {
+	return func(ctx context.Context, s *Session, res *DeviceResult) error {
+		r, err := e.EnforceDevice(ctx, s, res.Device)
+		if err != nil || r == nil {
+			return err
+		}
+		res.Diff = (&DriftReport{Changes: r.Changes}).String()
+		switch {
+		case r.Err != nil:
+			return r.Err
+		case !r.Applied:
+			return fmt.Errorf("configuration drift: %d changes, %s", len(r.Changes), r.Skipped)
+		}
+		return nil
+	}
+}
+
+// Enforce runs a single enforcement pass over the fleet.
+func (e *Enforcer) Enforce(ctx context.Context) *Report {
+	return e.Fleet.Run(ctx, e.Job())
+}
+
+// Run enforces the golden configurations every Interval until ctx is
+// cancelled.  The report of each pass is passed to fn if it is not nil.
+func (e *Enforcer) Run(ctx context.Context, fn func(*Report)) error {
+	if e.Interval <= 0 {
+		return fmt.Errorf("enforcer interval must be positive")
+	}
+
+	ticker := time.NewTicker(e.Interval)
+	defer ticker.Stop()
+	for {
+		report := e.Enforce(ctx)
+		if fn != nil {
+			fn(report)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// configContent returns the configuration of a snapshot without the <data>
+// or <config> element wrapping it, if any.
+func configContent(config []byte) []byte {
+	d := xml.NewDecoder(bytes.NewReader(config))
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			return config
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			if start.Name.Local == "data" || start.Name.Local == "config" {
+				return bytes.TrimSpace(innerXML(config))
+			}
+			return config
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// goldenStore holds a single snapshot per device.
+type goldenStore map[string]*Snapshot
+
+func (g goldenStore) Latest(ctx context.Context, device string) (*Snapshot, error) {
+	return g[device], nil
+}
+
+func (g goldenStore) Put(ctx context.Context, s *Snapshot) error {
+	g[s.Device] = s
+	return nil
+}
+
+func TestEnforcer(t *testing.T) {
+	const running = `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><data><system><host-name>r1</host-name><telnet/></system></data></rpc-reply>`
+	golden := goldenStore{"r1": {
+		Device: "r1",
+		Time:   time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Config: []byte(`<data><system><host-name>r1</host-name></system></data>`),
+	}}
+
+	tt := []struct {
+		name     string
+		enforcer Enforcer
+		caps     []string
+		replies  []string
+		ops      []string
+		applied  bool
+		skipped  string
+		failed   bool
+	}{
+		{
+			name:     "detectOnly",
+			enforcer: Enforcer{},
+			replies:  []string{running},
+			ops:      []string{"get-config"},
+			skipped:  "remediation disabled",
+		},
+		{
+			name: "declined",
+			enforcer: Enforcer{Remediate: true, Confirm: func(ctx context.Context, r *Remediation) (bool, error) {
+				return false, nil
+			}},
+			replies: []string{running},
+			ops:     []string{"get-config"},
+			skipped: "not confirmed",
+		},
+		{
+			name:     "candidate",
+			enforcer: Enforcer{Remediate: true},
+			caps:     []string{CapabilityCandidate},
+			replies:  []string{running, replyOK, replyOK, replyOK, replyOK},
+			ops:      []string{"get-config", "lock", "edit-config", "commit", "unlock"},
+			applied:  true,
+		},
+		{
+			name:     "copyConfig",
+			enforcer: Enforcer{Remediate: true, Method: EnforceCopyConfig, Transaction: TransactionOptions{NoLock: true}},
+			replies:  []string{running, replyOK},
+			ops:      []string{"get-config", "copy-config"},
+			applied:  true,
+		},
+		{
+			name:     "failed",
+			enforcer: Enforcer{Remediate: true},
+			replies:  []string{running, replyOK, replyError("invalid-value"), replyOK},
+			ops:      []string{"get-config", "lock", "edit-config", "unlock"},
+			failed:   true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var logged bytes.Buffer
+			var remediations []*Remediation
+			e := tc.enforcer
+			e.Golden = golden
+			e.Logger = log.New(&logged, "", 0)
+			e.OnRemediation = func(r *Remediation) { remediations = append(remediations, r) }
+			s, trans := newScriptedSession(tc.caps, tc.replies...)
+
+			res := &DeviceResult{Device: "r1"}
+			err := e.Job()(context.Background(), s, res)
+			if (err != nil) != (!tc.applied) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.ops, trans.operations()); diff != "" {
+				t.Errorf("operations mismatch (-want +got):\n%s", diff)
+			}
+			if len(remediations) != 1 {
+				t.Fatalf("expected a remediation, got %d", len(remediations))
+			}
+			r := remediations[0]
+			if r.Applied != tc.applied || r.Skipped != tc.skipped || (r.Err != nil) != tc.failed {
+				t.Errorf("unexpected remediation %+v", r)
+			}
+			if !strings.Contains(res.Diff, "telnet") {
+				t.Errorf("diff lacks the drift: %q", res.Diff)
+			}
+			if !strings.HasPrefix(logged.String(), "netconf: enforce r1: 1 changes") {
+				t.Errorf("unexpected log %q", logged.String())
+			}
+			if tc.applied {
+				edit := trans.sent[len(trans.sent)-1]
+				if e.Method == "" {
+					edit = trans.sent[2]
+				}
+				if !strings.Contains(edit, "<config><system><host-name>r1</host-name></system></config>") {
+					t.Errorf("golden configuration not applied: %s", edit)
+				}
+				if e.Method == "" && !strings.Contains(edit, "<default-operation>replace</default-operation>") {
+					t.Errorf("configuration not replaced: %s", edit)
+				}
+			}
+		})
+	}
+}
+
+func TestEnforcerNoDrift(t *testing.T) {
+	golden := goldenStore{"r1": {Device: "r1", Config: []byte(`<data><system/></data>`)}}
+	e := &Enforcer{DriftChecker: DriftChecker{Golden: golden}, Remediate: true}
+	s, trans := newScriptedSession(nil, `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><data><system/></data></rpc-reply>`)
+
+	r, err := e.EnforceDevice(context.Background(), s, "r1")
+	if err != nil || r != nil {
+		t.Errorf("expected no remediation, got %+v, %v", r, err)
+	}
+	if len(trans.sent) != 1 {
+		t.Errorf("unexpected requests %q", trans.sent)
+	}
+}
@@ -0,0 +1,78 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+)
+
+var (
+	// ErrSessionClosed matches transport failures caused by the peer closing
+	// the connection, see TransportError.
+	ErrSessionClosed = errors.New("netconf: session closed by peer")
+	// ErrTransportBroken matches all transport failures, see TransportError.
+	ErrTransportBroken = errors.New("netconf: transport broken")
+)
+
+// TransportError is returned when sending a request or receiving a reply
+// failed because of the underlying transport.  It matches
+// ErrTransportBroken with errors.Is, and ErrSessionClosed as well if the
+// peer closed the connection.
+type TransportError struct {
+	// Op is "write" or "read".
+	Op string
+	// InFlight reports whether the request had been sent completely, so
+	// the server may have executed it.
+	InFlight bool
+	Err      error
+}
+
+func (e *TransportError) Error() string {
+	if e.closed() {
+		return fmt.Sprintf("netconf: session closed by peer during %s: %v", e.Op, e.Err)
+	}
+	return fmt.Sprintf("netconf: transport %s failed: %v", e.Op, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches ErrTransportBroken or
+// ErrSessionClosed.
+func (e *TransportError) Is(target error) bool {
+	switch target {
+	case ErrTransportBroken:
+		return true
+	case ErrSessionClosed:
+		return e.closed()
+	}
+	return false
+}
+
+func (e *TransportError) closed() bool {
+	return e.Err == io.EOF ||
+		errors.Is(e.Err, io.ErrUnexpectedEOF) ||
+		connReset(e.Err)
+}
+
+// transportError wraps err, returned by the transport during op, in a
+// TransportError.  Timeouts and framing errors are returned as they are.
+func transportError(op string, inFlight bool, err error) error {
+	switch err.(type) {
+	case nil, *TimeoutError, *FramingError, *TransportError:
+		return err
+	}
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return err
+	}
+	return &TransportError{Op: op, InFlight: inFlight, Err: err}
+}
@@ -0,0 +1,83 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
+
+func (failingWriter) Read(p []byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (failingWriter) Close() error {
+	return nil
+}
+
+func TestTransportErrorIs(t *testing.T) {
+	tt := []struct {
+		name   string
+		err    error
+		closed bool
+	}{
+		{"eof", io.EOF, true},
+		{"unexpected eof", fmt.Errorf("reading: %w", io.ErrUnexpectedEOF), true},
+		{"other", errors.New("broken"), false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := transportError("read", true, tc.err)
+			if !errors.Is(err, ErrTransportBroken) {
+				t.Errorf("expected %v to match ErrTransportBroken", err)
+			}
+			if errors.Is(err, ErrSessionClosed) != tc.closed {
+				t.Errorf("expected ErrSessionClosed match to be %v for %v", tc.closed, err)
+			}
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected %v to wrap %v", err, tc.err)
+			}
+		})
+	}
+
+	for _, err := range []error{nil, &TimeoutError{Op: "read"}, &FramingError{Msg: "bad"}} {
+		if got := transportError("read", true, err); got != err {
+			t.Errorf("expected %v to be returned as is, got %v", err, got)
+		}
+	}
+}
+
+func TestSessionTransportErrors(t *testing.T) {
+	s, _ := newScriptedSession(nil)
+	_, err := s.Exec(MethodGetConfig("running"))
+	var te *TransportError
+	if !errors.As(err, &te) || te.Op != "read" || !te.InFlight {
+		t.Errorf("expected in flight read error, got %#v", err)
+	}
+
+	trans := &transportBasicIO{ReadWriteCloser: failingWriter{}}
+	s = &Session{Transport: trans}
+	_, err = s.Exec(MethodGetConfig("running"))
+	if !errors.As(err, &te) || te.Op != "write" || te.InFlight || !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("expected write error closing the session, got %#v", err)
+	}
+
+	s = &Session{Transport: trans}
+	_, err = s.Exec(MethodXML(make(chan int)))
+	if err == nil || errors.Is(err, ErrTransportBroken) {
+		t.Errorf("expected marshaling error, got %#v", err)
+	}
+}
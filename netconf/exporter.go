@@ -0,0 +1,234 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MonitoringNamespace is the namespace of ietf-netconf-monitoring (RFC 6022).
+const MonitoringNamespace = "urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring"
+
+// defaultExporterInterval is the polling interval of an Exporter when
+// Interval is not set.
+const defaultExporterInterval = time.Minute
+
+var statisticsFilter = SubtreeFilter(`<netconf-state xmlns="` + MonitoringNamespace + `"><statistics/></netconf-state>`)
+
+// Statistics holds the counters of /netconf-state/statistics.
+type Statistics struct {
+	NetconfStartTime time.Time
+	InBadHellos      uint64
+	InSessions       uint64
+	DroppedSessions  uint64
+	InRPCs           uint64
+	InBadRPCs        uint64
+	OutRPCErrors     uint64
+	OutNotifications uint64
+}
+
+// Statistics retrieves the server's ietf-netconf-monitoring counters.
+func (s *Session) Statistics(ctx context.Context) (*Statistics, error) {
+	reply, err := s.ExecContext(ctx, MethodGetFilter(statisticsFilter))
+	if err != nil {
+		return nil, err
+	}
+	root, err := configRoot(reply.Data)
+	if err != nil {
+		return nil, err
+	}
+	var stats *Node
+	if state := root.Child("netconf-state"); state != nil {
+		stats = state.Child("statistics")
+	}
+	if stats == nil {
+		return nil, fmt.Errorf("netconf: reply holds no netconf-state statistics")
+	}
+
+	counter := func(local string) uint64 {
+		v, _ := strconv.ParseUint(childValue(stats, local), 10, 64)
+		return v
+	}
+	return &Statistics{
+		NetconfStartTime: parseStreamTime(childValue(stats, "netconf-start-time")),
+		InBadHellos:      counter("in-bad-hellos"),
+		InSessions:       counter("in-sessions"),
+		DroppedSessions:  counter("dropped-sessions"),
+		InRPCs:           counter("in-rpcs"),
+		InBadRPCs:        counter("in-bad-rpcs"),
+		OutRPCErrors:     counter("out-rpc-errors"),
+		OutNotifications: counter("out-notifications"),
+	}, nil
+}
+
+// exporterSample is the outcome of the last poll of a device.
+type exporterSample struct {
+	stats    *Statistics
+	err      error
+	duration time.Duration
+}
+
+// Exporter periodically polls the netconf-state statistics of devices and
+// serves them as Prometheus metrics, labelled by device.
+type Exporter struct {
+	Pool *Pool
+	// Devices lists the names of the devices to poll, every device of the
+	// pool's inventory if empty.
+	Devices []string
+	// Interval is the polling interval, one minute if zero.
+	Interval time.Duration
+	// Timeout bounds a single poll of a device, Interval if zero.
+	Timeout time.Duration
+
+	mu      sync.Mutex
+	samples map[string]*exporterSample
+}
+
+// NewExporter returns an exporter polling the devices of pool.
+func NewExporter(pool *Pool) *Exporter {
+	return &Exporter{Pool: pool}
+}
+
+// Run polls the devices until ctx is done.
+func (e *Exporter) Run(ctx context.Context) error {
+	interval := e.Interval
+	if interval <= 0 {
+		interval = defaultExporterInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		e.Collect(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Collect polls every device once, in parallel.
+func (e *Exporter) Collect(ctx context.Context) {
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = e.Interval
+	}
+	if timeout <= 0 {
+		timeout = defaultExporterInterval
+	}
+
+	var wg sync.WaitGroup
+	for _, device := range e.devices() {
+		wg.Add(1)
+		go func(device string) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			started := time.Now()
+			sample := &exporterSample{}
+			sample.err = e.Pool.Do(ctx, device, func(s *Session) error {
+				var err error
+				sample.stats, err = s.Statistics(ctx)
+				return err
+			})
+			sample.duration = time.Since(started)
+
+			e.mu.Lock()
+			if e.samples == nil {
+				e.samples = make(map[string]*exporterSample)
+			}
+			e.samples[device] = sample
+			e.mu.Unlock()
+		}(device)
+	}
+	wg.Wait()
+}
+
+func (e *Exporter) devices() []string {
+	if len(e.Devices) > 0 {
+		return e.Devices
+	}
+	names := make([]string, 0, len(e.Pool.Inventory.Devices))
+	for _, d := range e.Pool.Inventory.Devices {
+		names = append(names, d.Name)
+	}
+	return names
+}
+
+// exporterMetrics lists the exported counters.
+var exporterMetrics = []struct {
+	name  string
+	help  string
+	value func(*Statistics) uint64
+}{
+	{"netconf_in_bad_hellos_total", "Sessions dropped because of an invalid hello.", func(s *Statistics) uint64 { return s.InBadHellos }},
+	{"netconf_in_sessions_total", "Sessions started.", func(s *Statistics) uint64 { return s.InSessions }},
+	{"netconf_dropped_sessions_total", "Sessions terminated abnormally.", func(s *Statistics) uint64 { return s.DroppedSessions }},
+	{"netconf_in_rpcs_total", "Correct RPCs received.", func(s *Statistics) uint64 { return s.InRPCs }},
+	{"netconf_in_bad_rpcs_total", "Invalid RPCs received.", func(s *Statistics) uint64 { return s.InBadRPCs }},
+	{"netconf_out_rpc_errors_total", "RPC replies containing an rpc-error.", func(s *Statistics) uint64 { return s.OutRPCErrors }},
+	{"netconf_out_notifications_total", "Notifications sent.", func(s *Statistics) uint64 { return s.OutNotifications }},
+}
+
+// ServeHTTP writes the metrics of the last poll in the Prometheus text
+// exposition format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	e.WriteMetrics(w)
+}
+
+// WriteMetrics writes the metrics of the last poll in the Prometheus text
+// exposition format.
+func (e *Exporter) WriteMetrics(w io.Writer) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	devices := make([]string, 0, len(e.samples))
+	for device := range e.samples {
+		devices = append(devices, device)
+	}
+	sort.Strings(devices)
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "# HELP netconf_up Whether the last poll of the device succeeded.\n# TYPE netconf_up gauge\n")
+	for _, device := range devices {
+		up := 0
+		if e.samples[device].err == nil {
+			up = 1
+		}
+		fmt.Fprintf(b, "netconf_up{device=%s} %d\n", promLabel(device), up)
+	}
+	fmt.Fprintf(b, "# HELP netconf_poll_duration_seconds Duration of the last poll of the device.\n# TYPE netconf_poll_duration_seconds gauge\n")
+	for _, device := range devices {
+		fmt.Fprintf(b, "netconf_poll_duration_seconds{device=%s} %g\n", promLabel(device), e.samples[device].duration.Seconds())
+	}
+	for _, m := range exporterMetrics {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", m.name, m.help, m.name)
+		for _, device := range devices {
+			if stats := e.samples[device].stats; stats != nil {
+				fmt.Fprintf(b, "%s{device=%s} %d\n", m.name, promLabel(device), m.value(stats))
+			}
+		}
+	}
+	return b.Flush()
+}
+
+// promLabel quotes a Prometheus label value.
+func promLabel(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v) + `"`
+}
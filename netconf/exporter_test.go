@@ -0,0 +1,69 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const replyStatistics = `<rpc-reply><data><netconf-state xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring"><statistics>
+<netconf-start-time>2020-01-02T03:04:05Z</netconf-start-time>
+<in-bad-hellos>1</in-bad-hellos><in-sessions>12</in-sessions><dropped-sessions>2</dropped-sessions>
+<in-rpcs>340</in-rpcs><in-bad-rpcs>3</in-bad-rpcs><out-rpc-errors>5</out-rpc-errors><out-notifications>0</out-notifications>
+</statistics></netconf-state></data></rpc-reply>`
+
+func TestSessionStatistics(t *testing.T) {
+	s, _ := newScriptedSession(nil, replyStatistics)
+	stats, err := s.Statistics(context.Background())
+	if err != nil {
+		t.Fatalf("Statistics failed: %v", err)
+	}
+	expected := &Statistics{
+		NetconfStartTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		InBadHellos:      1,
+		InSessions:       12,
+		DroppedSessions:  2,
+		InRPCs:           340,
+		InBadRPCs:        3,
+		OutRPCErrors:     5,
+	}
+	if diff := cmp.Diff(expected, stats); diff != "" {
+		t.Errorf("unexpected statistics (-want +got):\n%s", diff)
+	}
+}
+
+func TestExporter(t *testing.T) {
+	pool, _ := newTestPool(replyStatistics)
+	e := NewExporter(pool)
+	e.Devices = []string{"r1", "missing"}
+	e.Collect(context.Background())
+
+	var buf bytes.Buffer
+	if err := e.WriteMetrics(&buf); err != nil {
+		t.Fatalf("WriteMetrics failed: %v", err)
+	}
+	for _, line := range []string{
+		`netconf_up{device="missing"} 0`,
+		`netconf_up{device="r1"} 1`,
+		`netconf_in_sessions_total{device="r1"} 12`,
+		`netconf_in_bad_rpcs_total{device="r1"} 3`,
+		"# TYPE netconf_in_rpcs_total counter",
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("expected metrics to contain %q, got:\n%s", line, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), `netconf_in_rpcs_total{device="missing"}`) {
+		t.Error("expected no counters for a device that could not be polled")
+	}
+}
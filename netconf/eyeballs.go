@@ -0,0 +1,149 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// DefaultAttemptDelay is the delay between the connection attempts of Happy
+// Eyeballs dialing recommended by RFC 8305.
+const DefaultAttemptDelay = 250 * time.Millisecond
+
+// WithHappyEyeballs races the connections to the addresses of a host name
+// as described by RFC 8305: the addresses are tried alternating between
+// IPv6 and IPv4, a new attempt being started every delay, or as soon as the
+// previous one failed, until one connects.  A broken address family thus
+// costs delay instead of a connection timeout.  A zero delay selects
+// DefaultAttemptDelay.
+func WithHappyEyeballs(delay time.Duration) Option {
+	return func(c *SessionConfig) {
+		if delay <= 0 {
+			delay = DefaultAttemptDelay
+		}
+		c.HappyEyeballs = delay
+	}
+}
+
+// dialConn connects to target, a host:port.
+func (c *SessionConfig) dialConn(ctx context.Context, target string) (net.Conn, error) {
+	dial := c.dialFunc()
+	if c.HappyEyeballs <= 0 || net.ParseIP(targetHost(target)) != nil {
+		return dial(ctx, "tcp", target)
+	}
+
+	host, port := splitTarget(target)
+	ips, err := c.resolver().LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	addrs := interleaveFamilies(ips)
+	for i, a := range addrs {
+		addrs[i] = net.JoinHostPort(a, port)
+	}
+	return raceDial(ctx, addrs, c.HappyEyeballs, func(ctx context.Context, addr string) (net.Conn, error) {
+		return dial(ctx, "tcp", addr)
+	})
+}
+
+// interleaveFamilies orders ips alternating between IPv6 and IPv4, starting
+// with IPv6, keeping the order of the resolver within each family.
+func interleaveFamilies(ips []net.IPAddr) []string {
+	var v6, v4 []string
+	for _, ip := range ips {
+		if ip.IP.To4() != nil {
+			v4 = append(v4, ip.String())
+		} else {
+			v6 = append(v6, ip.String())
+		}
+	}
+	addrs := make([]string, 0, len(ips))
+	for i := 0; i < len(v6) || i < len(v4); i++ {
+		if i < len(v6) {
+			addrs = append(addrs, v6[i])
+		}
+		if i < len(v4) {
+			addrs = append(addrs, v4[i])
+		}
+	}
+	return addrs
+}
+
+// raceDial starts dialing addrs in order, every delay or once the previous
+// attempt failed, and returns the first connection established.  The other
+// attempts are cancelled and their connections closed.  If all fail the
+// error of the first is returned.
+func raceDial(ctx context.Context, addrs []string, delay time.Duration, dial func(ctx context.Context, addr string) (net.Conn, error)) (net.Conn, error) {
+	if len(addrs) == 0 {
+		return nil, errors.New("netconf: no address to dial")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+	results := make(chan result, len(addrs))
+	next, pending := 0, 0
+	start := func() {
+		addr := addrs[next]
+		next++
+		pending++
+		go func() {
+			conn, err := dial(ctx, addr)
+			results <- result{conn, err}
+		}()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	restart := func() {
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(delay)
+	}
+
+	start()
+	var first error
+	for pending > 0 {
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				go func(n int) {
+					for i := 0; i < n; i++ {
+						if r := <-results; r.conn != nil {
+							r.conn.Close()
+						}
+					}
+				}(pending)
+				return r.conn, nil
+			}
+			if first == nil {
+				first = r.err
+			}
+			if next < len(addrs) {
+				start()
+				restart()
+			}
+		case <-timer.C:
+			if next < len(addrs) {
+				start()
+				timer.Reset(delay)
+			}
+		}
+	}
+	return nil, first
+}
@@ -0,0 +1,139 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInterleaveFamilies(t *testing.T) {
+	var ips []net.IPAddr
+	for _, s := range []string{"192.0.2.1", "192.0.2.2", "2001:db8::1", "192.0.2.3", "2001:db8::2"} {
+		ips = append(ips, net.IPAddr{IP: net.ParseIP(s)})
+	}
+	ips = append(ips, net.IPAddr{IP: net.ParseIP("fe80::1"), Zone: "mgmt"})
+	expected := []string{"2001:db8::1", "192.0.2.1", "2001:db8::2", "192.0.2.2", "fe80::1%mgmt", "192.0.2.3"}
+	if diff := cmp.Diff(expected, interleaveFamilies(ips)); diff != "" {
+		t.Errorf("order mismatch (-expected +got):\n%s", diff)
+	}
+}
+
+func TestRaceDial(t *testing.T) {
+	refused := errors.New("refused")
+	tt := []struct {
+		name     string
+		behavior map[string]string
+		winner   string
+		started  []string
+		min, max time.Duration
+		err      bool
+	}{
+		{
+			name:     "first connects",
+			behavior: map[string]string{"a": "ok", "b": "ok"},
+			winner:   "a",
+			started:  []string{"a"},
+			max:      35 * time.Millisecond,
+		},
+		{
+			name:     "broken family hangs",
+			behavior: map[string]string{"a": "hang", "b": "ok"},
+			winner:   "b",
+			started:  []string{"a", "b"},
+			min:      30 * time.Millisecond,
+			max:      80 * time.Millisecond,
+		},
+		{
+			name:     "failure starts next at once",
+			behavior: map[string]string{"a": "fail", "b": "fail", "c": "ok"},
+			winner:   "c",
+			started:  []string{"a", "b", "c"},
+			max:      35 * time.Millisecond,
+		},
+		{
+			name:     "all fail",
+			behavior: map[string]string{"a": "fail", "b": "fail", "c": "fail"},
+			started:  []string{"a", "b", "c"},
+			max:      35 * time.Millisecond,
+			err:      true,
+		},
+	}
+
+	for _, tc := range tt {
+		var mu sync.Mutex
+		var started []string
+		closed := make(chan string, 3)
+		dial := func(ctx context.Context, addr string) (net.Conn, error) {
+			mu.Lock()
+			started = append(started, addr)
+			mu.Unlock()
+			switch tc.behavior[addr] {
+			case "ok":
+				client, server := net.Pipe()
+				go func() {
+					server.Read(make([]byte, 1))
+					closed <- addr
+				}()
+				return client, nil
+			case "hang":
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return nil, refused
+		}
+
+		begin := time.Now()
+		var addrs []string
+		for _, a := range []string{"a", "b", "c"} {
+			if tc.behavior[a] != "" {
+				addrs = append(addrs, a)
+			}
+		}
+		conn, err := raceDial(context.Background(), addrs, 40*time.Millisecond, dial)
+		elapsed := time.Since(begin)
+		if tc.err {
+			if err != refused {
+				t.Errorf("%s: got %v, expected the first error", tc.name, err)
+			}
+		} else if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+			continue
+		} else {
+			conn.Close()
+			if got := <-closed; got != tc.winner {
+				t.Errorf("%s: got connection to %s, expected %s", tc.name, got, tc.winner)
+			}
+		}
+		if elapsed < tc.min || elapsed > tc.max {
+			t.Errorf("%s: took %s, expected %s to %s", tc.name, elapsed, tc.min, tc.max)
+		}
+		mu.Lock()
+		if diff := cmp.Diff(tc.started, started); diff != "" {
+			t.Errorf("%s: attempts mismatch (-expected +got):\n%s", tc.name, diff)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestDialHappyEyeballs(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+
+	_, port, _ := net.SplitHostPort(srv.Addr())
+	s, err := Dial(net.JoinHostPort("localhost", port), WithSSHConfig(testSSHConfig()), WithHappyEyeballs(0))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	s.Close()
+}
@@ -0,0 +1,289 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Facts describe a device independently of its vendor.
+type Facts struct {
+	Hostname  string        `json:"hostname,omitempty"`
+	Vendor    string        `json:"vendor,omitempty"`
+	OSName    string        `json:"os_name,omitempty"`
+	OSVersion string        `json:"os_version,omitempty"`
+	Model     string        `json:"model,omitempty"`
+	Serial    string        `json:"serial,omitempty"`
+	Uptime    time.Duration `json:"uptime,omitempty"`
+}
+
+// complete reports whether every fact is known.
+func (f *Facts) complete() bool {
+	return f.Hostname != "" && f.Vendor != "" && f.OSVersion != "" &&
+		f.Model != "" && f.Serial != "" && f.Uptime != 0
+}
+
+// Namespaces of the standard models read by GatherFacts.
+const (
+	systemNamespace   = "urn:ietf:params:xml:ns:yang:ietf-system"
+	hardwareNamespace = "urn:ietf:params:xml:ns:yang:ietf-hardware"
+)
+
+// vendorNamespaces maps the domains found in the capabilities of servers
+// to their vendor.
+var vendorNamespaces = []struct{ domain, vendor string }{
+	{"juniper.net", "Juniper"},
+	{"cisco.com", "Cisco"},
+	{"nokia.com", "Nokia"},
+	{"arista.com", "Arista"},
+	{"huawei.com", "Huawei"},
+}
+
+// GatherFacts collects the facts of the device of s.  They are read from
+// ietf-system, ietf-hardware and ietf-netconf-monitoring if the server
+// announces them, and facts still missing are filled in by the Facts
+// function of the session's profile.  Without a profile the one of the
+// vendor found in the capabilities is used.  RPC errors are ignored so that
+// the facts that could be found are returned.
+func GatherFacts(ctx context.Context, s *Session) (*Facts, error) {
+	f := &Facts{}
+	if err := standardFacts(ctx, s, f); err != nil {
+		return nil, err
+	}
+
+	vendor := capabilityVendor(s.ServerCapabilities)
+	profile := s.Profile
+	if profile == nil && vendor != "" {
+		profile = vendorProfile(vendor)
+	}
+	setFact(&f.Vendor, vendor)
+	if profile != nil {
+		setFact(&f.Vendor, profile.Vendor)
+	}
+	if profile != nil && profile.Facts != nil && !f.complete() {
+		if err := profile.Facts(ctx, s, f); err != nil && !isRPCError(err) {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// standardFacts reads the facts held in the standard models.
+func standardFacts(ctx context.Context, s *Session, f *Facts) error {
+	caps := s.Capabilities()
+	library := caps.Has(CapabilityYANGLibrary) || caps.Has(CapabilityYANGLibrary11)
+	supports := func(module string) bool {
+		return library || caps.Module(module) != nil
+	}
+
+	var filter strings.Builder
+	if supports("ietf-system") {
+		filter.WriteString(`<system xmlns="` + systemNamespace + `"><hostname/></system>`)
+		filter.WriteString(`<system-state xmlns="` + systemNamespace + `"/>`)
+	}
+	if supports("ietf-hardware") {
+		filter.WriteString(`<hardware xmlns="` + hardwareNamespace + `"><component>` +
+			`<class/><parent/><mfg-name/><model-name/><serial-num/></component></hardware>`)
+	}
+	if supports("ietf-netconf-monitoring") {
+		filter.WriteString(`<netconf-state xmlns="` + MonitoringNamespace + `"><statistics><netconf-start-time/></statistics></netconf-state>`)
+	}
+	if filter.Len() == 0 {
+		return nil
+	}
+
+	root, err := getFacts(ctx, s, MethodGetFilter(SubtreeFilter(filter.String())))
+	if root == nil {
+		return err
+	}
+	f.Hostname = selectValue(root, "/system/hostname")
+	f.OSName = selectValue(root, "/system-state/platform/os-name")
+	f.OSVersion = selectValue(root, "/system-state/platform/os-release", "/system-state/platform/os-version")
+	f.Uptime = since(selectValue(root, "/system-state/clock/boot-datetime"), selectValue(root, "/system-state/clock/current-datetime"))
+
+	components, _ := root.Select("/hardware/component")
+	for _, c := range components {
+		if strings.HasSuffix(childValue(c, "class"), "chassis") && childValue(c, "parent") == "" {
+			f.Vendor = childValue(c, "mfg-name")
+			f.Model = childValue(c, "model-name")
+			f.Serial = childValue(c, "serial-num")
+			break
+		}
+	}
+
+	if f.Uptime == 0 {
+		// The uptime of the NETCONF server, if nothing better is known.
+		f.Uptime = since(selectValue(root, "/netconf-state/statistics/netconf-start-time"), "")
+	}
+	return nil
+}
+
+// getFacts executes m and returns the root of the data of the reply.  The
+// root is nil if there was an error other than an rpc-error.
+func getFacts(ctx context.Context, s *Session, m RPCMethod) (*Node, error) {
+	reply, err := s.ExecContext(ctx, m)
+	if err != nil {
+		if isRPCError(err) {
+			return &Node{}, nil
+		}
+		return nil, err
+	}
+	root, err := configRoot(reply.Data)
+	if err != nil {
+		return &Node{}, nil
+	}
+	return root, nil
+}
+
+func isRPCError(err error) bool {
+	var rpcErr *RPCError
+	return errors.As(err, &rpcErr)
+}
+
+// selectValue returns the value of the first node selected by one of the
+// expressions.
+func selectValue(root *Node, exprs ...string) string {
+	for _, expr := range exprs {
+		if nodes, _ := root.Select(expr); len(nodes) > 0 && nodes[0].Value() != "" {
+			return nodes[0].Value()
+		}
+	}
+	return ""
+}
+
+// since returns the time between the YANG date-and-time values start and
+// now, the current time if empty.  It is zero if start is not valid.
+func since(start, now string) time.Duration {
+	t := parseStreamTime(start)
+	if t.IsZero() {
+		return 0
+	}
+	end := time.Now()
+	if now != "" {
+		if end = parseStreamTime(now); end.IsZero() {
+			return 0
+		}
+	}
+	if d := end.Sub(t); d > 0 {
+		return d.Truncate(time.Second)
+	}
+	return 0
+}
+
+// setFact sets *fact to value unless it is known already.
+func setFact(fact *string, value string) {
+	if *fact == "" {
+		*fact = value
+	}
+}
+
+func capabilityVendor(caps []string) string {
+	for _, c := range caps {
+		for _, v := range vendorNamespaces {
+			if strings.Contains(c, v.domain) {
+				return v.vendor
+			}
+		}
+	}
+	return ""
+}
+
+// vendorProfile returns the first profile of the vendor.
+func vendorProfile(vendor string) *Profile {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	for _, p := range profiles {
+		if strings.EqualFold(p.Vendor, vendor) {
+			return p
+		}
+	}
+	return nil
+}
+
+var junosVersionComment = regexp.MustCompile(`\[([^\]]+)\]`)
+
+// junosFacts reads the facts of a Junos device from its operational RPCs.
+func junosFacts(ctx context.Context, s *Session, f *Facts) error {
+	root, err := getFacts(ctx, s, RawMethod("<get-software-information/>"))
+	if root == nil {
+		return err
+	}
+	setFact(&f.Hostname, selectValue(root, "//software-information/host-name"))
+	setFact(&f.Model, selectValue(root, "//software-information/product-model"))
+	setFact(&f.OSName, "Junos")
+	version := selectValue(root, "//software-information/junos-version")
+	if version == "" {
+		comment := selectValue(root, "//software-information/package-information[name='junos']/comment")
+		if m := junosVersionComment.FindStringSubmatch(comment); m != nil {
+			version = m[1]
+		}
+	}
+	setFact(&f.OSVersion, version)
+
+	if root, err = getFacts(ctx, s, RawMethod("<get-chassis-inventory/>")); root == nil {
+		return err
+	}
+	setFact(&f.Serial, selectValue(root, "//chassis-inventory/chassis/serial-number"))
+	setFact(&f.Model, selectValue(root, "//chassis-inventory/chassis/description"))
+
+	if f.Uptime == 0 {
+		if root, err = getFacts(ctx, s, RawMethod("<get-system-uptime-information/>")); root == nil {
+			return err
+		}
+		if nodes, _ := root.Select("//system-uptime-information/system-booted-time/time-length"); len(nodes) > 0 {
+			for _, a := range nodes[0].Attrs {
+				if a.Name.Local == "seconds" {
+					if secs, err := strconv.ParseInt(a.Value, 10, 64); err == nil {
+						f.Uptime = time.Duration(secs) * time.Second
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// iosxeFacts reads the facts of an IOS XE device from its native and
+// device hardware models.
+func iosxeFacts(ctx context.Context, s *Session, f *Facts) error {
+	root, err := getFacts(ctx, s, MethodGetFilter(SubtreeFilter(
+		`<native xmlns="http://cisco.com/ns/yang/Cisco-IOS-XE-native"><hostname/><version/></native>`+
+			`<device-hardware-data xmlns="http://cisco.com/ns/yang/Cisco-IOS-XE-device-hardware-oper"/>`)))
+	if root == nil {
+		return err
+	}
+	setFact(&f.Hostname, selectValue(root, "/native/hostname"))
+	setFact(&f.OSName, "IOS XE")
+	setFact(&f.OSVersion, selectValue(root, "/native/version"))
+	setFact(&f.Model, selectValue(root, "//device-inventory[hw-type='hw-type-chassis']/part-number"))
+	setFact(&f.Serial, selectValue(root, "//device-inventory[hw-type='hw-type-chassis']/serial-number"))
+	if f.Uptime == 0 {
+		f.Uptime = since(selectValue(root, "//device-system-data/boot-time"), selectValue(root, "//device-system-data/current-time"))
+	}
+	return nil
+}
+
+// srosFacts reads the facts of an SR OS device from its state model.
+func srosFacts(ctx context.Context, s *Session, f *Facts) error {
+	root, err := getFacts(ctx, s, MethodGetFilter(SubtreeFilter(
+		`<state xmlns="urn:nokia.com:sros:ns:yang:sr:state"><system><oper-name/><platform/><version><version-number/></version></system>`+
+			`<chassis><hardware-data><serial-number/></hardware-data></chassis></state>`)))
+	if root == nil {
+		return err
+	}
+	setFact(&f.Hostname, selectValue(root, "/state/system/oper-name"))
+	setFact(&f.OSName, "SR OS")
+	setFact(&f.OSVersion, selectValue(root, "/state/system/version/version-number"))
+	setFact(&f.Model, selectValue(root, "/state/system/platform"))
+	setFact(&f.Serial, selectValue(root, "/state/chassis/hardware-data/serial-number"))
+	return nil
+}
@@ -0,0 +1,140 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func factsReply(data string) string {
+	return `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0">` + data + `</rpc-reply>`
+}
+
+func TestGatherFacts(t *testing.T) {
+	tt := []struct {
+		name     string
+		caps     []string
+		profile  *Profile
+		replies  []string
+		ops      []string
+		expected *Facts
+	}{
+		{
+			name: "standard",
+			caps: []string{
+				CapabilityBase11,
+				"urn:ietf:params:xml:ns:yang:ietf-system?module=ietf-system&revision=2014-08-06",
+				"urn:ietf:params:xml:ns:yang:ietf-hardware?module=ietf-hardware&revision=2018-03-13",
+			},
+			replies: []string{factsReply(`<data>
+<system xmlns="urn:ietf:params:xml:ns:yang:ietf-system"><hostname>r1</hostname></system>
+<system-state xmlns="urn:ietf:params:xml:ns:yang:ietf-system">
+<platform><os-name>ExampleOS</os-name><os-release>4.2</os-release><machine>x86_64</machine></platform>
+<clock><current-datetime>2020-01-02T03:04:05Z</current-datetime><boot-datetime>2020-01-01T03:04:05Z</boot-datetime></clock>
+</system-state>
+<hardware xmlns="urn:ietf:params:xml:ns:yang:ietf-hardware">
+<component><name>slot0</name><class xmlns:ianahw="urn:ietf:params:xml:ns:yang:iana-hardware">ianahw:module</class><parent>chassis</parent><serial-num>M1</serial-num></component>
+<component><name>chassis</name><class xmlns:ianahw="urn:ietf:params:xml:ns:yang:iana-hardware">ianahw:chassis</class>
+<mfg-name>Example</mfg-name><model-name>EX-1</model-name><serial-num>S1</serial-num></component>
+</hardware></data>`)},
+			ops: []string{"get"},
+			expected: &Facts{
+				Hostname:  "r1",
+				Vendor:    "Example",
+				OSName:    "ExampleOS",
+				OSVersion: "4.2",
+				Model:     "EX-1",
+				Serial:    "S1",
+				Uptime:    24 * time.Hour,
+			},
+		},
+		{
+			name: "junos",
+			caps: []string{CapabilityBase10, "http://xml.juniper.net/netconf/junos/1.0"},
+			replies: []string{
+				factsReply(`<software-information><host-name>mx1</host-name><product-model>mx960</product-model>
+<package-information><name>junos</name><comment>JUNOS Base OS boot [18.4R2.7]</comment></package-information></software-information>`),
+				factsReply(`<chassis-inventory><chassis><name>Chassis</name><serial-number>JN11</serial-number><description>MX960</description></chassis></chassis-inventory>`),
+				factsReply(`<system-uptime-information xmlns:junos="http://xml.juniper.net/junos/18.4R2/junos"><system-booted-time>
+<date-time junos:seconds="1577934245">2020-01-02 03:04:05 UTC</date-time><time-length junos:seconds="3600">01:00:00</time-length>
+</system-booted-time></system-uptime-information>`),
+			},
+			ops: []string{"get-software-information", "get-chassis-inventory", "get-system-uptime-information"},
+			expected: &Facts{
+				Hostname:  "mx1",
+				Vendor:    "Juniper",
+				OSName:    "Junos",
+				OSVersion: "18.4R2.7",
+				Model:     "mx960",
+				Serial:    "JN11",
+				Uptime:    time.Hour,
+			},
+		},
+		{
+			name: "iosxe",
+			caps: []string{CapabilityBase11, "http://cisco.com/ns/yang/Cisco-IOS-XE-native?module=Cisco-IOS-XE-native&revision=2019-07-01"},
+			replies: []string{factsReply(`<data>
+<native xmlns="http://cisco.com/ns/yang/Cisco-IOS-XE-native"><version>16.12</version><hostname>csr1</hostname></native>
+<device-hardware-data xmlns="http://cisco.com/ns/yang/Cisco-IOS-XE-device-hardware-oper"><device-hardware>
+<device-inventory><hw-type>hw-type-dimm</hw-type><part-number>D1</part-number></device-inventory>
+<device-inventory><hw-type>hw-type-chassis</hw-type><part-number>C9300-24T</part-number><serial-number>FOC1</serial-number></device-inventory>
+<device-system-data><current-time>2020-01-01T00:02:00+00:00</current-time><boot-time>2020-01-01T00:00:00+00:00</boot-time></device-system-data>
+</device-hardware></device-hardware-data></data>`)},
+			ops: []string{"get"},
+			expected: &Facts{
+				Hostname:  "csr1",
+				Vendor:    "Cisco",
+				OSName:    "IOS XE",
+				OSVersion: "16.12",
+				Model:     "C9300-24T",
+				Serial:    "FOC1",
+				Uptime:    2 * time.Minute,
+			},
+		},
+		{
+			name:     "rpcError",
+			profile:  ProfileSROS,
+			replies:  []string{replyError("operation-not-supported")},
+			ops:      []string{"get"},
+			expected: &Facts{Vendor: "Nokia", OSName: "SR OS"},
+		},
+		{
+			name:     "unknown",
+			caps:     []string{CapabilityBase10},
+			expected: &Facts{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s, trans := newScriptedSession(tc.caps, tc.replies...)
+			s.Profile = tc.profile
+
+			facts, err := GatherFacts(context.Background(), s)
+			if err != nil {
+				t.Fatalf("GatherFacts failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, facts); diff != "" {
+				t.Errorf("facts mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.ops, trans.operations()); diff != "" {
+				t.Errorf("operations mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGatherFactsTransportError(t *testing.T) {
+	s, _ := newScriptedSession([]string{"http://xml.juniper.net/netconf/junos/1.0"})
+	if _, err := GatherFacts(context.Background(), s); err == nil {
+		t.Errorf("expected error")
+	}
+}
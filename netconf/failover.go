@@ -0,0 +1,149 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+// WithAttemptTimeout bounds every address tried by DialAny and DialDevice,
+// so that an unresponsive address leaves time for the next one.  Timeout
+// still bounds each attempt, the context all of them.
+func WithAttemptTimeout(d time.Duration) Option {
+	return func(c *SessionConfig) { c.AttemptTimeout = d }
+}
+
+// WithResolveAll makes DialAny and DialDevice resolve host names and try
+// every address of the name as a target of its own.  Without it a name is
+// a single target, whose addresses the dial function only moves through
+// while connecting fails.  DialAny fails over between targets on any error,
+// of the handshake too.
+func WithResolveAll() Option {
+	return func(c *SessionConfig) { c.ResolveAll = true }
+}
+
+// DialAttempt is an address tried by DialAny.
+type DialAttempt struct {
+	Address string
+	Err     error
+}
+
+// DialError is returned by DialAny if no address could be dialled.  It
+// unwraps to the error of the last attempt.
+type DialError struct {
+	Attempts []DialAttempt
+}
+
+func (e *DialError) Error() string {
+	if len(e.Attempts) == 0 {
+		return "netconf: no address to dial"
+	}
+	var b strings.Builder
+	b.WriteString("netconf: all addresses failed")
+	for i, a := range e.Attempts {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		b.WriteString(a.Address + ": " + a.Err.Error())
+	}
+	return b.String()
+}
+
+func (e *DialError) Unwrap() error {
+	if len(e.Attempts) == 0 {
+		return nil
+	}
+	return e.Attempts[len(e.Attempts)-1].Err
+}
+
+// dialAddress is an address to dial and the host name to verify the
+// server for.
+type dialAddress struct {
+	address string
+	host    string
+}
+
+// DialAny establishes a session to the first of targets that can be
+// dialled, trying them in order.  Session.Address reports the address
+// that succeeded.  The credentials, if a CredentialProvider is set, are
+// those of the first target.  If the only address fails, its error is
+// returned, else a *DialError listing all attempts.
+func (c *SessionConfig) DialAny(ctx context.Context, targets ...string) (*Session, error) {
+	if c.Credentials != nil && len(targets) > 0 {
+		config, err := c.resolveCredentials(ctx, targetDevice(targets[0]))
+		if err != nil {
+			return nil, err
+		}
+		return config.DialAny(ctx, targets...)
+	}
+
+	var attempts []DialAttempt
+	for _, target := range targets {
+		addrs, err := c.resolve(ctx, target)
+		if err != nil {
+			attempts = append(attempts, DialAttempt{Address: target, Err: err})
+			continue
+		}
+		for _, a := range addrs {
+			if ctx.Err() != nil {
+				break
+			}
+			s, err := c.dialAttempt(ctx, a)
+			if err == nil {
+				return s, nil
+			}
+			attempts = append(attempts, DialAttempt{Address: a.address, Err: err})
+			c.logf("netconf: dial %s failed: %v", a.address, err)
+		}
+	}
+	if err := ctx.Err(); err != nil && len(attempts) == 0 {
+		return nil, err
+	}
+	if len(attempts) == 1 {
+		return nil, attempts[0].Err
+	}
+	return nil, &DialError{Attempts: attempts}
+}
+
+func (c *SessionConfig) dialAttempt(ctx context.Context, a dialAddress) (*Session, error) {
+	if c.AttemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.AttemptTimeout)
+		defer cancel()
+	}
+	return c.dial(ctx, a.address, a.host)
+}
+
+// resolve returns the addresses to try for target: target itself, or the
+// addresses its host name resolves to if ResolveAll is set.
+func (c *SessionConfig) resolve(ctx context.Context, target string) ([]dialAddress, error) {
+	target = c.address(target)
+	host := targetHost(target)
+	if !c.ResolveAll || net.ParseIP(host) != nil {
+		return []dialAddress{{address: target, host: host}}, nil
+	}
+
+	name, port := splitTarget(target)
+	ips, err := c.resolver().LookupHost(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, errors.New("netconf: no address for " + name)
+	}
+	addrs := make([]dialAddress, len(ips))
+	for i, ip := range ips {
+		addrs[i] = dialAddress{address: net.JoinHostPort(ip, port), host: name}
+	}
+	return addrs, nil
+}
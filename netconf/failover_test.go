@@ -0,0 +1,103 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// closedAddress returns an address nothing listens on.
+func closedAddress(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return addr
+}
+
+func TestDialAny(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+
+	// A listener that never completes the SSH handshake.
+	silent, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+
+	var logs bytes.Buffer
+	config := NewSessionConfig(WithSSHConfig(testSSHConfig()), WithAttemptTimeout(100*time.Millisecond),
+		WithLogger(log.New(&logs, "", 0)))
+	refused := closedAddress(t)
+	s, err := config.DialAny(context.Background(), refused, silent.Addr().String(), srv.Addr())
+	if err != nil {
+		t.Fatalf("DialAny failed: %v", err)
+	}
+	defer s.Close()
+	if s.Address != srv.Addr() {
+		t.Errorf("got address %s, expected %s", s.Address, srv.Addr())
+	}
+	if strings.Count(logs.String(), "netconf: dial ") != 2 {
+		t.Errorf("expected two failed attempts to be logged, got %q", logs.String())
+	}
+
+	_, err = config.DialAny(context.Background(), refused, silent.Addr().String())
+	var dialErr *DialError
+	if !errors.As(err, &dialErr) || len(dialErr.Attempts) != 2 || dialErr.Attempts[0].Address != refused {
+		t.Fatalf("got %v, expected a DialError with two attempts", err)
+	}
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("expected the timeout of the last attempt, got %v", err)
+	}
+
+	// A single address fails with its own error.
+	if _, err := config.DialAny(context.Background(), refused); err == nil || errors.As(err, &dialErr) {
+		t.Errorf("got %v, expected the dial error", err)
+	}
+}
+
+func TestDialDeviceAddresses(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+
+	d := &Device{Name: "r1", Address: closedAddress(t), Addresses: []string{srv.Addr()}}
+	s, err := NewSessionConfig(WithSSHConfig(testSSHConfig())).DialDevice(context.Background(), d)
+	if err != nil {
+		t.Fatalf("DialDevice failed: %v", err)
+	}
+	defer s.Close()
+	if s.Address != srv.Addr() {
+		t.Errorf("got address %s, expected the out-of-band address %s", s.Address, srv.Addr())
+	}
+}
+
+func TestDialResolveAll(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+
+	_, port, _ := net.SplitHostPort(srv.Addr())
+	config := NewSessionConfig(WithSSHConfig(testSSHConfig()), WithResolveAll())
+	s, err := config.DialAny(context.Background(), net.JoinHostPort("localhost", port))
+	if err != nil {
+		t.Fatalf("DialAny failed: %v", err)
+	}
+	defer s.Close()
+	if s.Address != srv.Addr() {
+		t.Errorf("got address %s, expected %s", s.Address, srv.Addr())
+	}
+}
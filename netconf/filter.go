@@ -0,0 +1,71 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"sort"
+)
+
+// Filter types defined by RFC 6241.
+const (
+	FilterSubtree = "subtree"
+	FilterXPath   = "xpath"
+)
+
+// Filter selects the data returned by get and get-config.
+type Filter struct {
+	// Type is FilterSubtree or FilterXPath.
+	Type string
+	// Content is the XML of a subtree filter.
+	Content string
+	// Select is the expression of an XPath filter.
+	Select string
+	// Namespaces maps the prefixes used in Select to namespace URIs.
+	Namespaces map[string]string
+}
+
+// SubtreeFilter returns a subtree filter selecting the given XML.
+func SubtreeFilter(content string) *Filter {
+	return &Filter{Type: FilterSubtree, Content: content}
+}
+
+// XPathFilter returns an XPath filter selecting the nodes matched by expr.
+// namespaces maps the prefixes used in expr to namespace URIs and may be nil.
+func XPathFilter(expr string, namespaces map[string]string) *Filter {
+	return &Filter{Type: FilterXPath, Select: expr, Namespaces: namespaces}
+}
+
+// String returns the <filter> element.
+func (f *Filter) String() string {
+	var buf bytes.Buffer
+	buf.WriteString("<filter")
+
+	prefixes := make([]string, 0, len(f.Namespaces))
+	for p := range f.Namespaces {
+		prefixes = append(prefixes, p)
+	}
+	sort.Strings(prefixes)
+	for _, p := range prefixes {
+		writeAttr(&buf, xmlnsPrefix+":"+p, f.Namespaces[p])
+	}
+
+	if f.Type != "" {
+		writeAttr(&buf, "type", f.Type)
+	}
+	if f.Type == FilterXPath {
+		writeAttr(&buf, "select", f.Select)
+	}
+	if f.Content == "" {
+		buf.WriteString("/>")
+		return buf.String()
+	}
+	buf.WriteByte('>')
+	buf.WriteString(f.Content)
+	buf.WriteString("</filter>")
+	return buf.String()
+}
@@ -0,0 +1,219 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultFleetWorkers is the number of devices a Fleet works on in parallel
+// when Workers is not set.
+const defaultFleetWorkers = 10
+
+// FleetJob is the work performed against a single device by a Fleet.  The
+// job may annotate the result, e.g. by setting a Diff.
+type FleetJob func(ctx context.Context, s *Session, r *DeviceResult) error
+
+// Fleet runs a job against a set of devices in parallel.
+type Fleet struct {
+	Devices []*Device
+	// Dial opens a session to a device.  The session is closed by the Fleet
+	// once the job has finished.
+	Dial func(ctx context.Context, d *Device) (*Session, error)
+	// Workers caps the number of devices worked on at the same time.
+	Workers int
+	// DeviceLimit is applied to the sessions of every device that does not
+	// define its own Limits.
+	DeviceLimit *RateLimit
+	// Limiter, if set, is shared by all sessions opened by the fleet and
+	// caps the RPC rate of the runner as a whole.
+	Limiter *RateLimiter
+	// BreakerThreshold, if positive, enables a circuit breaker per device
+	// that skips the device after this many consecutive connection or
+	// transport failures, until BreakerCooldown has passed.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// Dampener, if set, skips devices that keep failing to connect or
+	// losing their sessions, with a quarantine growing on every
+	// suppression.  Unlike the breakers it may be shared with a Pool.
+	Dampener *Dampener
+	// Progress, if set, is called when a device is started and when it is
+	// done, e.g. to drive a progress bar.  Calls are serialized and must
+	// not block for long, as they hold up the workers.
+	Progress func(p FleetProgress)
+
+	mu       sync.Mutex
+	limiters map[string]*RateLimiter
+	breakers map[string]*CircuitBreaker
+}
+
+// Run executes job against every device and returns a report of the
+// results.  Results are reported in the order of Devices.
+func (f *Fleet) Run(ctx context.Context, job FleetJob) *Report {
+	report := &Report{
+		Started: time.Now(),
+		Results: make([]*DeviceResult, len(f.Devices)),
+	}
+
+	workers := f.Workers
+	if workers <= 0 {
+		workers = defaultFleetWorkers
+	}
+
+	tracker := newFleetTracker(f.Progress, len(f.Devices), report.Started)
+	var wg sync.WaitGroup
+	idx := make(chan int)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				tracker.start(f.Devices[i].Name)
+				report.Results[i] = f.runDevice(ctx, f.Devices[i], job)
+				tracker.finish(report.Results[i])
+			}
+		}()
+	}
+
+	for i := range f.Devices {
+		idx <- i
+	}
+	close(idx)
+	wg.Wait()
+
+	report.Duration = time.Since(report.Started)
+	return report
+}
+
+func (f *Fleet) runDevice(ctx context.Context, d *Device, job FleetJob) *DeviceResult {
+	r := &DeviceResult{
+		Device:  d.Name,
+		Started: time.Now(),
+	}
+	defer func() { r.Duration = time.Since(r.Started) }()
+
+	if err := ctx.Err(); err != nil {
+		r.Status = StatusSkipped
+		r.Error = err.Error()
+		return r
+	}
+
+	if f.Dial == nil {
+		r.fail(fmt.Errorf("fleet has no dial function"))
+		return r
+	}
+
+	breaker := f.deviceBreaker(d)
+	if breaker != nil {
+		if err := breaker.Allow(); err != nil {
+			r.Status = StatusSkipped
+			r.Error = err.Error()
+			return r
+		}
+	}
+
+	if f.Dampener != nil {
+		if err := f.Dampener.Allow(d.Name); err != nil {
+			r.Status = StatusSkipped
+			r.Error = err.Error()
+			return r
+		}
+	}
+
+	s, err := f.Dial(ctx, d)
+	if err != nil {
+		if breaker != nil && dialFailed(ctx, err) {
+			breaker.Failure()
+		} else if breaker != nil {
+			breaker.release()
+		}
+		if f.Dampener != nil && dialFailed(ctx, err) {
+			f.Dampener.Failure(d.Name, err)
+		}
+		r.fail(err)
+		return r
+	}
+	defer s.Close()
+	if l := f.deviceLimiter(d); l != nil {
+		s.Limiter = l
+	}
+	if s.Profile == nil && d.Profile != "" {
+		s.Profile = LookupProfile(d.Profile)
+	}
+
+	err = job(ctx, s, r)
+	if breaker != nil {
+		// Only a lost or unresponsive session counts against the device,
+		// other errors of the job show it is alive.
+		switch {
+		case flapped(err):
+			breaker.Failure()
+		case err != nil && ctx.Err() != nil:
+			breaker.release()
+		default:
+			breaker.Success()
+		}
+	}
+	if f.Dampener != nil && flapped(err) {
+		f.Dampener.Failure(d.Name, err)
+	}
+	if err != nil {
+		r.fail(err)
+		return r
+	}
+
+	r.Status = StatusOK
+	return r
+}
+
+// deviceLimiter returns the limiter shared by all sessions to d.  Limiters
+// are kept across runs so that repeated runs honour the same limits.
+func (f *Fleet) deviceLimiter(d *Device) *RateLimiter {
+	limit := d.Limits
+	if limit == nil {
+		limit = f.DeviceLimit
+	}
+	if limit == nil {
+		return f.Limiter
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.limiters == nil {
+		f.limiters = make(map[string]*RateLimiter)
+	}
+	l, ok := f.limiters[d.Name]
+	if !ok {
+		l = NewRateLimiter(*limit)
+		l.Parent = f.Limiter
+		f.limiters[d.Name] = l
+	}
+	return l
+}
+
+// deviceBreaker returns the circuit breaker of d, or nil if breakers are
+// disabled.
+func (f *Fleet) deviceBreaker(d *Device) *CircuitBreaker {
+	if f.BreakerThreshold <= 0 {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.breakers == nil {
+		f.breakers = make(map[string]*CircuitBreaker)
+	}
+	b, ok := f.breakers[d.Name]
+	if !ok {
+		b = NewCircuitBreaker(f.BreakerThreshold, f.BreakerCooldown)
+		f.breakers[d.Name] = b
+	}
+	return b
+}
@@ -0,0 +1,125 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func newFleetTest(names ...string) *Fleet {
+	f := &Fleet{Workers: 2}
+	for _, n := range names {
+		f.Devices = append(f.Devices, &Device{Name: n, Address: n})
+	}
+	f.Dial = func(ctx context.Context, d *Device) (*Session, error) {
+		if d.Name == "unreachable" {
+			return nil, errors.New("connection refused")
+		}
+		trans, _ := newTransportTest("")
+		return &Session{Transport: trans}, nil
+	}
+	return f
+}
+
+func TestFleetRun(t *testing.T) {
+	f := newFleetTest("ok", "unreachable", "rpcerror")
+
+	report := f.Run(context.Background(), func(ctx context.Context, s *Session, r *DeviceResult) error {
+		if r.Device == "rpcerror" {
+			return fmt.Errorf("edit failed: %w", &RPCError{Severity: "error", Tag: "invalid-value", Message: "bad"})
+		}
+		r.Diff = "+ foo"
+		return nil
+	})
+
+	expected := map[string]DeviceStatus{
+		"ok":          StatusOK,
+		"unreachable": StatusFailed,
+		"rpcerror":    StatusFailed,
+	}
+	if len(report.Results) != len(expected) {
+		t.Fatalf("got %d results, expected %d", len(report.Results), len(expected))
+	}
+	for i, res := range report.Results {
+		if res.Device != f.Devices[i].Name {
+			t.Errorf("result %d is for %s, expected %s", i, res.Device, f.Devices[i].Name)
+		}
+		if res.Status != expected[res.Device] {
+			t.Errorf("%s: got status %s, expected %s", res.Device, res.Status, expected[res.Device])
+		}
+	}
+	if rpcErrs := report.Results[2].RPCErrors; len(rpcErrs) != 1 || rpcErrs[0].Tag != "invalid-value" {
+		t.Errorf("rpc-error was not recorded: %+v", report.Results[2])
+	}
+	if report.OK() {
+		t.Errorf("report should not be OK")
+	}
+}
+
+func TestFleetRunCancelled(t *testing.T) {
+	f := newFleetTest("a", "b")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report := f.Run(ctx, func(ctx context.Context, s *Session, r *DeviceResult) error {
+		t.Errorf("job should not run on %s", r.Device)
+		return nil
+	})
+	if n := report.Count(StatusSkipped); n != 2 {
+		t.Errorf("got %d skipped devices, expected 2", n)
+	}
+}
+
+func TestReportRender(t *testing.T) {
+	report := &Report{Results: []*DeviceResult{
+		{Device: "a", Status: StatusOK, Diff: "+ foo"},
+		{Device: "b", Status: StatusFailed, Error: "boom", RPCErrors: []RPCError{{Tag: "lock-denied"}}},
+		{Device: "c", Status: StatusSkipped, Error: "context canceled"},
+	}}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid json output: %v", err)
+	}
+	if len(decoded.Results) != 3 || decoded.Results[1].RPCErrors[0].Tag != "lock-denied" {
+		t.Errorf("unexpected json round trip: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := report.WriteTable(&buf); err != nil {
+		t.Fatalf("WriteTable failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "1 ok, 1 failed, 1 skipped") {
+		t.Errorf("unexpected table output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := report.WriteJUnit(&buf); err != nil {
+		t.Fatalf("WriteJUnit failed: %v", err)
+	}
+	var suite junitTestSuite
+	if err := xml.Unmarshal(buf.Bytes(), &suite); err != nil {
+		t.Fatalf("invalid junit output: %v", err)
+	}
+	if suite.Tests != 3 || suite.Failures != 1 || suite.Skipped != 1 {
+		t.Errorf("unexpected junit counts: %+v", suite)
+	}
+	if suite.TestCases[1].Failure == nil || !strings.Contains(suite.TestCases[1].Failure.Body, "lock-denied") {
+		t.Errorf("missing failure details: %s", buf.String())
+	}
+}
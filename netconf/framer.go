@@ -0,0 +1,237 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Framer reads and writes NETCONF messages on a byte stream, framed with the
+// end-of-message delimiter of NETCONF 1.0 or the chunked framing of
+// RFC 6242.  It is the framing layer of the transports of this package,
+// exported for proxies, recorders and protocol test tools.  One goroutine
+// may read frames while another writes them.
+type Framer struct {
+	r         io.Reader
+	w         io.Writer
+	framing   Framing
+	chunkSize int
+	// pending holds data read past the end of the previous message.
+	pending []byte
+}
+
+// NewFramer returns a framer reading from r and writing to w with
+// end-of-message framing, as used for the hello exchange.
+func NewFramer(r io.Reader, w io.Writer) *Framer {
+	return &Framer{r: r, w: w, framing: FramingEOM}
+}
+
+// Framing returns the framing in use.
+func (f *Framer) Framing() Framing {
+	if f.framing == FramingChunked {
+		return FramingChunked
+	}
+	return FramingEOM
+}
+
+// SetFraming switches the framing, typically to FramingChunked once both
+// peers announced base:1.1 in their hello.
+func (f *Framer) SetFraming(framing Framing) {
+	f.framing = framing
+}
+
+// SetChunkSize sets the maximum size of the chunks messages are split into
+// with chunked framing.  Sizes below 1 select DefaultChunkSize.
+func (f *Framer) SetChunkSize(size int) {
+	f.chunkSize = size
+}
+
+// WriteFrame writes msg as a single framed message.
+func (f *Framer) WriteFrame(msg []byte) error {
+	w := f.FrameWriter()
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// FrameWriter returns a writer for a single outgoing message.  Data is
+// framed as it is written and Close completes the message, so messages
+// need not be held in memory as a whole.
+func (f *Framer) FrameWriter() io.WriteCloser {
+	if f.Framing() == FramingChunked {
+		size := f.chunkSize
+		if size <= 0 {
+			size = DefaultChunkSize
+		}
+		return &chunkWriter{w: f.w, size: size}
+	}
+	return &eomWriter{w: bufio.NewWriter(f.w)}
+}
+
+// ReadFrame reads the next message and returns it without its framing.
+// Malformed chunked messages are reported with a *FramingError.  The end of
+// the stream is reported as io.EOF between messages and as
+// io.ErrUnexpectedEOF within one.
+func (f *Framer) ReadFrame() ([]byte, error) {
+	if f.Framing() != FramingChunked {
+		return f.waitForBytes([]byte(msgSeperator))
+	}
+
+	framed, err := f.waitFor(func(buf []byte) (int, int, error) {
+		_, n, err := scanChunks(buf, false)
+		if err == errIncompleteFrame {
+			return -1, -1, nil
+		}
+		return n, n, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return DecodeChunks(framed)
+}
+
+// waitFor reads until fn reports the end of the output along with the start
+// of the following data, which may lie beyond the end if a delimiter
+// separates the two.  Whitespace left at the end of the stream counts as
+// the space between messages.
+func (f *Framer) waitFor(fn func([]byte) (end int, next int, err error)) ([]byte, error) {
+	buf := f.pending
+	f.pending = nil
+	chunk := make([]byte, 8192)
+
+	var readErr error
+	for {
+		if len(buf) > 0 {
+			end, next, err := fn(buf)
+			if err != nil {
+				return nil, err
+			}
+			if end > -1 {
+				if next < len(buf) {
+					f.pending = append([]byte(nil), buf[next:]...)
+				}
+				return buf[:end], nil
+			}
+		}
+
+		if readErr != nil {
+			if readErr != io.EOF {
+				return nil, readErr
+			}
+			break
+		}
+
+		var n int
+		n, readErr = f.r.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+	}
+
+	if len(bytes.TrimSpace(buf)) == 0 {
+		return nil, io.EOF
+	}
+	return nil, io.ErrUnexpectedEOF
+}
+
+// waitForBytes reads until the delimiter b and returns the data before it.
+func (f *Framer) waitForBytes(b []byte) ([]byte, error) {
+	// Only search the data not searched before, allowing for a delimiter
+	// split across reads.
+	from := 0
+	return f.waitFor(func(buf []byte) (int, int, error) {
+		i := bytes.Index(buf[from:], b)
+		if i < 0 {
+			if from = len(buf) - len(b) + 1; from < 0 {
+				from = 0
+			}
+			return -1, -1, nil
+		}
+		return from + i, from + i + len(b), nil
+	})
+}
+
+// eomWriter frames a message with the end-of-message marker of NETCONF 1.0.
+type eomWriter struct {
+	w *bufio.Writer
+}
+
+func (e *eomWriter) Write(p []byte) (int, error) {
+	return e.w.Write(p)
+}
+
+func (e *eomWriter) Close() error {
+	e.w.WriteString(msgSeperator)
+	return e.w.Flush()
+}
+
+// chunkWriter frames a message with the chunked framing of NETCONF 1.1,
+// emitting a chunk whenever size bytes were written.
+type chunkWriter struct {
+	w    io.Writer
+	size int
+	buf  []byte
+}
+
+func (c *chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// Write full chunks straight from p rather than copying them.
+		if len(c.buf) == 0 && len(p) >= c.size {
+			if err := c.chunk(p[:c.size]); err != nil {
+				return written, err
+			}
+			p = p[c.size:]
+			written += c.size
+			continue
+		}
+
+		if c.buf == nil {
+			c.buf = make([]byte, 0, c.size)
+		}
+		n := c.size - len(c.buf)
+		if n > len(p) {
+			n = len(p)
+		}
+		c.buf = append(c.buf, p[:n]...)
+		p = p[n:]
+		written += n
+		if len(c.buf) == c.size {
+			if err := c.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (c *chunkWriter) flush() error {
+	if len(c.buf) == 0 {
+		return nil
+	}
+	err := c.chunk(c.buf)
+	c.buf = c.buf[:0]
+	return err
+}
+
+func (c *chunkWriter) chunk(data []byte) error {
+	if _, err := fmt.Fprintf(c.w, "\n#%d\n", len(data)); err != nil {
+		return err
+	}
+	_, err := c.w.Write(data)
+	return err
+}
+
+func (c *chunkWriter) Close() error {
+	if err := c.flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(c.w, msgSeperator_v11)
+	return err
+}
@@ -0,0 +1,127 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFramer(t *testing.T) {
+	tt := []struct {
+		framing   Framing
+		chunkSize int
+		expected  string
+	}{
+		{FramingEOM, 0, "<a/>]]>]]><b>]]></b>]]>]]>"},
+		{FramingChunked, 0, "\n#4\n<a/>\n##\n\n#10\n<b>]]></b>\n##\n"},
+		{FramingChunked, 4, "\n#4\n<a/>\n##\n\n#4\n<b>]\n#4\n]></\n#2\nb>\n##\n"},
+	}
+	messages := [][]byte{[]byte("<a/>"), []byte("<b>]]></b>")}
+	for _, tc := range tt {
+		var buf bytes.Buffer
+		w := NewFramer(nil, &buf)
+		w.SetFraming(tc.framing)
+		w.SetChunkSize(tc.chunkSize)
+		for _, msg := range messages {
+			if err := w.WriteFrame(msg); err != nil {
+				t.Fatalf("%s: WriteFrame failed: %v", tc.framing, err)
+			}
+		}
+		if diff := cmp.Diff(tc.expected, buf.String()); diff != "" {
+			t.Errorf("%s: framed output mismatch (-want +got):\n%s", tc.framing, diff)
+		}
+
+		r := NewFramer(&trickleReader{buf.Bytes()}, nil)
+		r.SetFraming(tc.framing)
+		for _, msg := range messages {
+			got, err := r.ReadFrame()
+			if err != nil {
+				t.Fatalf("%s: ReadFrame failed: %v", tc.framing, err)
+			}
+			if !bytes.Equal(got, msg) {
+				t.Errorf("%s: read %q, expected %q", tc.framing, got, msg)
+			}
+		}
+		if _, err := r.ReadFrame(); err == nil {
+			t.Errorf("%s: expected error at end of stream", tc.framing)
+		}
+	}
+}
+
+func TestFramerSwitchFraming(t *testing.T) {
+	f := NewFramer(strings.NewReader("<hello/>]]>]]>\n#5\n<rpc/\n#1\n>\n##\n\n#3\nbad"), nil)
+	if f.Framing() != FramingEOM {
+		t.Errorf("expected end-of-message framing, got %s", f.Framing())
+	}
+	if hello, err := f.ReadFrame(); err != nil || string(hello) != "<hello/>" {
+		t.Fatalf("unexpected hello %q: %v", hello, err)
+	}
+	f.SetFraming(FramingChunked)
+	if msg, err := f.ReadFrame(); err != nil || string(msg) != "<rpc/>" {
+		t.Fatalf("unexpected message %q: %v", msg, err)
+	}
+	if _, err := f.ReadFrame(); err != io.ErrUnexpectedEOF {
+		t.Errorf("got %v for truncated chunk, expected io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestFramerEOF(t *testing.T) {
+	tt := []struct {
+		name    string
+		input   string
+		framing Framing
+		err     error
+	}{
+		{"eom boundary", "<rpc/>]]>]]>\n", FramingEOM, io.EOF},
+		{"eom partial", "<rpc/>]]>]]><rpc", FramingEOM, io.ErrUnexpectedEOF},
+		{"chunked boundary", "\n#6\n<rpc/>\n##\n", FramingChunked, io.EOF},
+		{"chunked partial", "\n#6\n<rpc/>\n##\n\n#6\n<r", FramingChunked, io.ErrUnexpectedEOF},
+	}
+
+	for _, tc := range tt {
+		f := NewFramer(strings.NewReader(tc.input), nil)
+		f.SetFraming(tc.framing)
+		if msg, err := f.ReadFrame(); err != nil || string(msg) != "<rpc/>" {
+			t.Errorf("%s: unexpected message %q: %v", tc.name, msg, err)
+			continue
+		}
+		if _, err := f.ReadFrame(); err != tc.err {
+			t.Errorf("%s: got %v, expected %v", tc.name, err, tc.err)
+		}
+	}
+}
+
+func TestFramerFramingError(t *testing.T) {
+	f := NewFramer(strings.NewReader("\n#x\n"), nil)
+	f.SetFraming(FramingChunked)
+	_, err := f.ReadFrame()
+	var ferr *FramingError
+	if !errors.As(err, &ferr) || ferr.Offset != 2 {
+		t.Errorf("expected framing error at offset 2, got %v", err)
+	}
+}
+
+func TestFrameWriter(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFramer(nil, &buf)
+	f.SetFraming(FramingChunked)
+	w := f.FrameWriter()
+	io.WriteString(w, "<rpc>")
+	io.WriteString(w, "</rpc>")
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if got, want := buf.String(), "\n#11\n<rpc></rpc>\n##\n"; got != want {
+		t.Errorf("got %q, expected %q", got, want)
+	}
+}
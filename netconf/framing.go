@@ -0,0 +1,136 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"errors"
+	"fmt"
+)
+
+// maxChunkSize is the largest chunk size allowed by RFC 6242.
+const maxChunkSize = 4294967295
+
+// FramingError reports a message violating the chunked framing of RFC 6242.
+type FramingError struct {
+	// Offset of the offending bytes within the framed message.
+	Offset int
+	// Data holds the offending bytes, truncated to a few bytes.
+	Data []byte
+	Msg  string
+}
+
+func (e *FramingError) Error() string {
+	return fmt.Sprintf("netconf: chunked framing: %s at offset %d: %q", e.Msg, e.Offset, e.Data)
+}
+
+// errIncompleteFrame is returned by scanChunks for messages that end before
+// their end-of-chunks marker.
+var errIncompleteFrame = errors.New("netconf: incomplete chunked message")
+
+func framingError(data []byte, offset int, format string, args ...interface{}) error {
+	end := offset + 16
+	if end > len(data) {
+		end = len(data)
+	}
+	return &FramingError{
+		Offset: offset,
+		Data:   append([]byte(nil), data[offset:end]...),
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+// scanChunks validates the chunked message at the start of data.  It returns
+// the length of the framed message, along with its payload if decode is set,
+// or errIncompleteFrame if data ends before the message does.  Chunks are
+// delimited by their size only, so payloads may contain any bytes.
+func scanChunks(data []byte, decode bool) ([]byte, int, error) {
+	var payload []byte
+	chunks := 0
+	pos := 0
+	for {
+		if len(data) < pos+3 {
+			return nil, 0, errIncompleteFrame
+		}
+		if data[pos] != '\n' {
+			return nil, 0, framingError(data, pos, "expected LF before chunk header")
+		}
+		if data[pos+1] != '#' {
+			return nil, 0, framingError(data, pos+1, "expected '#' in chunk header")
+		}
+
+		if data[pos+2] == '#' {
+			if len(data) < pos+4 {
+				return nil, 0, errIncompleteFrame
+			}
+			if data[pos+3] != '\n' {
+				return nil, 0, framingError(data, pos+3, "expected LF after end-of-chunks")
+			}
+			if chunks == 0 {
+				return nil, 0, framingError(data, pos, "message without chunks")
+			}
+			return payload, pos + 4, nil
+		}
+
+		start := pos + 2
+		var size uint64
+		digits := 0
+		for pos = start; ; pos++ {
+			if pos == len(data) {
+				return nil, 0, errIncompleteFrame
+			}
+			c := data[pos]
+			if c == '\n' {
+				break
+			}
+			if c < '0' || c > '9' {
+				return nil, 0, framingError(data, pos, "invalid character %q in chunk size", c)
+			}
+			if digits == 0 && c == '0' {
+				return nil, 0, framingError(data, pos, "chunk size with leading zero")
+			}
+			if digits++; digits > 10 {
+				return nil, 0, framingError(data, start, "chunk size too long")
+			}
+			size = size*10 + uint64(c-'0')
+		}
+		if digits == 0 {
+			return nil, 0, framingError(data, start, "missing chunk size")
+		}
+		if size > maxChunkSize {
+			return nil, 0, framingError(data, start, "chunk size %d exceeds %d", size, uint64(maxChunkSize))
+		}
+
+		// The check keeps size within the data, and so within int, before
+		// it is converted.
+		pos++
+		if uint64(len(data)-pos) < size {
+			return nil, 0, errIncompleteFrame
+		}
+		if decode {
+			payload = append(payload, data[pos:pos+int(size)]...)
+		}
+		pos += int(size)
+		chunks++
+	}
+}
+
+// DecodeChunks decodes a complete message in the chunked framing of
+// RFC 6242 and returns its payload.  Malformed messages are reported with a
+// *FramingError.
+func DecodeChunks(data []byte) ([]byte, error) {
+	payload, n, err := scanChunks(data, true)
+	if err == errIncompleteFrame {
+		return nil, framingError(data, len(data), "message truncated")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if n != len(data) {
+		return nil, framingError(data, n, "trailing data after end-of-chunks")
+	}
+	return payload, nil
+}
@@ -0,0 +1,96 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodeChunks(t *testing.T) {
+	tt := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr *FramingError
+	}{
+		{
+			name:  "single",
+			input: "\n#6\n<rpc/>\n##\n",
+			want:  "<rpc/>",
+		},
+		{
+			name:  "binary",
+			input: "\n#5\na\r\n#b\n#1\n\n\n##\n",
+			want:  "a\r\n#b\n",
+		},
+		{
+			name:    "nonDigit",
+			input:   "\n#1a\nx\n##\n",
+			wantErr: &FramingError{Offset: 3, Data: []byte("a\nx\n##\n"), Msg: `invalid character 'a' in chunk size`},
+		},
+		{
+			name:    "leadingZero",
+			input:   "\n#01\nx\n##\n",
+			wantErr: &FramingError{Offset: 2, Data: []byte("01\nx\n##\n"), Msg: "chunk size with leading zero"},
+		},
+		{
+			name:    "zero",
+			input:   "\n#0\n\n##\n",
+			wantErr: &FramingError{Offset: 2, Data: []byte("0\n\n##\n"), Msg: "chunk size with leading zero"},
+		},
+		{
+			name:    "tooLarge",
+			input:   "\n#4294967296\nx\n##\n",
+			wantErr: &FramingError{Offset: 2, Data: []byte("4294967296\nx\n##\n"), Msg: "chunk size 4294967296 exceeds 4294967295"},
+		},
+		{
+			name:    "missingHash",
+			input:   "\n6\n<rpc/>\n##\n",
+			wantErr: &FramingError{Offset: 1, Data: []byte("6\n<rpc/>\n##\n"), Msg: "expected '#' in chunk header"},
+		},
+		{
+			name:    "sizeMismatch",
+			input:   "\n#3\n<rpc/>\n##\n",
+			wantErr: &FramingError{Offset: 7, Data: []byte("c/>\n##\n"), Msg: "expected LF before chunk header"},
+		},
+		{
+			name:    "noChunks",
+			input:   "\n##\n",
+			wantErr: &FramingError{Offset: 0, Data: []byte("\n##\n"), Msg: "message without chunks"},
+		},
+		{
+			name:    "truncated",
+			input:   "\n#6\n<rpc",
+			wantErr: &FramingError{Offset: 8, Msg: "message truncated"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DecodeChunks([]byte(tc.input))
+			if tc.wantErr == nil {
+				if err != nil {
+					t.Fatalf("DecodeChunks failed: %v", err)
+				}
+				if string(got) != tc.want {
+					t.Errorf("DecodeChunks(%q) = %q, want %q", tc.input, got, tc.want)
+				}
+				return
+			}
+
+			fe, ok := err.(*FramingError)
+			if !ok {
+				t.Fatalf("expected *FramingError, got %v", err)
+			}
+			if diff := cmp.Diff(tc.wantErr, fe); diff != "" {
+				t.Errorf("error mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzDecodeChunks(f *testing.F) {
+	for _, seed := range []string{
+		"\n#6\n<rpc/>\n##\n",
+		"\n#1\na\n#2\nbc\n##\n",
+		"\n#4294967295\n",
+		"\n#01\nx\n##\n",
+		"\n##\n",
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		payload, err := DecodeChunks(data)
+		if err != nil {
+			if _, ok := err.(*FramingError); !ok {
+				t.Fatalf("unexpected error type %T", err)
+			}
+			return
+		}
+
+		// Re-framing the payload must decode to the same payload.
+		var trans transportTest
+		var out bytes.Buffer
+		trans.ReadWriteCloser = newNilCloser(nil, &out)
+		trans.SetVersion("v1.1")
+		if len(payload) == 0 {
+			t.Fatalf("decoded empty payload from %q", data)
+		}
+		trans.Send(payload)
+		again, err := DecodeChunks(out.Bytes())
+		if err != nil || !bytes.Equal(again, payload) {
+			t.Fatalf("round trip of %q failed: %q, %v", payload, again, err)
+		}
+	})
+}
+
+func FuzzParseRPCReply(f *testing.F) {
+	f.Add([]byte(replyOK))
+	f.Add([]byte(replyError("lock-denied")))
+	f.Add([]byte(`<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><data><a>`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ParseRPCReply(data)
+	})
+}
+
+func FuzzParseHello(f *testing.F) {
+	f.Add([]byte(`<hello xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><capabilities><capability>urn:ietf:params:netconf:base:1.1</capability></capabilities><session-id>4</session-id></hello>`))
+	f.Add([]byte(`<hello><session-id>-1</session-id>`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if hello, _ := ParseHello(data); hello == nil {
+			t.Fatalf("ParseHello returned nil hello")
+		}
+	})
+}
+
+func FuzzParseNotification(f *testing.F) {
+	f.Add([]byte(testNotification(1)))
+	f.Add([]byte(`<notification><eventTime>2020-01-02T03:04:05Z</eventTime><event>`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ParseNotification(data)
+	})
+}
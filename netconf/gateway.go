@@ -0,0 +1,165 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// maxGatewayRequest bounds the size of RPCs accepted by the gateway.
+const maxGatewayRequest = 16 << 20
+
+// errNoAuthorization is returned for all requests of a gateway without an
+// Authorize hook.
+var errNoAuthorization = errors.New("netconf: gateway has no authorization configured")
+
+// errUnauthorized is returned by APIKeyAuth for requests without a valid
+// key.
+var errUnauthorized = errors.New("netconf: missing or invalid API key")
+
+// Gateway is an http.Handler exposing the operations of a Service over
+// HTTP:
+//
+//	GET  /devices/{name}/config?source=running&filter=<subtree>
+//	POST /devices/{name}/rpc     (body: the XML of the operation)
+//
+// Replies are returned as XML.  RPC errors reported by the device are
+// answered with 422 and the <rpc-error> as body.
+type Gateway struct {
+	Service *Service
+	// Authorize is called for every request; requests for which it returns
+	// an error are rejected with 401.  While it is nil, all requests are
+	// rejected; set it to AllowUnauthenticated to serve requests without
+	// authentication, e.g. behind an authenticating proxy.
+	Authorize func(r *http.Request) error
+}
+
+// NewGateway returns a gateway for the devices of pool.
+func NewGateway(pool *Pool) *Gateway {
+	return &Gateway{Service: &Service{Pool: pool}}
+}
+
+// AllowUnauthenticated is an Authorize hook accepting every request.
+func AllowUnauthenticated(r *http.Request) error {
+	return nil
+}
+
+// APIKeyAuth returns an Authorize hook accepting requests that carry one of
+// keys in the X-API-Key header or as bearer token.
+func APIKeyAuth(keys ...string) func(r *http.Request) error {
+	return func(r *http.Request) error {
+		key := r.Header.Get("X-API-Key")
+		if auth := r.Header.Get("Authorization"); key == "" && strings.HasPrefix(auth, "Bearer ") {
+			key = strings.TrimPrefix(auth, "Bearer ")
+		}
+		for _, k := range keys {
+			if key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(k)) == 1 {
+				return nil
+			}
+		}
+		return errUnauthorized
+	}
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if g.Authorize == nil {
+		http.Error(w, errNoAuthorization.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err := g.Authorize(r); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "devices" || parts[1] == "" {
+		http.NotFound(w, r)
+		return
+	}
+	device := parts[1]
+
+	switch {
+	case parts[2] == "config" && r.Method == http.MethodGet:
+		g.getConfig(w, r, device)
+	case parts[2] == "rpc" && r.Method == http.MethodPost:
+		g.rpc(w, r, device)
+	case parts[2] == "config" || parts[2] == "rpc":
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (g *Gateway) getConfig(w http.ResponseWriter, r *http.Request, device string) {
+	var filter *Filter
+	if f := r.URL.Query().Get("filter"); f != "" {
+		filter = SubtreeFilter(f)
+	}
+	data, err := g.Service.GetConfig(r.Context(), device, r.URL.Query().Get("source"), filter)
+	if err != nil {
+		writeGatewayError(w, err)
+		return
+	}
+	writeXML(w, data)
+}
+
+func (g *Gateway) rpc(w http.ResponseWriter, r *http.Request, device string) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxGatewayRequest))
+	switch {
+	case err != nil && len(body) >= maxGatewayRequest:
+		http.Error(w, "rpc too large", http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case len(strings.TrimSpace(string(body))) == 0:
+		http.Error(w, "empty rpc", http.StatusBadRequest)
+		return
+	case strings.Contains(string(body), msgSeperator):
+		// The separator would end the message early with end-of-message
+		// framing, smuggling the rest in as further RPCs.
+		http.Error(w, "rpc contains the message separator", http.StatusBadRequest)
+		return
+	}
+	reply, err := g.Service.Exec(r.Context(), device, string(body))
+	if err != nil {
+		writeGatewayError(w, err)
+		return
+	}
+	writeXML(w, reply)
+}
+
+func writeXML(w http.ResponseWriter, data []byte) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write(data)
+}
+
+// writeGatewayError maps err to an HTTP status.
+func writeGatewayError(w http.ResponseWriter, err error) {
+	var rpcErr *RPCError
+	switch {
+	case errors.As(err, &rpcErr):
+		// Info holds the inner XML of the error, marshaling it would repeat
+		// the other fields.
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, "<rpc-error><error-type>%s</error-type><error-tag>%s</error-tag>"+
+			"<error-severity>%s</error-severity><error-message>%s</error-message></rpc-error>",
+			EscapeText(rpcErr.Type), EscapeText(rpcErr.Tag), EscapeText(rpcErr.Severity), EscapeText(rpcErr.Message))
+	case errors.Is(err, ErrUnknownDevice):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrCapabilityUnsupported):
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+	default:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	}
+}
@@ -0,0 +1,95 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGateway(t *testing.T) {
+	tt := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		key    string
+		reply  string
+		status int
+		output string
+	}{
+		{
+			name: "get config", method: "GET", path: "/devices/r1/config?filter=%3Csystem%2F%3E", key: "secret",
+			reply: `<rpc-reply><data><system/></data></rpc-reply>`, status: 200, output: "<data><system/></data>",
+		},
+		{
+			name: "rpc", method: "POST", path: "/devices/r1/rpc", body: "<commit/>", key: "secret",
+			reply: replyOK, status: 200, output: "<ok/>",
+		},
+		{
+			name: "rpc error", method: "POST", path: "/devices/r1/rpc", body: "<commit/>", key: "secret",
+			reply: replyError("lock-denied"), status: 422, output: "<error-tag>lock-denied</error-tag>",
+		},
+		{name: "unauthorized", method: "GET", path: "/devices/r1/config", key: "wrong", status: 401},
+		{name: "unknown device", method: "GET", path: "/devices/r2/config", key: "secret", status: 404},
+		{name: "method", method: "GET", path: "/devices/r1/rpc", key: "secret", status: 405},
+		{name: "empty rpc", method: "POST", path: "/devices/r1/rpc", key: "secret", status: 400},
+		{name: "not found", method: "GET", path: "/devices/r1", key: "secret", status: 404},
+		{name: "separator", method: "POST", path: "/devices/r1/rpc", body: "<get/>]]>]]><rpc><commit/></rpc>", key: "secret", status: 400},
+		{name: "too large", method: "POST", path: "/devices/r1/rpc", body: "<get>" + strings.Repeat(" ", maxGatewayRequest) + "</get>", key: "secret", status: 413},
+	}
+
+	for _, tc := range tt {
+		pool, _ := newTestPool(tc.reply)
+		g := NewGateway(pool)
+		g.Authorize = APIKeyAuth("secret")
+
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Authorization", "Bearer "+tc.key)
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+
+		if rec.Code != tc.status {
+			t.Errorf("%s: got status %d, expected %d: %s", tc.name, rec.Code, tc.status, rec.Body)
+			continue
+		}
+		if !strings.Contains(rec.Body.String(), tc.output) {
+			t.Errorf("%s: got body %q, expected it to contain %q", tc.name, rec.Body, tc.output)
+		}
+	}
+}
+
+func TestGatewayDeniesByDefault(t *testing.T) {
+	pool, _ := newTestPool(replyOK)
+	g := NewGateway(pool)
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/devices/r1/config", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d without Authorize, expected 401", rec.Code)
+	}
+
+	g.Authorize = AllowUnauthenticated
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/devices/r1/config", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("got status %d with AllowUnauthenticated, expected 200: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	auth := APIKeyAuth("k1", "k2")
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if auth(req) == nil {
+		t.Error("expected request without key to be rejected")
+	}
+	req.Header.Set("X-API-Key", "k2")
+	if err := auth(req); err != nil {
+		t.Errorf("expected X-API-Key to be accepted, got %v", err)
+	}
+}
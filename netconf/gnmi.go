@@ -0,0 +1,826 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// YANGPushNamespace is the namespace of the YANG push module (RFC 8641).
+const YANGPushNamespace = "urn:ietf:params:xml:ns:yang:ietf-yang-push"
+
+// GNMIPathElem is an element of a gNMI path, with the keys selecting a list
+// entry.
+type GNMIPathElem struct {
+	// Name is the element name, optionally prefixed by the name of the
+	// module defining it, e.g. openconfig-interfaces:interfaces.
+	Name string
+	Key  map[string]string
+}
+
+// GNMIPath is a gNMI path.
+type GNMIPath struct {
+	Origin string
+	Elem   []GNMIPathElem
+}
+
+// ParseGNMIPath parses a path in the gNMI string form, e.g.
+// /interfaces/interface[name=eth0]/state.  An origin may precede the path,
+// as in openconfig:/interfaces.  Backslashes escape ] and \ in key values.
+func ParseGNMIPath(s string) (*GNMIPath, error) {
+	p := &GNMIPath{}
+	if i := strings.Index(s, ":/"); i > 0 && !strings.ContainsAny(s[:i], "/[") {
+		p.Origin, s = s[:i], s[i+1:]
+	}
+	s = strings.TrimPrefix(s, "/")
+
+	for len(s) > 0 {
+		end := strings.IndexAny(s, "/[")
+		if end < 0 {
+			end = len(s)
+		}
+		elem := GNMIPathElem{Name: s[:end]}
+		if elem.Name == "" {
+			return nil, fmt.Errorf("gnmi path %q: empty element", s)
+		}
+		s = s[end:]
+		for strings.HasPrefix(s, "[") {
+			eq := strings.IndexByte(s, '=')
+			if eq < 0 {
+				return nil, fmt.Errorf("gnmi path: key of %s without value", elem.Name)
+			}
+			var value strings.Builder
+			i := eq + 1
+			for ; i < len(s) && s[i] != ']'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				value.WriteByte(s[i])
+			}
+			if i == len(s) {
+				return nil, fmt.Errorf("gnmi path: unterminated key of %s", elem.Name)
+			}
+			if elem.Key == nil {
+				elem.Key = make(map[string]string)
+			}
+			elem.Key[s[1:eq]] = value.String()
+			s = s[i+1:]
+		}
+		p.Elem = append(p.Elem, elem)
+		if len(s) > 0 {
+			if s[0] != '/' {
+				return nil, fmt.Errorf("gnmi path: unexpected %q", s)
+			}
+			s = s[1:]
+		}
+	}
+	return p, nil
+}
+
+// MustParseGNMIPath is like ParseGNMIPath but panics if the path is invalid.
+func MustParseGNMIPath(s string) *GNMIPath {
+	p, err := ParseGNMIPath(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *GNMIPath) String() string {
+	var b strings.Builder
+	if p.Origin != "" {
+		b.WriteString(p.Origin + ":")
+	}
+	for _, e := range p.Elem {
+		b.WriteString("/" + e.Name)
+		keys := make([]string, 0, len(e.Key))
+		for k := range e.Key {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := strings.NewReplacer(`\`, `\\`, `]`, `\]`).Replace(e.Key[k])
+			b.WriteString("[" + k + "=" + v + "]")
+		}
+	}
+	if len(p.Elem) == 0 {
+		b.WriteString("/")
+	}
+	return b.String()
+}
+
+// join returns the path q below the prefix p, which may be nil.
+func (p *GNMIPath) join(q *GNMIPath) *GNMIPath {
+	if p == nil {
+		return q
+	}
+	joined := &GNMIPath{Origin: p.Origin, Elem: append(append([]GNMIPathElem(nil), p.Elem...), q.Elem...)}
+	if q.Origin != "" {
+		joined.Origin = q.Origin
+	}
+	return joined
+}
+
+// child returns the path of an element below p.
+func (p *GNMIPath) child(e GNMIPathElem) *GNMIPath {
+	return &GNMIPath{Origin: p.Origin, Elem: append(append([]GNMIPathElem(nil), p.Elem...), e)}
+}
+
+// GNMIUpdate is a value at a path.
+//
+// Values read from a device are leaves, given as string, and leaf-lists,
+// given as []string.  Values written may also be bool and numbers, written
+// as their decimal form, map[string]interface{} and []interface{} as
+// obtained by decoding JSON, holding the children of a container and the
+// entries of a list or leaf-list, and *Node or RawXML holding the content of
+// the element.
+type GNMIUpdate struct {
+	Path *GNMIPath
+	Val  interface{}
+}
+
+// GNMINotification is a set of updates and deletes at a point in time.
+type GNMINotification struct {
+	// Timestamp is the time in nanoseconds since the Unix epoch.
+	Timestamp int64
+	Update    []GNMIUpdate
+	Delete    []*GNMIPath
+}
+
+// GNMIDataType selects the data returned by GNMIBridge.Get.
+type GNMIDataType string
+
+// Data types of gNMI Get requests.  NETCONF only tells configuration and
+// state apart for the whole datastore, so GNMIDataState and
+// GNMIDataOperational return configuration as well.
+const (
+	GNMIDataAll         GNMIDataType = "ALL"
+	GNMIDataConfig      GNMIDataType = "CONFIG"
+	GNMIDataState       GNMIDataType = "STATE"
+	GNMIDataOperational GNMIDataType = "OPERATIONAL"
+)
+
+// GNMIGetRequest is a gNMI Get request.
+type GNMIGetRequest struct {
+	Prefix *GNMIPath
+	Path   []*GNMIPath
+	Type   GNMIDataType
+}
+
+// GNMIGetResponse holds a notification per requested path.
+type GNMIGetResponse struct {
+	Notification []*GNMINotification
+}
+
+// GNMISetRequest is a gNMI Set request.  Deletes are applied first, then
+// replaces, then updates, all in a single transaction.
+type GNMISetRequest struct {
+	Prefix  *GNMIPath
+	Delete  []*GNMIPath
+	Replace []GNMIUpdate
+	Update  []GNMIUpdate
+}
+
+// GNMIOperation is the operation applied to a path by a Set request.
+type GNMIOperation string
+
+// Operations of Set requests.
+const (
+	GNMIOpDelete  GNMIOperation = "DELETE"
+	GNMIOpReplace GNMIOperation = "REPLACE"
+	GNMIOpUpdate  GNMIOperation = "UPDATE"
+)
+
+// GNMIUpdateResult is the result of an operation of a Set request.
+type GNMIUpdateResult struct {
+	Path *GNMIPath
+	Op   GNMIOperation
+}
+
+// GNMISetResponse is the response to a Set request.
+type GNMISetResponse struct {
+	Response []GNMIUpdateResult
+	// Timestamp is the time the transaction completed in nanoseconds since
+	// the Unix epoch.
+	Timestamp int64
+}
+
+// GNMISubscriptionMode selects when a subscription sends updates.
+type GNMISubscriptionMode string
+
+// Subscription modes, mapped to periodic and on-change YANG push
+// subscriptions.
+const (
+	GNMISample   GNMISubscriptionMode = "SAMPLE"
+	GNMIOnChange GNMISubscriptionMode = "ON_CHANGE"
+)
+
+// GNMISubscribeRequest is a gNMI Subscribe request.
+type GNMISubscribeRequest struct {
+	Prefix *GNMIPath
+	Path   []*GNMIPath
+	Mode   GNMISubscriptionMode
+	// SampleInterval is the period of GNMISample subscriptions, and the
+	// dampening period of GNMIOnChange subscriptions if set.
+	SampleInterval time.Duration
+	// Options tunes the buffering of notifications.  Its stream, filter and
+	// times are ignored.
+	Options SubscriptionOptions
+}
+
+// GNMIBridge executes gNMI style requests over a NETCONF session: Get with
+// get or get-config and a subtree filter, Set with an edit-config
+// transaction and Subscribe with a YANG push subscription (RFC 8641).
+//
+// The namespace of a top-level path element is that of the module it is
+// prefixed with, looked up in Namespaces and then in the capabilities of
+// the server, or Namespaces[name] for elements without a prefix.  Other
+// elements inherit the namespace of their parent unless prefixed.
+type GNMIBridge struct {
+	Session *Session
+	// Namespaces maps module names, and the names of top-level elements, to
+	// namespace URIs.
+	Namespaces map[string]string
+	// ListKeys maps the local name of list elements to the names of their
+	// key leaves, which are used for the paths of returned list entries.
+	// Repeated elements without a hint are keyed by a <name> child.
+	ListKeys map[string][]string
+	// Target is the datastore Set edits, the candidate if the server
+	// supports it and running otherwise.
+	Target string
+	// Transaction tunes the lock, validate and commit sequence of Set.  Its
+	// Replace field is ignored.
+	Transaction TransactionOptions
+}
+
+// namespace returns the namespace of a path element named name, given the
+// namespace of its parent.
+func (b *GNMIBridge) namespace(name, parent string) (string, string, error) {
+	module := ""
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		module, name = name[:i], name[i+1:]
+	}
+	if module == "" {
+		if parent != "" {
+			return name, parent, nil
+		}
+		if ns, ok := b.Namespaces[name]; ok {
+			return name, ns, nil
+		}
+		return "", "", fmt.Errorf("gnmi: no namespace for %s, prefix it with its module", name)
+	}
+	if ns, ok := b.Namespaces[module]; ok {
+		return name, ns, nil
+	}
+	if m := b.Session.Capabilities().Module(module); m != nil && m.Namespace != "" {
+		return name, m.Namespace, nil
+	}
+	return "", "", fmt.Errorf("gnmi: unknown module %s", module)
+}
+
+// gnmiTree builds XML trees for paths, merging common ancestors.
+type gnmiTree struct {
+	b     *GNMIBridge
+	roots []*Node
+	// keys holds the keys of the path elements the nodes were created
+	// for, so that a path without keys gets a node of its own.
+	keys map[*Node]map[string]string
+}
+
+// insert adds the elements of p to the tree and returns the last one along
+// with its parent, which is nil for top-level elements.
+func (t *gnmiTree) insert(p *GNMIPath) (n, parent *Node, err error) {
+	if len(p.Elem) == 0 {
+		return nil, nil, fmt.Errorf("gnmi: the root path is not supported")
+	}
+	siblings := &t.roots
+	space := ""
+	for _, e := range p.Elem {
+		name, ns, err := t.b.namespace(e.Name, space)
+		if err != nil {
+			return nil, nil, err
+		}
+		space = ns
+
+		parent, n = n, nil
+		for _, c := range *siblings {
+			if c.XMLName.Local == name && c.XMLName.Space == ns && sameKeys(t.keys[c], e.Key) {
+				n = c
+				break
+			}
+		}
+		if n == nil {
+			n = NewNode(ns, name)
+			if t.keys == nil {
+				t.keys = make(map[*Node]map[string]string)
+			}
+			t.keys[n] = e.Key
+			for _, k := range sortedKeys(e.Key) {
+				n.Children = append(n.Children, NewLeaf(ns, k, e.Key[k]))
+			}
+			*siblings = append(*siblings, n)
+		}
+		siblings = &n.Children
+	}
+	return n, parent, nil
+}
+
+// siblings returns the list a node inserted with parent belongs to.
+func (t *gnmiTree) siblings(parent *Node) *[]*Node {
+	if parent == nil {
+		return &t.roots
+	}
+	return &parent.Children
+}
+
+func (t *gnmiTree) String() string {
+	var b strings.Builder
+	for _, n := range t.roots {
+		b.WriteString(n.String())
+	}
+	return b.String()
+}
+
+func hasKeys(n *Node, keys map[string]string) bool {
+	for k, v := range keys {
+		if c := n.Child(k); c == nil || c.Value() != v {
+			return false
+		}
+	}
+	return true
+}
+
+func sameKeys(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// setValue sets the content of n, inserted below parent, to v.  Slices
+// other than []string add a sibling of n per further entry.
+func (t *gnmiTree) setValue(n, parent *Node, v interface{}) error {
+	var entries []interface{}
+	switch v := v.(type) {
+	case []interface{}:
+		entries = v
+	case []string:
+		for _, s := range v {
+			entries = append(entries, s)
+		}
+	default:
+		return t.setContent(n, v)
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+	siblings := t.siblings(parent)
+	for i, e := range entries {
+		entry := n
+		if i > 0 {
+			entry = &Node{XMLName: n.XMLName, Attrs: n.Attrs}
+			*siblings = append(*siblings, entry)
+		}
+		if err := t.setContent(entry, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *gnmiTree) setContent(n *Node, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+	case string:
+		n.Text = v
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		n.Text = fmt.Sprint(v)
+	case float32:
+		n.Text = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		n.Text = strconv.FormatFloat(v, 'f', -1, 64)
+	case RawXML:
+		nodes, err := ParseNodes(v)
+		if err != nil {
+			return err
+		}
+		n.Children = append(n.Children, nodes...)
+	case *Node:
+		n.Children = append(n.Children, v.Clone())
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			name, ns, err := t.b.namespace(k, n.XMLName.Space)
+			if err != nil {
+				return err
+			}
+			if c := n.Child(name); c != nil && c.IsLeaf() {
+				// A list key given in the path as well.
+				if err := t.setContent(c, v[k]); err != nil {
+					return err
+				}
+				continue
+			}
+			c := NewNode(ns, name)
+			n.Children = append(n.Children, c)
+			if err := t.setValue(c, n, v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("gnmi: unsupported value type %T", v)
+	}
+	return nil
+}
+
+// Get retrieves the values at the requested paths.  The notification of a
+// path holds an update per leaf and leaf-list below it.
+func (b *GNMIBridge) Get(ctx context.Context, req *GNMIGetRequest) (*GNMIGetResponse, error) {
+	if len(req.Path) == 0 {
+		return nil, fmt.Errorf("gnmi: get of the root path is not supported")
+	}
+	paths := make([]*GNMIPath, len(req.Path))
+	t := &gnmiTree{b: b}
+	for i, p := range req.Path {
+		paths[i] = req.Prefix.join(p)
+		if _, _, err := t.insert(paths[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	var m RPCMethod = MethodGetFilter(SubtreeFilter(t.String()))
+	if req.Type == GNMIDataConfig {
+		m = MethodGetConfigFilter("running", SubtreeFilter(t.String()))
+	}
+	reply, err := b.Session.ExecContext(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	root, err := configRoot(reply.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixNano()
+	resp := &GNMIGetResponse{}
+	for _, p := range paths {
+		n := &GNMINotification{Timestamp: now}
+		for _, match := range b.match(root, p) {
+			n.Update = b.flatten(n.Update, match.node, match.path)
+		}
+		resp.Notification = append(resp.Notification, n)
+	}
+	return resp, nil
+}
+
+type gnmiMatch struct {
+	node *Node
+	path *GNMIPath
+}
+
+// match returns the nodes below root selected by p, along with their
+// paths.  Elements without keys select all entries of a list.
+func (b *GNMIBridge) match(root *Node, p *GNMIPath) []gnmiMatch {
+	matches := []gnmiMatch{{node: root, path: &GNMIPath{Origin: p.Origin}}}
+	for _, e := range p.Elem {
+		name := e.Name
+		if i := strings.IndexByte(name, ':'); i >= 0 {
+			name = name[i+1:]
+		}
+		var next []gnmiMatch
+		for _, m := range matches {
+			count := 0
+			for _, c := range m.node.Children {
+				if c.XMLName.Local == name {
+					count++
+				}
+			}
+			for _, c := range m.node.Children {
+				if c.XMLName.Local != name || !hasKeys(c, e.Key) {
+					continue
+				}
+				elem := e
+				if elem.Key == nil {
+					elem.Key = b.entryKeys(c, count > 1)
+				}
+				next = append(next, gnmiMatch{node: c, path: m.path.child(elem)})
+			}
+		}
+		matches = next
+	}
+	return matches
+}
+
+// flatten appends the updates of the leaves below n, at path p.
+func (b *GNMIBridge) flatten(updates []GNMIUpdate, n *Node, p *GNMIPath) []GNMIUpdate {
+	if n.IsLeaf() {
+		return append(updates, GNMIUpdate{Path: p, Val: n.Value()})
+	}
+
+	count := make(map[string]int)
+	for _, c := range n.Children {
+		count[c.XMLName.Local]++
+	}
+	leafLists := make(map[string]int)
+	for _, c := range n.Children {
+		name := c.XMLName.Local
+		if c.IsLeaf() && count[name] > 1 {
+			// Leaf-lists are a single update holding all values.
+			if i, ok := leafLists[name]; ok {
+				updates[i].Val = append(updates[i].Val.([]string), c.Value())
+				continue
+			}
+			leafLists[name] = len(updates)
+			updates = append(updates, GNMIUpdate{Path: p.child(GNMIPathElem{Name: name}), Val: []string{c.Value()}})
+			continue
+		}
+		updates = b.flatten(updates, c, p.child(GNMIPathElem{Name: name, Key: b.entryKeys(c, count[name] > 1)}))
+	}
+	return updates
+}
+
+// entryKeys returns the keys of a list entry, or nil if n is not known to
+// be one.
+func (b *GNMIBridge) entryKeys(n *Node, repeated bool) map[string]string {
+	leaves, ok := b.ListKeys[n.XMLName.Local]
+	if !ok {
+		if !repeated || n.Child("name") == nil {
+			return nil
+		}
+		leaves = []string{"name"}
+	}
+	keys := make(map[string]string, len(leaves))
+	for _, l := range leaves {
+		keys[l] = childValue(n, l)
+	}
+	return keys
+}
+
+// Set applies the deletes, replaces and updates of req in a single
+// transaction, see EditTransaction.
+func (b *GNMIBridge) Set(ctx context.Context, req *GNMISetRequest) (*GNMISetResponse, error) {
+	t := &gnmiTree{b: b}
+	resp := &GNMISetResponse{}
+	for _, p := range req.Delete {
+		p = req.Prefix.join(p)
+		n, _, err := t.insert(p)
+		if err != nil {
+			return nil, err
+		}
+		n.SetOperation(OperationRemove)
+		resp.Response = append(resp.Response, GNMIUpdateResult{Path: p, Op: GNMIOpDelete})
+	}
+	for _, list := range []struct {
+		updates []GNMIUpdate
+		op      GNMIOperation
+	}{{req.Replace, GNMIOpReplace}, {req.Update, GNMIOpUpdate}} {
+		for _, u := range list.updates {
+			p := req.Prefix.join(u.Path)
+			n, parent, err := t.insert(p)
+			if err != nil {
+				return nil, err
+			}
+			if list.op == GNMIOpReplace {
+				n.SetOperation(OperationReplace)
+			}
+			if err := t.setValue(n, parent, u.Val); err != nil {
+				return nil, fmt.Errorf("gnmi: %s: %v", p, err)
+			}
+			resp.Response = append(resp.Response, GNMIUpdateResult{Path: p, Op: list.op})
+		}
+	}
+	if len(resp.Response) == 0 {
+		return nil, fmt.Errorf("gnmi: empty set request")
+	}
+
+	target := b.Target
+	if target == "" {
+		target = "running"
+		if b.Session.HasCapability(CapabilityCandidate) {
+			target = "candidate"
+		}
+	}
+	opts := b.Transaction
+	opts.Replace = false
+	if err := b.Session.EditTransaction(ctx, target, t.String(), &opts); err != nil {
+		return nil, err
+	}
+	resp.Timestamp = time.Now().UnixNano()
+	return resp, nil
+}
+
+// GNMISubscription delivers the updates of a YANG push subscription.
+type GNMISubscription struct {
+	// C delivers the notifications.  It is closed when the subscription
+	// ends.
+	C <-chan *GNMINotification
+	// ID is the identifier of the subscription assigned by the server.
+	ID string
+
+	sub *Subscription
+}
+
+// Err returns the error that ended the subscription, or nil if it is still
+// running or was closed.
+func (gs *GNMISubscription) Err() error {
+	return gs.sub.Err()
+}
+
+// Close ends the subscription and closes its session.
+func (gs *GNMISubscription) Close() error {
+	return gs.sub.Close()
+}
+
+// Subscribe establishes a YANG push subscription to the operational
+// datastore for the requested paths.  As with Session.Subscribe the session
+// must not be used for other RPCs afterwards.
+func (b *GNMIBridge) Subscribe(ctx context.Context, req *GNMISubscribeRequest) (*GNMISubscription, error) {
+	t := &gnmiTree{b: b}
+	for _, p := range req.Path {
+		if _, _, err := t.insert(req.Prefix.join(p)); err != nil {
+			return nil, err
+		}
+	}
+	if len(t.roots) == 0 {
+		return nil, fmt.Errorf("gnmi: subscribe without paths")
+	}
+
+	var m strings.Builder
+	m.WriteString(`<establish-subscription xmlns="` + SubscribedNotificationsNamespace + `" xmlns:yp="` + YANGPushNamespace + `">`)
+	m.WriteString(`<yp:datastore xmlns:ds="` + DatastoresNamespace + `">ds:operational</yp:datastore>`)
+	m.WriteString(`<yp:datastore-subtree-filter>` + t.String() + `</yp:datastore-subtree-filter>`)
+	// YANG push periods are in centiseconds.
+	period := int64(req.SampleInterval / (10 * time.Millisecond))
+	switch req.Mode {
+	case GNMISample:
+		if period <= 0 {
+			return nil, fmt.Errorf("gnmi: sample subscription without interval")
+		}
+		fmt.Fprintf(&m, "<yp:periodic><yp:period>%d</yp:period></yp:periodic>", period)
+	case GNMIOnChange:
+		if period > 0 {
+			fmt.Fprintf(&m, "<yp:on-change><yp:dampening-period>%d</yp:dampening-period></yp:on-change>", period)
+		} else {
+			m.WriteString("<yp:on-change/>")
+		}
+	default:
+		return nil, fmt.Errorf("gnmi: unsupported subscription mode %q", req.Mode)
+	}
+	m.WriteString("</establish-subscription>")
+
+	reply, err := b.Session.ExecContext(ctx, RawMethod(m.String()))
+	if err != nil {
+		return nil, err
+	}
+	var id struct {
+		ID string `xml:"id"`
+	}
+	if err := reply.Decode(&id); err != nil {
+		return nil, err
+	}
+
+	opts := req.Options
+	sub := newSubscription(b.Session, &opts)
+	size := cap(sub.c)
+	c := make(chan *GNMINotification, size)
+	go func() {
+		defer close(c)
+		for n := range sub.C {
+			gn := b.pushNotification(n)
+			if gn == nil {
+				continue
+			}
+			select {
+			case c <- gn:
+			case <-sub.done:
+				// Closed while the consumer is not reading; drain the
+				// subscription so that it ends.
+				for range sub.C {
+				}
+				return
+			}
+		}
+	}()
+	return &GNMISubscription{C: c, ID: id.ID, sub: sub}, nil
+}
+
+// pushNotification converts a push-update or push-change-update
+// notification, returning nil for other notifications.
+func (b *GNMIBridge) pushNotification(n *Notification) *GNMINotification {
+	nodes, err := ParseNodes(n.Event)
+	if err != nil || len(nodes) == 0 {
+		return nil
+	}
+	gn := &GNMINotification{Timestamp: n.EventTime.UnixNano()}
+	event := nodes[0]
+	switch event.XMLName.Local {
+	case "push-update":
+		if contents := event.Child("datastore-contents"); contents != nil {
+			gn.Update = b.flatten(nil, contents, &GNMIPath{})
+		}
+	case "push-change-update":
+		patch := event.Child("datastore-changes")
+		if patch != nil {
+			patch = patch.Child("yang-patch")
+		}
+		if patch == nil {
+			return gn
+		}
+		for _, edit := range patch.ChildrenNamed("edit") {
+			p := b.patchTarget(childValue(edit, "target"))
+			switch childValue(edit, "operation") {
+			case "delete", "remove":
+				gn.Delete = append(gn.Delete, p)
+			default:
+				if value := edit.Child("value"); value != nil {
+					for _, c := range value.Children {
+						// The value holds the target element itself.
+						gn.Update = b.flatten(gn.Update, c, p)
+					}
+				}
+			}
+		}
+	default:
+		return nil
+	}
+	return gn
+}
+
+// patchTarget converts the RESTCONF style target of a YANG patch edit,
+// e.g. /ietf-interfaces:interfaces/interface=eth0, to a gNMI path.  Keys
+// are named after ListKeys, "name" if there is no hint.
+func (b *GNMIBridge) patchTarget(target string) *GNMIPath {
+	p := &GNMIPath{}
+	for _, seg := range strings.Split(strings.Trim(strings.TrimSpace(target), "/"), "/") {
+		if seg == "" {
+			continue
+		}
+		e := GNMIPathElem{Name: seg}
+		if i := strings.IndexByte(seg, '='); i >= 0 {
+			e.Name = seg[:i]
+			local := e.Name
+			if j := strings.IndexByte(local, ':'); j >= 0 {
+				local = local[j+1:]
+			}
+			names, ok := b.ListKeys[local]
+			if !ok {
+				names = []string{"name"}
+			}
+			e.Key = make(map[string]string)
+			for k, v := range strings.Split(seg[i+1:], ",") {
+				if k < len(names) {
+					v, _ = unescapePathValue(v)
+					e.Key[names[k]] = v
+				}
+			}
+		}
+		p.Elem = append(p.Elem, e)
+	}
+	return p
+}
+
+// unescapePathValue decodes the percent-encoding of a RESTCONF key value.
+func unescapePathValue(v string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] == '%' && i+2 < len(v) {
+			c, err := strconv.ParseUint(v[i+1:i+3], 16, 8)
+			if err != nil {
+				return v, err
+			}
+			b.WriteByte(byte(c))
+			i += 2
+			continue
+		}
+		b.WriteByte(v[i])
+	}
+	return b.String(), nil
+}
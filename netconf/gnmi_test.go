@@ -0,0 +1,307 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseGNMIPath(t *testing.T) {
+	tt := []struct {
+		in       string
+		expected *GNMIPath
+		str      string
+		err      bool
+	}{
+		{in: "/", expected: &GNMIPath{}, str: "/"},
+		{
+			in: "/interfaces/interface[name=eth0]/state",
+			expected: &GNMIPath{Elem: []GNMIPathElem{
+				{Name: "interfaces"},
+				{Name: "interface", Key: map[string]string{"name": "eth0"}},
+				{Name: "state"},
+			}},
+		},
+		{
+			in: "openconfig:/network-instances/network-instance[name=a/b]/protocols/protocol[identifier=BGP][name=x\\]y]",
+			expected: &GNMIPath{Origin: "openconfig", Elem: []GNMIPathElem{
+				{Name: "network-instances"},
+				{Name: "network-instance", Key: map[string]string{"name": "a/b"}},
+				{Name: "protocols"},
+				{Name: "protocol", Key: map[string]string{"identifier": "BGP", "name": "x]y"}},
+			}},
+		},
+		{
+			in: "openconfig-system:system/config",
+			expected: &GNMIPath{Elem: []GNMIPathElem{
+				{Name: "openconfig-system:system"},
+				{Name: "config"},
+			}},
+			str: "/openconfig-system:system/config",
+		},
+		{in: "/a//b", err: true},
+		{in: "/a[name]", err: true},
+		{in: "/a[name=x", err: true},
+		{in: "/a[name=x]b", err: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.in, func(t *testing.T) {
+			p, err := ParseGNMIPath(tc.in)
+			if tc.err {
+				if err == nil {
+					t.Errorf("expected error, got %v", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseGNMIPath failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, p); diff != "" {
+				t.Errorf("path mismatch (-want +got):\n%s", diff)
+			}
+			str := tc.str
+			if str == "" {
+				str = tc.in
+			}
+			if p.String() != str {
+				t.Errorf("String() = %q, expected %q", p.String(), str)
+			}
+		})
+	}
+}
+
+func TestGNMIBridgeGet(t *testing.T) {
+	s, trans := newScriptedSession([]string{"http://openconfig.net/yang/interfaces?module=openconfig-interfaces"},
+		factsReply(`<data><interfaces xmlns="http://openconfig.net/yang/interfaces">
+<interface><name>eth0</name><state><name>eth0</name><mtu>1500</mtu><counters><in-octets>7</in-octets></counters></state></interface>
+<interface><name>eth1</name><state><name>eth1</name><mtu>9000</mtu></state></interface>
+</interfaces>
+<system xmlns="urn:example:system"><dns><server>192.0.2.1</server><server>192.0.2.2</server></dns></system></data>`))
+	b := &GNMIBridge{Session: s, Namespaces: map[string]string{"system": "urn:example:system"}}
+
+	resp, err := b.Get(context.Background(), &GNMIGetRequest{Path: []*GNMIPath{
+		MustParseGNMIPath("/openconfig-interfaces:interfaces/interface[name=eth0]/state"),
+		MustParseGNMIPath("/openconfig-interfaces:interfaces/interface/state/mtu"),
+		MustParseGNMIPath("/system/dns"),
+	}})
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	filter := `<interfaces xmlns="http://openconfig.net/yang/interfaces"><interface><name>eth0</name><state/></interface>` +
+		`<interface><state><mtu/></state></interface></interfaces><system xmlns="urn:example:system"><dns/></system>`
+	if !strings.Contains(trans.sent[0], filter) {
+		t.Errorf("unexpected request %s", trans.sent[0])
+	}
+
+	var got [][]string
+	for _, n := range resp.Notification {
+		var updates []string
+		for _, u := range n.Update {
+			updates = append(updates, u.Path.String()+" "+strings.Join(toStrings(u.Val), ","))
+		}
+		got = append(got, updates)
+	}
+	expected := [][]string{
+		{
+			"/openconfig-interfaces:interfaces/interface[name=eth0]/state/name eth0",
+			"/openconfig-interfaces:interfaces/interface[name=eth0]/state/mtu 1500",
+			"/openconfig-interfaces:interfaces/interface[name=eth0]/state/counters/in-octets 7",
+		},
+		{
+			"/openconfig-interfaces:interfaces/interface[name=eth0]/state/mtu 1500",
+			"/openconfig-interfaces:interfaces/interface[name=eth1]/state/mtu 9000",
+		},
+		{"/system/dns/server 192.0.2.1,192.0.2.2"},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("updates mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func toStrings(v interface{}) []string {
+	if s, ok := v.([]string); ok {
+		return s
+	}
+	return []string{v.(string)}
+}
+
+func TestGNMIBridgeSet(t *testing.T) {
+	s, trans := newScriptedSession([]string{CapabilityCandidate}, replyOK, replyOK, replyOK, replyOK)
+	b := &GNMIBridge{Session: s, Namespaces: map[string]string{"interfaces": "urn:example:if"}}
+
+	resp, err := b.Set(context.Background(), &GNMISetRequest{
+		Prefix: MustParseGNMIPath("/interfaces"),
+		Delete: []*GNMIPath{MustParseGNMIPath("interface[name=eth2]")},
+		Replace: []GNMIUpdate{{
+			Path: MustParseGNMIPath("interface[name=eth0]/config"),
+			Val:  map[string]interface{}{"mtu": float64(1500), "enabled": true, "tags": []interface{}{"a", "b"}},
+		}},
+		Update: []GNMIUpdate{
+			{Path: MustParseGNMIPath("interface[name=eth0]/config/description"), Val: "uplink"},
+			{Path: MustParseGNMIPath("interface"), Val: []interface{}{
+				map[string]interface{}{"name": "eth3"},
+				map[string]interface{}{"name": "eth4"},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"lock", "edit-config", "commit", "unlock"}, trans.operations()); diff != "" {
+		t.Errorf("operations mismatch (-want +got):\n%s", diff)
+	}
+	config := `<config><interfaces xmlns="urn:example:if">` +
+		`<interface xmlns:nc="urn:ietf:params:xml:ns:netconf:base:1.0" nc:operation="remove"><name>eth2</name></interface>` +
+		`<interface><name>eth0</name><config xmlns:nc="urn:ietf:params:xml:ns:netconf:base:1.0" nc:operation="replace">` +
+		`<enabled>true</enabled><mtu>1500</mtu><tags>a</tags><tags>b</tags><description>uplink</description></config></interface>` +
+		`<interface><name>eth3</name></interface><interface><name>eth4</name></interface>` +
+		`</interfaces></config>`
+	if !strings.Contains(trans.sent[1], config) {
+		t.Errorf("unexpected edit-config %s", trans.sent[1])
+	}
+
+	ops := make([]string, len(resp.Response))
+	for i, r := range resp.Response {
+		ops[i] = string(r.Op) + " " + r.Path.String()
+	}
+	expected := []string{
+		"DELETE /interfaces/interface[name=eth2]",
+		"REPLACE /interfaces/interface[name=eth0]/config",
+		"UPDATE /interfaces/interface[name=eth0]/config/description",
+		"UPDATE /interfaces/interface",
+	}
+	if diff := cmp.Diff(expected, ops); diff != "" {
+		t.Errorf("results mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := b.Set(context.Background(), &GNMISetRequest{Update: []GNMIUpdate{{Path: MustParseGNMIPath("/unknown"), Val: "x"}}}); err == nil {
+		t.Errorf("expected error for a path without namespace")
+	}
+}
+
+func TestGNMIBridgeSubscribe(t *testing.T) {
+	notification := func(event string) string {
+		return `<notification xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0"><eventTime>2020-01-01T00:00:00Z</eventTime>` + event + `</notification>`
+	}
+	s, trans := newScriptedSession(nil,
+		`<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><id xmlns="urn:ietf:params:xml:ns:yang:ietf-subscribed-notifications">7</id></rpc-reply>`,
+		notification(`<push-update xmlns="urn:ietf:params:xml:ns:yang:ietf-yang-push"><id>7</id><datastore-contents>
+<interfaces xmlns="urn:example:if"><interface><name>eth0</name><oper-status>up</oper-status></interface></interfaces>
+</datastore-contents></push-update>`),
+		notification(`<push-change-update xmlns="urn:ietf:params:xml:ns:yang:ietf-yang-push"><id>7</id><datastore-changes>
+<yang-patch><patch-id>1</patch-id>
+<edit><edit-id>1</edit-id><operation>replace</operation><target>/example-if:interfaces/interface=eth1</target>
+<value><interface xmlns="urn:example:if"><name>eth1</name><oper-status>down</oper-status></interface></value></edit>
+<edit><edit-id>2</edit-id><operation>delete</operation><target>/example-if:interfaces/interface=eth%2F2</target></edit>
+</yang-patch></datastore-changes></push-change-update>`),
+		notification(`<other xmlns="urn:example"/>`))
+	b := &GNMIBridge{
+		Session:    s,
+		Namespaces: map[string]string{"interfaces": "urn:example:if"},
+		ListKeys:   map[string][]string{"interface": {"name"}},
+	}
+
+	sub, err := b.Subscribe(context.Background(), &GNMISubscribeRequest{
+		Path:           []*GNMIPath{MustParseGNMIPath("/interfaces/interface/oper-status")},
+		Mode:           GNMIOnChange,
+		SampleInterval: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if sub.ID != "7" {
+		t.Errorf("unexpected subscription id %q", sub.ID)
+	}
+	for _, part := range []string{
+		`<yp:datastore xmlns:ds="urn:ietf:params:xml:ns:yang:ietf-datastores">ds:operational</yp:datastore>`,
+		`<yp:datastore-subtree-filter><interfaces xmlns="urn:example:if"><interface><oper-status/></interface></interfaces></yp:datastore-subtree-filter>`,
+		`<yp:on-change><yp:dampening-period>100</yp:dampening-period></yp:on-change>`,
+	} {
+		if !strings.Contains(trans.sent[0], part) {
+			t.Errorf("request lacks %s: %s", part, trans.sent[0])
+		}
+	}
+
+	var got []string
+	for n := range sub.C {
+		if n.Timestamp != time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano() {
+			t.Errorf("unexpected timestamp %d", n.Timestamp)
+		}
+		for _, u := range n.Update {
+			got = append(got, u.Path.String()+" "+u.Val.(string))
+		}
+		for _, d := range n.Delete {
+			got = append(got, "delete "+d.String())
+		}
+	}
+	expected := []string{
+		"/interfaces/interface[name=eth0]/name eth0",
+		"/interfaces/interface[name=eth0]/oper-status up",
+		"/example-if:interfaces/interface[name=eth1]/name eth1",
+		"/example-if:interfaces/interface[name=eth1]/oper-status down",
+		"delete /example-if:interfaces/interface[name=eth/2]",
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("updates mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGNMIBridgeSubscribeClose(t *testing.T) {
+	replies := []string{`<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><id xmlns="urn:ietf:params:xml:ns:yang:ietf-subscribed-notifications">7</id></rpc-reply>`}
+	for i := 0; i < 5; i++ {
+		replies = append(replies, `<notification xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0"><eventTime>2020-01-01T00:00:00Z</eventTime>`+
+			`<push-update xmlns="urn:ietf:params:xml:ns:yang:ietf-yang-push"><id>7</id><datastore-contents/></push-update></notification>`)
+	}
+	s, _ := newScriptedSession(nil, replies...)
+	b := &GNMIBridge{Session: s, Namespaces: map[string]string{"interfaces": "urn:example:if"}}
+	sub, err := b.Subscribe(context.Background(), &GNMISubscribeRequest{
+		Path:    []*GNMIPath{MustParseGNMIPath("/interfaces")},
+		Mode:    GNMIOnChange,
+		Options: SubscriptionOptions{BufferSize: 1, Overflow: OverflowBlock},
+	})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	sub.Close()
+	time.Sleep(10 * time.Millisecond)
+
+	n := 0
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-sub.C:
+			if !ok {
+				if n > 1 {
+					t.Errorf("got %d notifications after Close, expected only the buffered one", n)
+				}
+				return
+			}
+			n++
+		case <-timeout:
+			t.Fatal("C not closed after Close")
+		}
+	}
+}
+
+func TestGNMIBridgeSubscribeErrors(t *testing.T) {
+	b := &GNMIBridge{Session: &Session{}, Namespaces: map[string]string{"a": "urn:a"}}
+	for _, req := range []*GNMISubscribeRequest{
+		{Mode: GNMIOnChange},
+		{Path: []*GNMIPath{MustParseGNMIPath("/a")}, Mode: GNMISample},
+		{Path: []*GNMIPath{MustParseGNMIPath("/a")}, Mode: "POLL"},
+	} {
+		if _, err := b.Subscribe(context.Background(), req); err == nil {
+			t.Errorf("expected error for %+v", req)
+		}
+	}
+}
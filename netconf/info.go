@@ -0,0 +1,96 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"crypto/tls"
+	"net"
+)
+
+// Framing is the message framing used on a session (RFC 6242).
+type Framing string
+
+// Framing modes.
+const (
+	FramingEOM     Framing = "end-of-message"
+	FramingChunked Framing = "chunked"
+)
+
+// Version returns the negotiated NETCONF version, "1.0" or "1.1".  It is
+// empty for sessions not created by NewSession.
+func (s *Session) Version() string {
+	return s.version
+}
+
+// Framing returns the message framing negotiated for the session.
+func (s *Session) Framing() Framing {
+	if s.version == "1.1" {
+		return FramingChunked
+	}
+	return FramingEOM
+}
+
+// TransportType returns the kind of transport used by the session, e.g.
+// "ssh", "tls", "tcp" or "unix".  Custom transports can report their type
+// through a Type() string method.
+func (s *Session) TransportType() string {
+	if t, ok := s.Transport.(interface{ Type() string }); ok {
+		return t.Type()
+	}
+	return "unknown"
+}
+
+// Banner returns the banner the server sent before the session was
+// established, such as the SSH pre-authentication banner, or an empty
+// string.  Custom transports can report it through a Banner() string
+// method.
+func (s *Session) Banner() string {
+	if t, ok := s.Transport.(interface{ Banner() string }); ok {
+		return t.Banner()
+	}
+	return ""
+}
+
+// RemoteAddr returns the address of the server if the transport knows it.
+func (s *Session) RemoteAddr() net.Addr {
+	if t, ok := s.Transport.(interface{ RemoteAddr() net.Addr }); ok {
+		return t.RemoteAddr()
+	}
+	return nil
+}
+
+// Type returns "ssh".
+func (t *TransportSSH) Type() string {
+	return "ssh"
+}
+
+// RemoteAddr returns the address of the SSH server.
+func (t *TransportSSH) RemoteAddr() net.Addr {
+	if t.sshClient == nil {
+		return nil
+	}
+	return t.sshClient.RemoteAddr()
+}
+
+// Type returns "tls" for TLS connections and the network of the connection,
+// such as "tcp" or "unix", otherwise.
+func (t *TransportConn) Type() string {
+	if _, ok := t.conn.(*tls.Conn); ok {
+		return "tls"
+	}
+	return t.conn.RemoteAddr().Network()
+}
+
+// Type returns "io".
+func (t *TransportIO) Type() string {
+	return "io"
+}
+
+// Type returns "junos".
+func (t *TransportJunos) Type() string {
+	return "junos"
+}
@@ -0,0 +1,47 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"time"
+)
+
+// Invoker executes RPCs, see Session.ExecContext.
+type Invoker func(ctx context.Context, methods []RPCMethod) (*RPCReply, error)
+
+// Interceptor wraps the execution of RPCs on a session, e.g. for logging,
+// metrics or auditing.  It calls invoke to proceed with the RPC, which runs
+// the remaining interceptors and sends the request, including retries.
+type Interceptor func(ctx context.Context, methods []RPCMethod, invoke Invoker) (*RPCReply, error)
+
+// intercept runs the session's interceptors around invoke.
+func (s *Session) intercept(ctx context.Context, methods []RPCMethod, invoke Invoker) (*RPCReply, error) {
+	for i := len(s.Interceptors) - 1; i >= 0; i-- {
+		interceptor, next := s.Interceptors[i], invoke
+		invoke = func(ctx context.Context, methods []RPCMethod) (*RPCReply, error) {
+			return interceptor(ctx, methods, next)
+		}
+	}
+	return invoke(ctx, methods)
+}
+
+// MetricsInterceptor returns an interceptor passing the operation, the
+// duration and the outcome of every RPC to record.  The operation is the
+// name of the first method, e.g. "get-config".
+func MetricsInterceptor(record func(operation string, d time.Duration, err error)) Interceptor {
+	return func(ctx context.Context, methods []RPCMethod, invoke Invoker) (*RPCReply, error) {
+		started := time.Now()
+		reply, err := invoke(ctx, methods)
+		var op string
+		if len(methods) > 0 {
+			op = methodName(methods[0])
+		}
+		record(op, time.Since(started), err)
+		return reply, err
+	}
+}
@@ -0,0 +1,63 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInterceptors(t *testing.T) {
+	s, trans := newScriptedSession(nil, replyOK, replyError("lock-denied"))
+
+	var calls []string
+	trace := func(name string) Interceptor {
+		return func(ctx context.Context, methods []RPCMethod, invoke Invoker) (*RPCReply, error) {
+			calls = append(calls, name+" before")
+			reply, err := invoke(ctx, methods)
+			calls = append(calls, name+" after")
+			return reply, err
+		}
+	}
+	var ops []string
+	s.Interceptors = []Interceptor{
+		trace("outer"),
+		trace("inner"),
+		MetricsInterceptor(func(op string, d time.Duration, err error) {
+			ops = append(ops, op+" "+map[bool]string{true: "ok", false: "failed"}[err == nil])
+		}),
+	}
+
+	if _, err := s.Exec(MethodCommit()); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if _, err := s.Exec(MethodLock("candidate")); err == nil {
+		t.Fatal("expected lock to fail")
+	}
+
+	expected := []string{"outer before", "inner before", "inner after", "outer after"}
+	if diff := cmp.Diff(append(expected, expected...), calls); diff != "" {
+		t.Errorf("calls mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"commit ok", "lock failed"}, ops); diff != "" {
+		t.Errorf("metrics mismatch (-want +got):\n%s", diff)
+	}
+	if len(trans.sent) != 2 {
+		t.Errorf("expected 2 requests, got %d", len(trans.sent))
+	}
+
+	// An interceptor may answer without sending the RPC.
+	s.Interceptors = []Interceptor{func(ctx context.Context, methods []RPCMethod, invoke Invoker) (*RPCReply, error) {
+		return &RPCReply{}, nil
+	}}
+	if _, err := s.Exec(MethodCommit()); err != nil || len(trans.sent) != 2 {
+		t.Errorf("expected short-circuited RPC, got %v after %d requests", err, len(trans.sent))
+	}
+}
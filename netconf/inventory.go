@@ -0,0 +1,166 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Device describes how to reach a single NETCONF device.
+type Device struct {
+	Name       string   `yaml:"name"`
+	Address    string   `yaml:"address"`
+	Port       int      `yaml:"port,omitempty"`
+	Username   string   `yaml:"username,omitempty"`
+	Credential string   `yaml:"credential,omitempty"`
+	Profile    string   `yaml:"profile,omitempty"`
+	Tags       []string `yaml:"tags,omitempty"`
+	// Fingerprint identifies the device when it calls home, see
+	// CallHomeListener.
+	Fingerprint string `yaml:"fingerprint,omitempty"`
+	// Limits overrides the fleet wide per-device rate limit.
+	Limits *RateLimit `yaml:"limits,omitempty"`
+	// Addresses are tried in order if Address cannot be dialled, e.g. the
+	// out-of-band management address of the device.
+	Addresses []string `yaml:"addresses,omitempty"`
+}
+
+// Target returns the host:port used to dial the device.  If no port is
+// set, neither in Port nor in Address, the default port of the device's
+// profile is used, or the default NETCONF over SSH port.  Address may be an
+// IPv6 literal with a zone, such as fe80::1%mgmt.
+func (d *Device) Target() string {
+	return d.target(d.Address)
+}
+
+// Targets returns the host:port of Address followed by those of Addresses.
+func (d *Device) Targets() []string {
+	targets := []string{d.Target()}
+	for _, a := range d.Addresses {
+		targets = append(targets, d.target(a))
+	}
+	return targets
+}
+
+func (d *Device) target(address string) string {
+	host, hostPort := splitTarget(address)
+	if d.Port == 0 && hostPort != "" {
+		return net.JoinHostPort(host, hostPort)
+	}
+	port := d.Port
+	if p := LookupProfile(d.Profile); port == 0 && p != nil {
+		port = p.Port
+	}
+	if port == 0 {
+		port = sshDefaultPort
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// HasTag reports whether the device carries the given tag.
+func (d *Device) HasTag(tag string) bool {
+	for _, t := range d.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Inventory is a set of device definitions.
+type Inventory struct {
+	Devices []*Device `yaml:"devices"`
+}
+
+// LoadInventory reads an inventory from a YAML or JSON file.
+func LoadInventory(path string) (*Inventory, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := ParseInventory(buf)
+	if err != nil {
+		return nil, fmt.Errorf("inventory %s: %v", path, err)
+	}
+	return inv, nil
+}
+
+// ParseInventory parses an inventory document.  As JSON is a subset of YAML
+// both formats are accepted.
+func ParseInventory(data []byte) (*Inventory, error) {
+	inv := new(Inventory)
+	if err := yaml.UnmarshalStrict(data, inv); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for i, d := range inv.Devices {
+		if d == nil || d.Name == "" {
+			return nil, fmt.Errorf("device #%d has no name", i)
+		}
+		if d.Address == "" {
+			return nil, fmt.Errorf("device %s has no address", d.Name)
+		}
+		for _, a := range d.Addresses {
+			if a == "" {
+				return nil, fmt.Errorf("device %s has an empty address", d.Name)
+			}
+		}
+		if seen[d.Name] {
+			return nil, fmt.Errorf("duplicate device %s", d.Name)
+		}
+		seen[d.Name] = true
+	}
+	return inv, nil
+}
+
+// Device returns the device with the given name or nil if there is none.
+func (inv *Inventory) Device(name string) *Device {
+	for _, d := range inv.Devices {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// DeviceByFingerprint returns the device with the given certificate or host
+// key fingerprint, or nil if there is none.  Fingerprints are compared
+// ignoring case and colons.
+func (inv *Inventory) DeviceByFingerprint(fp string) *Device {
+	fp = normalizeFingerprint(fp)
+	for _, d := range inv.Devices {
+		if d.Fingerprint != "" && normalizeFingerprint(d.Fingerprint) == fp {
+			return d
+		}
+	}
+	return nil
+}
+
+// Select returns the devices carrying all of the given tags.  With no tags
+// every device is returned.
+func (inv *Inventory) Select(tags ...string) []*Device {
+	var out []*Device
+	for _, d := range inv.Devices {
+		match := true
+		for _, tag := range tags {
+			if !d.HasTag(tag) {
+				match = false
+				break
+			}
+		}
+		if match {
+			out = append(out, d)
+		}
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseInventory(t *testing.T) {
+	tt := []struct {
+		name     string
+		input    string
+		expected *Inventory
+	}{
+		{
+			name: "yaml",
+			input: `
+devices:
+  - name: core1
+    address: 10.0.0.1
+    username: admin
+    credential: vault:core
+    profile: junos
+    tags: [core, dc1]
+  - name: edge1
+    address: 10.0.0.2
+    port: 22
+`,
+			expected: &Inventory{Devices: []*Device{
+				{Name: "core1", Address: "10.0.0.1", Username: "admin", Credential: "vault:core", Profile: "junos", Tags: []string{"core", "dc1"}},
+				{Name: "edge1", Address: "10.0.0.2", Port: 22},
+			}},
+		},
+		{
+			name:  "json",
+			input: `{"devices": [{"name": "core1", "address": "fe80::1", "tags": ["core"]}]}`,
+			expected: &Inventory{Devices: []*Device{
+				{Name: "core1", Address: "fe80::1", Tags: []string{"core"}},
+			}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			inv, err := ParseInventory([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !cmp.Equal(inv, tc.expected) {
+				t.Errorf("unexpected inventory:\n%s", cmp.Diff(tc.expected, inv))
+			}
+		})
+	}
+}
+
+func TestParseInventoryInvalid(t *testing.T) {
+	tt := []struct {
+		name  string
+		input string
+	}{
+		{"noname", `devices: [{address: 10.0.0.1}]`},
+		{"noaddress", `devices: [{name: core1}]`},
+		{"duplicate", `devices: [{name: a, address: x}, {name: a, address: y}]`},
+		{"unknownfield", `devices: [{name: a, address: x, vendor: y}]`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseInventory([]byte(tc.input)); err == nil {
+				t.Errorf("expected error for %q", tc.input)
+			}
+		})
+	}
+}
+
+func TestInventorySelect(t *testing.T) {
+	inv := &Inventory{Devices: []*Device{
+		{Name: "a", Tags: []string{"core", "dc1"}},
+		{Name: "b", Tags: []string{"edge", "dc1"}},
+		{Name: "c", Tags: []string{"core", "dc2"}},
+	}}
+
+	names := func(devs []*Device) []string {
+		var out []string
+		for _, d := range devs {
+			out = append(out, d.Name)
+		}
+		return out
+	}
+
+	if got := names(inv.Select("core")); !cmp.Equal(got, []string{"a", "c"}) {
+		t.Errorf("select core: got %v", got)
+	}
+	if got := names(inv.Select("core", "dc1")); !cmp.Equal(got, []string{"a"}) {
+		t.Errorf("select core,dc1: got %v", got)
+	}
+	if got := names(inv.Select()); len(got) != 3 {
+		t.Errorf("select all: got %v", got)
+	}
+}
+
+func TestDeviceTarget(t *testing.T) {
+	RegisterProfile(&Profile{Name: "whitebox", Port: 22, Subsystem: "netconf-xml"})
+
+	tt := []struct {
+		dev      Device
+		expected string
+	}{
+		{Device{Address: "10.0.0.1"}, "10.0.0.1:830"},
+		{Device{Address: "10.0.0.1", Port: 22}, "10.0.0.1:22"},
+		{Device{Address: "fe80::1"}, "[fe80::1]:830"},
+		{Device{Address: "fe80::1%mgmt"}, "[fe80::1%mgmt]:830"},
+		{Device{Address: "[fe80::1%mgmt]:2830", Profile: "whitebox"}, "[fe80::1%mgmt]:2830"},
+		{Device{Address: "[fe80::1]", Port: 22}, "[fe80::1]:22"},
+		{Device{Address: "10.0.0.1", Profile: "whitebox"}, "10.0.0.1:22"},
+		{Device{Address: "10.0.0.1", Port: 8300, Profile: "whitebox"}, "10.0.0.1:8300"},
+		{Device{Address: "10.0.0.1", Profile: "junos"}, "10.0.0.1:830"},
+	}
+
+	for _, tc := range tt {
+		if got := tc.dev.Target(); got != tc.expected {
+			t.Errorf("Target() = %q, want %q", got, tc.expected)
+		}
+	}
+}
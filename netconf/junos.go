@@ -0,0 +1,103 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"fmt"
+)
+
+// Attributes Junos uses to deactivate and reactivate configuration.
+const (
+	junosInactive = "inactive"
+	junosActive   = "active"
+)
+
+// MethodJunosOpenEphemeral files a Junos open-configuration request for the
+// ephemeral configuration database with the remote host.  An empty instance
+// selects the default ephemeral instance.
+func MethodJunosOpenEphemeral(instance string) RawMethod {
+	if instance == "" {
+		return RawMethod("<open-configuration><ephemeral/></open-configuration>")
+	}
+	return RawMethod(fmt.Sprintf("<open-configuration><ephemeral-instance>%s</ephemeral-instance></open-configuration>", EscapeText(instance)))
+}
+
+// MethodJunosCloseConfiguration files a Junos close-configuration request
+// with the remote host.
+func MethodJunosCloseConfiguration() RawMethod {
+	return RawMethod("<close-configuration/>")
+}
+
+// MethodJunosLoadConfiguration files a Junos load-configuration request
+// with the remote host.  action is e.g. "merge", "replace" or "update",
+// config the XML content of the <configuration> element.
+func MethodJunosLoadConfiguration(action string, config string) RawMethod {
+	return RawMethod(fmt.Sprintf(`<load-configuration action="%s" format="xml"><configuration>%s</configuration></load-configuration>`, EscapeText(action), config))
+}
+
+// MethodJunosCommitConfiguration files a Junos commit-configuration request
+// with the remote host.
+func MethodJunosCommitConfiguration() RawMethod {
+	return RawMethod("<commit-configuration/>")
+}
+
+// JunosEphemeral is an open instance of the Junos ephemeral configuration
+// database.  While it is open, loads and commits of the session apply to
+// the ephemeral instance.
+type JunosEphemeral struct {
+	*Session
+	Instance string
+}
+
+// OpenJunosEphemeral opens the ephemeral configuration database instance on
+// s.  An empty instance selects the default instance.
+func OpenJunosEphemeral(ctx context.Context, s *Session, instance string) (*JunosEphemeral, error) {
+	if _, err := s.ExecContext(ctx, MethodJunosOpenEphemeral(instance)); err != nil {
+		return nil, err
+	}
+	return &JunosEphemeral{Session: s, Instance: instance}, nil
+}
+
+// Load merges config, the content of a <configuration> element, into the
+// ephemeral instance.
+func (e *JunosEphemeral) Load(ctx context.Context, config string) error {
+	_, err := e.ExecContext(ctx, MethodJunosLoadConfiguration("merge", config))
+	return err
+}
+
+// Commit commits the ephemeral instance.
+func (e *JunosEphemeral) Commit(ctx context.Context) error {
+	_, err := e.ExecContext(ctx, MethodJunosCommitConfiguration())
+	return err
+}
+
+// Close closes the ephemeral instance.  Uncommitted changes are discarded.
+func (e *JunosEphemeral) Close(ctx context.Context) error {
+	_, err := e.ExecContext(ctx, MethodJunosCloseConfiguration())
+	return err
+}
+
+// JunosDeactivate marks the configuration node inactive, so Junos keeps but
+// ignores it once committed.
+func JunosDeactivate(n *Node) {
+	n.RemoveAttr("", junosActive)
+	n.SetAttr("", junosInactive, junosInactive)
+}
+
+// JunosActivate marks a deactivated configuration node active again.
+func JunosActivate(n *Node) {
+	n.RemoveAttr("", junosInactive)
+	n.SetAttr("", junosActive, junosActive)
+}
+
+// JunosInactive reports whether the configuration node is deactivated, as
+// shown in configurations retrieved from Junos.
+func JunosInactive(n *Node) bool {
+	v, ok := n.Attr("", junosInactive)
+	return ok && v == junosInactive
+}
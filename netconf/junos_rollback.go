@@ -0,0 +1,157 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MethodJunosCompare files a Junos request for the text difference between
+// the candidate and the running configuration, for use as
+// PlanOptions.DeviceCompare.
+func MethodJunosCompare() RawMethod {
+	return MethodJunosCompareRollback(0)
+}
+
+// MethodJunosCompareRollback files a Junos request for the text difference
+// between the given rollback and the candidate configuration, as shown by
+// "show | compare rollback n".
+func MethodJunosCompareRollback(rollback int) RawMethod {
+	return RawMethod(fmt.Sprintf(`<get-configuration compare="rollback" rollback="%d" format="text"/>`, rollback))
+}
+
+// MethodJunosGetRollbackInformation files a Junos get-rollback-information
+// request for the configuration saved as the given rollback.
+func MethodJunosGetRollbackInformation(rollback int) RawMethod {
+	return RawMethod(fmt.Sprintf("<get-rollback-information><rollback>%d</rollback></get-rollback-information>", rollback))
+}
+
+// MethodJunosCompareRollbacks files a Junos get-rollback-information
+// request for the text difference between two rollbacks, as shown by "show
+// system rollback n compare m".
+func MethodJunosCompareRollbacks(rollback, compare int) RawMethod {
+	return RawMethod(fmt.Sprintf("<get-rollback-information><rollback>%d</rollback><compare>%d</compare><format>text</format></get-rollback-information>", rollback, compare))
+}
+
+// MethodJunosLoadRollback files a Junos request loading the given rollback
+// into the candidate configuration.
+func MethodJunosLoadRollback(rollback int) RawMethod {
+	return RawMethod(fmt.Sprintf(`<load-configuration rollback="%d"/>`, rollback))
+}
+
+// JunosDiffSection is a hierarchy of a Junos text diff and the statements
+// changed below it.
+type JunosDiffSection struct {
+	// Path is the hierarchy, e.g. "edit interfaces ge-0/0/0".
+	Path  string
+	Lines []JunosDiffLine
+}
+
+// JunosDiffLine is a single statement of a Junos text diff.  Type is
+// ChangeAdded, ChangeRemoved or empty for context lines.
+type JunosDiffLine struct {
+	Type ChangeType
+	Text string
+}
+
+// ParseJunosDiff decodes the output of "show | compare".
+func ParseJunosDiff(text string) []JunosDiffSection {
+	var sections []JunosDiffSection
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \r")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			continue
+		case strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"):
+			sections = append(sections, JunosDiffSection{Path: trimmed[1 : len(trimmed)-1]})
+			continue
+		}
+		if len(sections) == 0 {
+			sections = append(sections, JunosDiffSection{})
+		}
+
+		dl := JunosDiffLine{Text: trimmed}
+		switch line[0] {
+		case '+':
+			dl.Type, dl.Text = ChangeAdded, strings.TrimSpace(line[1:])
+		case '-':
+			dl.Type, dl.Text = ChangeRemoved, strings.TrimSpace(line[1:])
+		}
+		last := &sections[len(sections)-1]
+		last.Lines = append(last.Lines, dl)
+	}
+	return sections
+}
+
+// JunosCompare returns the difference between the given rollback and the
+// candidate configuration.
+func (s *Session) JunosCompare(ctx context.Context, rollback int) ([]JunosDiffSection, error) {
+	return s.junosDiff(ctx, MethodJunosCompareRollback(rollback))
+}
+
+// JunosCompareRollbacks returns the difference between two rollbacks.
+func (s *Session) JunosCompareRollbacks(ctx context.Context, rollback, compare int) ([]JunosDiffSection, error) {
+	return s.junosDiff(ctx, MethodJunosCompareRollbacks(rollback, compare))
+}
+
+func (s *Session) junosDiff(ctx context.Context, method RPCMethod) ([]JunosDiffSection, error) {
+	reply, err := s.ExecContext(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := ParseNodes(reply.Data)
+	if err != nil {
+		return nil, err
+	}
+	out := findDescendant(&Node{Children: nodes}, "configuration-output")
+	if out == nil {
+		return nil, nil
+	}
+	return ParseJunosDiff(out.Text), nil
+}
+
+// JunosRollbackConfig returns the configuration saved as the given
+// rollback.
+func (s *Session) JunosRollbackConfig(ctx context.Context, rollback int) (RawXML, error) {
+	reply, err := s.ExecContext(ctx, MethodJunosGetRollbackInformation(rollback))
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := ParseNodes(reply.Data)
+	if err != nil {
+		return nil, err
+	}
+	config := findDescendant(&Node{Children: nodes}, "configuration")
+	if config == nil {
+		return nil, fmt.Errorf("netconf: reply holds no configuration for rollback %d", rollback)
+	}
+	return RawXML(config.String()), nil
+}
+
+// JunosRollback loads the given rollback into the candidate configuration.
+// The change takes effect once committed.
+func (s *Session) JunosRollback(ctx context.Context, rollback int) error {
+	_, err := s.ExecContext(ctx, MethodJunosLoadRollback(rollback))
+	return err
+}
+
+// findDescendant returns the first node below n with the given local name,
+// searching depth first.
+func findDescendant(n *Node, local string) *Node {
+	for _, c := range n.Children {
+		if c.XMLName.Local == local {
+			return c
+		}
+		if d := findDescendant(c, local); d != nil {
+			return d
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJunosEphemeral(t *testing.T) {
+	s, trans := newScriptedSession(nil, replyOK, replyOK, replyOK, replyOK)
+	ctx := context.Background()
+
+	e, err := OpenJunosEphemeral(ctx, s, "sdn")
+	if err != nil {
+		t.Fatalf("OpenJunosEphemeral failed: %v", err)
+	}
+	if err := e.Load(ctx, "<system><host-name>r1</host-name></system>"); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := e.Commit(ctx); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if err := e.Close(ctx); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	expected := []string{"open-configuration", "load-configuration", "commit-configuration", "close-configuration"}
+	if diff := cmp.Diff(expected, trans.operations()); diff != "" {
+		t.Errorf("operations mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMethodJunosOpenEphemeral(t *testing.T) {
+	tt := []struct {
+		instance string
+		expected string
+	}{
+		{"", "<open-configuration><ephemeral/></open-configuration>"},
+		{"sdn", "<open-configuration><ephemeral-instance>sdn</ephemeral-instance></open-configuration>"},
+	}
+	for _, tc := range tt {
+		if got := MethodJunosOpenEphemeral(tc.instance).MarshalMethod(); got != tc.expected {
+			t.Errorf("unexpected method: (want %q, got %q)", tc.expected, got)
+		}
+	}
+}
+
+func TestJunosInactive(t *testing.T) {
+	n, err := ParseNode([]byte(`<interface inactive="inactive"><name>ge-0/0/0</name></interface>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !JunosInactive(n) {
+		t.Error("expected node to be inactive")
+	}
+
+	JunosActivate(n)
+	if JunosInactive(n) {
+		t.Error("expected node to be active")
+	}
+	if got, want := n.String(), `<interface active="active"><name>ge-0/0/0</name></interface>`; got != want {
+		t.Errorf("unexpected node: (want %q, got %q)", want, got)
+	}
+
+	JunosDeactivate(n)
+	if got, want := n.String(), `<interface inactive="inactive"><name>ge-0/0/0</name></interface>`; got != want {
+		t.Errorf("unexpected node: (want %q, got %q)", want, got)
+	}
+}
+
+func TestParseJunosDiff(t *testing.T) {
+	text := `
+[edit system]
+-  host-name r1;
++  host-name r2;
+[edit interfaces ge-0/0/0 unit 0 family inet]
+   address 10.0.0.1/24 { ... }
++  address 10.0.1.1/24;
+`
+	expected := []JunosDiffSection{
+		{Path: "edit system", Lines: []JunosDiffLine{
+			{Type: ChangeRemoved, Text: "host-name r1;"},
+			{Type: ChangeAdded, Text: "host-name r2;"},
+		}},
+		{Path: "edit interfaces ge-0/0/0 unit 0 family inet", Lines: []JunosDiffLine{
+			{Text: "address 10.0.0.1/24 { ... }"},
+			{Type: ChangeAdded, Text: "address 10.0.1.1/24;"},
+		}},
+	}
+	if diff := cmp.Diff(expected, ParseJunosDiff(text)); diff != "" {
+		t.Errorf("diff mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestJunosRollback(t *testing.T) {
+	s, trans := newScriptedSession(nil,
+		`<rpc-reply><configuration-information><configuration-output>
+[edit system]
++  host-name r2;
+</configuration-output></configuration-information></rpc-reply>`,
+		`<rpc-reply><rollback-information><configuration><system><host-name>r1</host-name></system></configuration></rollback-information></rpc-reply>`,
+		replyOK,
+	)
+	ctx := context.Background()
+
+	sections, err := s.JunosCompare(ctx, 1)
+	if err != nil {
+		t.Fatalf("JunosCompare failed: %v", err)
+	}
+	if len(sections) != 1 || sections[0].Path != "edit system" || len(sections[0].Lines) != 1 {
+		t.Errorf("unexpected diff %+v", sections)
+	}
+	config, err := s.JunosRollbackConfig(ctx, 1)
+	if err != nil {
+		t.Fatalf("JunosRollbackConfig failed: %v", err)
+	}
+	if expected := "<configuration><system><host-name>r1</host-name></system></configuration>"; config.String() != expected {
+		t.Errorf("got %s, expected %s", config, expected)
+	}
+	if err := s.JunosRollback(ctx, 1); err != nil {
+		t.Fatalf("JunosRollback failed: %v", err)
+	}
+
+	expected := []string{"get-configuration", "get-rollback-information", "load-configuration"}
+	if diff := cmp.Diff(expected, trans.operations()); diff != "" {
+		t.Errorf("operations mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(trans.sent[2], `<load-configuration rollback="1"/>`) {
+		t.Errorf("unexpected request: %s", trans.sent[2])
+	}
+}
@@ -0,0 +1,247 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrLockLost is the error of a lease whose lock the server no longer holds
+// for the session, see LockManager.Refresh.
+var ErrLockLost = errors.New("netconf: lock no longer held")
+
+// DefaultUnlockTimeout bounds the unlock RPCs a LockManager sends on its own.
+const DefaultUnlockTimeout = 10 * time.Second
+
+// LockManager tracks the datastore locks held by this process and makes sure
+// they are released: when the lease is unlocked, when the context it was
+// acquired with is done, when its session is closed, or by UnlockAll, which
+// is meant to be deferred in main so that no lock is stranded on shutdown.
+//
+// A lock that cannot be unlocked because its session is broken is released
+// by closing the session, as servers release the locks of ended sessions.
+type LockManager struct {
+	// UnlockTimeout bounds the unlock RPCs sent when a context is done, a
+	// session is closed or by UnlockAll, DefaultUnlockTimeout if zero.
+	UnlockTimeout time.Duration
+	// Logger, if set, receives a message for every lock that could not be
+	// unlocked.
+	Logger Logger
+
+	mu     sync.Mutex
+	leases map[*Lease]bool
+}
+
+// Lease is a lock on a datastore held through a LockManager.
+type Lease struct {
+	Session  *Session
+	Target   string
+	Acquired time.Time
+
+	m           *LockManager
+	removeHook  func()
+	once        sync.Once
+	done        chan struct{}
+	err         error
+	lastChecked time.Time
+}
+
+// NewLockManager returns an empty LockManager.
+func NewLockManager() *LockManager {
+	return &LockManager{leases: make(map[*Lease]bool)}
+}
+
+// Lock locks target on s.  The lock is released once ctx is done unless it
+// was unlocked before.
+func (m *LockManager) Lock(ctx context.Context, s *Session, target string) (*Lease, error) {
+	if _, err := s.ExecContext(ctx, MethodLock(target)); err != nil {
+		return nil, err
+	}
+
+	l := &Lease{Session: s, Target: target, Acquired: time.Now(), m: m, done: make(chan struct{})}
+	l.lastChecked = l.Acquired
+	m.mu.Lock()
+	if m.leases == nil {
+		m.leases = make(map[*Lease]bool)
+	}
+	m.leases[l] = true
+	m.mu.Unlock()
+
+	// The session is being closed, so it must not be closed on failure.
+	l.removeHook = s.onClose(func() { m.autoUnlock(l, "session closed", false) })
+	go func() {
+		select {
+		case <-ctx.Done():
+			m.autoUnlock(l, "context done", true)
+		case <-l.done:
+		}
+	}()
+	return l, nil
+}
+
+// Leases returns the leases held, oldest first.
+func (m *LockManager) Leases() []*Lease {
+	m.mu.Lock()
+	leases := make([]*Lease, 0, len(m.leases))
+	for l := range m.leases {
+		leases = append(leases, l)
+	}
+	m.mu.Unlock()
+	sort.Slice(leases, func(i, j int) bool { return leases[i].Acquired.Before(leases[j].Acquired) })
+	return leases
+}
+
+// UnlockAll unlocks all leases and returns the first error.
+func (m *LockManager) UnlockAll() error {
+	var first error
+	for _, l := range m.Leases() {
+		if err := m.autoUnlock(l, "unlock all", true); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Refresh checks in the ietf-netconf-monitoring state of the servers that
+// the locks of all leases are still held by their sessions.  Leases whose
+// lock is not, or whose session failed, end with ErrLockLost and are
+// returned.
+func (m *LockManager) Refresh(ctx context.Context) ([]*Lease, error) {
+	bySession := make(map[*Session][]*Lease)
+	var sessions []*Session
+	for _, l := range m.Leases() {
+		if bySession[l.Session] == nil {
+			sessions = append(sessions, l.Session)
+		}
+		bySession[l.Session] = append(bySession[l.Session], l)
+	}
+
+	var lost []*Lease
+	for _, s := range sessions {
+		holders, err := datastoreLocks(ctx, s)
+		if err != nil && isRPCError(err) {
+			return lost, err
+		}
+		for _, l := range bySession[s] {
+			if err == nil && holders[l.Target] == s.SessionID {
+				m.mu.Lock()
+				l.lastChecked = time.Now()
+				m.mu.Unlock()
+				continue
+			}
+			l.finish(ErrLockLost)
+			lost = append(lost, l)
+		}
+	}
+	return lost, nil
+}
+
+// datastoreLocks returns the ids of the sessions holding the global locks
+// of the datastores of the server of s, keyed by datastore.
+func datastoreLocks(ctx context.Context, s *Session) (map[string]int, error) {
+	filter := `<netconf-state xmlns="` + MonitoringNamespace + `"><datastores/></netconf-state>`
+	reply, err := s.ExecContext(ctx, MethodGetFilter(SubtreeFilter(filter)))
+	if err != nil {
+		return nil, err
+	}
+	root, err := configRoot(reply.Data)
+	if err != nil {
+		return nil, err
+	}
+	holders := make(map[string]int)
+	datastores, _ := root.Select("/netconf-state/datastores/datastore")
+	for _, ds := range datastores {
+		if id, err := strconv.Atoi(selectValue(ds, "locks/global-lock/locked-by-session")); err == nil {
+			holders[childValue(ds, "name")] = id
+		}
+	}
+	return holders, nil
+}
+
+// autoUnlock unlocks l with a fresh context bounded by UnlockTimeout.
+func (m *LockManager) autoUnlock(l *Lease, reason string, closeOnFailure bool) error {
+	timeout := m.UnlockTimeout
+	if timeout <= 0 {
+		timeout = DefaultUnlockTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := l.unlock(ctx, closeOnFailure)
+	if err != nil && m.Logger != nil {
+		m.Logger.Printf("netconf: unlock %s of session %d on %s: %v", l.Target, l.Session.SessionID, reason, err)
+	}
+	return err
+}
+
+// Unlock unlocks the datastore.  If the unlock fails other than with an
+// rpc-error, e.g. because the session is broken, the session is closed so
+// that the server releases the lock.  Unlocking a lease again does nothing.
+func (l *Lease) Unlock(ctx context.Context) error {
+	return l.unlock(ctx, true)
+}
+
+func (l *Lease) unlock(ctx context.Context, closeOnFailure bool) error {
+	var err error
+	l.once.Do(func() {
+		l.removeHook()
+		_, err = l.Session.ExecContext(ctx, MethodUnlock(l.Target))
+		if err != nil && !isRPCError(err) && closeOnFailure {
+			l.Session.Close()
+		}
+		l.end(err)
+	})
+	return err
+}
+
+// finish ends the lease without unlocking.
+func (l *Lease) finish(err error) {
+	l.once.Do(func() {
+		l.removeHook()
+		l.end(err)
+	})
+}
+
+func (l *Lease) end(err error) {
+	l.m.mu.Lock()
+	delete(l.m.leases, l)
+	l.m.mu.Unlock()
+	l.err = err
+	close(l.done)
+}
+
+// Done returns a channel closed once the lease ended.
+func (l *Lease) Done() <-chan struct{} {
+	return l.done
+}
+
+// Err returns the error the lease ended with once Done is closed: nil if it
+// was unlocked, the error of the unlock or ErrLockLost.
+func (l *Lease) Err() error {
+	select {
+	case <-l.done:
+		return l.err
+	default:
+		return nil
+	}
+}
+
+// Checked returns the time the lock was last known to be held.
+func (l *Lease) Checked() time.Time {
+	l.m.mu.Lock()
+	defer l.m.mu.Unlock()
+	return l.lastChecked
+}
+
+func (l *Lease) String() string {
+	return fmt.Sprintf("lock of %s by session %d since %s", l.Target, l.Session.SessionID, l.Acquired.Format(time.RFC3339))
+}
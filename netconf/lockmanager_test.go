@@ -0,0 +1,157 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLockManager(t *testing.T) {
+	tt := []struct {
+		name    string
+		replies []string
+		release func(ctx context.Context, m *LockManager, s *Session, l *Lease, cancel func()) error
+		ops     []string
+		closed  bool
+		err     bool
+	}{
+		{
+			name:    "unlock",
+			replies: []string{replyOK, replyOK},
+			release: func(ctx context.Context, m *LockManager, s *Session, l *Lease, cancel func()) error {
+				return l.Unlock(ctx)
+			},
+			ops: []string{"lock", "unlock"},
+		},
+		{
+			name:    "context done",
+			replies: []string{replyOK, replyOK},
+			release: func(ctx context.Context, m *LockManager, s *Session, l *Lease, cancel func()) error {
+				cancel()
+				<-l.Done()
+				return l.Err()
+			},
+			ops: []string{"lock", "unlock"},
+		},
+		{
+			name:    "session closed",
+			replies: []string{replyOK, replyOK},
+			release: func(ctx context.Context, m *LockManager, s *Session, l *Lease, cancel func()) error {
+				return s.Close()
+			},
+			ops:    []string{"lock", "unlock"},
+			closed: true,
+		},
+		{
+			name:    "unlock all",
+			replies: []string{replyOK, replyOK},
+			release: func(ctx context.Context, m *LockManager, s *Session, l *Lease, cancel func()) error {
+				return m.UnlockAll()
+			},
+			ops: []string{"lock", "unlock"},
+		},
+		{
+			name:    "broken session",
+			replies: []string{replyOK},
+			release: func(ctx context.Context, m *LockManager, s *Session, l *Lease, cancel func()) error {
+				return l.Unlock(ctx)
+			},
+			ops:    []string{"lock", "unlock"},
+			closed: true,
+			err:    true,
+		},
+		{
+			name:    "rpc error",
+			replies: []string{replyOK, replyError("operation-failed")},
+			release: func(ctx context.Context, m *LockManager, s *Session, l *Lease, cancel func()) error {
+				return l.Unlock(ctx)
+			},
+			ops: []string{"lock", "unlock"},
+			err: true,
+		},
+	}
+
+	for _, tc := range tt {
+		s, trans := newScriptedSession(nil, tc.replies...)
+		m := NewLockManager()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		l, err := m.Lock(ctx, s, "candidate")
+		if err != nil {
+			t.Fatalf("%s: Lock failed: %v", tc.name, err)
+		}
+		if leases := m.Leases(); len(leases) != 1 || leases[0] != l {
+			t.Errorf("%s: got leases %v, expected the lease", tc.name, leases)
+		}
+
+		err = tc.release(context.Background(), m, s, l, cancel)
+		cancel()
+		if tc.err != (err != nil) {
+			t.Errorf("%s: got error %v, expected error %v", tc.name, err, tc.err)
+		}
+		if diff := cmp.Diff(tc.ops, trans.operations()); diff != "" {
+			t.Errorf("%s: operations mismatch (-expected +got):\n%s", tc.name, diff)
+		}
+		if trans.closed != tc.closed {
+			t.Errorf("%s: got closed %v, expected %v", tc.name, trans.closed, tc.closed)
+		}
+		if leases := m.Leases(); len(leases) != 0 {
+			t.Errorf("%s: got leases %v, expected none", tc.name, leases)
+		}
+		if l.Unlock(context.Background()) != nil || len(trans.sent) != len(tc.ops) {
+			t.Errorf("%s: expected a second unlock to do nothing", tc.name)
+		}
+	}
+}
+
+func TestLockManagerLockDenied(t *testing.T) {
+	s, _ := newScriptedSession(nil, replyError("lock-denied"))
+	m := NewLockManager()
+	if _, err := m.Lock(context.Background(), s, "running"); !isRPCError(err) {
+		t.Errorf("got %v, expected the rpc-error", err)
+	}
+	if len(m.Leases()) != 0 {
+		t.Error("expected no lease for a denied lock")
+	}
+}
+
+func TestLockManagerRefresh(t *testing.T) {
+	state := `<rpc-reply><data><netconf-state xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring"><datastores>
+<datastore><name>running</name><locks><global-lock><locked-by-session>9</locked-by-session></global-lock></locks></datastore>
+<datastore><name>candidate</name><locks><global-lock><locked-by-session>5</locked-by-session></global-lock></locks></datastore>
+</datastores></netconf-state></data></rpc-reply>`
+	s, _ := newScriptedSession(nil, replyOK, replyOK, state)
+	s.SessionID = 5
+	m := NewLockManager()
+
+	running, _ := m.Lock(context.Background(), s, "running")
+	candidate, _ := m.Lock(context.Background(), s, "candidate")
+	checked := candidate.Checked()
+	time.Sleep(time.Millisecond)
+
+	lost, err := m.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if len(lost) != 1 || lost[0] != running || !errors.Is(running.Err(), ErrLockLost) {
+		t.Errorf("got lost leases %v, expected the lock of running", lost)
+	}
+	if leases := m.Leases(); len(leases) != 1 || leases[0] != candidate || !candidate.Checked().After(checked) {
+		t.Errorf("got leases %v, expected the refreshed lock of candidate", leases)
+	}
+
+	// A failed session loses its locks.
+	lost, err = m.Refresh(context.Background())
+	if err != nil || len(lost) != 1 || lost[0] != candidate {
+		t.Errorf("got %v, %v, expected the lock of candidate to be lost", lost, err)
+	}
+}
@@ -0,0 +1,212 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// YANG library capabilities (RFC 7950 section 5.6.4 and RFC 8526).
+const (
+	CapabilityYANGLibrary   = "urn:ietf:params:netconf:capability:yang-library:1.0"
+	CapabilityYANGLibrary11 = "urn:ietf:params:netconf:capability:yang-library:1.1"
+	YANGLibraryNamespace    = "urn:ietf:params:xml:ns:yang:ietf-yang-library"
+)
+
+// YANGLibraryModules returns the modules listed in the YANG library of the
+// server, for servers implementing YANG 1.1 which do not announce their
+// modules in the hello.  Both the modules-state of RFC 7895 and the module
+// sets of RFC 8525 are read.
+func (s *Session) YANGLibraryModules(ctx context.Context) ([]Module, error) {
+	filter := `<modules-state xmlns="` + YANGLibraryNamespace + `"/>`
+	// yang-library:1.1 replaced rather than extended 1.0, so unlike
+	// HasCapability only the exact URI counts.
+	for _, c := range s.ServerCapabilities {
+		if capabilityBase(c) == CapabilityYANGLibrary11 {
+			filter = `<yang-library xmlns="` + YANGLibraryNamespace + `"/>`
+		}
+	}
+	reply, err := s.ExecContext(ctx, MethodGetFilter(SubtreeFilter(filter)))
+	if err != nil {
+		return nil, err
+	}
+	root, err := configRoot(reply.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	var modules []Module
+	var collect func(n *Node)
+	collect = func(n *Node) {
+		for _, c := range n.Children {
+			if c.XMLName.Local != "module" || c.Child("name") == nil {
+				collect(c)
+				continue
+			}
+			m := Module{
+				Name:      childValue(c, "name"),
+				Namespace: childValue(c, "namespace"),
+				Revision:  childValue(c, "revision"),
+			}
+			for _, f := range c.ChildrenNamed("feature") {
+				m.Features = append(m.Features, f.Value())
+			}
+			for _, d := range c.ChildrenNamed("deviation") {
+				name := d.Value()
+				if d.Child("name") != nil {
+					name = childValue(d, "name")
+				}
+				m.Deviations = append(m.Deviations, name)
+			}
+			modules = append(modules, m)
+		}
+	}
+	collect(root)
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Name < modules[j].Name })
+	return modules, nil
+}
+
+// Kinds of capability matrix rows.
+const (
+	MatrixCapability = "capability"
+	MatrixModule     = "module"
+	MatrixFeature    = "feature"
+)
+
+// MatrixRow is a capability, module or feature along with the devices
+// supporting it.
+type MatrixRow struct {
+	Kind string `json:"kind"`
+	// Name is the capability URI without parameters, the module name or
+	// module:feature.
+	Name string `json:"name"`
+	// Support maps the devices supporting the row to the revision of the
+	// module, or "yes".
+	Support map[string]string `json:"support"`
+}
+
+// CapabilityMatrix shows which capabilities, YANG modules and features each
+// of a set of devices supports.
+type CapabilityMatrix struct {
+	Devices []string    `json:"devices"`
+	Rows    []MatrixRow `json:"rows"`
+}
+
+// NewCapabilityMatrix builds the matrix of the capabilities of devices,
+// given by device name.  Rows are sorted by kind and name.
+func NewCapabilityMatrix(devices map[string]*Capabilities) *CapabilityMatrix {
+	m := &CapabilityMatrix{}
+	rows := make(map[[2]string]map[string]string)
+	add := func(kind, name, device, value string) {
+		key := [2]string{kind, name}
+		if rows[key] == nil {
+			rows[key] = make(map[string]string)
+		}
+		rows[key][device] = value
+	}
+
+	for device, caps := range devices {
+		m.Devices = append(m.Devices, device)
+		for _, uri := range caps.URIs {
+			if !strings.Contains(uri, "module=") {
+				add(MatrixCapability, capabilityBase(uri), device, "yes")
+			}
+		}
+		for _, mod := range caps.Modules {
+			rev := mod.Revision
+			if rev == "" {
+				rev = "yes"
+			}
+			add(MatrixModule, mod.Name, device, rev)
+			for _, f := range mod.Features {
+				add(MatrixFeature, mod.Name+":"+f, device, "yes")
+			}
+		}
+	}
+	sort.Strings(m.Devices)
+
+	order := map[string]int{MatrixCapability: 0, MatrixModule: 1, MatrixFeature: 2}
+	for key, support := range rows {
+		m.Rows = append(m.Rows, MatrixRow{Kind: key[0], Name: key[1], Support: support})
+	}
+	sort.Slice(m.Rows, func(i, j int) bool {
+		a, b := m.Rows[i], m.Rows[j]
+		if a.Kind != b.Kind {
+			return order[a.Kind] < order[b.Kind]
+		}
+		return a.Name < b.Name
+	})
+	return m
+}
+
+// CapabilityMatrix connects to the devices of the fleet and builds the
+// matrix of their capabilities, including the modules of their YANG
+// library if they announce one.  Devices that fail are left out of the
+// matrix and reported in the returned report.
+func (f *Fleet) CapabilityMatrix(ctx context.Context) (*CapabilityMatrix, *Report) {
+	var mu sync.Mutex
+	devices := make(map[string]*Capabilities)
+	report := f.Run(ctx, func(ctx context.Context, s *Session, r *DeviceResult) error {
+		caps := ParseCapabilities(s.ServerCapabilities)
+		if s.HasCapability(CapabilityYANGLibrary) || s.HasCapability(CapabilityYANGLibrary11) {
+			modules, err := s.YANGLibraryModules(ctx)
+			if err != nil {
+				return err
+			}
+			caps.Modules = mergeModules(caps.Modules, modules)
+		}
+		mu.Lock()
+		devices[r.Device] = caps
+		mu.Unlock()
+		return nil
+	})
+	return NewCapabilityMatrix(devices), report
+}
+
+// mergeModules adds the modules of b missing from a, keeping a sorted.
+func mergeModules(a, b []Module) []Module {
+	seen := make(map[string]bool, len(a))
+	for _, m := range a {
+		seen[m.Name] = true
+	}
+	for _, m := range b {
+		if !seen[m.Name] {
+			a = append(a, m)
+		}
+	}
+	sort.Slice(a, func(i, j int) bool { return a[i].Name < a[j].Name })
+	return a
+}
+
+// WriteJSON writes the matrix as indented JSON.
+func (m *CapabilityMatrix) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// WriteCSV writes the matrix as CSV with a column per device, holding the
+// module revision or "yes" for supported rows and nothing otherwise.
+func (m *CapabilityMatrix) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(append([]string{"kind", "name"}, m.Devices...))
+	for _, row := range m.Rows {
+		record := []string{row.Kind, row.Name}
+		for _, d := range m.Devices {
+			record = append(record, row.Support[d])
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,134 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCapabilityMatrix(t *testing.T) {
+	m := NewCapabilityMatrix(map[string]*Capabilities{
+		"r1": ParseCapabilities([]string{
+			CapabilityBase11,
+			CapabilityCandidate,
+			"urn:example:system?module=example-system&revision=2020-01-01&features=ntp,dns",
+		}),
+		"r2": ParseCapabilities([]string{
+			CapabilityBase11,
+			"urn:ietf:params:netconf:capability:url:1.0?scheme=http",
+			"urn:example:system?module=example-system&revision=2021-06-01",
+		}),
+	})
+
+	expected := &CapabilityMatrix{
+		Devices: []string{"r1", "r2"},
+		Rows: []MatrixRow{
+			{Kind: MatrixCapability, Name: CapabilityBase11, Support: map[string]string{"r1": "yes", "r2": "yes"}},
+			{Kind: MatrixCapability, Name: CapabilityCandidate, Support: map[string]string{"r1": "yes"}},
+			{Kind: MatrixCapability, Name: CapabilityURL, Support: map[string]string{"r2": "yes"}},
+			{Kind: MatrixModule, Name: "example-system", Support: map[string]string{"r1": "2020-01-01", "r2": "2021-06-01"}},
+			{Kind: MatrixFeature, Name: "example-system:dns", Support: map[string]string{"r1": "yes"}},
+			{Kind: MatrixFeature, Name: "example-system:ntp", Support: map[string]string{"r1": "yes"}},
+		},
+	}
+	if diff := cmp.Diff(expected, m); diff != "" {
+		t.Fatalf("matrix mismatch (-want +got):\n%s", diff)
+	}
+
+	var buf bytes.Buffer
+	if err := m.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	csv := `kind,name,r1,r2
+capability,urn:ietf:params:netconf:base:1.1,yes,yes
+capability,urn:ietf:params:netconf:capability:candidate:1.0,yes,
+capability,urn:ietf:params:netconf:capability:url:1.0,,yes
+module,example-system,2020-01-01,2021-06-01
+feature,example-system:dns,yes,
+feature,example-system:ntp,yes,
+`
+	if diff := cmp.Diff(csv, buf.String()); diff != "" {
+		t.Errorf("CSV mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestYANGLibraryModules(t *testing.T) {
+	tt := []struct {
+		name  string
+		caps  []string
+		reply string
+		op    string
+	}{
+		{
+			name: "modulesState",
+			caps: []string{CapabilityYANGLibrary},
+			reply: `<modules-state xmlns="urn:ietf:params:xml:ns:yang:ietf-yang-library">
+<module><name>example-system</name><revision>2020-01-01</revision><namespace>urn:example:system</namespace>
+<feature>ntp</feature><deviation><name>example-dev</name><revision>2020-02-02</revision></deviation></module>
+<module><name>example-base</name><revision></revision><namespace>urn:example:base</namespace></module>
+</modules-state>`,
+			op: "<modules-state ",
+		},
+		{
+			name: "yangLibrary",
+			caps: []string{CapabilityYANGLibrary11},
+			reply: `<yang-library xmlns="urn:ietf:params:xml:ns:yang:ietf-yang-library"><module-set><name>all</name>
+<module><name>example-system</name><revision>2020-01-01</revision><namespace>urn:example:system</namespace>
+<feature>ntp</feature><deviation>example-dev</deviation></module>
+<module><name>example-base</name><namespace>urn:example:base</namespace></module>
+</module-set></yang-library>`,
+			op: "<yang-library ",
+		},
+	}
+
+	expected := []Module{
+		{Name: "example-base", Namespace: "urn:example:base"},
+		{Name: "example-system", Namespace: "urn:example:system", Revision: "2020-01-01", Features: []string{"ntp"}, Deviations: []string{"example-dev"}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s, trans := newScriptedSession(tc.caps, `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><data>`+tc.reply+`</data></rpc-reply>`)
+			modules, err := s.YANGLibraryModules(context.Background())
+			if err != nil {
+				t.Fatalf("YANGLibraryModules failed: %v", err)
+			}
+			if diff := cmp.Diff(expected, modules); diff != "" {
+				t.Errorf("modules mismatch (-want +got):\n%s", diff)
+			}
+			if !bytes.Contains([]byte(trans.sent[0]), []byte(tc.op)) {
+				t.Errorf("unexpected request %s", trans.sent[0])
+			}
+		})
+	}
+}
+
+func TestFleetCapabilityMatrix(t *testing.T) {
+	f := &Fleet{Devices: []*Device{{Name: "r1"}, {Name: "r2"}, {Name: "down"}}}
+	f.Dial = func(ctx context.Context, d *Device) (*Session, error) {
+		if d.Name == "down" {
+			return nil, errors.New("connection refused")
+		}
+		s, _ := newScriptedSession([]string{CapabilityBase10, "urn:example:" + d.Name + "?module=" + d.Name})
+		return s, nil
+	}
+
+	m, report := f.CapabilityMatrix(context.Background())
+	if diff := cmp.Diff([]string{"r1", "r2"}, m.Devices); diff != "" {
+		t.Errorf("devices mismatch (-want +got):\n%s", diff)
+	}
+	if len(m.Rows) != 3 {
+		t.Errorf("expected 3 rows, got %+v", m.Rows)
+	}
+	if report.Count(StatusFailed) != 1 {
+		t.Errorf("expected the unreachable device to fail: %+v", report.Results)
+	}
+}
@@ -0,0 +1,183 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NamespaceMap maps prefixes to namespace URIs, e.g. for the Namespaces of
+// an XPath filter.
+type NamespaceMap map[string]string
+
+// Declare adds prefix for uri.  It fails if prefix is already bound to a
+// different namespace.
+func (m NamespaceMap) Declare(prefix, uri string) error {
+	if old, ok := m[prefix]; ok && old != uri {
+		return fmt.Errorf("netconf: prefix %q already bound to %q", prefix, old)
+	}
+	m[prefix] = uri
+	return nil
+}
+
+// Prefix returns a prefix bound to uri.
+func (m NamespaceMap) Prefix(uri string) (string, bool) {
+	prefixes := m.prefixes()
+	for _, p := range prefixes {
+		if m[p] == uri {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// Expand resolves a prefixed name such as "if:interfaces".  Names without
+// prefix are returned without namespace.
+func (m NamespaceMap) Expand(qname string) (xml.Name, error) {
+	i := strings.IndexByte(qname, ':')
+	if i < 0 {
+		return xml.Name{Local: qname}, nil
+	}
+	uri, ok := m[qname[:i]]
+	if !ok {
+		return xml.Name{}, fmt.Errorf("netconf: undeclared prefix %q", qname[:i])
+	}
+	return xml.Name{Space: uri, Local: qname[i+1:]}, nil
+}
+
+// Attrs returns the xmlns declarations of the map, sorted by prefix, each
+// preceded by a space.
+func (m NamespaceMap) Attrs() string {
+	var buf bytes.Buffer
+	for _, p := range m.prefixes() {
+		writeAttr(&buf, xmlnsPrefix+":"+p, m[p])
+	}
+	return buf.String()
+}
+
+// Undeclared returns the prefixes used in the XPath expression that the map
+// does not declare.
+func (m NamespaceMap) Undeclared(expr string) []string {
+	var missing []string
+	seen := make(map[string]bool)
+	for _, p := range xpathPrefixes(expr) {
+		if _, ok := m[p]; !ok && !seen[p] {
+			seen[p] = true
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+func (m NamespaceMap) prefixes() []string {
+	prefixes := make([]string, 0, len(m))
+	for p := range m {
+		prefixes = append(prefixes, p)
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// xpathPrefixes returns the prefixes of the names in an XPath expression,
+// skipping string literals and axes such as child::.
+func xpathPrefixes(expr string) []string {
+	var prefixes []string
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case c == '\'' || c == '"':
+			if end := strings.IndexByte(expr[i+1:], c); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(expr)
+			}
+		case isNameStart(c):
+			start := i
+			for i < len(expr) && isNameChar(expr[i]) {
+				i++
+			}
+			if i+1 < len(expr) && expr[i] == ':' && expr[i+1] != ':' {
+				prefixes = append(prefixes, expr[start:i])
+			}
+			i--
+		}
+	}
+	return prefixes
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || c == '-' || c == '.' || (c >= '0' && c <= '9')
+}
+
+// SetDefaultNamespace puts n and all its descendants that have no
+// namespace into uri, the way a default xmlns declaration on n would.
+func (n *Node) SetDefaultNamespace(uri string) {
+	if n.XMLName.Space == "" {
+		n.XMLName.Space = uri
+	}
+	for _, c := range n.Children {
+		c.SetDefaultNamespace(uri)
+	}
+}
+
+// WithNamespace returns the XML fragment with the elements that have no
+// namespace put into uri.  It is used to qualify filters and configuration
+// written without xmlns:
+//
+//	SubtreeFilter(WithNamespace("<interfaces/>", "urn:ietf:params:xml:ns:yang:ietf-interfaces"))
+func WithNamespace(fragment, uri string) (string, error) {
+	nodes, err := ParseNodes([]byte(fragment))
+	if err != nil {
+		return "", err
+	}
+	var buf strings.Builder
+	for _, n := range nodes {
+		n.SetDefaultNamespace(uri)
+		buf.WriteString(n.String())
+	}
+	return buf.String(), nil
+}
+
+// NormalizeNamespaces rewrites the XML fragment so that every element
+// declares its namespace as default namespace where it changes, removing
+// element prefixes.  Prefix declarations used by attributes are kept.
+func NormalizeNamespaces(fragment string) (string, error) {
+	return WithNamespace(fragment, "")
+}
+
+// UnqualifiedElements returns the paths of the elements in the XML fragment
+// that have no namespace.  NETCONF servers ignore such elements in filters
+// and configuration, which typically leads to empty replies.
+func UnqualifiedElements(fragment string) ([]string, error) {
+	nodes, err := ParseNodes([]byte(fragment))
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	var walk func(n *Node, path string)
+	walk = func(n *Node, path string) {
+		path += "/" + n.XMLName.Local
+		if n.XMLName.Space == "" {
+			paths = append(paths, path)
+		}
+		for _, c := range n.Children {
+			walk(c, path)
+		}
+	}
+	for _, n := range nodes {
+		walk(n, "")
+	}
+	return paths, nil
+}
@@ -0,0 +1,98 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNamespaceMap(t *testing.T) {
+	m := NamespaceMap{}
+	if err := m.Declare("if", "urn:if"); err != nil {
+		t.Fatalf("Declare failed: %v", err)
+	}
+	m.Declare("ip", "urn:ip")
+	if err := m.Declare("if", "urn:other"); err == nil {
+		t.Error("expected error rebinding a prefix")
+	}
+
+	if p, ok := m.Prefix("urn:ip"); !ok || p != "ip" {
+		t.Errorf("got prefix %q, %v", p, ok)
+	}
+	name, err := m.Expand("if:interfaces")
+	if err != nil || name != (xml.Name{Space: "urn:if", Local: "interfaces"}) {
+		t.Errorf("got %v, %v", name, err)
+	}
+	if _, err := m.Expand("x:y"); err == nil {
+		t.Error("expected error for undeclared prefix")
+	}
+	if got := m.Attrs(); got != ` xmlns:if="urn:if" xmlns:ip="urn:ip"` {
+		t.Errorf("got attrs %s", got)
+	}
+
+	missing := m.Undeclared(`/if:interfaces/if:interface[if:name='a:b']/ipv4:address | child::oc:x`)
+	if diff := cmp.Diff([]string{"ipv4", "oc"}, missing); diff != "" {
+		t.Errorf("undeclared mismatch (-want +got):\n%s", diff)
+	}
+
+	// A NamespaceMap can be used directly for XPath filters.
+	f := XPathFilter("/if:interfaces", m)
+	if f.String() != `<filter xmlns:if="urn:if" xmlns:ip="urn:ip" type="xpath" select="/if:interfaces"/>` {
+		t.Errorf("unexpected filter %s", f)
+	}
+}
+
+func TestWithNamespace(t *testing.T) {
+	tt := []struct {
+		name     string
+		input    string
+		fn       func(string) (string, error)
+		expected string
+	}{
+		{
+			name:     "default",
+			input:    `<interfaces><interface><name>a</name></interface></interfaces>`,
+			fn:       func(s string) (string, error) { return WithNamespace(s, "urn:if") },
+			expected: `<interfaces xmlns="urn:if"><interface><name>a</name></interface></interfaces>`,
+		},
+		{
+			name:     "mixed",
+			input:    `<interfaces><ip:ipv4 xmlns:ip="urn:ip"><mtu>1500</mtu></ip:ipv4></interfaces>`,
+			fn:       func(s string) (string, error) { return WithNamespace(s, "urn:if") },
+			expected: `<interfaces xmlns="urn:if"><ipv4 xmlns="urn:ip" xmlns:ip="urn:ip"><mtu xmlns="urn:if">1500</mtu></ipv4></interfaces>`,
+		},
+		{
+			name:     "normalize",
+			input:    `<if:interfaces xmlns:if="urn:if"><if:interface/></if:interfaces>`,
+			fn:       NormalizeNamespaces,
+			expected: `<interfaces xmlns="urn:if" xmlns:if="urn:if"><interface/></interfaces>`,
+		},
+	}
+	for _, tc := range tt {
+		got, err := tc.fn(tc.input)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if got != tc.expected {
+			t.Errorf("%s: got %s, expected %s", tc.name, got, tc.expected)
+		}
+	}
+}
+
+func TestUnqualifiedElements(t *testing.T) {
+	paths, err := UnqualifiedElements(`<interfaces xmlns="urn:if"><interface/></interfaces><system><host-name/></system>`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"/system", "/system/host-name"}, paths); diff != "" {
+		t.Errorf("paths mismatch (-want +got):\n%s", diff)
+	}
+}
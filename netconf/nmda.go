@@ -0,0 +1,158 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Namespaces of the NMDA modules (RFC 8342, RFC 8526).
+const (
+	NMDANamespace       = "urn:ietf:params:xml:ns:yang:ietf-netconf-nmda"
+	DatastoresNamespace = "urn:ietf:params:xml:ns:yang:ietf-datastores"
+	OriginNamespace     = "urn:ietf:params:xml:ns:yang:ietf-origin"
+)
+
+// Datastores defined by RFC 8342, for use with MethodGetData.
+const (
+	DatastoreRunning     = "running"
+	DatastoreCandidate   = "candidate"
+	DatastoreStartup     = "startup"
+	DatastoreIntended    = "intended"
+	DatastoreOperational = "operational"
+)
+
+// Origin is the origin of a node of the operational datastore, the local
+// name of an ietf-origin identity.  It can be used as an XML attribute of
+// decoded structs:
+//
+//	Origin netconf.Origin `xml:"urn:ietf:params:xml:ns:yang:ietf-origin origin,attr"`
+type Origin string
+
+// Origins defined by RFC 8342.
+const (
+	OriginIntended Origin = "intended"
+	OriginDynamic  Origin = "dynamic"
+	OriginSystem   Origin = "system"
+	OriginLearned  Origin = "learned"
+	OriginDefault  Origin = "default"
+	OriginUnknown  Origin = "unknown"
+)
+
+// UnmarshalXMLAttr decodes an origin identity, dropping its prefix.
+func (o *Origin) UnmarshalXMLAttr(attr xml.Attr) error {
+	*o = parseOrigin(attr.Value)
+	return nil
+}
+
+func parseOrigin(v string) Origin {
+	v = strings.TrimSpace(v)
+	if i := strings.LastIndexByte(v, ':'); i >= 0 {
+		v = v[i+1:]
+	}
+	return Origin(v)
+}
+
+// GetDataOptions tunes MethodGetData.
+type GetDataOptions struct {
+	Filter *Filter
+	// WithOrigin requests origin metadata, for the operational datastore.
+	WithOrigin bool
+	// OriginFilter restricts the reply to nodes of the given origins.
+	OriginFilter []Origin
+	// MaxDepth limits the depth of the returned subtrees, unlimited if zero.
+	MaxDepth int
+}
+
+// MethodGetData files a NETCONF get-data request (RFC 8526) for the given
+// datastore, e.g. DatastoreOperational, with the remote host.  opts may be
+// nil.
+func MethodGetData(datastore string, opts *GetDataOptions) RawMethod {
+	if opts == nil {
+		opts = &GetDataOptions{}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<get-data xmlns="` + NMDANamespace + `" xmlns:ds="` + DatastoresNamespace + `"`)
+	if opts.WithOrigin || len(opts.OriginFilter) > 0 {
+		buf.WriteString(` xmlns:or="` + OriginNamespace + `"`)
+	}
+	buf.WriteString("><datastore>ds:" + EscapeText(datastore) + "</datastore>")
+
+	if f := opts.Filter; f != nil {
+		switch f.Type {
+		case FilterXPath:
+			buf.WriteString("<xpath-filter")
+			prefixes := make([]string, 0, len(f.Namespaces))
+			for p := range f.Namespaces {
+				prefixes = append(prefixes, p)
+			}
+			sort.Strings(prefixes)
+			for _, p := range prefixes {
+				writeAttr(&buf, xmlnsPrefix+":"+p, f.Namespaces[p])
+			}
+			buf.WriteString(">" + EscapeText(f.Select) + "</xpath-filter>")
+		default:
+			buf.WriteString("<subtree-filter>" + f.Content + "</subtree-filter>")
+		}
+	}
+	for _, o := range opts.OriginFilter {
+		buf.WriteString("<origin-filter>or:" + EscapeText(string(o)) + "</origin-filter>")
+	}
+	if opts.MaxDepth > 0 {
+		buf.WriteString("<max-depth>" + strconv.Itoa(opts.MaxDepth) + "</max-depth>")
+	}
+	if opts.WithOrigin {
+		buf.WriteString("<with-origin/>")
+	}
+	buf.WriteString("</get-data>")
+	return RawMethod(buf.String())
+}
+
+// GetData retrieves the content of datastore and returns it as a synthetic
+// root node holding the top-level elements.  opts may be nil.
+func (s *Session) GetData(ctx context.Context, datastore string, opts *GetDataOptions) (*Node, error) {
+	reply, err := s.ExecContext(ctx, MethodGetData(datastore, opts))
+	if err != nil {
+		return nil, err
+	}
+	return configRoot(reply.Data)
+}
+
+// Origin returns the origin annotated on the node, or "" if it has none.
+// Nodes without annotation inherit the origin of their parent, see
+// WalkOrigins.
+func (n *Node) Origin() Origin {
+	v, ok := n.Attr(OriginNamespace, "origin")
+	if !ok {
+		return ""
+	}
+	return parseOrigin(v)
+}
+
+// WalkOrigins calls fn for every node below root with its effective
+// origin, taking inheritance from ancestors into account.  Nodes without
+// any annotated ancestor have an empty origin.
+func WalkOrigins(root *Node, fn func(n *Node, origin Origin)) {
+	walkOrigins(root, root.Origin(), fn)
+}
+
+func walkOrigins(n *Node, inherited Origin, fn func(n *Node, origin Origin)) {
+	for _, c := range n.Children {
+		origin := c.Origin()
+		if origin == "" {
+			origin = inherited
+		}
+		fn(c, origin)
+		walkOrigins(c, origin, fn)
+	}
+}
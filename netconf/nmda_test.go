@@ -0,0 +1,90 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMethodGetData(t *testing.T) {
+	tt := []struct {
+		name     string
+		opts     *GetDataOptions
+		expected string
+	}{
+		{
+			name: "plain",
+			expected: `<get-data xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-nmda" xmlns:ds="urn:ietf:params:xml:ns:yang:ietf-datastores">` +
+				`<datastore>ds:operational</datastore></get-data>`,
+		},
+		{
+			name: "origin",
+			opts: &GetDataOptions{Filter: SubtreeFilter("<interfaces/>"), WithOrigin: true, OriginFilter: []Origin{OriginLearned}, MaxDepth: 2},
+			expected: `<get-data xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-nmda" xmlns:ds="urn:ietf:params:xml:ns:yang:ietf-datastores" xmlns:or="urn:ietf:params:xml:ns:yang:ietf-origin">` +
+				`<datastore>ds:operational</datastore><subtree-filter><interfaces/></subtree-filter>` +
+				`<origin-filter>or:learned</origin-filter><max-depth>2</max-depth><with-origin/></get-data>`,
+		},
+		{
+			name: "xpath",
+			opts: &GetDataOptions{Filter: XPathFilter("/if:interfaces", map[string]string{"if": "urn:if"})},
+			expected: `<get-data xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-nmda" xmlns:ds="urn:ietf:params:xml:ns:yang:ietf-datastores">` +
+				`<datastore>ds:operational</datastore><xpath-filter xmlns:if="urn:if">/if:interfaces</xpath-filter></get-data>`,
+		},
+	}
+	for _, tc := range tt {
+		if got := MethodGetData(DatastoreOperational, tc.opts).MarshalMethod(); got != tc.expected {
+			t.Errorf("%s: got %s, expected %s", tc.name, got, tc.expected)
+		}
+	}
+}
+
+const replyOperational = `<rpc-reply><data xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-nmda">
+<interfaces xmlns="urn:if" xmlns:or="urn:ietf:params:xml:ns:yang:ietf-origin" or:origin="or:intended">
+<interface><name>eth0</name><mtu or:origin="or:system">1500</mtu></interface>
+<interface or:origin="or:learned"><name>lo</name></interface>
+</interfaces></data></rpc-reply>`
+
+func TestSessionGetDataOrigins(t *testing.T) {
+	s, _ := newScriptedSession(nil, replyOperational)
+	root, err := s.GetData(context.Background(), DatastoreOperational, &GetDataOptions{WithOrigin: true})
+	if err != nil {
+		t.Fatalf("GetData failed: %v", err)
+	}
+
+	var got []string
+	WalkOrigins(root, func(n *Node, origin Origin) {
+		got = append(got, n.XMLName.Local+"="+string(origin))
+	})
+	expected := []string{
+		"interfaces=intended",
+		"interface=intended", "name=intended", "mtu=system",
+		"interface=learned", "name=learned",
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("origins mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOriginUnmarshalXMLAttr(t *testing.T) {
+	var v struct {
+		MTU struct {
+			Origin Origin `xml:"urn:ietf:params:xml:ns:yang:ietf-origin origin,attr"`
+			Value  int    `xml:",chardata"`
+		} `xml:"mtu"`
+	}
+	data := `<interface xmlns:or="urn:ietf:params:xml:ns:yang:ietf-origin"><mtu or:origin="or:learned">9000</mtu></interface>`
+	if err := xml.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if v.MTU.Origin != OriginLearned || v.MTU.Value != 9000 {
+		t.Errorf("unexpected result %+v", v.MTU)
+	}
+}
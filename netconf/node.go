@@ -0,0 +1,276 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	xmlnsPrefix = "xmlns"
+	xmlURL      = "http://www.w3.org/XML/1998/namespace"
+)
+
+// Node is a generic XML element tree used to inspect and compare
+// configuration and state data without modelling it in Go types.
+type Node struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr
+	Children []*Node
+	// Text is the character data of the element.  Whitespace between child
+	// elements is not kept.
+	Text string
+}
+
+// ParseNode parses an XML document into a Node tree.
+func ParseNode(data []byte) (*Node, error) {
+	nodes, err := ParseNodes(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) != 1 {
+		return nil, fmt.Errorf("expected a single root element, got %d", len(nodes))
+	}
+	return nodes[0], nil
+}
+
+// ParseNodes parses an XML fragment that may hold several top-level
+// elements, such as the content of a <config> or <data> element.
+func ParseNodes(data []byte) ([]*Node, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+
+	var roots []*Node
+	var stack []*Node
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &Node{XMLName: tok.Name}
+			if len(tok.Attr) > 0 {
+				n.Attrs = make([]xml.Attr, len(tok.Attr))
+				copy(n.Attrs, tok.Attr)
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, n)
+			} else {
+				roots = append(roots, n)
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			n := stack[len(stack)-1]
+			if len(n.Children) > 0 && strings.TrimSpace(n.Text) == "" {
+				n.Text = ""
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text += string(tok)
+			}
+		}
+	}
+	return roots, nil
+}
+
+// IsLeaf reports whether the node has no child elements.
+func (n *Node) IsLeaf() bool {
+	return len(n.Children) == 0
+}
+
+// Child returns the first child element with the given local name.
+func (n *Node) Child(local string) *Node {
+	for _, c := range n.Children {
+		if c.XMLName.Local == local {
+			return c
+		}
+	}
+	return nil
+}
+
+// ChildrenNamed returns all child elements with the given local name.
+func (n *Node) ChildrenNamed(local string) []*Node {
+	var out []*Node
+	for _, c := range n.Children {
+		if c.XMLName.Local == local {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Attr returns the value of the attribute with the given namespace and local
+// name.  An empty space matches attributes without namespace.
+func (n *Node) Attr(space, local string) (string, bool) {
+	for _, a := range n.Attrs {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetAttr sets the attribute with the given namespace and local name,
+// adding it if the node does not carry it yet.
+func (n *Node) SetAttr(space, local, value string) {
+	for i, a := range n.Attrs {
+		if a.Name.Space == space && a.Name.Local == local {
+			n.Attrs[i].Value = value
+			return
+		}
+	}
+	n.Attrs = append(n.Attrs, xml.Attr{Name: xml.Name{Space: space, Local: local}, Value: value})
+}
+
+// RemoveAttr removes the attribute with the given namespace and local name.
+func (n *Node) RemoveAttr(space, local string) {
+	for i, a := range n.Attrs {
+		if a.Name.Space == space && a.Name.Local == local {
+			n.Attrs = append(n.Attrs[:i], n.Attrs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Value returns the trimmed character data of the node.
+func (n *Node) Value() string {
+	return strings.TrimSpace(n.Text)
+}
+
+// Clone returns a deep copy of the node.
+func (n *Node) Clone() *Node {
+	c := &Node{XMLName: n.XMLName, Text: n.Text}
+	if n.Attrs != nil {
+		c.Attrs = append([]xml.Attr(nil), n.Attrs...)
+	}
+	for _, child := range n.Children {
+		c.Children = append(c.Children, child.Clone())
+	}
+	return c
+}
+
+// String returns the XML encoding of the node.
+func (n *Node) String() string {
+	var buf bytes.Buffer
+	n.write(&buf, "", nil)
+	return buf.String()
+}
+
+// MarshalMethod allows a Node to be sent as an RPC method.
+func (n *Node) MarshalMethod() string {
+	return n.String()
+}
+
+// write encodes the node.  xmlns is the default namespace in scope and
+// prefixes maps namespace URIs to the prefixes declared by ancestors.
+func (n *Node) write(buf *bytes.Buffer, xmlns string, prefixes map[string]string) {
+	buf.WriteByte('<')
+	buf.WriteString(n.XMLName.Local)
+	if n.XMLName.Space != xmlns {
+		xmlns = n.XMLName.Space
+		buf.WriteString(` xmlns="`)
+		xml.EscapeText(buf, []byte(xmlns))
+		buf.WriteByte('"')
+	}
+
+	// Keep the prefixes declared on this element so that prefixed attributes
+	// are written the way the document declared them.
+	scoped := false
+	for _, a := range n.Attrs {
+		if a.Name.Space != xmlnsPrefix {
+			continue
+		}
+		if !scoped {
+			prefixes = copyPrefixes(prefixes)
+			scoped = true
+		}
+		prefixes[a.Value] = a.Name.Local
+		writeAttr(buf, xmlnsPrefix+":"+a.Name.Local, a.Value)
+	}
+
+	for _, a := range n.Attrs {
+		switch {
+		case a.Name.Space == xmlnsPrefix:
+		case a.Name.Space == "" && a.Name.Local == xmlnsPrefix:
+		case a.Name.Space == "":
+			writeAttr(buf, a.Name.Local, a.Value)
+		case a.Name.Space == xmlURL:
+			writeAttr(buf, "xml:"+a.Name.Local, a.Value)
+		default:
+			prefix, ok := prefixes[a.Name.Space]
+			if !ok {
+				if !scoped {
+					prefixes = copyPrefixes(prefixes)
+					scoped = true
+				}
+				prefix = newPrefix(a.Name.Space, prefixes)
+				prefixes[a.Name.Space] = prefix
+				writeAttr(buf, xmlnsPrefix+":"+prefix, a.Name.Space)
+			}
+			writeAttr(buf, prefix+":"+a.Name.Local, a.Value)
+		}
+	}
+
+	if len(n.Children) == 0 && n.Text == "" {
+		buf.WriteString("/>")
+		return
+	}
+	buf.WriteByte('>')
+	textEscaper.WriteString(buf, n.Text)
+	for _, c := range n.Children {
+		c.write(buf, xmlns, prefixes)
+	}
+	buf.WriteString("</")
+	buf.WriteString(n.XMLName.Local)
+	buf.WriteByte('>')
+}
+
+func writeAttr(buf *bytes.Buffer, name, value string) {
+	buf.WriteByte(' ')
+	buf.WriteString(name)
+	buf.WriteString(`="`)
+	xml.EscapeText(buf, []byte(value))
+	buf.WriteByte('"')
+}
+
+// textEscaper escapes character data.  Unlike xml.EscapeText it leaves line
+// breaks and tabs alone so multi-line text stays readable.
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+
+// newPrefix returns the prefix to declare for space: nc for the NETCONF
+// namespace, as is customary for the operation attribute, else a generated
+// one.
+func newPrefix(space string, prefixes map[string]string) string {
+	prefix := fmt.Sprintf("ns%d", len(prefixes))
+	if space != BaseNamespace {
+		return prefix
+	}
+	for _, p := range prefixes {
+		if p == "nc" {
+			return prefix
+		}
+	}
+	return "nc"
+}
+
+func copyPrefixes(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m)+1)
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
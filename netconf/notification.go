@@ -0,0 +1,116 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"time"
+)
+
+// Namespaces of the modules describing event streams.
+const (
+	// NotificationStreamsNamespace is the namespace of the RFC 5277 stream
+	// list in /netconf/streams.
+	NotificationStreamsNamespace = "urn:ietf:params:xml:ns:netmod:notification"
+	// SubscribedNotificationsNamespace is the namespace of the RFC 8639
+	// stream list in /streams.
+	SubscribedNotificationsNamespace = "urn:ietf:params:xml:ns:yang:ietf-subscribed-notifications"
+)
+
+// NotificationStream describes an event stream offered by the server.
+type NotificationStream struct {
+	Name        string
+	Description string
+	// ReplaySupport reports whether the stream can replay past events.
+	ReplaySupport bool
+	// ReplayLogCreationTime is the time of the oldest event available for
+	// replay, zero if unknown.
+	ReplayLogCreationTime time.Time
+	// ReplayLogAgedTime, if known, is the time events were last dropped
+	// from the replay log.
+	ReplayLogAgedTime time.Time
+}
+
+var (
+	streamsFilter = SubtreeFilter(`<netconf xmlns="` + NotificationStreamsNamespace + `"><streams/></netconf>`)
+	// subscribedStreamsFilter selects the streams of servers implementing
+	// ietf-subscribed-notifications instead of RFC 5277.
+	subscribedStreamsFilter = SubtreeFilter(`<streams xmlns="` + SubscribedNotificationsNamespace + `"/>`)
+)
+
+// NotificationStreams returns the event streams the server offers.  The
+// RFC 5277 stream list is queried first; if the server does not provide
+// it, the RFC 8639 list of ietf-subscribed-notifications is used.
+func (s *Session) NotificationStreams(ctx context.Context) ([]NotificationStream, error) {
+	streams, err := s.queryStreams(ctx, streamsFilter)
+	if err == nil && len(streams) > 0 {
+		return streams, nil
+	}
+	if fallback, ferr := s.queryStreams(ctx, subscribedStreamsFilter); ferr == nil {
+		return fallback, nil
+	}
+	return streams, err
+}
+
+func (s *Session) queryStreams(ctx context.Context, filter *Filter) ([]NotificationStream, error) {
+	reply, err := s.ExecContext(ctx, MethodGetFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+	root, err := configRoot(reply.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	var streams []NotificationStream
+	collectStreams(root, &streams)
+	return streams, nil
+}
+
+// collectStreams appends the <stream> entries below n to streams.  RFC 5277
+// and RFC 8639 name the leaves differently.
+func collectStreams(n *Node, streams *[]NotificationStream) {
+	for _, c := range n.Children {
+		if c.XMLName.Local != "stream" {
+			collectStreams(c, streams)
+			continue
+		}
+		st := NotificationStream{
+			Name:        childValue(c, "name"),
+			Description: childValue(c, "description"),
+		}
+		switch {
+		case c.Child("replaySupport") != nil:
+			st.ReplaySupport = childValue(c, "replaySupport") == "true"
+			st.ReplayLogCreationTime = parseStreamTime(childValue(c, "replayLogCreationTime"))
+			st.ReplayLogAgedTime = parseStreamTime(childValue(c, "replayLogAgedTime"))
+		default:
+			// replay-support is an empty leaf in RFC 8639.
+			st.ReplaySupport = c.Child("replay-support") != nil
+			st.ReplayLogCreationTime = parseStreamTime(childValue(c, "replay-log-creation-time"))
+			st.ReplayLogAgedTime = parseStreamTime(childValue(c, "replay-log-aged-time"))
+		}
+		*streams = append(*streams, st)
+	}
+}
+
+func childValue(n *Node, local string) string {
+	if c := n.Child(local); c != nil {
+		return c.Value()
+	}
+	return ""
+}
+
+// parseStreamTime parses a YANG date-and-time, returning the zero time if
+// it is missing or malformed.
+func parseStreamTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
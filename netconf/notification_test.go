@@ -0,0 +1,75 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNotificationStreams(t *testing.T) {
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tt := []struct {
+		name     string
+		replies  []string
+		expected []NotificationStream
+		requests int
+	}{
+		{
+			name: "rfc5277",
+			replies: []string{`<rpc-reply><data><netconf xmlns="urn:ietf:params:xml:ns:netmod:notification"><streams>
+<stream><name>NETCONF</name><description>default</description><replaySupport>true</replaySupport><replayLogCreationTime>2020-01-02T03:04:05Z</replayLogCreationTime></stream>
+<stream><name>SNMP</name><replaySupport>false</replaySupport></stream>
+</streams></netconf></data></rpc-reply>`},
+			expected: []NotificationStream{
+				{Name: "NETCONF", Description: "default", ReplaySupport: true, ReplayLogCreationTime: created},
+				{Name: "SNMP"},
+			},
+			requests: 1,
+		},
+		{
+			name: "rfc8639",
+			replies: []string{
+				replyError("operation-not-supported"),
+				`<rpc-reply><data><streams xmlns="urn:ietf:params:xml:ns:yang:ietf-subscribed-notifications">
+<stream><name>NETCONF</name><replay-support/><replay-log-creation-time>2020-01-02T03:04:05Z</replay-log-creation-time></stream>
+</streams></data></rpc-reply>`,
+			},
+			expected: []NotificationStream{
+				{Name: "NETCONF", ReplaySupport: true, ReplayLogCreationTime: created},
+			},
+			requests: 2,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s, trans := newScriptedSession(nil, tc.replies...)
+			streams, err := s.NotificationStreams(context.Background())
+			if err != nil {
+				t.Fatalf("NotificationStreams failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, streams); diff != "" {
+				t.Errorf("streams mismatch (-want +got):\n%s", diff)
+			}
+			if len(trans.sent) != tc.requests {
+				t.Errorf("expected %d requests, got %d", tc.requests, len(trans.sent))
+			}
+		})
+	}
+}
+
+func TestNotificationStreamsError(t *testing.T) {
+	s, _ := newScriptedSession(nil, replyError("operation-not-supported"), replyError("operation-not-supported"))
+	if _, err := s.NotificationStreams(context.Background()); err == nil {
+		t.Error("expected error without stream lists")
+	}
+}
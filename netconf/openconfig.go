@@ -0,0 +1,189 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"time"
+)
+
+// Namespaces of the OpenConfig models read by the OpenConfig getters.
+const (
+	OpenConfigInterfacesNamespace = "http://openconfig.net/yang/interfaces"
+	OpenConfigLLDPNamespace       = "http://openconfig.net/yang/lldp"
+	OpenConfigSystemNamespace     = "http://openconfig.net/yang/system"
+)
+
+// InterfaceState is the operational state of an interface from
+// openconfig-interfaces.
+type InterfaceState struct {
+	Name        string `xml:"name"`
+	Type        string `xml:"state>type"`
+	Description string `xml:"state>description"`
+	MTU         int    `xml:"state>mtu"`
+	Enabled     bool   `xml:"state>enabled"`
+	// AdminStatus is UP, DOWN or TESTING.
+	AdminStatus string `xml:"state>admin-status"`
+	// OperStatus is UP, DOWN, TESTING, UNKNOWN, DORMANT, NOT_PRESENT or
+	// LOWER_LAYER_DOWN.
+	OperStatus string `xml:"state>oper-status"`
+	// LastChange is the time of the last change of OperStatus in
+	// nanoseconds since the Unix epoch.
+	LastChange uint64            `xml:"state>last-change"`
+	Counters   InterfaceCounters `xml:"state>counters"`
+}
+
+// Up reports whether the interface is operationally up.
+func (i *InterfaceState) Up() bool {
+	return i.OperStatus == "UP"
+}
+
+// InterfaceCounters are the counters of an interface.
+type InterfaceCounters struct {
+	InOctets           uint64 `xml:"in-octets"`
+	InUnicastPkts      uint64 `xml:"in-unicast-pkts"`
+	InBroadcastPkts    uint64 `xml:"in-broadcast-pkts"`
+	InMulticastPkts    uint64 `xml:"in-multicast-pkts"`
+	InDiscards         uint64 `xml:"in-discards"`
+	InErrors           uint64 `xml:"in-errors"`
+	InFCSErrors        uint64 `xml:"in-fcs-errors"`
+	OutOctets          uint64 `xml:"out-octets"`
+	OutUnicastPkts     uint64 `xml:"out-unicast-pkts"`
+	OutBroadcastPkts   uint64 `xml:"out-broadcast-pkts"`
+	OutMulticastPkts   uint64 `xml:"out-multicast-pkts"`
+	OutDiscards        uint64 `xml:"out-discards"`
+	OutErrors          uint64 `xml:"out-errors"`
+	CarrierTransitions uint64 `xml:"carrier-transitions"`
+}
+
+// LLDPNeighbor is a neighbor discovered by LLDP, from openconfig-lldp.
+type LLDPNeighbor struct {
+	// Interface is the local interface the neighbor was discovered on.
+	Interface         string `xml:"-"`
+	ID                string `xml:"id"`
+	SystemName        string `xml:"state>system-name"`
+	SystemDescription string `xml:"state>system-description"`
+	ChassisID         string `xml:"state>chassis-id"`
+	ChassisIDType     string `xml:"state>chassis-id-type"`
+	PortID            string `xml:"state>port-id"`
+	PortIDType        string `xml:"state>port-id-type"`
+	PortDescription   string `xml:"state>port-description"`
+	ManagementAddress string `xml:"state>management-address"`
+}
+
+// SystemState is the system state from openconfig-system.
+type SystemState struct {
+	Hostname        string
+	DomainName      string
+	SoftwareVersion string
+	CurrentTime     time.Time
+	BootTime        time.Time
+	// PhysicalMemory and ReservedMemory are in bytes.
+	PhysicalMemory uint64
+	ReservedMemory uint64
+}
+
+// Uptime returns the time since the system booted, or zero if either time
+// is unknown.
+func (st *SystemState) Uptime() time.Duration {
+	if st.BootTime.IsZero() || st.CurrentTime.IsZero() {
+		return 0
+	}
+	return st.CurrentTime.Sub(st.BootTime)
+}
+
+// OpenConfigInterfaces returns the state of the interfaces of the device.
+func (s *Session) OpenConfigInterfaces(ctx context.Context) ([]InterfaceState, error) {
+	return s.openConfigInterfaces(ctx, "<interface><name/><state/></interface>")
+}
+
+// OpenConfigInterface returns the state of the named interface, or nil if
+// the device has no such interface.
+func (s *Session) OpenConfigInterface(ctx context.Context, name string) (*InterfaceState, error) {
+	ifaces, err := s.openConfigInterfaces(ctx, "<interface><name>"+EscapeText(name)+"</name><state/></interface>")
+	if err != nil || len(ifaces) == 0 {
+		return nil, err
+	}
+	return &ifaces[0], nil
+}
+
+func (s *Session) openConfigInterfaces(ctx context.Context, selection string) ([]InterfaceState, error) {
+	var data struct {
+		Interfaces []InterfaceState `xml:"interfaces>interface"`
+	}
+	filter := `<interfaces xmlns="` + OpenConfigInterfacesNamespace + `">` + selection + `</interfaces>`
+	if err := s.getOpenConfig(ctx, filter, &data); err != nil {
+		return nil, err
+	}
+	return data.Interfaces, nil
+}
+
+// OpenConfigLLDPNeighbors returns the LLDP neighbors of all interfaces of
+// the device.
+func (s *Session) OpenConfigLLDPNeighbors(ctx context.Context) ([]LLDPNeighbor, error) {
+	var data struct {
+		Interfaces []struct {
+			Name      string         `xml:"name"`
+			Neighbors []LLDPNeighbor `xml:"neighbors>neighbor"`
+		} `xml:"lldp>interfaces>interface"`
+	}
+	filter := `<lldp xmlns="` + OpenConfigLLDPNamespace + `"><interfaces><interface><name/>` +
+		`<neighbors><neighbor><id/><state/></neighbor></neighbors></interface></interfaces></lldp>`
+	if err := s.getOpenConfig(ctx, filter, &data); err != nil {
+		return nil, err
+	}
+
+	var neighbors []LLDPNeighbor
+	for _, iface := range data.Interfaces {
+		for _, n := range iface.Neighbors {
+			n.Interface = iface.Name
+			neighbors = append(neighbors, n)
+		}
+	}
+	return neighbors, nil
+}
+
+// OpenConfigSystemState returns the system state of the device.
+func (s *Session) OpenConfigSystemState(ctx context.Context) (*SystemState, error) {
+	var data struct {
+		Hostname        string `xml:"system>state>hostname"`
+		DomainName      string `xml:"system>state>domain-name"`
+		SoftwareVersion string `xml:"system>state>software-version"`
+		CurrentTime     string `xml:"system>state>current-datetime"`
+		// BootTime is in nanoseconds since the Unix epoch.
+		BootTime       uint64 `xml:"system>state>boot-time"`
+		PhysicalMemory uint64 `xml:"system>memory>state>physical"`
+		ReservedMemory uint64 `xml:"system>memory>state>reserved"`
+	}
+	filter := `<system xmlns="` + OpenConfigSystemNamespace + `"><state/><memory><state/></memory></system>`
+	if err := s.getOpenConfig(ctx, filter, &data); err != nil {
+		return nil, err
+	}
+
+	st := &SystemState{
+		Hostname:        data.Hostname,
+		DomainName:      data.DomainName,
+		SoftwareVersion: data.SoftwareVersion,
+		CurrentTime:     parseStreamTime(data.CurrentTime),
+		PhysicalMemory:  data.PhysicalMemory,
+		ReservedMemory:  data.ReservedMemory,
+	}
+	if data.BootTime > 0 {
+		st.BootTime = time.Unix(0, int64(data.BootTime)).UTC()
+	}
+	return st, nil
+}
+
+// getOpenConfig retrieves the state selected by the subtree filter and
+// decodes it into v.
+func (s *Session) getOpenConfig(ctx context.Context, filter string, v interface{}) error {
+	reply, err := s.ExecContext(ctx, MethodGetFilter(SubtreeFilter(filter)))
+	if err != nil {
+		return err
+	}
+	return reply.Decode(v)
+}
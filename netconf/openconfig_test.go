@@ -0,0 +1,131 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOpenConfigInterfaces(t *testing.T) {
+	s, trans := newScriptedSession(nil, factsReply(`<data><interfaces xmlns="http://openconfig.net/yang/interfaces">
+<interface><name>eth0</name><state><type xmlns:ianaift="urn:ietf:params:xml:ns:yang:iana-if-type">ianaift:ethernetCsmacd</type>
+<mtu>1500</mtu><enabled>true</enabled><admin-status>UP</admin-status><oper-status>UP</oper-status>
+<last-change>1577836800000000000</last-change>
+<counters><in-octets>100</in-octets><in-errors>2</in-errors><out-octets>200</out-octets><carrier-transitions>3</carrier-transitions></counters>
+</state></interface>
+<interface><name>eth1</name><state><enabled>false</enabled><admin-status>DOWN</admin-status><oper-status>DOWN</oper-status></state></interface>
+</interfaces></data>`))
+
+	ifaces, err := s.OpenConfigInterfaces(context.Background())
+	if err != nil {
+		t.Fatalf("OpenConfigInterfaces failed: %v", err)
+	}
+	expected := []InterfaceState{
+		{
+			Name:        "eth0",
+			Type:        "ianaift:ethernetCsmacd",
+			MTU:         1500,
+			Enabled:     true,
+			AdminStatus: "UP",
+			OperStatus:  "UP",
+			LastChange:  1577836800000000000,
+			Counters:    InterfaceCounters{InOctets: 100, InErrors: 2, OutOctets: 200, CarrierTransitions: 3},
+		},
+		{Name: "eth1", AdminStatus: "DOWN", OperStatus: "DOWN"},
+	}
+	if diff := cmp.Diff(expected, ifaces); diff != "" {
+		t.Errorf("interfaces mismatch (-want +got):\n%s", diff)
+	}
+	if !ifaces[0].Up() || ifaces[1].Up() {
+		t.Errorf("unexpected Up results")
+	}
+	if !strings.Contains(trans.sent[0], `<interfaces xmlns="http://openconfig.net/yang/interfaces"><interface><name/><state/></interface></interfaces>`) {
+		t.Errorf("unexpected request %s", trans.sent[0])
+	}
+}
+
+func TestOpenConfigInterface(t *testing.T) {
+	s, trans := newScriptedSession(nil,
+		factsReply(`<data><interfaces xmlns="http://openconfig.net/yang/interfaces"><interface><name>ge-0/0/0</name></interface></interfaces></data>`),
+		factsReply(`<data/>`))
+
+	iface, err := s.OpenConfigInterface(context.Background(), "ge-0/0/0")
+	if err != nil || iface == nil || iface.Name != "ge-0/0/0" {
+		t.Fatalf("unexpected result %+v, %v", iface, err)
+	}
+	if !strings.Contains(trans.sent[0], "<interface><name>ge-0/0/0</name><state/></interface>") {
+		t.Errorf("unexpected request %s", trans.sent[0])
+	}
+	if iface, err := s.OpenConfigInterface(context.Background(), "missing"); err != nil || iface != nil {
+		t.Errorf("expected no interface, got %+v, %v", iface, err)
+	}
+}
+
+func TestOpenConfigLLDPNeighbors(t *testing.T) {
+	s, _ := newScriptedSession(nil, factsReply(`<data><lldp xmlns="http://openconfig.net/yang/lldp"><interfaces>
+<interface><name>eth0</name><neighbors>
+<neighbor><id>n1</id><state><system-name>sw1</system-name><chassis-id>00:11:22:33:44:55</chassis-id>
+<chassis-id-type>MAC_ADDRESS</chassis-id-type><port-id>Gi1/0/1</port-id><port-id-type>INTERFACE_NAME</port-id-type></state></neighbor>
+<neighbor><id>n2</id><state><system-name>sw2</system-name></state></neighbor>
+</neighbors></interface>
+<interface><name>eth1</name></interface>
+<interface><name>eth2</name><neighbors><neighbor><id>n3</id><state><system-name>sw3</system-name><management-address>192.0.2.3</management-address></state></neighbor></neighbors></interface>
+</interfaces></lldp></data>`))
+
+	neighbors, err := s.OpenConfigLLDPNeighbors(context.Background())
+	if err != nil {
+		t.Fatalf("OpenConfigLLDPNeighbors failed: %v", err)
+	}
+	expected := []LLDPNeighbor{
+		{Interface: "eth0", ID: "n1", SystemName: "sw1", ChassisID: "00:11:22:33:44:55", ChassisIDType: "MAC_ADDRESS", PortID: "Gi1/0/1", PortIDType: "INTERFACE_NAME"},
+		{Interface: "eth0", ID: "n2", SystemName: "sw2"},
+		{Interface: "eth2", ID: "n3", SystemName: "sw3", ManagementAddress: "192.0.2.3"},
+	}
+	if diff := cmp.Diff(expected, neighbors); diff != "" {
+		t.Errorf("neighbors mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOpenConfigSystemState(t *testing.T) {
+	s, _ := newScriptedSession(nil, factsReply(`<data><system xmlns="http://openconfig.net/yang/system">
+<state><hostname>r1</hostname><domain-name>example.net</domain-name><software-version>1.2.3</software-version>
+<current-datetime>2020-01-01T01:00:00Z</current-datetime><boot-time>1577836800000000000</boot-time></state>
+<memory><state><physical>8589934592</physical><reserved>1024</reserved></state></memory>
+</system></data>`))
+
+	st, err := s.OpenConfigSystemState(context.Background())
+	if err != nil {
+		t.Fatalf("OpenConfigSystemState failed: %v", err)
+	}
+	expected := &SystemState{
+		Hostname:        "r1",
+		DomainName:      "example.net",
+		SoftwareVersion: "1.2.3",
+		CurrentTime:     time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+		BootTime:        time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		PhysicalMemory:  8589934592,
+		ReservedMemory:  1024,
+	}
+	if diff := cmp.Diff(expected, st); diff != "" {
+		t.Errorf("system state mismatch (-want +got):\n%s", diff)
+	}
+	if st.Uptime() != time.Hour {
+		t.Errorf("unexpected uptime %v", st.Uptime())
+	}
+}
+
+func TestOpenConfigError(t *testing.T) {
+	s, _ := newScriptedSession(nil, replyError("operation-not-supported"))
+	if _, err := s.OpenConfigSystemState(context.Background()); err == nil {
+		t.Errorf("expected error")
+	}
+}
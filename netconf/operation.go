@@ -0,0 +1,71 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// BaseNamespace is the namespace of the NETCONF protocol, which qualifies
+// the operation attribute of edit-config payloads.
+const BaseNamespace = "urn:ietf:params:xml:ns:netconf:base:1.0"
+
+// Operation is the operation applied to a configuration node by
+// edit-config, see RFC 6241 section 7.2.
+type Operation string
+
+// Operations of edit-config.
+const (
+	OperationMerge   Operation = "merge"
+	OperationReplace Operation = "replace"
+	OperationCreate  Operation = "create"
+	OperationDelete  Operation = "delete"
+	OperationRemove  Operation = "remove"
+)
+
+// NewNode returns an element in namespace space with the given children.
+func NewNode(space, local string, children ...*Node) *Node {
+	return &Node{XMLName: xml.Name{Space: space, Local: local}, Children: children}
+}
+
+// NewLeaf returns an element in namespace space holding value.
+func NewLeaf(space, local, value string) *Node {
+	return &Node{XMLName: xml.Name{Space: space, Local: local}, Text: value}
+}
+
+// NewListEntry returns the entry of list identified by keys, given as pairs
+// of key leaf names and values, e.g. NewListEntry(ns, "interface", "name",
+// "ge-0/0/0").  It is typically given an operation with WithOperation.
+func NewListEntry(space, list string, keys ...string) (*Node, error) {
+	if len(keys) == 0 || len(keys)%2 != 0 {
+		return nil, fmt.Errorf("netconf: list %s: keys must be given as name value pairs", list)
+	}
+	n := NewNode(space, list)
+	for i := 0; i < len(keys); i += 2 {
+		n.Children = append(n.Children, NewLeaf(space, keys[i], keys[i+1]))
+	}
+	return n, nil
+}
+
+// WithOperation sets the nc:operation attribute of n and returns n.  The nc
+// prefix is declared on n unless an ancestor already declares it.
+func (n *Node) WithOperation(op Operation) *Node {
+	n.SetOperation(op)
+	return n
+}
+
+// SetOperation sets the nc:operation attribute of n.
+func (n *Node) SetOperation(op Operation) {
+	n.SetAttr(BaseNamespace, "operation", string(op))
+}
+
+// Operation returns the nc:operation attribute of n, if any.
+func (n *Node) Operation() (Operation, bool) {
+	op, ok := n.Attr(BaseNamespace, "operation")
+	return Operation(op), ok
+}
@@ -0,0 +1,64 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOperationNodes(t *testing.T) {
+	const ns = "urn:if"
+	entry, err := NewListEntry(ns, "interface", "name", "ge-0/0/0")
+	if err != nil {
+		t.Fatalf("NewListEntry failed: %v", err)
+	}
+	tt := []struct {
+		name     string
+		node     *Node
+		expected string
+	}{
+		{
+			name:     "delete list entry",
+			node:     NewNode(ns, "interfaces", entry.WithOperation(OperationDelete)),
+			expected: `<interfaces xmlns="urn:if"><interface xmlns:nc="` + BaseNamespace + `" nc:operation="delete"><name>ge-0/0/0</name></interface></interfaces>`,
+		},
+		{
+			name: "siblings",
+			node: NewNode(ns, "system",
+				NewLeaf(ns, "host-name", "r1").WithOperation(OperationReplace),
+				NewNode(ns, "syslog").WithOperation(OperationRemove),
+			),
+			expected: `<system xmlns="urn:if"><host-name xmlns:nc="` + BaseNamespace + `" nc:operation="replace">r1</host-name>` +
+				`<syslog xmlns:nc="` + BaseNamespace + `" nc:operation="remove"/></system>`,
+		},
+	}
+	for _, tc := range tt {
+		if diff := cmp.Diff(tc.expected, tc.node.String()); diff != "" {
+			t.Errorf("%s: mismatch (-want +got):\n%s", tc.name, diff)
+		}
+	}
+
+	root := NewNode(ns, "system", NewLeaf(ns, "host-name", "r1").WithOperation(OperationCreate))
+	root.SetAttr(xmlnsPrefix, "nc", BaseNamespace)
+	expected := `<system xmlns="urn:if" xmlns:nc="` + BaseNamespace + `"><host-name nc:operation="create">r1</host-name></system>`
+	if diff := cmp.Diff(expected, root.String()); diff != "" {
+		t.Errorf("declared prefix mismatch (-want +got):\n%s", diff)
+	}
+
+	parsed, err := ParseNode([]byte(root.String()))
+	if err != nil {
+		t.Fatalf("ParseNode failed: %v", err)
+	}
+	if op, ok := parsed.Children[0].Operation(); !ok || op != OperationCreate {
+		t.Errorf("unexpected operation %q", op)
+	}
+	if _, err := NewListEntry(ns, "interface", "name"); err == nil {
+		t.Error("expected error for odd number of keys")
+	}
+}
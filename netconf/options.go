@@ -0,0 +1,371 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Logger receives diagnostic messages.  *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// SessionConfig holds the settings used by Dial to establish a session.  It
+// is usually built from Options.
+type SessionConfig struct {
+	// SSHConfig configures NETCONF over SSH, the default transport.
+	SSHConfig *ssh.ClientConfig
+	// TLSConfig, if set, selects NETCONF over TLS.
+	TLSConfig *tls.Config
+	// Timeout bounds connecting, the transport handshake and the hello
+	// exchange.  Zero waits as long as the context allows.
+	Timeout time.Duration
+	// AttemptTimeout and ResolveAll tune the failover of DialAny, see
+	// WithAttemptTimeout and WithResolveAll.
+	AttemptTimeout time.Duration
+	ResolveAll     bool
+	// HappyEyeballs, if set, races the connections to the addresses of
+	// host names, see WithHappyEyeballs.
+	HappyEyeballs time.Duration
+	// DialFunc and Resolver, if set, replace the default dialer and
+	// resolver, see WithDialFunc and WithResolver.
+	DialFunc DialFunc
+	Resolver *net.Resolver
+	// SocketOptions, if set, configures the connections opened without a
+	// DialFunc, see WithSocketOptions.
+	SocketOptions *SocketOptions
+	// Chaos, if set, injects faults into the sessions, see WithChaos.
+	Chaos *Chaos
+	// Deadlines bounds every RPC of the session, see Session.Deadlines.
+	Deadlines Deadlines
+	Logger    Logger
+	Profile   *Profile
+	// Framing, if set to FramingEOM, restricts the session to NETCONF 1.0
+	// and end-of-message framing for servers with a broken 1.1
+	// implementation.  By default the framing is negotiated.
+	Framing Framing
+	Limiter *RateLimiter
+	Queue   *RPCQueue
+	// Bandwidth, if set, throttles the bytes written to the connection.
+	Bandwidth   *BandwidthLimiter
+	RetryPolicy *RetryPolicy
+	// Capabilities lists server capabilities the session requires, see
+	// NewStrictSession.
+	Capabilities []string
+	Interceptors []Interceptor
+	RPCAttrs     []xml.Attr
+	ReadOnly     bool
+	// Credentials, if set, supplies the credentials of the session when it
+	// is dialled.
+	Credentials CredentialProvider
+}
+
+// Option configures a SessionConfig.
+type Option func(*SessionConfig)
+
+// NewSessionConfig returns a SessionConfig with opts applied.
+func NewSessionConfig(opts ...Option) *SessionConfig {
+	c := &SessionConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithSSHConfig sets the SSH client configuration.
+func WithSSHConfig(config *ssh.ClientConfig) Option {
+	return func(c *SessionConfig) { c.SSHConfig = config }
+}
+
+// WithPassword authenticates over SSH with user and password.  Host keys
+// are checked against ~/.ssh/known_hosts unless an earlier SSH
+// configuration has a HostKeyCallback, see also WithInsecureIgnoreHostKey.
+func WithPassword(user, password string) Option {
+	return func(c *SessionConfig) {
+		config := ssh.ClientConfig{}
+		if c.SSHConfig != nil {
+			config = *c.SSHConfig
+		}
+		if config.HostKeyCallback == nil {
+			config.HostKeyCallback = checkKnownHosts
+		}
+		config.User = user
+		config.Auth = append([]ssh.AuthMethod{ssh.Password(password)}, config.Auth...)
+		c.SSHConfig = &config
+	}
+}
+
+// WithPasswordCallback authenticates over SSH with user and the password
+// returned by password.  It is called every time the session is dialled, so
+// rotated passwords are picked up on reconnect without being stored.  Host
+// keys are checked against ~/.ssh/known_hosts unless an earlier SSH
+// configuration has a HostKeyCallback.
+func WithPasswordCallback(user string, password func() (string, error)) Option {
+	return func(c *SessionConfig) {
+		config := ssh.ClientConfig{}
+		if c.SSHConfig != nil {
+			config = *c.SSHConfig
+		}
+		if config.HostKeyCallback == nil {
+			config.HostKeyCallback = checkKnownHosts
+		}
+		config.User = user
+		config.Auth = append([]ssh.AuthMethod{ssh.PasswordCallback(password)}, config.Auth...)
+		c.SSHConfig = &config
+	}
+}
+
+// WithInsecureIgnoreHostKey accepts any SSH host key.  It is meant for labs
+// and tests only, as the server is not authenticated.
+func WithInsecureIgnoreHostKey() Option {
+	return func(c *SessionConfig) {
+		config := ssh.ClientConfig{}
+		if c.SSHConfig != nil {
+			config = *c.SSHConfig
+		}
+		config.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+		c.SSHConfig = &config
+	}
+}
+
+// WithTLSConfig selects NETCONF over TLS with the given configuration.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *SessionConfig) { c.TLSConfig = config }
+}
+
+// WithTimeout bounds the establishment of the session.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *SessionConfig) { c.Timeout = timeout }
+}
+
+// WithRPCDeadlines bounds the time every RPC of the session may spend
+// writing its request and reading its reply.
+func WithRPCDeadlines(d Deadlines) Option {
+	return func(c *SessionConfig) { c.Deadlines = d }
+}
+
+// WithLogger sets the logger of the session.
+func WithLogger(l Logger) Option {
+	return func(c *SessionConfig) { c.Logger = l }
+}
+
+// WithProfile selects vendor specific behaviour, such as the default port
+// and SSH subsystem.
+func WithProfile(p *Profile) Option {
+	return func(c *SessionConfig) { c.Profile = p }
+}
+
+// WithFraming fixes the message framing instead of negotiating it.
+func WithFraming(f Framing) Option {
+	return func(c *SessionConfig) { c.Framing = f }
+}
+
+// WithRateLimiter throttles the RPCs of the session.
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(c *SessionConfig) { c.Limiter = l }
+}
+
+// WithBandwidthLimit throttles the bytes written to the connection of the
+// session.
+func WithBandwidthLimit(l *BandwidthLimiter) Option {
+	return func(c *SessionConfig) { c.Bandwidth = l }
+}
+
+// WithRPCQueue serializes the RPCs of the session by priority, see
+// WithPriority.
+func WithRPCQueue(q *RPCQueue) Option {
+	return func(c *SessionConfig) { c.Queue = q }
+}
+
+// WithRetryPolicy retries failed RPCs as set out by p.
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return func(c *SessionConfig) { c.RetryPolicy = p }
+}
+
+// WithCapabilities fails session establishment unless the server announces
+// the given capabilities.
+func WithCapabilities(capabilities ...string) Option {
+	return func(c *SessionConfig) { c.Capabilities = append(c.Capabilities, capabilities...) }
+}
+
+// WithInterceptors adds interceptors wrapping every RPC of the session.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(c *SessionConfig) { c.Interceptors = append(c.Interceptors, interceptors...) }
+}
+
+// WithReadOnly rejects every state changing operation of the session with
+// ErrReadOnlySession, see Session.ReadOnly.
+func WithReadOnly() Option {
+	return func(c *SessionConfig) { c.ReadOnly = true }
+}
+
+// WithRPCAttrs adds attributes, such as a vendor namespace declaration, to
+// the <rpc> element of every request, see RPCMessage.Attrs.
+func WithRPCAttrs(attrs ...xml.Attr) Option {
+	return func(c *SessionConfig) { c.RPCAttrs = append(c.RPCAttrs, attrs...) }
+}
+
+// Dial establishes a session to target, a host or host:port, configured by
+// opts.  Without a port the default port of the profile, of NETCONF over
+// TLS or of NETCONF over SSH is used.
+func Dial(target string, opts ...Option) (*Session, error) {
+	return NewSessionConfig(opts...).DialContext(context.Background(), target)
+}
+
+// DialContext is like Dial, but ctx bounds the establishment of the
+// session.
+func DialContext(ctx context.Context, target string, opts ...Option) (*Session, error) {
+	return NewSessionConfig(opts...).DialContext(ctx, target)
+}
+
+// DialContext establishes a session to target with the configuration.
+func (c *SessionConfig) DialContext(ctx context.Context, target string) (*Session, error) {
+	if c.Credentials != nil {
+		config, err := c.resolveCredentials(ctx, targetDevice(target))
+		if err != nil {
+			return nil, err
+		}
+		return config.DialContext(ctx, target)
+	}
+	target = c.address(target)
+	return c.dial(ctx, target, targetHost(target))
+}
+
+// dial establishes a session to target, a host:port.  host is the name the
+// TLS certificate of the server is verified for.
+func (c *SessionConfig) dial(ctx context.Context, target, host string) (*Session, error) {
+	if c.SSHConfig == nil && c.TLSConfig == nil {
+		return nil, fmt.Errorf("netconf: no SSH or TLS configuration for %s", target)
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	conn, err := c.dialConn(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	// The handshakes do not take a context; bound them by its deadline.
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if c.Bandwidth != nil {
+		conn = c.Bandwidth.Conn(conn)
+	}
+
+	var t Transport
+	if c.TLSConfig != nil {
+		tconn := tls.Client(conn, c.tlsConfig(host))
+		if err := tconn.Handshake(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		t = NewTransportConn(tconn)
+	} else {
+		st := &TransportSSH{}
+		if c.Profile != nil {
+			st.Subsystem = c.Profile.Subsystem
+		}
+		if err := st.handshake(conn, c.SSHConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		t = st
+	}
+	if c.Chaos != nil {
+		t = c.Chaos.Transport(t)
+	}
+
+	s, err := c.NewSession(t)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	s.Address = target
+	if c.RetryPolicy != nil {
+		s.redial = func(ctx context.Context) (*Session, error) {
+			return c.dial(ctx, target, host)
+		}
+	}
+	c.logf("netconf: session %d established to %s (%s framing)", s.SessionID, target, s.Framing())
+	return s, nil
+}
+
+// NewSession exchanges hello messages over t and returns a session with the
+// configuration applied.  The transport is closed if the session cannot be
+// created.
+func (c *SessionConfig) NewSession(t Transport) (*Session, error) {
+	capabilities := DefaultCapabilities
+	if c.Framing == FramingEOM {
+		capabilities = []string{CapabilityBase10}
+	}
+	s, err := newSessionHello(t, capabilities)
+	if err == nil && len(c.Capabilities) > 0 {
+		err = s.ValidateHello(c.Capabilities...)
+	}
+	if err != nil {
+		t.Close()
+		return nil, err
+	}
+
+	s.Deadlines = c.Deadlines
+	s.Logger = c.Logger
+	s.Profile = c.Profile
+	s.Limiter = c.Limiter
+	s.Queue = c.Queue
+	s.RetryPolicy = c.RetryPolicy
+	s.Interceptors = c.Interceptors
+	s.RPCAttrs = c.RPCAttrs
+	s.ReadOnly = c.ReadOnly
+	return s, nil
+}
+
+// address adds the default port to target if it has none.
+func (c *SessionConfig) address(target string) string {
+	port := sshDefaultPort
+	switch {
+	case c.Profile != nil && c.Profile.Port != 0:
+		port = c.Profile.Port
+	case c.TLSConfig != nil:
+		port = tlsDefaultPort
+	}
+	return withDefaultPort(target, port)
+}
+
+// tlsConfig returns the TLS configuration with the server name set to host
+// if missing.
+func (c *SessionConfig) tlsConfig(host string) *tls.Config {
+	if c.TLSConfig.ServerName != "" || c.TLSConfig.InsecureSkipVerify {
+		return c.TLSConfig
+	}
+	config := c.TLSConfig.Clone()
+	config.ServerName = host
+	return config
+}
+
+func (c *SessionConfig) logf(format string, v ...interface{}) {
+	if c.Logger != nil {
+		c.Logger.Printf(format, v...)
+	}
+}
+
+// logf writes to the session's Logger, if any.
+func (s *Session) logf(format string, v ...interface{}) {
+	if s.Logger != nil {
+		s.Logger.Printf(format, v...)
+	}
+}
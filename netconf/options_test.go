@@ -0,0 +1,158 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"crypto/tls"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDialOptions(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+
+	var logs bytes.Buffer
+	profile := &Profile{Name: "test", Subsystem: "netconf-xml"}
+	limiter := NewRateLimiter(RateLimit{})
+	s, err := Dial(srv.Addr(),
+		WithSSHConfig(testSSHConfig()),
+		WithTimeout(5*time.Second),
+		WithRPCDeadlines(Deadlines{Read: time.Second}),
+		WithLogger(log.New(&logs, "", 0)),
+		WithProfile(profile),
+		WithRateLimiter(limiter),
+		WithFraming(FramingEOM),
+	)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer s.Close()
+
+	if _, err := s.Exec(MethodGetConfig("running")); err != nil {
+		t.Errorf("Exec failed: %v", err)
+	}
+	if s.Profile != profile || s.Limiter != limiter || s.Deadlines.Read != time.Second || s.Logger == nil {
+		t.Errorf("options not applied to session: %+v", s)
+	}
+	if s.Framing() != FramingEOM {
+		t.Errorf("expected end-of-message framing, got %s", s.Framing())
+	}
+	if !strings.Contains(logs.String(), "session 1 established") {
+		t.Errorf("unexpected log output %q", logs.String())
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if diff := cmp.Diff([]string{"subsystem netconf-xml"}, srv.requests); diff != "" {
+		t.Errorf("requests mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDialOptionsErrors(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+
+	if _, err := Dial(srv.Addr()); err == nil {
+		t.Error("expected error without SSH or TLS configuration")
+	}
+	_, err := Dial(srv.Addr(), WithSSHConfig(testSSHConfig()), WithCapabilities(CapabilityCandidate))
+	if err == nil {
+		t.Error("expected error for missing required capability")
+	}
+}
+
+func TestSessionConfigAddress(t *testing.T) {
+	tt := []struct {
+		config   *SessionConfig
+		target   string
+		expected string
+	}{
+		{NewSessionConfig(), "r1", "r1:830"},
+		{NewSessionConfig(), "r1:22", "r1:22"},
+		{NewSessionConfig(), "2001:db8::1", "[2001:db8::1]:830"},
+		{NewSessionConfig(), "fe80::1%mgmt", "[fe80::1%mgmt]:830"},
+		{NewSessionConfig(), "[fe80::1%25mgmt]:22", "[fe80::1%mgmt]:22"},
+		{NewSessionConfig(WithTLSConfig(&tls.Config{})), "r1", "r1:6513"},
+		{NewSessionConfig(WithProfile(&Profile{Port: 2022})), "r1", "r1:2022"},
+	}
+	for _, tc := range tt {
+		if got := tc.config.address(tc.target); got != tc.expected {
+			t.Errorf("%s: got %s, expected %s", tc.target, got, tc.expected)
+		}
+	}
+}
+
+func TestWithPassword(t *testing.T) {
+	c := NewSessionConfig(WithSSHConfig(testSSHConfig()), WithPassword("admin", "secret"))
+	if c.SSHConfig.User != "admin" || len(c.SSHConfig.Auth) != 1 || c.SSHConfig.HostKeyCallback == nil {
+		t.Errorf("unexpected SSH config %+v", c.SSHConfig)
+	}
+	c = NewSessionConfig(WithPassword("admin", "secret"))
+	if c.SSHConfig == nil || c.SSHConfig.User != "admin" {
+		t.Fatalf("unexpected SSH config %+v", c.SSHConfig)
+	}
+	if reflect.ValueOf(c.SSHConfig.HostKeyCallback).Pointer() != reflect.ValueOf(checkKnownHosts).Pointer() {
+		t.Error("expected host keys to be checked against known_hosts")
+	}
+	for _, opts := range [][]Option{
+		{WithPassword("admin", "secret"), WithInsecureIgnoreHostKey()},
+		{WithInsecureIgnoreHostKey(), WithPassword("admin", "secret")},
+	} {
+		c := NewSessionConfig(opts...)
+		if c.SSHConfig.User != "admin" || reflect.ValueOf(c.SSHConfig.HostKeyCallback).Pointer() == reflect.ValueOf(checkKnownHosts).Pointer() {
+			t.Errorf("expected host keys to be ignored, got %+v", c.SSHConfig)
+		}
+	}
+}
+
+func TestWithPasswordCallback(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+	rotate := func(password string) {
+		srv.mu.Lock()
+		srv.password = password
+		srv.mu.Unlock()
+	}
+	rotate("v1")
+
+	var calls int
+	current := "v1"
+	opt := WithPasswordCallback("ops", func() (string, error) {
+		calls++
+		return current, nil
+	})
+	if c := NewSessionConfig(opt); reflect.ValueOf(c.SSHConfig.HostKeyCallback).Pointer() != reflect.ValueOf(checkKnownHosts).Pointer() {
+		t.Error("expected host keys to be checked against known_hosts")
+	}
+	for _, password := range []string{"v1", "v2"} {
+		rotate(password)
+		current = password
+		s, err := Dial(srv.Addr(), WithSSHConfig(testSSHConfig()), opt, WithTimeout(5*time.Second))
+		if err != nil {
+			t.Fatalf("Dial with password %s failed: %v", password, err)
+		}
+		s.Close()
+	}
+	if calls != 2 {
+		t.Errorf("expected password callback to be called on every dial, got %d calls", calls)
+	}
+
+	current = "stale"
+	if _, err := Dial(srv.Addr(), WithSSHConfig(testSSHConfig()), opt, WithTimeout(5*time.Second)); err == nil {
+		t.Error("expected error for wrong password")
+	}
+	if _, err := Dial(srv.Addr(), WithSSHConfig(testSSHConfig())); err == nil {
+		t.Error("expected error without password")
+	}
+}
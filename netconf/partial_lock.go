@@ -0,0 +1,47 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// PartialLock is a lock on parts of the running datastore, see RFC 5717.
+type PartialLock struct {
+	ID uint32 `xml:"lock-id"`
+	// LockedNodes holds the instance identifiers of the locked nodes.
+	LockedNodes []string `xml:"locked-node"`
+}
+
+// PartialLock locks the nodes of the running datastore selected by the
+// XPath expressions.
+func (s *Session) PartialLock(ctx context.Context, selects ...string) (*PartialLock, error) {
+	if err := s.requireCapability("partial-lock", CapabilityPartialLock); err != nil {
+		return nil, err
+	}
+	reply, err := s.ExecContext(ctx, MethodPartialLock(selects...))
+	if err != nil {
+		return nil, err
+	}
+
+	var lock PartialLock
+	wrapped := append(append([]byte("<partial-lock-reply>"), reply.Data...), "</partial-lock-reply>"...)
+	if err := xml.Unmarshal(wrapped, &lock); err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// PartialUnlock releases a lock obtained with PartialLock.
+func (s *Session) PartialUnlock(ctx context.Context, lock *PartialLock) error {
+	if err := s.requireCapability("partial-unlock", CapabilityPartialLock); err != nil {
+		return err
+	}
+	_, err := s.ExecContext(ctx, MethodPartialUnlock(lock.ID))
+	return err
+}
@@ -0,0 +1,106 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+)
+
+// Plan is the predicted outcome of a configuration change.
+type Plan struct {
+	// Delta holds the differences between running and the candidate with
+	// the change loaded.
+	Delta *Delta
+	// DeviceDiff holds the reply to PlanOptions.DeviceCompare, if it was set
+	// and the device supports it.
+	DeviceDiff RawXML
+}
+
+// PlanOptions tunes Plan.
+type PlanOptions struct {
+	// NoLock disables locking the candidate.
+	NoLock bool
+	// SkipValidate disables validation.  Validation is also skipped when the
+	// server does not announce :validate.
+	SkipValidate bool
+	// DeviceCompare, if set, is sent after loading the change to obtain the
+	// device's own view of the difference, e.g. MethodJunosCompare().
+	DeviceCompare RPCMethod
+}
+
+// Plan loads config into the candidate datastore, validates it and returns
+// the resulting differences, then discards the change.  Nothing is
+// committed.  Failures are reported as *TransactionError naming the stage.
+// opts may be nil.
+func (c *CandidateSession) Plan(ctx context.Context, config string, opts *PlanOptions) (*Plan, error) {
+	if opts == nil {
+		opts = &PlanOptions{}
+	}
+
+	// Like EditTransaction, cleanup runs even once ctx is done.
+	cleanup := context.Background()
+	locked := false
+	finish := func(stage TransactionStage, err error) error {
+		var cleanupErr error
+		if stage != StageLock {
+			_, cleanupErr = c.ExecContext(cleanup, MethodDiscardChanges())
+		}
+		if locked {
+			if _, uerr := c.ExecContext(cleanup, MethodUnlock("candidate")); uerr != nil && cleanupErr == nil {
+				cleanupErr = uerr
+			}
+		}
+		switch {
+		case err != nil:
+			return &TransactionError{Stage: stage, Err: err, CleanupErr: cleanupErr}
+		case cleanupErr != nil:
+			return &TransactionError{Stage: StageUnlock, Err: cleanupErr}
+		}
+		return nil
+	}
+
+	if !opts.NoLock {
+		if err := c.Lock(ctx); err != nil {
+			return nil, finish(StageLock, err)
+		}
+		locked = true
+	}
+
+	if err := c.Load(ctx, config); err != nil {
+		return nil, finish(StageEdit, err)
+	}
+
+	if !opts.SkipValidate && c.HasCapability(CapabilityValidate) {
+		if err := c.Validate(ctx); err != nil {
+			return nil, finish(StageValidate, err)
+		}
+	}
+
+	plan := &Plan{}
+	var err error
+	if plan.Delta, err = c.Compare(ctx); err != nil {
+		return nil, finish(StageEdit, err)
+	}
+
+	if opts.DeviceCompare != nil {
+		reply, err := c.ExecContext(ctx, opts.DeviceCompare)
+		var rpcErr *RPCError
+		switch {
+		case err == nil:
+			plan.DeviceDiff = append(RawXML(nil), reply.Data...)
+		case errors.As(err, &rpcErr) && rpcErr.Tag == "operation-not-supported":
+		default:
+			return nil, finish(StageEdit, err)
+		}
+	}
+
+	if err := finish(StageEdit, nil); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
@@ -0,0 +1,88 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPlan(t *testing.T) {
+	caps := []string{CapabilityCandidate, CapabilityValidate}
+	running := `<rpc-reply><data><system><host-name>a</host-name></system></data></rpc-reply>`
+	candidate := `<rpc-reply><data><system><host-name>b</host-name></system></data></rpc-reply>`
+
+	tt := []struct {
+		name     string
+		opts     *PlanOptions
+		replies  []string
+		stage    TransactionStage
+		changes  int
+		device   string
+		expected []string
+	}{
+		{
+			name:     "plan",
+			replies:  []string{replyOK, replyOK, replyOK, running, candidate, replyOK, replyOK},
+			changes:  1,
+			expected: []string{"lock", "edit-config", "validate", "get-config", "get-config", "discard-changes", "unlock"},
+		},
+		{
+			name:     "device compare",
+			opts:     &PlanOptions{NoLock: true, DeviceCompare: MethodJunosCompare()},
+			replies:  []string{replyOK, replyOK, running, candidate, `<rpc-reply><configuration-information>[edit system]</configuration-information></rpc-reply>`, replyOK},
+			changes:  1,
+			device:   "<configuration-information>[edit system]</configuration-information>",
+			expected: []string{"edit-config", "validate", "get-config", "get-config", "get-configuration", "discard-changes"},
+		},
+		{
+			name:     "compare unsupported",
+			opts:     &PlanOptions{NoLock: true, SkipValidate: true, DeviceCompare: MethodJunosCompare()},
+			replies:  []string{replyOK, running, running, replyError("operation-not-supported"), replyOK},
+			expected: []string{"edit-config", "get-config", "get-config", "get-configuration", "discard-changes"},
+		},
+		{
+			name:     "invalid",
+			replies:  []string{replyOK, replyOK, replyError("invalid-value"), replyOK, replyOK},
+			stage:    StageValidate,
+			expected: []string{"lock", "edit-config", "validate", "discard-changes", "unlock"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s, trans := newScriptedSession(caps, tc.replies...)
+			c, err := NewCandidateSession(s)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			plan, err := c.Plan(context.Background(), "<system><host-name>b</host-name></system>", tc.opts)
+
+			if tc.stage != "" {
+				var txErr *TransactionError
+				if !errors.As(err, &txErr) || txErr.Stage != tc.stage {
+					t.Errorf("expected failure at %s, got %v", tc.stage, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else {
+				if len(plan.Delta.Changes) != tc.changes {
+					t.Errorf("got %d changes, expected %d: %s", len(plan.Delta.Changes), tc.changes, plan.Delta)
+				}
+				if plan.DeviceDiff.String() != tc.device {
+					t.Errorf("got device diff %q, expected %q", plan.DeviceDiff, tc.device)
+				}
+			}
+			if diff := cmp.Diff(tc.expected, trans.operations()); diff != "" {
+				t.Errorf("operations mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
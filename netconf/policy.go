@@ -0,0 +1,250 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PolicyRequest describes an operation about to be sent, for a Policy to
+// inspect.
+type PolicyRequest struct {
+	// Operation is the local name of the operation, e.g. "edit-config".
+	Operation string
+	Namespace string
+	// Target and Source are the datastores of the operation, if any, e.g.
+	// "running".  A URL is given as is.
+	Target string
+	Source string
+	// Elements lists the top-level elements of the configuration of an
+	// edit-config or copy-config, e.g. "system" and "interfaces".
+	Elements []string
+	// Size is the size of the method in bytes, zero for streamed requests
+	// whose payload is not inspected.  A method carrying several operations
+	// yields a request for each, all of the same size.
+	Size int
+	// Ticket is the change ticket of the context, see WithChangeTicket.
+	Ticket string
+	Method RPCMethod
+}
+
+// Policy vetoes operations by returning an error, the reason for the
+// refusal.
+type Policy func(ctx context.Context, req *PolicyRequest) error
+
+// ErrPolicyDenied matches the errors returned for operations refused by a
+// policy.
+var ErrPolicyDenied = errors.New("netconf: operation denied by policy")
+
+// PolicyError is returned, before anything is sent, for operations refused
+// by a policy.
+type PolicyError struct {
+	Operation string
+	Reason    error
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("netconf: %s denied by policy: %v", e.Operation, e.Reason)
+}
+
+// Is makes PolicyError match ErrPolicyDenied.
+func (e *PolicyError) Is(target error) bool {
+	return target == ErrPolicyDenied
+}
+
+// Unwrap returns the reason.
+func (e *PolicyError) Unwrap() error {
+	return e.Reason
+}
+
+type changeTicketKey struct{}
+
+// WithChangeTicket returns a context carrying the change ticket that
+// authorizes the RPCs executed with it.
+func WithChangeTicket(ctx context.Context, ticket string) context.Context {
+	return context.WithValue(ctx, changeTicketKey{}, ticket)
+}
+
+// ChangeTicket returns the change ticket of ctx, if any.
+func ChangeTicket(ctx context.Context) string {
+	t, _ := ctx.Value(changeTicketKey{}).(string)
+	return t
+}
+
+// PolicyInterceptor returns an interceptor passing every operation to p
+// before the RPC is sent.
+func PolicyInterceptor(p Policy) Interceptor {
+	return func(ctx context.Context, methods []RPCMethod, invoke Invoker) (*RPCReply, error) {
+		ticket := ChangeTicket(ctx)
+		for _, m := range methods {
+			reqs, err := inspectRequests(m)
+			if err != nil {
+				return nil, &PolicyError{Operation: methodName(m), Reason: err}
+			}
+			for _, req := range reqs {
+				req.Ticket = ticket
+				if err := p(ctx, req); err != nil {
+					return nil, &PolicyError{Operation: req.Operation, Reason: err}
+				}
+			}
+		}
+		return invoke(ctx, methods)
+	}
+}
+
+// WithPolicy checks every operation of the session against p.
+func WithPolicy(p Policy) Option {
+	return WithInterceptors(PolicyInterceptor(p))
+}
+
+// inspectRequests parses the operations of m, one request for each
+// top-level element.  Streamed requests are not read.  Requests that cannot
+// be parsed are refused, as the policy could not be checked.
+func inspectRequests(m RPCMethod) ([]*PolicyRequest, error) {
+	if _, ok := m.(interface{ methodName() string }); ok {
+		req := &PolicyRequest{Method: m, Operation: methodName(m)}
+		if e, ok := m.(*EditConfigReader); ok {
+			req.Target = e.Target
+		}
+		return []*PolicyRequest{req}, nil
+	}
+
+	data := m.MarshalMethod()
+	roots, err := ParseNodes([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("unreadable request: %v", err)
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("empty request")
+	}
+	reqs := make([]*PolicyRequest, len(roots))
+	for i, n := range roots {
+		reqs[i] = inspectNode(n)
+		reqs[i].Method = m
+		reqs[i].Size = len(data)
+	}
+	return reqs, nil
+}
+
+// inspectNode returns the request for the operation element n.
+func inspectNode(n *Node) *PolicyRequest {
+	req := &PolicyRequest{Operation: n.XMLName.Local, Namespace: n.XMLName.Space}
+	req.Target = datastoreName(n.Child("target"))
+	req.Source = datastoreName(n.Child("source"))
+	if ds := n.Child("datastore"); ds != nil && req.Target == "" {
+		// NMDA operations name the datastore by identity, e.g. ds:running.
+		name := ds.Value()
+		req.Target = name[strings.IndexByte(name, ':')+1:]
+	}
+	if c := n.Child("config"); c != nil {
+		for _, e := range c.Children {
+			req.Elements = append(req.Elements, e.XMLName.Local)
+		}
+	}
+	return req
+}
+
+// datastoreName returns the datastore named by a <target> or <source>
+// element.
+func datastoreName(n *Node) string {
+	if n == nil || len(n.Children) == 0 {
+		return ""
+	}
+	c := n.Children[0]
+	if c.XMLName.Local == "url" {
+		return c.Value()
+	}
+	return c.XMLName.Local
+}
+
+// readOnlyOperations are the operations known not to change the
+// configuration or state of a device.  Locks are included as they only
+// guard the datastores against other sessions.
+var readOnlyOperations = map[string]bool{
+	"get":                 true,
+	"get-config":          true,
+	"get-data":            true,
+	"get-schema":          true,
+	"lock":                true,
+	"unlock":              true,
+	"partial-lock":        true,
+	"partial-unlock":      true,
+	"validate":            true,
+	"close-session":       true,
+	"create-subscription": true,
+	// Junos operations.
+	"get-configuration": true,
+}
+
+// IsReadOnly reports whether the operation is known not to change the
+// configuration or state of the device, e.g. get-config.  Besides the
+// NETCONF operations this includes the Junos get-*-information requests.
+func IsReadOnly(operation string) bool {
+	if readOnlyOperations[operation] {
+		return true
+	}
+	return strings.HasPrefix(operation, "get-") && strings.HasSuffix(operation, "-information")
+}
+
+// IsStateChanging reports whether the operation may change the configuration
+// or state of the device, e.g. edit-config or commit.  Operations not known
+// to be read-only, see IsReadOnly, are taken to be state changing.
+func IsStateChanging(operation string) bool {
+	return !IsReadOnly(operation)
+}
+
+// DenyOperations returns a policy refusing the given operations, e.g.
+// "delete-config".
+func DenyOperations(operations ...string) Policy {
+	return func(ctx context.Context, req *PolicyRequest) error {
+		for _, op := range operations {
+			if req.Operation == op {
+				return fmt.Errorf("operation %s is not allowed", op)
+			}
+		}
+		return nil
+	}
+}
+
+// DenyTargets returns a policy refusing operations whose target is one of
+// datastores, e.g. edits of running bypassing the candidate.
+func DenyTargets(datastores ...string) Policy {
+	return func(ctx context.Context, req *PolicyRequest) error {
+		for _, ds := range datastores {
+			if req.Target == ds {
+				return fmt.Errorf("datastore %s may not be the target of %s", ds, req.Operation)
+			}
+		}
+		return nil
+	}
+}
+
+// RequireChangeTicket returns a policy refusing state changing operations,
+// see IsStateChanging, unless the context carries a change ticket.
+func RequireChangeTicket() Policy {
+	return func(ctx context.Context, req *PolicyRequest) error {
+		if IsStateChanging(req.Operation) && req.Ticket == "" {
+			return errors.New("change ticket required")
+		}
+		return nil
+	}
+}
+
+// Policies returns a policy refusing operations refused by any of policies.
+func Policies(policies ...Policy) Policy {
+	return func(ctx context.Context, req *PolicyRequest) error {
+		for _, p := range policies {
+			if err := p(ctx, req); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,119 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestInspectRequest(t *testing.T) {
+	tt := []struct {
+		method   RPCMethod
+		expected *PolicyRequest
+	}{
+		{
+			MethodEditConfig("running", "<system/><interfaces/>"),
+			&PolicyRequest{Operation: "edit-config", Target: "running", Elements: []string{"system", "interfaces"}},
+		},
+		{
+			MethodCopyConfig("running", "startup"),
+			&PolicyRequest{Operation: "copy-config", Target: "startup", Source: "running"},
+		},
+		{
+			RawMethod(`<delete-config><target><url>ftp://x/y</url></target></delete-config>`),
+			&PolicyRequest{Operation: "delete-config", Target: "ftp://x/y"},
+		},
+		{
+			RawMethod(`<edit-data xmlns="` + NMDANamespace + `" xmlns:ds="` + DatastoresNamespace + `"><datastore>ds:running</datastore></edit-data>`),
+			&PolicyRequest{Operation: "edit-data", Namespace: NMDANamespace, Target: "running"},
+		},
+		{
+			MethodEditConfigReader("candidate", strings.NewReader("<system/>")),
+			&PolicyRequest{Operation: "edit-config", Target: "candidate"},
+		},
+		{MethodCommit(), &PolicyRequest{Operation: "commit"}},
+	}
+	for _, tc := range tt {
+		got, err := inspectRequests(tc.method)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.expected.Operation, err)
+			continue
+		}
+		if diff := cmp.Diff([]*PolicyRequest{tc.expected}, got, cmpopts.IgnoreFields(PolicyRequest{}, "Method", "Size")); diff != "" {
+			t.Errorf("%s: mismatch (-want +got):\n%s", tc.expected.Operation, diff)
+		}
+	}
+
+	got, err := inspectRequests(RawMethod(`<get/><delete-config><target><startup/></target></delete-config>`))
+	if err != nil || len(got) != 2 || got[1].Operation != "delete-config" || got[1].Target != "startup" {
+		t.Errorf("got %+v, error %v, expected get and delete-config", got, err)
+	}
+	for _, m := range []RPCMethod{RawMethod(`<edit-config><target>`), RawMethod(``)} {
+		if _, err := inspectRequests(m); err == nil {
+			t.Errorf("%q: expected an error", m.MarshalMethod())
+		}
+	}
+}
+
+func TestPolicyInterceptor(t *testing.T) {
+	policy := Policies(
+		DenyOperations("delete-config"),
+		DenyTargets("running"),
+		RequireChangeTicket(),
+	)
+	ticket := WithChangeTicket(context.Background(), "CHG-1")
+
+	tt := []struct {
+		name   string
+		ctx    context.Context
+		method RPCMethod
+		denied bool
+	}{
+		{"delete-config", ticket, RawMethod(`<delete-config><target><startup/></target></delete-config>`), true},
+		{"edit running", ticket, MethodEditConfig("running", "<system/>"), true},
+		{"edit candidate", ticket, MethodEditConfig("candidate", "<system/>"), false},
+		{"commit without ticket", context.Background(), MethodCommit(), true},
+		{"read without ticket", context.Background(), MethodGetConfig("running"), false},
+		{"delete-config after get", ticket, RawMethod(`<get/><delete-config><target><startup/></target></delete-config>`), true},
+		{"unreadable", ticket, RawMethod(`<get/><edit-config>`), true},
+	}
+	for _, tc := range tt {
+		s, trans := newScriptedSession(nil, replyOK)
+		s.Interceptors = []Interceptor{PolicyInterceptor(policy)}
+		_, err := s.ExecContext(tc.ctx, tc.method)
+		if !tc.denied {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tc.name, err)
+			}
+			continue
+		}
+		var perr *PolicyError
+		if !errors.Is(err, ErrPolicyDenied) || !errors.As(err, &perr) {
+			t.Errorf("%s: expected policy error, got %v", tc.name, err)
+		}
+		if len(trans.sent) != 0 {
+			t.Errorf("%s: denied request sent", tc.name)
+		}
+	}
+
+	var seen *PolicyRequest
+	config := NewSessionConfig(WithPolicy(func(ctx context.Context, req *PolicyRequest) error {
+		seen = req
+		return nil
+	}))
+	s, _ := newScriptedSession(nil, replyOK)
+	s.Interceptors = config.Interceptors
+	if _, err := s.ExecContext(ticket, MethodCommit()); err != nil || seen == nil || seen.Ticket != "CHG-1" || seen.Size == 0 {
+		t.Errorf("unexpected request %+v: %v", seen, err)
+	}
+}
@@ -0,0 +1,165 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// defaultPoolIdle is the number of idle sessions kept per device when
+// Pool.MaxIdle is not set.
+const defaultPoolIdle = 2
+
+var (
+	// ErrPoolClosed is returned by Get once the pool was closed.
+	ErrPoolClosed = errors.New("netconf: pool closed")
+	// ErrUnknownDevice is returned for devices missing from the inventory.
+	ErrUnknownDevice = errors.New("netconf: unknown device")
+)
+
+// Pool keeps sessions to the devices of an inventory open for reuse.  It is
+// safe for concurrent use; each session is handed to one user at a time.
+type Pool struct {
+	Inventory *Inventory
+	// Dial opens a session to a device.
+	Dial func(ctx context.Context, d *Device) (*Session, error)
+	// MaxIdle caps the number of idle sessions kept per device.
+	MaxIdle int
+	// Dampener, if set, suppresses new sessions to devices whose dials or
+	// sessions keep failing.  Idle sessions are still handed out.
+	Dampener *Dampener
+
+	mu     sync.Mutex
+	idle   map[string][]*Session
+	closed bool
+}
+
+// NewPool returns a pool dialing the devices of inv with dial.
+func NewPool(inv *Inventory, dial func(ctx context.Context, d *Device) (*Session, error)) *Pool {
+	return &Pool{Inventory: inv, Dial: dial}
+}
+
+// Get returns an idle session to the named device, or dials a new one.  The
+// session must be returned with Put.
+func (p *Pool) Get(ctx context.Context, device string) (*Session, error) {
+	d := p.Inventory.Device(device)
+	if d == nil {
+		return nil, fmt.Errorf("%w %q", ErrUnknownDevice, device)
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrPoolClosed
+	}
+	if idle := p.idle[d.Name]; len(idle) > 0 {
+		s := idle[len(idle)-1]
+		p.idle[d.Name] = idle[:len(idle)-1]
+		p.mu.Unlock()
+		return s, nil
+	}
+	p.mu.Unlock()
+
+	if p.Dampener != nil {
+		if err := p.Dampener.Allow(d.Name); err != nil {
+			return nil, err
+		}
+	}
+	s, err := p.Dial(ctx, d)
+	if p.Dampener != nil && dialFailed(ctx, err) {
+		p.Dampener.Failure(d.Name, err)
+	}
+	return s, err
+}
+
+// Put returns a session obtained from Get.  err is the error of the last
+// use of the session: sessions that failed at the transport level, or were
+// abandoned, are closed instead of being kept.
+func (p *Pool) Put(device string, s *Session, err error) {
+	if !reusable(s, err) {
+		if p.Dampener != nil && flapped(err) {
+			p.Dampener.Failure(device, err)
+		}
+		s.Close()
+		return
+	}
+
+	max := p.MaxIdle
+	if max <= 0 {
+		max = defaultPoolIdle
+	}
+
+	p.mu.Lock()
+	if p.closed || len(p.idle[device]) >= max {
+		p.mu.Unlock()
+		s.Close()
+		return
+	}
+	if p.idle == nil {
+		p.idle = make(map[string][]*Session)
+	}
+	p.idle[device] = append(p.idle[device], s)
+	p.mu.Unlock()
+}
+
+// reusable reports whether a session that ended its last use with err can
+// serve further RPCs.
+func reusable(s *Session, err error) bool {
+	if s.abandoned {
+		return false
+	}
+	var timeout net.Error
+	var framing *FramingError
+	if errors.As(err, &timeout) && timeout.Timeout() {
+		return false
+	}
+	return !errors.Is(err, ErrTransportBroken) && !errors.As(err, &framing) &&
+		!errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// Do runs fn with a session to the named device and returns the session to
+// the pool afterwards.
+func (p *Pool) Do(ctx context.Context, device string, fn func(s *Session) error) error {
+	s, err := p.Get(ctx, device)
+	if err != nil {
+		return err
+	}
+	err = fn(s)
+	p.Put(device, s, err)
+	return err
+}
+
+// Idle returns the number of idle sessions to the named device.
+func (p *Pool) Idle(device string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.idle[device])
+}
+
+// Close closes all idle sessions.  Sessions in use are closed when they are
+// returned.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+
+	var first error
+	for _, sessions := range idle {
+		for _, s := range sessions {
+			if err := s.Close(); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
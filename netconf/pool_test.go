@@ -0,0 +1,80 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"io"
+	"testing"
+)
+
+func newTestPool(replies ...string) (*Pool, *int) {
+	dials := 0
+	inv := &Inventory{Devices: []*Device{{Name: "r1", Address: "r1"}}}
+	return NewPool(inv, func(ctx context.Context, d *Device) (*Session, error) {
+		dials++
+		s, _ := newScriptedSession([]string{CapabilityNotification}, replies...)
+		return s, nil
+	}), &dials
+}
+
+func TestPoolReuse(t *testing.T) {
+	p, dials := newTestPool(replyOK, replyOK)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		err := p.Do(ctx, "r1", func(s *Session) error {
+			_, err := s.ExecContext(ctx, MethodCommit())
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Do failed: %v", err)
+		}
+	}
+	if *dials != 1 {
+		t.Errorf("expected a single dial, got %d", *dials)
+	}
+	if p.Idle("r1") != 1 {
+		t.Errorf("expected one idle session, got %d", p.Idle("r1"))
+	}
+
+	// The script is exhausted, the failing session must not be kept.
+	p.Do(ctx, "r1", func(s *Session) error {
+		_, err := s.ExecContext(ctx, MethodCommit())
+		return err
+	})
+	if p.Idle("r1") != 0 {
+		t.Errorf("expected broken session to be closed, got %d idle", p.Idle("r1"))
+	}
+
+	if _, err := p.Get(ctx, "missing"); err == nil {
+		t.Error("expected error for unknown device")
+	}
+	p.Close()
+	if _, err := p.Get(ctx, "r1"); err != ErrPoolClosed {
+		t.Errorf("expected ErrPoolClosed, got %v", err)
+	}
+}
+
+func TestPoolReusable(t *testing.T) {
+	tt := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"ok", nil, true},
+		{"rpc error", &RPCError{Severity: "error"}, true},
+		{"transport", transportError("read", true, io.EOF), false},
+		{"timeout", &TimeoutError{Op: "read"}, false},
+		{"cancelled", context.Canceled, false},
+	}
+	for _, tc := range tt {
+		if got := reusable(&Session{}, tc.err); got != tc.expected {
+			t.Errorf("%s: got %v, expected %v", tc.name, got, tc.expected)
+		}
+	}
+}
@@ -1,17 +1,27 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
 package netconf
 
 import "strings"
 
-// processChunkedFraming 处理 chunked framing 数据
+// ProcessChunkedFraming removes the chunked framing from data.  The
+// end-of-chunks marker may be missing.  Data that is not a valid chunked
+// message is returned unchanged.
+//
+// Deprecated: Receive already removes the framing; use DecodeChunks to
+// decode captured messages.
 func ProcessChunkedFraming(data string) string {
-	var result strings.Builder
-	lines := strings.Split(data, "\n")
-	for _, line := range lines {
-
-		if strings.HasPrefix(line, "#") {
-			continue
-		}
-		result.WriteString(line)
+	framed := data
+	if !strings.HasSuffix(framed, msgSeperator_v11) {
+		framed += msgSeperator_v11
+	}
+	payload, err := DecodeChunks([]byte(framed))
+	if err != nil {
+		return data
 	}
-	return result.String()
+	return string(payload)
 }
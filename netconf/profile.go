@@ -0,0 +1,83 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Profile captures vendor specific behaviour of a NETCONF server.
+type Profile struct {
+	Name string
+	// Vendor is the name of the vendor, as reported by GatherFacts.
+	Vendor string
+	// SaveConfig, if set, is executed by Session.SaveConfig instead of a
+	// copy-config from running to startup.
+	SaveConfig RPCMethod
+	// Port is the default port of the device's NETCONF service, 830 if 0.
+	Port int
+	// Subsystem is the name of the SSH subsystem providing NETCONF,
+	// "netconf" if empty.
+	Subsystem string
+	// Facts, if set, fills in the facts GatherFacts could not find in the
+	// standard models, usually with vendor specific RPCs.
+	Facts func(ctx context.Context, s *Session, f *Facts) error
+}
+
+// Built-in vendor profiles.
+var (
+	ProfileJunos = &Profile{
+		Name:   "junos",
+		Vendor: "Juniper",
+		Facts:  junosFacts,
+	}
+	ProfileIOSXE = &Profile{
+		Name:       "iosxe",
+		Vendor:     "Cisco",
+		Facts:      iosxeFacts,
+		SaveConfig: RawMethod(`<save-config xmlns="http://cisco.com/yang/cisco-ia"/>`),
+	}
+	ProfileSROS = &Profile{
+		Name:       "sros",
+		Vendor:     "Nokia",
+		Facts:      srosFacts,
+		SaveConfig: RawMethod(`<action xmlns="urn:ietf:params:xml:ns:yang:1"><admin xmlns="urn:nokia.com:sros:ns:yang:sr:oper-admin"><save/></admin></action>`),
+	}
+)
+
+var profiles = []*Profile{ProfileJunos, ProfileIOSXE, ProfileSROS}
+
+var profilesMu sync.RWMutex
+
+// LookupProfile returns the built-in or registered profile with the given
+// name, or nil.  Names are matched case-insensitively.
+func LookupProfile(name string) *Profile {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	for _, p := range profiles {
+		if strings.EqualFold(p.Name, name) {
+			return p
+		}
+	}
+	return nil
+}
+
+// RegisterProfile makes a profile available to LookupProfile, replacing any
+// profile of the same name.
+func RegisterProfile(p *Profile) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	for i, old := range profiles {
+		if strings.EqualFold(old.Name, p.Name) {
+			profiles[i] = p
+			return
+		}
+	}
+	profiles = append(profiles, p)
+}
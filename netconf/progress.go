@@ -0,0 +1,102 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FleetProgress describes the progress of a fleet run when a device is
+// started or finished.
+type FleetProgress struct {
+	Device string
+	// Result is the result of the device once it finished, nil when it was
+	// started.
+	Result *DeviceResult
+	// Started and Done count the devices started and finished so far, out
+	// of Total.
+	Started, Done, Total int
+	Elapsed              time.Duration
+	// ETA estimates the time until all devices are done from the average
+	// pace so far, zero until the first device is done.
+	ETA time.Duration
+}
+
+// Percent returns the share of devices done, from 0 to 100.
+func (p FleetProgress) Percent() float64 {
+	if p.Total == 0 {
+		return 100
+	}
+	return 100 * float64(p.Done) / float64(p.Total)
+}
+
+func (p FleetProgress) String() string {
+	if p.Result == nil {
+		return fmt.Sprintf("%d/%d %.0f%% started %s", p.Done, p.Total, p.Percent(), p.Device)
+	}
+	return fmt.Sprintf("%d/%d %.0f%% %s %s, eta %s", p.Done, p.Total, p.Percent(), p.Device, p.Result.Status,
+		p.ETA.Round(time.Second))
+}
+
+// fleetTracker reports the progress of a run to the Progress hook of a
+// Fleet.  The hook is called with the lock held, so that calls are
+// serialized and counts never go backwards.
+type fleetTracker struct {
+	hook  func(FleetProgress)
+	total int
+	begun time.Time
+
+	mu      sync.Mutex
+	started int
+	done    int
+}
+
+// newFleetTracker returns a tracker for total devices, or nil if hook is
+// nil.
+func newFleetTracker(hook func(FleetProgress), total int, begun time.Time) *fleetTracker {
+	if hook == nil {
+		return nil
+	}
+	return &fleetTracker{hook: hook, total: total, begun: begun}
+}
+
+func (t *fleetTracker) start(device string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.started++
+	t.hook(t.progress(device, nil))
+}
+
+func (t *fleetTracker) finish(r *DeviceResult) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.done++
+	t.hook(t.progress(r.Device, r))
+}
+
+func (t *fleetTracker) progress(device string, r *DeviceResult) FleetProgress {
+	p := FleetProgress{
+		Device:  device,
+		Result:  r,
+		Started: t.started,
+		Done:    t.done,
+		Total:   t.total,
+		Elapsed: time.Since(t.begun),
+	}
+	if t.done > 0 {
+		p.ETA = p.Elapsed / time.Duration(t.done) * time.Duration(t.total-t.done)
+	}
+	return p
+}
@@ -0,0 +1,49 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFleetProgress(t *testing.T) {
+	f := newFleetTest("r1", "r2", "unreachable", "r4")
+	var updates []FleetProgress
+	f.Progress = func(p FleetProgress) { updates = append(updates, p) }
+
+	f.Run(context.Background(), func(ctx context.Context, s *Session, r *DeviceResult) error {
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+
+	if len(updates) != 8 {
+		t.Fatalf("got %d updates, expected 8", len(updates))
+	}
+	done := 0
+	finished := make(map[string]DeviceStatus)
+	for i, p := range updates {
+		if p.Total != 4 || p.Done < done || p.Started < p.Done {
+			t.Errorf("update %d: inconsistent progress %s", i, p)
+		}
+		if p.Result != nil {
+			finished[p.Device] = p.Result.Status
+			if p.ETA < 0 || p.Done < 4 && p.ETA == 0 {
+				t.Errorf("update %d: unexpected eta %s", i, p.ETA)
+			}
+		}
+		done = p.Done
+	}
+	last := updates[len(updates)-1]
+	if last.Percent() != 100 || last.ETA != 0 || last.Started != 4 {
+		t.Errorf("unexpected last update %s", last)
+	}
+	if len(finished) != 4 || finished["unreachable"] != StatusFailed || finished["r4"] != StatusOK {
+		t.Errorf("unexpected results %v", finished)
+	}
+}
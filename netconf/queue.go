@@ -0,0 +1,151 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// Priority orders the RPCs waiting in an RPCQueue; higher priorities are
+// sent first.
+type Priority int
+
+// Common priorities.
+const (
+	PriorityLow    Priority = -10
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 10
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a context giving RPCs executed with it priority p in
+// the session's Queue.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+func priority(ctx context.Context) Priority {
+	p, _ := ctx.Value(priorityKey{}).(Priority)
+	return p
+}
+
+// RPCQueue serializes the RPCs of goroutines sharing a session.  Waiting
+// RPCs are sent by priority, those of equal priority in arrival order, so
+// health checks and small reads are not stuck behind a queue of bulk
+// retrievals.  The RPC in flight is never interrupted.
+type RPCQueue struct {
+	mu      sync.Mutex
+	busy    bool
+	seq     uint64
+	waiting waiterHeap
+}
+
+// NewRPCQueue returns an empty queue.
+func NewRPCQueue() *RPCQueue {
+	return &RPCQueue{}
+}
+
+// Len returns the number of RPCs waiting.
+func (q *RPCQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.waiting)
+}
+
+// Acquire blocks until it is the turn of an RPC of priority p.  Every
+// successful call to Acquire must be followed by a call to Release.
+func (q *RPCQueue) Acquire(ctx context.Context, p Priority) error {
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	if !q.busy {
+		q.busy = true
+		q.mu.Unlock()
+		return nil
+	}
+	q.seq++
+	w := &waiter{priority: p, seq: q.seq, ready: make(chan struct{})}
+	heap.Push(&q.waiting, w)
+	q.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		if w.index >= 0 {
+			heap.Remove(&q.waiting, w.index)
+			q.mu.Unlock()
+			return ctx.Err()
+		}
+		q.mu.Unlock()
+		// The turn was handed over concurrently; pass it on.
+		q.Release()
+		return ctx.Err()
+	}
+}
+
+// Release hands the session over to the next RPC waiting.
+func (q *RPCQueue) Release() {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.waiting) == 0 {
+		q.busy = false
+		return
+	}
+	w := heap.Pop(&q.waiting).(*waiter)
+	close(w.ready)
+}
+
+type waiter struct {
+	priority Priority
+	seq      uint64
+	ready    chan struct{}
+	// index is the position in the heap, -1 once popped.
+	index int
+}
+
+// waiterHeap implements heap.Interface, ordering waiters by priority and
+// then by arrival.
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int { return len(h) }
+
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiterHeap) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() interface{} {
+	old := *h
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	w.index = -1
+	*h = old[:len(old)-1]
+	return w
+}
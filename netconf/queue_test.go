@@ -0,0 +1,94 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRPCQueue(t *testing.T) {
+	q := NewRPCQueue()
+	ctx := context.Background()
+	if err := q.Acquire(ctx, PriorityNormal); err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	enqueue := func(name string, p Priority, queued int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := q.Acquire(ctx, p); err != nil {
+				t.Errorf("%s: Acquire failed: %v", name, err)
+				return
+			}
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			q.Release()
+		}()
+		waitQueued(t, q, queued)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	errc := make(chan error, 1)
+	go func() { errc <- q.Acquire(cancelled, PriorityHigh) }()
+	waitQueued(t, q, 1)
+
+	enqueue("bulk", PriorityLow, 2)
+	enqueue("read", PriorityNormal, 3)
+	enqueue("health", PriorityHigh, 4)
+	enqueue("read2", PriorityNormal, 5)
+
+	cancel()
+	if err := <-errc; err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if n := q.Len(); n != 4 {
+		t.Errorf("expected cancelled RPC to leave the queue, got %d waiting", n)
+	}
+
+	q.Release()
+	wg.Wait()
+	if diff := cmp.Diff([]string{"health", "read", "read2", "bulk"}, order); diff != "" {
+		t.Errorf("order mismatch (-want +got):\n%s", diff)
+	}
+	if err := q.Acquire(ctx, PriorityNormal); err != nil {
+		t.Errorf("Acquire on idle queue failed: %v", err)
+	}
+}
+
+// waitQueued waits until n RPCs are waiting in q.
+func waitQueued(t *testing.T, q *RPCQueue, n int) {
+	for deadline := time.Now().Add(time.Second); q.Len() != n; {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d RPCs queued, got %d", n, q.Len())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSessionQueue(t *testing.T) {
+	s, trans := newScriptedSession(nil, replyOK, replyOK)
+	s.Queue = NewRPCQueue()
+	ctx := WithPriority(context.Background(), PriorityHigh)
+	for i := 0; i < 2; i++ {
+		if _, err := s.ExecContext(ctx, MethodGetConfig("running")); err != nil {
+			t.Fatalf("Exec failed: %v", err)
+		}
+	}
+	if len(trans.sent) != 2 || s.Queue.Len() != 0 || s.Queue.busy {
+		t.Errorf("queue not released: %d sent, %d waiting", len(trans.sent), s.Queue.Len())
+	}
+}
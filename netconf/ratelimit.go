@@ -0,0 +1,125 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimit describes how aggressively RPCs may be issued to a device.  Zero
+// values disable the corresponding limit.
+type RateLimit struct {
+	// MaxConcurrent is the maximum number of RPCs in flight at once.
+	MaxConcurrent int `yaml:"max_concurrent,omitempty"`
+	// PerSecond is the maximum number of RPCs started per second.
+	PerSecond float64 `yaml:"per_second,omitempty"`
+	// Delay is the minimum pause between the completion of one RPC and the
+	// start of the next.
+	Delay time.Duration `yaml:"delay,omitempty"`
+}
+
+// RateLimiter enforces a RateLimit.  A single RateLimiter may be shared by
+// several sessions to the same device.
+type RateLimiter struct {
+	limit RateLimit
+	// Parent, if set, is acquired after this limiter, allowing a per-device
+	// limiter to be nested in a runner wide one.  A device waiting for its
+	// own limit thus holds no capacity of the runner.
+	Parent *RateLimiter
+
+	sem chan struct{}
+
+	mu        sync.Mutex
+	nextStart time.Time
+	lastDone  time.Time
+}
+
+// NewRateLimiter creates a new RateLimiter enforcing the given limit.
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	l := &RateLimiter{limit: limit}
+	if limit.MaxConcurrent > 0 {
+		l.sem = make(chan struct{}, limit.MaxConcurrent)
+	}
+	return l
+}
+
+// Wait blocks until an RPC may be started.  Every successful call to Wait
+// must be followed by a call to Done once the RPC has finished.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	if err := l.wait(ctx); err != nil {
+		return err
+	}
+	if err := l.Parent.Wait(ctx); err != nil {
+		l.release()
+		return err
+	}
+	return nil
+}
+
+func (l *RateLimiter) wait(ctx context.Context) error {
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	start := now
+	if l.nextStart.After(start) {
+		start = l.nextStart
+	}
+	if l.limit.Delay > 0 && !l.lastDone.IsZero() {
+		if t := l.lastDone.Add(l.limit.Delay); t.After(start) {
+			start = t
+		}
+	}
+	if l.limit.PerSecond > 0 {
+		l.nextStart = start.Add(time.Duration(float64(time.Second) / l.limit.PerSecond))
+	}
+	l.mu.Unlock()
+
+	if d := start.Sub(now); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			l.release()
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Done marks an RPC started after Wait as finished.
+func (l *RateLimiter) Done() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	l.lastDone = time.Now()
+	l.mu.Unlock()
+	l.release()
+
+	l.Parent.Done()
+}
+
+func (l *RateLimiter) release() {
+	if l.sem != nil {
+		<-l.sem
+	}
+}
@@ -0,0 +1,95 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterPerSecond(t *testing.T) {
+	l := NewRateLimiter(RateLimit{PerSecond: 20})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		l.Done()
+	}
+
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("3 RPCs at 20/s took %s, expected at least 100ms", elapsed)
+	}
+}
+
+func TestRateLimiterDelay(t *testing.T) {
+	l := NewRateLimiter(RateLimit{Delay: 50 * time.Millisecond})
+
+	l.Wait(context.Background())
+	l.Done()
+
+	start := time.Now()
+	l.Wait(context.Background())
+	l.Done()
+
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("second RPC started after %s, expected at least 50ms", elapsed)
+	}
+}
+
+func TestRateLimiterMaxConcurrent(t *testing.T) {
+	parent := NewRateLimiter(RateLimit{MaxConcurrent: 1})
+	l := NewRateLimiter(RateLimit{MaxConcurrent: 2})
+	l.Parent = parent
+
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected parent limit to block, got %v", err)
+	}
+
+	l.Done()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Errorf("unexpected error after Done: %v", err)
+	}
+	l.Done()
+}
+
+func TestRateLimiterParentLast(t *testing.T) {
+	// A device throttled by its delay must not hold the runner wide slot
+	// another device could use.
+	parent := NewRateLimiter(RateLimit{MaxConcurrent: 1})
+	slow := NewRateLimiter(RateLimit{Delay: time.Hour})
+	slow.Parent = parent
+	fast := NewRateLimiter(RateLimit{})
+	fast.Parent = parent
+
+	slow.Wait(context.Background())
+	slow.Done()
+	ctx, cancel := context.WithCancel(context.Background())
+	waiting := make(chan error, 1)
+	go func() { waiting <- slow.Wait(ctx) }()
+	time.Sleep(10 * time.Millisecond)
+
+	timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second)
+	defer cancelTimeout()
+	if err := fast.Wait(timeout); err != nil {
+		t.Errorf("got %v, expected the throttled device to leave the parent slot free", err)
+	} else {
+		fast.Done()
+	}
+	cancel()
+	if err := <-waiting; err != context.Canceled {
+		t.Errorf("got %v for the throttled device, expected context.Canceled", err)
+	}
+}
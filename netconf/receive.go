@@ -0,0 +1,153 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+)
+
+// MessageKind classifies messages received from the server.
+type MessageKind int
+
+// Message kinds.
+const (
+	MessageOther MessageKind = iota
+	MessageReply
+	MessageNotification
+)
+
+func (k MessageKind) String() string {
+	switch k {
+	case MessageReply:
+		return "rpc-reply"
+	case MessageNotification:
+		return "notification"
+	}
+	return "other"
+}
+
+// Message is a message received with Session.Receive.
+type Message struct {
+	Kind MessageKind
+	// MessageID is the message-id of an rpc-reply.
+	MessageID string
+	// Raw holds the message without its framing and XML declaration.
+	Raw RawXML
+}
+
+// Reply parses the message as an rpc-reply, see ParseRPCReply.
+func (m *Message) Reply() (*RPCReply, error) {
+	return newRPCReply(m.Raw, false, m.MessageID)
+}
+
+// Notification parses the message as a notification, see ParseNotification.
+func (m *Message) Notification() (*Notification, error) {
+	return ParseNotification(m.Raw)
+}
+
+// SendRPC sends a request without waiting for its reply and returns its
+// message-id.  Replies and notifications are then read with Receive.  The
+// Limiter, RetryPolicy and Interceptors of the session are not applied.
+func (s *Session) SendRPC(ctx context.Context, methods ...RPCMethod) (string, error) {
+	if s.abandoned {
+		return "", ErrSessionAbandoned
+	}
+	if err := s.checkReadOnly(methods); err != nil {
+		return "", err
+	}
+	rpc := NewRPCMessage(methods)
+	rpc.Attrs = s.RPCAttrs
+	err := s.withDeadline(ctx, "write", s.deadlines(ctx).Write, func() error {
+		return s.send(rpc)
+	})
+	if err != nil {
+		return "", err
+	}
+	return rpc.MessageID, nil
+}
+
+// Receive reads the next message from the server, bypassing the matching of
+// replies to requests done by Exec, for building custom demultiplexers.  The
+// session must not be used with Exec or subscriptions at the same time.  If
+// ctx is cancelled before a message arrived the session is abandoned, as
+// with ExecContext.
+func (s *Session) Receive(ctx context.Context) (*Message, error) {
+	if s.abandoned {
+		return nil, ErrSessionAbandoned
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	d := s.deadlines(ctx).Read
+	receive := func() ([]byte, error) {
+		var data []byte
+		err := s.withDeadline(ctx, "read", d, func() error {
+			var err error
+			data, err = s.Transport.Receive()
+			return err
+		})
+		return data, transportError("read", true, err)
+	}
+
+	var data []byte
+	var err error
+	if ctx.Done() == nil {
+		data, err = receive()
+	} else {
+		done := make(chan error, 1)
+		go func() {
+			var err error
+			data, err = receive()
+			done <- err
+		}()
+		select {
+		case err = <-done:
+		case <-ctx.Done():
+			s.abandon(done)
+			return nil, ctx.Err()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err = toUTF8(data, s.Charset, s.CharsetReader); err != nil {
+		return nil, err
+	}
+	return newMessage(stripDeclarations(data)), nil
+}
+
+// newMessage classifies data by its root element.
+func newMessage(data []byte) *Message {
+	m := &Message{Raw: data}
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			return m
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "rpc-reply":
+			m.Kind = MessageReply
+			for _, a := range start.Attr {
+				if a.Name.Local == "message-id" {
+					m.MessageID = a.Value
+				}
+			}
+		case "notification":
+			m.Kind = MessageNotification
+		}
+		return m
+	}
+}
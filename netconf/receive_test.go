@@ -0,0 +1,88 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSessionReceive(t *testing.T) {
+	reply := `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0" message-id="m2"><data><a/></data></rpc-reply>`
+	s, trans := newScriptedSession(nil,
+		testNotification(1),
+		`<?xml version="1.0" encoding="UTF-8"?>`+reply,
+		`<hello xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"/>`,
+	)
+	ctx := context.Background()
+
+	id, err := s.SendRPC(ctx, MethodGetConfig("running"))
+	if err != nil {
+		t.Fatalf("SendRPC failed: %v", err)
+	}
+	if len(trans.sent) != 1 || !strings.Contains(trans.sent[0], `message-id="`+id+`"`) {
+		t.Errorf("unexpected request %q for message-id %s", trans.sent, id)
+	}
+
+	var kinds []MessageKind
+	var ids []string
+	for {
+		m, err := s.Receive(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Receive failed: %v", err)
+		}
+		kinds = append(kinds, m.Kind)
+		ids = append(ids, m.MessageID)
+
+		switch m.Kind {
+		case MessageNotification:
+			if _, err := m.Notification(); err != nil {
+				t.Errorf("Notification failed: %v", err)
+			}
+		case MessageReply:
+			r, err := m.Reply()
+			if err != nil || r.Data.String() != "<data><a/></data>" || r.MessageID != "m2" {
+				t.Errorf("unexpected reply %+v: %v", r, err)
+			}
+			if m.Raw.String() != reply {
+				t.Errorf("declaration not stripped: %q", m.Raw)
+			}
+		}
+	}
+	if diff := cmp.Diff([]MessageKind{MessageNotification, MessageReply, MessageOther}, kinds); diff != "" {
+		t.Errorf("kinds mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"", "m2", ""}, ids); diff != "" {
+		t.Errorf("message-ids mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSessionReceiveCancel(t *testing.T) {
+	trans := &silentTransport{done: make(chan struct{})}
+	s := &Session{Transport: trans}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := s.Receive(ctx); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, err := s.Receive(context.Background()); err != ErrSessionAbandoned {
+		t.Errorf("expected ErrSessionAbandoned, got %v", err)
+	}
+	if _, err := s.SendRPC(context.Background(), MethodCommit()); err != ErrSessionAbandoned {
+		t.Errorf("expected ErrSessionAbandoned, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// DefaultRedaction replaces redacted content.
+const DefaultRedaction = "********"
+
+// RedactRule selects elements whose content is redacted.
+type RedactRule struct {
+	// Element matches elements by local name, e.g. "password".
+	Element string
+	// Path matches elements by a path of local names separated by slashes,
+	// a subset of XPath.  Relative paths such as "authentication/key" match
+	// at any depth, absolute paths such as "/configuration/snmp/community"
+	// from the root.  A "*" step matches any element.
+	Path string
+}
+
+// Redactor masks secrets in XML, such as passwords and keys, before
+// transcripts, logs and dry-run output leave the process.
+type Redactor struct {
+	Rules []RedactRule
+	// Replacement replaces the content of matched elements,
+	// DefaultRedaction if empty.
+	Replacement string
+}
+
+// NewRedactor returns a redactor applying rules.
+func NewRedactor(rules ...RedactRule) *Redactor {
+	return &Redactor{Rules: rules}
+}
+
+// DefaultRedactRules mask the secrets commonly found in configurations.
+var DefaultRedactRules = []RedactRule{
+	{Element: "password"},
+	{Element: "secret"},
+	{Element: "key-data"},
+	{Element: "encrypted-password"},
+	{Element: "pre-shared-key"},
+	{Element: "private-key"},
+	{Element: "authentication-key"},
+	{Element: "community"},
+}
+
+// Redact returns data with the content of matched elements replaced.  data
+// may be an XML fragment with several roots, or text with embedded XML such
+// as a log message.  Once malformed XML is met, the remaining elements are
+// matched by Element rules only.
+func (r *Redactor) Redact(data []byte) []byte {
+	if r == nil || len(r.Rules) == 0 || !bytes.ContainsRune(data, '<') {
+		return data
+	}
+	repl := r.Replacement
+	if repl == "" {
+		repl = DefaultRedaction
+	}
+
+	var out bytes.Buffer
+	copied := int64(0)
+	var stack []string
+	// matched is the depth of the outermost matched element being
+	// redacted and start the offset of its content.
+	matched, start := -1, int64(0)
+
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+	for {
+		before := d.InputOffset()
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if matched >= 0 {
+				// Fail closed inside a matched element.
+				out.Write(data[copied:start])
+				out.WriteString(repl)
+				return out.Bytes()
+			}
+			out.Write(r.redactNames(data[copied:], repl))
+			return out.Bytes()
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, tok.Name.Local)
+			if matched < 0 && r.match(stack) {
+				matched, start = len(stack), d.InputOffset()
+			}
+		case xml.EndElement:
+			if len(stack) == 0 || stack[len(stack)-1] != tok.Name.Local {
+				continue
+			}
+			if matched == len(stack) {
+				out.Write(data[copied:start])
+				if before > start {
+					out.WriteString(repl)
+				}
+				copied = before
+				matched = -1
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if matched >= 0 {
+		out.Write(data[copied:start])
+		out.WriteString(repl)
+		return out.Bytes()
+	}
+	out.Write(data[copied:])
+	return out.Bytes()
+}
+
+// match reports whether the element at the top of stack is selected.
+func (r *Redactor) match(stack []string) bool {
+	name := stack[len(stack)-1]
+	for _, rule := range r.Rules {
+		if rule.Element != "" && rule.Element == name {
+			return true
+		}
+		if rule.Path != "" && matchPath(rule.Path, stack) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPath(path string, stack []string) bool {
+	absolute := strings.HasPrefix(path, "/")
+	steps := strings.Split(strings.Trim(path, "/"), "/")
+	if len(steps) > len(stack) || absolute && len(steps) != len(stack) {
+		return false
+	}
+	tail := stack[len(stack)-len(steps):]
+	for i, step := range steps {
+		if i := strings.IndexByte(step, ':'); i >= 0 {
+			step = step[i+1:]
+		}
+		if step != "*" && step != tail[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// redactNames replaces the content of elements matched by Element rules in
+// text that could not be parsed.
+func (r *Redactor) redactNames(data []byte, repl string) []byte {
+	for _, rule := range r.Rules {
+		if rule.Element == "" {
+			continue
+		}
+		name := regexp.QuoteMeta(rule.Element)
+		re := regexp.MustCompile(`(?s)(<(?:[\w.-]+:)?` + name + `(?:\s[^>]*)?>).*?(</(?:[\w.-]+:)?` + name + `\s*>)`)
+		data = re.ReplaceAll(data, []byte("${1}"+strings.Replace(repl, "$", "$$", -1)+"${2}"))
+	}
+	return data
+}
+
+// RedactString is Redact for strings.
+func (r *Redactor) RedactString(s string) string {
+	return string(r.Redact([]byte(s)))
+}
+
+// Logger returns a logger passing redacted messages to l.
+func (r *Redactor) Logger(l Logger) Logger {
+	return &redactingLogger{l: l, r: r}
+}
+
+type redactingLogger struct {
+	l Logger
+	r *Redactor
+}
+
+func (rl *redactingLogger) Printf(format string, v ...interface{}) {
+	rl.l.Printf("%s", rl.r.RedactString(fmt.Sprintf(format, v...)))
+}
@@ -0,0 +1,108 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRedact(t *testing.T) {
+	r := NewRedactor(DefaultRedactRules...)
+	r.Rules = append(r.Rules, RedactRule{Path: "radius/server/secret-key"}, RedactRule{Path: "/configuration/system/host-name"})
+
+	tt := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "element",
+			input:    `<user><name>ops</name><password type="hash">$6$abc</password></user>`,
+			expected: `<user><name>ops</name><password type="hash">********</password></user>`,
+		},
+		{
+			name:     "prefixed nested",
+			input:    `<a:key-data xmlns:a="urn:x"><part>1</part><part>2</part></a:key-data><b/>`,
+			expected: `<a:key-data xmlns:a="urn:x">********</a:key-data><b/>`,
+		},
+		{
+			name:     "empty",
+			input:    `<secret/><secret></secret>`,
+			expected: `<secret/><secret></secret>`,
+		},
+		{
+			name:     "relative path",
+			input:    `<radius><server><secret-key>k</secret-key></server></radius><server><secret-key>k</secret-key></server>`,
+			expected: `<radius><server><secret-key>********</secret-key></server></radius><server><secret-key>k</secret-key></server>`,
+		},
+		{
+			name:     "absolute path",
+			input:    `<configuration><system><host-name>r1</host-name></system></configuration><system><host-name>r2</host-name></system>`,
+			expected: `<configuration><system><host-name>********</host-name></system></configuration><system><host-name>r2</host-name></system>`,
+		},
+		{
+			name:     "log message",
+			input:    `rpc failed: <edit-config><password>hunter2</password></edit-config> (attempt 1)`,
+			expected: `rpc failed: <edit-config><password>********</password></edit-config> (attempt 1)`,
+		},
+		{
+			name:     "malformed",
+			input:    `a < b <password>hunter2</password>`,
+			expected: `a < b <password>********</password>`,
+		},
+		{
+			name:     "truncated",
+			input:    `<user><password>hunt`,
+			expected: `<user><password>********`,
+		},
+		{
+			name:     "no secrets",
+			input:    `<get-config><source><running/></source></get-config>`,
+			expected: `<get-config><source><running/></source></get-config>`,
+		},
+	}
+	for _, tc := range tt {
+		if diff := cmp.Diff(tc.expected, r.RedactString(tc.input)); diff != "" {
+			t.Errorf("%s: mismatch (-want +got):\n%s", tc.name, diff)
+		}
+	}
+
+	var nilRedactor *Redactor
+	if got := nilRedactor.RedactString("<password>x</password>"); got != "<password>x</password>" {
+		t.Errorf("nil redactor changed data: %s", got)
+	}
+}
+
+func TestRedactorLoggerAndAudit(t *testing.T) {
+	r := NewRedactor(RedactRule{Element: "password"})
+	r.Replacement = "***"
+
+	var logs bytes.Buffer
+	l := r.Logger(log.New(&logs, "", 0))
+	l.Printf("sending %s", "<password>hunter2</password>")
+	if got := strings.TrimSpace(logs.String()); got != "sending <password>***</password>" {
+		t.Errorf("unexpected log output %q", got)
+	}
+
+	var buf bytes.Buffer
+	audit := NewAuditLog(&buf)
+	audit.Redactor = r
+	if err := audit.Record(AuditRequest, "edit-config", []byte("<config><password>hunter2</password></config>"), nil); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("secret recorded in audit log: %s", buf.String())
+	}
+	if _, err := VerifyAuditLog(&buf); err != nil {
+		t.Errorf("VerifyAuditLog failed: %v", err)
+	}
+}
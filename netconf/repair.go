@@ -0,0 +1,182 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"regexp"
+)
+
+var (
+	entityRE = regexp.MustCompile(`^&(#[0-9]+|#x[0-9a-fA-F]+|[A-Za-z_][A-Za-z0-9_.-]*);`)
+	attrRE   = regexp.MustCompile(`\s+([^\s=/>]+)\s*=\s*("[^"]*"|'[^']*')`)
+)
+
+// markup sections copied verbatim by RepairXML, by opening and closing
+// delimiter.
+var verbatimSections = [][2]string{
+	{"<!--", "-->"},
+	{"<![CDATA[", "]]>"},
+	{"<?", "?>"},
+	{"<!", ">"},
+}
+
+// RepairXML fixes common defects of XML emitted by older device firmware so
+// that it can be parsed: control characters not allowed in XML are removed,
+// ampersands that do not start an entity or character reference are escaped
+// and repeated attributes, typically namespace declarations, are dropped.
+// Well-formed XML is returned unchanged.
+func RepairXML(data []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(data))
+
+	for i := 0; i < len(data); {
+		switch c := data[i]; {
+		case c == '<':
+			n := verbatimLen(data[i:])
+			if n == 0 {
+				n = tagLen(data[i:])
+				out.Write(repairTag(data[i : i+n]))
+			} else {
+				out.Write(data[i : i+n])
+			}
+			i += n
+			continue
+		case c == '&':
+			if entityRE.Match(data[i:]) {
+				out.WriteByte(c)
+			} else {
+				out.WriteString("&amp;")
+			}
+		case isIllegalControl(c):
+		default:
+			out.WriteByte(c)
+		}
+		i++
+	}
+	return out.Bytes()
+}
+
+// verbatimLen returns the length of the comment, CDATA section, processing
+// instruction or declaration at the start of data, or 0 if there is none.
+func verbatimLen(data []byte) int {
+	for _, s := range verbatimSections {
+		if !bytes.HasPrefix(data, []byte(s[0])) {
+			continue
+		}
+		end := bytes.Index(data[len(s[0]):], []byte(s[1]))
+		if end < 0 {
+			return len(data)
+		}
+		return len(s[0]) + end + len(s[1])
+	}
+	return 0
+}
+
+// tagLen returns the length of the tag at the start of data.  Quoted
+// attribute values may contain '>'.
+func tagLen(data []byte) int {
+	var quote byte
+	for i := 1; i < len(data); i++ {
+		switch c := data[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i + 1
+		case c == '<':
+			// An unterminated tag; let the parser report it.
+			return i
+		}
+	}
+	return len(data)
+}
+
+// repairTag drops repeated attributes from a start tag and repairs their
+// values.
+func repairTag(tag []byte) []byte {
+	attrs := attrRE.FindAllSubmatchIndex(tag, -1)
+	if len(attrs) == 0 {
+		return tag
+	}
+
+	var out bytes.Buffer
+	seen := make(map[string]bool)
+	last := 0
+	for _, loc := range attrs {
+		out.Write(tag[last:loc[0]])
+		last = loc[1]
+
+		name := string(tag[loc[2]:loc[3]])
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		out.Write(tag[loc[0]:loc[4]])
+		out.Write(RepairXML(tag[loc[4]:loc[5]]))
+	}
+	out.Write(tag[last:])
+	return out.Bytes()
+}
+
+// utf8BOM is the UTF-8 encoded byte order mark.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// stripDeclarations removes byte order marks and XML declarations from a
+// reply.  Some devices emit them after the chunk header of every chunk or in
+// the middle of the reply, where they end up in RPCReply.Data or break
+// parsing.  Comments and CDATA sections are left alone.
+func stripDeclarations(data []byte) []byte {
+	if !bytes.Contains(data, utf8BOM) && !bytes.Contains(data, []byte("<?xml")) {
+		return data
+	}
+
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); {
+		rest := data[i:]
+		switch {
+		case bytes.HasPrefix(rest, utf8BOM):
+			i += len(utf8BOM)
+		case isDeclaration(rest):
+			end := bytes.Index(rest, []byte("?>"))
+			if end < 0 {
+				return append(out, rest...)
+			}
+			i += end + 2
+		case bytes.HasPrefix(rest, []byte("<!--")), bytes.HasPrefix(rest, []byte("<![CDATA[")):
+			n := verbatimLen(rest)
+			out = append(out, rest[:n]...)
+			i += n
+		default:
+			out = append(out, data[i])
+			i++
+		}
+	}
+	return out
+}
+
+// isDeclaration reports whether data starts with an XML declaration rather
+// than a processing instruction whose target merely starts with "xml".
+func isDeclaration(data []byte) bool {
+	if !bytes.HasPrefix(data, []byte("<?xml")) || len(data) < 6 {
+		return false
+	}
+	switch data[5] {
+	case ' ', '\t', '\r', '\n', '?':
+		return true
+	}
+	return false
+}
+
+// isIllegalControl reports whether c is a control character XML 1.0 does not
+// allow.
+func isIllegalControl(c byte) bool {
+	return c < 0x20 && c != '\t' && c != '\n' && c != '\r'
+}
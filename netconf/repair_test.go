@@ -0,0 +1,97 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import "testing"
+
+func TestRepairXML(t *testing.T) {
+	tt := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "wellFormed",
+			input: `<a x="1 > 0"><!-- & --><![CDATA[ & < ]]><b>&amp;&#38;&#x26;&lt;</b></a>`,
+			want:  `<a x="1 > 0"><!-- & --><![CDATA[ & < ]]><b>&amp;&#38;&#x26;&lt;</b></a>`,
+		},
+		{
+			name:  "controlCharacters",
+			input: "<description>port\x00 one\x1b</description>\n",
+			want:  "<description>port one</description>\n",
+		},
+		{
+			name:  "ampersand",
+			input: `<description a="R&D">R&D & co</description>`,
+			want:  `<description a="R&amp;D">R&amp;D &amp; co</description>`,
+		},
+		{
+			name:  "duplicateNamespace",
+			input: `<rpc-reply xmlns="urn:a" xmlns:junos="urn:j" xmlns="urn:a" xmlns:junos="urn:j"><ok/></rpc-reply>`,
+			want:  `<rpc-reply xmlns="urn:a" xmlns:junos="urn:j"><ok/></rpc-reply>`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := string(RepairXML([]byte(tc.input))); got != tc.want {
+				t.Errorf("RepairXML(%q) = %q, want %q", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSessionTolerantXML(t *testing.T) {
+	reply := "<rpc-reply xmlns=\"urn:ietf:params:xml:ns:netconf:base:1.0\"><data><d>R&D\x01</d></data></rpc-reply>"
+
+	s, _ := newScriptedSession(nil, reply)
+	if _, err := s.Exec(MethodGetConfig("running")); err == nil {
+		t.Fatalf("expected strict parsing to fail")
+	}
+
+	s, _ = newScriptedSession(nil, reply)
+	s.TolerantXML = true
+	r, err := s.Exec(MethodGetConfig("running"))
+	if err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if r.Data.String() != "<data><d>R&amp;D</d></data>" {
+		t.Errorf("unexpected data %q", r.Data)
+	}
+}
+
+func TestStripDeclarations(t *testing.T) {
+	tt := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "prologue",
+			input: "\xef\xbb\xbf<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<rpc-reply/>",
+			want:  "\n<rpc-reply/>",
+		},
+		{
+			name:  "midStream",
+			input: "<rpc-reply><data>\xef\xbb\xbf<?xml version=\"1.0\"?><a/></data></rpc-reply>",
+			want:  "<rpc-reply><data><a/></data></rpc-reply>",
+		},
+		{
+			name:  "keepOther",
+			input: "<r><?xml-stylesheet href=\"s\"?><![CDATA[<?xml version=\"1.0\"?>]]><!-- <?xml ?> --></r>",
+			want:  "<r><?xml-stylesheet href=\"s\"?><![CDATA[<?xml version=\"1.0\"?>]]><!-- <?xml ?> --></r>",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := string(stripDeclarations([]byte(tc.input))); got != tc.want {
+				t.Errorf("stripDeclarations(%q) = %q, want %q", tc.input, got, tc.want)
+			}
+		})
+	}
+}
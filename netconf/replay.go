@@ -0,0 +1,313 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultResumeBackoff is used by ResumeOptions without Backoff.
+var defaultResumeBackoff = ExponentialBackoff(time.Second, time.Minute)
+
+// NotificationGap reports events a ResumingSubscription may have missed
+// while it reconnected.
+type NotificationGap struct {
+	// From is the eventTime of the last notification delivered before the
+	// subscription was lost.
+	From time.Time
+	// To is the time events are complete again from: the start of the
+	// replay log if it no longer reaches back to From, or the time of the
+	// new subscription if the stream has no replay.
+	To time.Time
+	// Err is the error that ended the previous subscription.
+	Err error
+}
+
+func (g *NotificationGap) String() string {
+	return fmt.Sprintf("notifications between %s and %s may be missing: %v",
+		g.From.Format(time.RFC3339Nano), g.To.Format(time.RFC3339Nano), g.Err)
+}
+
+// ResumeOptions tunes NewResumingSubscription.  Overflow and BlockTimeout
+// of the embedded SubscriptionOptions are ignored: notifications are never
+// discarded, a slow consumer stops reading from the server instead.
+type ResumeOptions struct {
+	SubscriptionOptions
+	// Backoff returns the delay before the given reconnection attempt
+	// (starting at 1).  If nil, ExponentialBackoff(1s, 1m) is used.
+	Backoff func(attempt int) time.Duration
+	// OnGap, if set, is called for every gap detected, before the first
+	// notification of the new subscription is delivered.
+	OnGap func(g *NotificationGap)
+	// Logger, if set, receives a message for every failed reconnection.
+	Logger Logger
+}
+
+// ResumingSubscription is a subscription that survives the loss of its
+// session.  It tracks the eventTime of the last notification delivered and,
+// when the session fails, dials a new one and resubscribes with a replay
+// from that time if the stream supports replay.  Replayed notifications
+// already delivered are dropped.  Events that could not be replayed are
+// reported as a NotificationGap.
+type ResumingSubscription struct {
+	// C delivers the notifications.  It is closed when the subscription
+	// ends: once its stop time is reached, its context is done or it is
+	// closed.
+	C <-chan *Notification
+
+	c      chan *Notification
+	dial   func(ctx context.Context) (*Session, error)
+	opts   ResumeOptions
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu        sync.Mutex
+	started   time.Time
+	last      time.Time
+	delivered bool
+	seen      map[string]bool
+	gaps      []NotificationGap
+}
+
+// NewResumingSubscription dials a session and subscribes on it.  The
+// subscription runs until ctx is done or it is closed; sessions are dialed
+// with dial, and closed by the subscription.  opts may be nil.
+func NewResumingSubscription(ctx context.Context, dial func(ctx context.Context) (*Session, error), opts *ResumeOptions) (*ResumingSubscription, error) {
+	if opts == nil {
+		opts = &ResumeOptions{}
+	}
+	size := opts.BufferSize
+	if size <= 0 {
+		size = DefaultSubscriptionBuffer
+	}
+	r := &ResumingSubscription{
+		c:    make(chan *Notification, size),
+		dial: dial,
+		opts: *opts,
+		done: make(chan struct{}),
+		seen: make(map[string]bool),
+	}
+	r.C = r.c
+
+	r.started = time.Now()
+	sub, _, err := r.subscribe(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	ctx, r.cancel = context.WithCancel(ctx)
+	go r.run(ctx, sub)
+	return r, nil
+}
+
+// LastEventTime returns the eventTime of the last notification delivered,
+// or the zero time if none was.
+func (r *ResumingSubscription) LastEventTime() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.delivered {
+		return time.Time{}
+	}
+	return r.last
+}
+
+// Gaps returns the gaps detected so far, oldest first.
+func (r *ResumingSubscription) Gaps() []NotificationGap {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]NotificationGap(nil), r.gaps...)
+}
+
+// Close ends the subscription and closes its session.
+func (r *ResumingSubscription) Close() error {
+	r.cancel()
+	<-r.done
+	return nil
+}
+
+func (r *ResumingSubscription) run(ctx context.Context, sub *Subscription) {
+	defer close(r.done)
+	defer close(r.c)
+
+	replaying := false
+	for {
+		err := r.forward(ctx, sub, replaying)
+		sub.Close()
+		// Wait for the subscription to end, so that none of its
+		// goroutines outlive it.
+		for range sub.C {
+		}
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		for attempt := 1; ; attempt++ {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(r.backoff(attempt)):
+			}
+			var rerr error
+			if sub, replaying, rerr = r.subscribe(ctx, err); rerr == nil {
+				break
+			}
+			if r.opts.Logger != nil {
+				r.opts.Logger.Printf("netconf: resubscribe attempt %d failed: %v", attempt, rerr)
+			}
+		}
+	}
+}
+
+func (r *ResumingSubscription) backoff(attempt int) time.Duration {
+	if r.opts.Backoff != nil {
+		return r.opts.Backoff(attempt)
+	}
+	return defaultResumeBackoff(attempt)
+}
+
+// forward passes the notifications of sub to the consumer until sub ends,
+// returning the error it ended with, or nil once ctx is done.
+func (r *ResumingSubscription) forward(ctx context.Context, sub *Subscription, replaying bool) error {
+	for {
+		select {
+		case n, ok := <-sub.C:
+			if !ok {
+				return sub.Err()
+			}
+			if replaying && isReplayComplete(n) {
+				// The consumer did not ask for this replay.
+				replaying = false
+				continue
+			}
+			if !r.record(n, replaying) {
+				continue
+			}
+			select {
+			case r.c <- n:
+			case <-ctx.Done():
+				return nil
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// record tracks n as delivered.  It reports false for a replayed
+// notification that was delivered before.
+func (r *ResumingSubscription) record(n *Notification, replaying bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event := string(n.Event)
+	if replaying && r.delivered && (n.EventTime.Before(r.last) || n.EventTime.Equal(r.last) && r.seen[event]) {
+		return false
+	}
+	if !r.delivered || n.EventTime.After(r.last) {
+		r.delivered = true
+		r.last = n.EventTime
+		r.seen = make(map[string]bool)
+	}
+	if n.EventTime.Equal(r.last) {
+		// Events sharing the time of the last one are told apart by content.
+		r.seen[event] = true
+	}
+	return true
+}
+
+// resumePoint returns the time to replay from: the eventTime of the last
+// notification delivered, else the start time of the subscription.
+func (r *ResumingSubscription) resumePoint() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case r.delivered:
+		return r.last
+	case !r.opts.StartTime.IsZero():
+		return r.opts.StartTime
+	}
+	return r.started
+}
+
+// subscribe dials a session and subscribes on it.  cause is the error that
+// ended the previous subscription, nil for the first one.  replaying
+// reports whether the previous events are replayed.
+func (r *ResumingSubscription) subscribe(ctx context.Context, cause error) (sub *Subscription, replaying bool, err error) {
+	s, err := r.dial(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	opts := r.opts.SubscriptionOptions
+	opts.Overflow = OverflowBlock
+	opts.BlockTimeout = 0
+
+	var gap *NotificationGap
+	if cause != nil {
+		from := r.resumePoint()
+		gap = &NotificationGap{From: from, Err: cause}
+		opts.StartTime = time.Time{}
+		if stream := replayStream(ctx, s, opts.Stream); stream != nil {
+			opts.StartTime = from
+			replaying = true
+			start := stream.ReplayLogCreationTime
+			if stream.ReplayLogAgedTime.After(start) {
+				start = stream.ReplayLogAgedTime
+			}
+			if start.After(from) {
+				gap.To = start
+			} else {
+				gap = nil
+			}
+		}
+	}
+
+	sub, err = s.Subscribe(ctx, &opts)
+	if err != nil {
+		s.Close()
+		return nil, false, err
+	}
+	if gap != nil {
+		if gap.To.IsZero() {
+			gap.To = time.Now()
+		}
+		r.mu.Lock()
+		r.gaps = append(r.gaps, *gap)
+		r.mu.Unlock()
+		if r.opts.Logger != nil {
+			r.opts.Logger.Printf("netconf: %s", gap)
+		}
+		if r.opts.OnGap != nil {
+			r.opts.OnGap(gap)
+		}
+	}
+	return sub, replaying, nil
+}
+
+// replayStream returns the description of the stream on s if it supports
+// replay, or nil if it does not or cannot be queried.
+func replayStream(ctx context.Context, s *Session, name string) *NotificationStream {
+	if name == "" {
+		name = "NETCONF"
+	}
+	streams, err := s.NotificationStreams(ctx)
+	if err != nil {
+		return nil
+	}
+	for i := range streams {
+		if streams[i].Name == name && streams[i].ReplaySupport {
+			return &streams[i]
+		}
+	}
+	return nil
+}
+
+// isReplayComplete reports whether n is the replayComplete event sent once
+// the replay of a subscription with a start time finished.
+func isReplayComplete(n *Notification) bool {
+	return isStreamEvent(n, "replayComplete")
+}
@@ -0,0 +1,169 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testStreams(replay bool, aged string) string {
+	stream := `<stream><name>NETCONF</name><replaySupport>false</replaySupport></stream>`
+	if replay {
+		stream = `<stream><name>NETCONF</name><replaySupport>true</replaySupport>` +
+			`<replayLogCreationTime>2020-01-01T00:00:00Z</replayLogCreationTime>`
+		if aged != "" {
+			stream += `<replayLogAgedTime>` + aged + `</replayLogAgedTime>`
+		}
+		stream += `</stream>`
+	}
+	return `<rpc-reply><data><netconf xmlns="urn:ietf:params:xml:ns:netmod:notification"><streams>` + stream +
+		`</streams></netconf></data></rpc-reply>`
+}
+
+const (
+	testReplayComplete       = `<notification xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0"><eventTime>2020-01-02T03:04:09Z</eventTime><replayComplete xmlns="urn:ietf:params:xml:ns:netmod:notification"/></notification>`
+	testNotificationComplete = `<notification xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0"><eventTime>2020-01-02T03:04:09Z</eventTime><notificationComplete/></notification>`
+	testNotificationSameTime = `<notification><eventTime>2020-01-02T03:04:02Z</eventTime><event xmlns="urn:x"><seq>2b</seq></event></notification>`
+	testLastEventTime        = "2020-01-02T03:04:02Z"
+)
+
+func TestResumingSubscription(t *testing.T) {
+	last := time.Date(2020, 1, 2, 3, 4, 2, 0, time.UTC)
+	tt := []struct {
+		name     string
+		resumed  []string
+		events   []string
+		gaps     []NotificationGap
+		startSet bool
+	}{
+		{
+			name: "replay",
+			resumed: []string{testStreams(true, ""), replyOK,
+				testNotification(2), testNotificationSameTime, testNotification(3), testReplayComplete, testNotification(4), testNotificationComplete},
+			events:   []string{"1", "2", "2b", "3", "4"},
+			startSet: true,
+		},
+		{
+			name:     "aged replay log",
+			resumed:  []string{testStreams(true, "2020-01-02T03:04:03Z"), replyOK, testNotification(3), testReplayComplete, testNotificationComplete},
+			events:   []string{"1", "2", "3"},
+			gaps:     []NotificationGap{{From: last, To: time.Date(2020, 1, 2, 3, 4, 3, 0, time.UTC)}},
+			startSet: true,
+		},
+		{
+			name:    "no replay",
+			resumed: []string{testStreams(false, ""), replyOK, testNotification(4), testNotificationComplete},
+			events:  []string{"1", "2", "4"},
+			gaps:    []NotificationGap{{From: last}},
+		},
+	}
+
+	for _, tc := range tt {
+		first, _ := newScriptedSession([]string{CapabilityNotification}, replyOK, testNotification(1), testNotification(2))
+		resumed, trans := newScriptedSession([]string{CapabilityNotification}, tc.resumed...)
+		sessions := []*Session{first, resumed}
+		dial := func(ctx context.Context) (*Session, error) {
+			if len(sessions) == 0 {
+				return nil, errors.New("unreachable")
+			}
+			s := sessions[0]
+			sessions = sessions[1:]
+			return s, nil
+		}
+		var reported []*NotificationGap
+		r, err := NewResumingSubscription(context.Background(), dial, &ResumeOptions{
+			Backoff: func(int) time.Duration { return 0 },
+			OnGap:   func(g *NotificationGap) { reported = append(reported, g) },
+		})
+		if err != nil {
+			t.Fatalf("%s: NewResumingSubscription failed: %v", tc.name, err)
+		}
+
+		var events []string
+		for n := range r.C {
+			v := n.Event.String()
+			events = append(events, v[strings.Index(v, "<seq>")+5:strings.Index(v, "</seq>")])
+		}
+		r.Close()
+		if diff := cmp.Diff(tc.events, events); diff != "" {
+			t.Errorf("%s: events mismatch (-expected +got):\n%s", tc.name, diff)
+		}
+
+		gaps := r.Gaps()
+		if len(gaps) != len(tc.gaps) || len(reported) != len(tc.gaps) {
+			t.Errorf("%s: got gaps %v, reported %v, expected %v", tc.name, gaps, reported, tc.gaps)
+		}
+		for i := 0; i < len(gaps) && i < len(tc.gaps); i++ {
+			if !gaps[i].From.Equal(tc.gaps[i].From) || gaps[i].Err == nil {
+				t.Errorf("%s: unexpected gap %v", tc.name, &gaps[i])
+			}
+			if !tc.gaps[i].To.IsZero() && !gaps[i].To.Equal(tc.gaps[i].To) {
+				t.Errorf("%s: got gap to %s, expected %s", tc.name, gaps[i].To, tc.gaps[i].To)
+			}
+		}
+
+		subscribe := trans.sent[len(trans.sent)-1]
+		if got := strings.Contains(subscribe, "<startTime>"+testLastEventTime+"</startTime>"); got != tc.startSet {
+			t.Errorf("%s: unexpected resubscription %s", tc.name, subscribe)
+		}
+		if !trans.closed {
+			t.Errorf("%s: expected the session to be closed", tc.name)
+		}
+	}
+}
+
+func TestResumingSubscriptionClose(t *testing.T) {
+	s, trans := newScriptedSession([]string{CapabilityNotification}, replyOK)
+	dialed := 0
+	dial := func(ctx context.Context) (*Session, error) {
+		dialed++
+		if dialed > 1 {
+			return nil, errors.New("unreachable")
+		}
+		return s, nil
+	}
+	r, err := NewResumingSubscription(context.Background(), dial, &ResumeOptions{Backoff: func(int) time.Duration { return time.Millisecond }})
+	if err != nil {
+		t.Fatalf("NewResumingSubscription failed: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	r.Close()
+	if _, ok := <-r.C; ok {
+		t.Error("expected C to be closed")
+	}
+	if !trans.closed || !r.LastEventTime().IsZero() {
+		t.Errorf("got closed %v, last event %s, expected a closed session and no event", trans.closed, r.LastEventTime())
+	}
+}
+
+func TestResumingSubscriptionCloseBlocked(t *testing.T) {
+	replies := []string{replyOK}
+	for i := 1; i <= 5; i++ {
+		replies = append(replies, testNotification(i))
+	}
+	s, trans := newScriptedSession([]string{CapabilityNotification}, replies...)
+	dial := func(ctx context.Context) (*Session, error) { return s, nil }
+	r, err := NewResumingSubscription(context.Background(), dial, &ResumeOptions{
+		SubscriptionOptions: SubscriptionOptions{BufferSize: 1},
+	})
+	if err != nil {
+		t.Fatalf("NewResumingSubscription failed: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	r.Close()
+	// Unless the subscription was drained, its reader would still be
+	// blocked on the full buffer.
+	if len(trans.replies) != 0 {
+		t.Errorf("%d notifications left unread after Close", len(trans.replies))
+	}
+}
@@ -0,0 +1,172 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// DeviceStatus is the outcome of a fleet job on a single device.
+type DeviceStatus string
+
+// Possible device statuses.
+const (
+	StatusOK      DeviceStatus = "ok"
+	StatusFailed  DeviceStatus = "failed"
+	StatusSkipped DeviceStatus = "skipped"
+)
+
+// DeviceResult holds the result of a fleet job on a single device.
+type DeviceResult struct {
+	Device    string        `json:"device"`
+	Status    DeviceStatus  `json:"status"`
+	Started   time.Time     `json:"started"`
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`
+	RPCErrors []RPCError    `json:"rpc_errors,omitempty"`
+	Diff      string        `json:"diff,omitempty"`
+}
+
+func (r *DeviceResult) fail(err error) {
+	r.Status = StatusFailed
+	r.Error = err.Error()
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		r.RPCErrors = append(r.RPCErrors, *rpcErr)
+	}
+}
+
+// Report is the structured result of a fleet run.
+type Report struct {
+	Started  time.Time       `json:"started"`
+	Duration time.Duration   `json:"duration"`
+	Results  []*DeviceResult `json:"results"`
+}
+
+// Count returns the number of devices with the given status.
+func (r *Report) Count(status DeviceStatus) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// OK reports whether the job succeeded on every device.
+func (r *Report) OK() bool {
+	return r.Count(StatusOK) == len(r.Results)
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteTable writes a human readable summary table of the report.
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DEVICE\tSTATUS\tDURATION\tERROR")
+	for _, res := range r.Results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", res.Device, res.Status,
+			res.Duration.Round(time.Millisecond), firstLine(res.Error))
+	}
+	fmt.Fprintf(tw, "\n%d ok, %d failed, %d skipped in %s\n",
+		r.Count(StatusOK), r.Count(StatusFailed), r.Count(StatusSkipped),
+		r.Duration.Round(time.Millisecond))
+	return tw.Flush()
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      float64         `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// WriteJUnit writes the report as a JUnit XML test suite with one test case
+// per device, suitable for gating CI pipelines.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:      "netconf",
+		Tests:     len(r.Results),
+		Failures:  r.Count(StatusFailed),
+		Skipped:   r.Count(StatusSkipped),
+		Time:      r.Duration.Seconds(),
+		Timestamp: r.Started.UTC().Format(time.RFC3339),
+	}
+
+	for _, res := range r.Results {
+		tc := junitTestCase{
+			Name:      res.Device,
+			ClassName: "netconf",
+			Time:      res.Duration.Seconds(),
+			SystemOut: res.Diff,
+		}
+		switch res.Status {
+		case StatusFailed:
+			var body strings.Builder
+			for _, e := range res.RPCErrors {
+				fmt.Fprintf(&body, "%s %s [%s] %s\n", e.Type, e.Tag, e.Path, strings.TrimSpace(e.Message))
+			}
+			tc.Failure = &junitFailure{Message: res.Error, Body: body.String()}
+		case StatusSkipped:
+			tc.Skipped = &junitSkipped{Message: res.Error}
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
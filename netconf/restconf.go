@@ -0,0 +1,293 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Media type and namespace of RESTCONF (RFC 8040).
+const (
+	RESTCONFMediaType = "application/yang-data+xml"
+	RESTCONFNamespace = "urn:ietf:params:xml:ns:yang:ietf-restconf"
+)
+
+// RESTCONFClient accesses the datastores of a device over RESTCONF, using
+// the XML encoding so that data is exchanged as Node trees as with NETCONF.
+//
+// Paths are RFC 8040 data resource paths relative to the data resource,
+// e.g. ietf-interfaces:interfaces/interface=eth0, see RESTCONFKey.
+type RESTCONFClient struct {
+	// URL is the base URL of the server, e.g. https://r1.example.net.
+	URL string
+	// Root is the path of the RESTCONF root resource.  If empty it is
+	// discovered from /.well-known/host-meta, falling back to /restconf.
+	Root string
+	// Client is used for the requests.  If nil a client is created
+	// presenting the certificate of Credentials, if any.
+	Client *http.Client
+	// Credentials, if set, provide the username and password for basic
+	// authentication and the client certificate.
+	Credentials *Credentials
+	// Header holds additional request headers.
+	Header http.Header
+
+	once   sync.Once
+	root   string
+	client *http.Client
+}
+
+// RESTCONFGetOptions tunes RESTCONFClient.Get.
+type RESTCONFGetOptions struct {
+	// Datastore, if set, reads an NMDA datastore (RFC 8527), e.g.
+	// DatastoreOperational, instead of the unified data resource.
+	Datastore string
+	// Content is "config", "nonconfig" or "all", the server's default if
+	// empty.
+	Content string
+	// Depth limits the depth of the returned subtree, unlimited if zero.
+	Depth int
+	// WithDefaults is a with-defaults mode, e.g. "report-all".
+	WithDefaults string
+	// Fields selects parts of the resource, e.g. "name;mtu".
+	Fields string
+}
+
+// RESTCONFError is returned for requests the server answered with an error
+// status.  It unwraps to the first of Errors, so that errors.As finds the
+// *RPCError as for NETCONF.
+type RESTCONFError struct {
+	StatusCode int
+	Errors     []RPCError
+}
+
+func (e *RESTCONFError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("netconf: restconf status %d", e.StatusCode)
+	}
+	err := e.Errors[0]
+	return fmt.Sprintf("netconf: restconf status %d: %s: %s", e.StatusCode, err.Tag, err.Message)
+}
+
+// Unwrap returns the first error reported by the server.
+func (e *RESTCONFError) Unwrap() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return &e.Errors[0]
+}
+
+// RESTCONFKey returns the suffix selecting a list entry by its key values,
+// e.g. "=ge-0%2F0%2F0" for RESTCONFKey("ge-0/0/0").
+func RESTCONFKey(values ...string) string {
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = restconfEscaper.Replace(url.PathEscape(v))
+	}
+	return "=" + strings.Join(escaped, ",")
+}
+
+// restconfEscaper escapes the characters that url.PathEscape leaves in
+// place but that separate the keys of RESTCONF paths.
+var restconfEscaper = strings.NewReplacer(",", "%2C", "=", "%3D", ":", "%3A", "'", "%27")
+
+// Get retrieves the data resource at path and returns its element.  opts
+// may be nil.
+func (c *RESTCONFClient) Get(ctx context.Context, path string, opts *RESTCONFGetOptions) (*Node, error) {
+	if opts == nil {
+		opts = &RESTCONFGetOptions{}
+	}
+	query := url.Values{}
+	if opts.Content != "" {
+		query.Set("content", opts.Content)
+	}
+	if opts.Depth > 0 {
+		query.Set("depth", strconv.Itoa(opts.Depth))
+	}
+	if opts.WithDefaults != "" {
+		query.Set("with-defaults", opts.WithDefaults)
+	}
+	if opts.Fields != "" {
+		query.Set("fields", opts.Fields)
+	}
+
+	resource := "data"
+	if opts.Datastore != "" {
+		resource = "ds/ietf-datastores:" + opts.Datastore
+	}
+	body, err := c.do(ctx, http.MethodGet, resource, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNode(body)
+}
+
+// Put creates or replaces the data resource at path with n.
+func (c *RESTCONFClient) Put(ctx context.Context, path string, n *Node) error {
+	_, err := c.do(ctx, http.MethodPut, "data", path, nil, n)
+	return err
+}
+
+// Patch merges n into the data resource at path.
+func (c *RESTCONFClient) Patch(ctx context.Context, path string, n *Node) error {
+	_, err := c.do(ctx, http.MethodPatch, "data", path, nil, n)
+	return err
+}
+
+// Post creates n as a child of the data resource at path, which is the
+// datastore itself if empty.
+func (c *RESTCONFClient) Post(ctx context.Context, path string, n *Node) error {
+	_, err := c.do(ctx, http.MethodPost, "data", path, nil, n)
+	return err
+}
+
+// Delete deletes the data resource at path.
+func (c *RESTCONFClient) Delete(ctx context.Context, path string) error {
+	_, err := c.do(ctx, http.MethodDelete, "data", path, nil, nil)
+	return err
+}
+
+// Invoke invokes the operation (RPC) named by path, e.g.
+// ietf-system:system-restart, with the given input, which may be nil.  It
+// returns the output of the operation, or nil if there is none.
+func (c *RESTCONFClient) Invoke(ctx context.Context, path string, input *Node) (*Node, error) {
+	body, err := c.do(ctx, http.MethodPost, "operations", path, nil, input)
+	if err != nil || len(bytes.TrimSpace(body)) == 0 {
+		return nil, err
+	}
+	return ParseNode(body)
+}
+
+func (c *RESTCONFClient) init(ctx context.Context) {
+	c.once.Do(func() {
+		c.client = c.Client
+		if c.client == nil {
+			c.client = http.DefaultClient
+			if c.Credentials != nil && c.Credentials.Certificate != nil {
+				c.client = &http.Client{Transport: &http.Transport{
+					Proxy:           http.ProxyFromEnvironment,
+					TLSClientConfig: &tls.Config{Certificates: []tls.Certificate{*c.Credentials.Certificate}},
+				}}
+			}
+		}
+		c.root = c.Root
+		if c.root == "" {
+			c.root = c.discoverRoot(ctx)
+		}
+		c.root = "/" + strings.Trim(c.root, "/")
+	})
+}
+
+// discoverRoot reads the RESTCONF root from the host-meta resource (RFC
+// 6415), returning /restconf if that fails.
+func (c *RESTCONFClient) discoverRoot(ctx context.Context) string {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(c.URL, "/")+"/.well-known/host-meta", nil)
+	if err != nil {
+		return "/restconf"
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/xrd+xml")
+	c.authorize(req)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "/restconf"
+	}
+	defer resp.Body.Close()
+
+	var xrd struct {
+		Links []struct {
+			Rel  string `xml:"rel,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"Link"`
+	}
+	if resp.StatusCode == http.StatusOK && xml.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&xrd) == nil {
+		for _, l := range xrd.Links {
+			if l.Rel == "restconf" && l.Href != "" {
+				return l.Href
+			}
+		}
+	}
+	return "/restconf"
+}
+
+func (c *RESTCONFClient) authorize(req *http.Request) {
+	for k, v := range c.Header {
+		req.Header[k] = v
+	}
+	if c.Credentials != nil && c.Credentials.Username != "" {
+		req.SetBasicAuth(c.Credentials.Username, c.Credentials.Password)
+	}
+}
+
+// do sends a request for path below the given resource of the RESTCONF
+// root, with n as body if not nil, and returns the response body.
+func (c *RESTCONFClient) do(ctx context.Context, method, resource, path string, query url.Values, n *Node) ([]byte, error) {
+	c.init(ctx)
+
+	u := strings.TrimSuffix(c.URL, "/") + c.root + "/" + resource
+	if path = strings.Trim(path, "/"); path != "" {
+		u += "/" + path
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if n != nil {
+		body = strings.NewReader(n.String())
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", RESTCONFMediaType)
+	if n != nil {
+		req.Header.Set("Content-Type", RESTCONFMediaType)
+	}
+	c.authorize(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, restconfError(resp.StatusCode, data)
+	}
+	return data, nil
+}
+
+// restconfError parses the <errors> of an error response.
+func restconfError(status int, body []byte) error {
+	var errs struct {
+		Errors []RPCError `xml:"error"`
+	}
+	e := &RESTCONFError{StatusCode: status}
+	if xml.Unmarshal(body, &errs) == nil {
+		e.Errors = errs.Errors
+	}
+	if len(e.Errors) == 0 && len(bytes.TrimSpace(body)) > 0 {
+		e.Errors = []RPCError{{Severity: "error", Message: strings.TrimSpace(string(body))}}
+	}
+	return e
+}
@@ -0,0 +1,170 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type restconfRequest struct {
+	Method, URI, ContentType, Body, User string
+}
+
+func newRESTCONFTest(status int, reply string) (*RESTCONFClient, *httptest.Server, *[]restconfRequest) {
+	var reqs []restconfRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/.well-known/host-meta" {
+			w.Write([]byte(`<XRD xmlns="http://docs.oasis-open.org/ns/xri/xrd-1.0"><Link rel="restconf" href="/top/restconf"/></XRD>`))
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		user, _, _ := r.BasicAuth()
+		reqs = append(reqs, restconfRequest{r.Method, r.URL.RequestURI(), r.Header.Get("Content-Type"), string(body), user})
+		w.Header().Set("Content-Type", RESTCONFMediaType)
+		w.WriteHeader(status)
+		w.Write([]byte(reply))
+	}))
+	return &RESTCONFClient{URL: srv.URL, Credentials: &Credentials{Username: "admin", Password: "secret"}}, srv, &reqs
+}
+
+func TestRESTCONFClient(t *testing.T) {
+	ns := "urn:ietf:params:xml:ns:yang:ietf-interfaces"
+	iface := NewNode(ns, "interface", NewLeaf(ns, "name", "ge-0/0/0"), NewLeaf(ns, "mtu", "9000"))
+
+	tt := []struct {
+		name     string
+		call     func(c *RESTCONFClient) error
+		expected restconfRequest
+	}{
+		{
+			name: "get",
+			call: func(c *RESTCONFClient) error {
+				_, err := c.Get(context.Background(), "ietf-interfaces:interfaces", &RESTCONFGetOptions{Depth: 2, Content: "config"})
+				return err
+			},
+			expected: restconfRequest{Method: "GET", URI: "/top/restconf/data/ietf-interfaces:interfaces?content=config&depth=2"},
+		},
+		{
+			name: "get datastore",
+			call: func(c *RESTCONFClient) error {
+				_, err := c.Get(context.Background(), "ietf-interfaces:interfaces", &RESTCONFGetOptions{Datastore: DatastoreOperational})
+				return err
+			},
+			expected: restconfRequest{Method: "GET", URI: "/top/restconf/ds/ietf-datastores:operational/ietf-interfaces:interfaces"},
+		},
+		{
+			name: "put",
+			call: func(c *RESTCONFClient) error {
+				return c.Put(context.Background(), "ietf-interfaces:interfaces/interface"+RESTCONFKey("ge-0/0/0"), iface)
+			},
+			expected: restconfRequest{
+				Method: "PUT", URI: "/top/restconf/data/ietf-interfaces:interfaces/interface=ge-0%2F0%2F0",
+				ContentType: RESTCONFMediaType, Body: iface.String(),
+			},
+		},
+		{
+			name: "patch",
+			call: func(c *RESTCONFClient) error {
+				return c.Patch(context.Background(), "ietf-interfaces:interfaces", iface)
+			},
+			expected: restconfRequest{
+				Method: "PATCH", URI: "/top/restconf/data/ietf-interfaces:interfaces",
+				ContentType: RESTCONFMediaType, Body: iface.String(),
+			},
+		},
+		{
+			name: "post",
+			call: func(c *RESTCONFClient) error {
+				return c.Post(context.Background(), "", iface)
+			},
+			expected: restconfRequest{
+				Method: "POST", URI: "/top/restconf/data",
+				ContentType: RESTCONFMediaType, Body: iface.String(),
+			},
+		},
+		{
+			name: "delete",
+			call: func(c *RESTCONFClient) error {
+				return c.Delete(context.Background(), "ietf-interfaces:interfaces/interface"+RESTCONFKey("eth0"))
+			},
+			expected: restconfRequest{Method: "DELETE", URI: "/top/restconf/data/ietf-interfaces:interfaces/interface=eth0"},
+		},
+		{
+			name: "invoke",
+			call: func(c *RESTCONFClient) error {
+				_, err := c.Invoke(context.Background(), "ietf-system:system-restart", nil)
+				return err
+			},
+			expected: restconfRequest{Method: "POST", URI: "/top/restconf/operations/ietf-system:system-restart"},
+		},
+	}
+
+	for _, tc := range tt {
+		c, srv, reqs := newRESTCONFTest(http.StatusOK, "<ok/>")
+		err := tc.call(c)
+		srv.Close()
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+			continue
+		}
+		tc.expected.User = "admin"
+		if len(*reqs) != 1 {
+			t.Errorf("%s: got %d requests, expected 1", tc.name, len(*reqs))
+			continue
+		}
+		if diff := cmp.Diff(tc.expected, (*reqs)[0]); diff != "" {
+			t.Errorf("%s: request mismatch (-expected +got):\n%s", tc.name, diff)
+		}
+	}
+}
+
+func TestRESTCONFClientGet(t *testing.T) {
+	c, srv, _ := newRESTCONFTest(http.StatusOK,
+		`<interfaces xmlns="urn:ietf:params:xml:ns:yang:ietf-interfaces"><interface><name>eth0</name></interface></interfaces>`)
+	defer srv.Close()
+	c.Root = "/restconf"
+
+	n, err := c.Get(context.Background(), "ietf-interfaces:interfaces", nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	nodes, _ := n.Select("interface/name")
+	if len(nodes) != 1 || nodes[0].Value() != "eth0" {
+		t.Errorf("got %s, expected interface eth0", n)
+	}
+}
+
+func TestRESTCONFClientError(t *testing.T) {
+	c, srv, _ := newRESTCONFTest(http.StatusConflict, `<errors xmlns="urn:ietf:params:xml:ns:yang:ietf-restconf">
+  <error>
+    <error-type>protocol</error-type>
+    <error-tag>lock-denied</error-tag>
+    <error-message>Lock failed, lock already held</error-message>
+  </error>
+</errors>`)
+	defer srv.Close()
+
+	err := c.Delete(context.Background(), "ietf-interfaces:interfaces")
+	var restErr *RESTCONFError
+	if !errors.As(err, &restErr) || restErr.StatusCode != http.StatusConflict {
+		t.Fatalf("got %v, expected a RESTCONFError with status 409", err)
+	}
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected %v to unwrap to an RPCError", err)
+	}
+	if rpcErr.Tag != "lock-denied" || rpcErr.Type != "protocol" || rpcErr.Message != "Lock failed, lock already held" {
+		t.Errorf("got %+v, expected the lock-denied error", rpcErr)
+	}
+}
@@ -0,0 +1,110 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"time"
+)
+
+// RetryPolicy controls whether and how failed RPCs are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+	// Backoff returns the delay before the given retry (starting at 1).  If
+	// nil, ExponentialBackoff(100ms, 5s) is used.
+	Backoff func(retry int) time.Duration
+	// Retryable decides whether a failed RPC may be retried.  If nil,
+	// DefaultRetryable is used.
+	Retryable func(methods []RPCMethod, err error) bool
+}
+
+// defaultBackoff is used by policies without Backoff.
+var defaultBackoff = ExponentialBackoff(100*time.Millisecond, 5*time.Second)
+
+// ExponentialBackoff returns a Backoff function doubling the delay from base
+// on every retry, up to max.
+func ExponentialBackoff(base, max time.Duration) func(retry int) time.Duration {
+	return func(retry int) time.Duration {
+		d := base
+		for i := 1; i < retry && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+// readOnlyMethods are the operations that are safe to repeat.
+var readOnlyMethods = map[string]bool{
+	"get":        true,
+	"get-config": true,
+	"get-data":   true,
+	"get-schema": true,
+}
+
+// DefaultRetryable allows retrying RPCs made up only of read operations
+// (get, get-config, get-data, get-schema) that failed with a timeout or a
+// transport error.  RPC errors reported by the server are never retried.
+// Such failures leave the session unusable, so the retry takes place on a
+// new session, see Session.RetryPolicy.
+func DefaultRetryable(methods []RPCMethod, err error) bool {
+	if !isTransientError(err) {
+		return false
+	}
+	for _, m := range methods {
+		if !readOnlyMethods[methodName(m)] {
+			return false
+		}
+	}
+	return len(methods) > 0
+}
+
+// isTransientError reports whether err is a timeout or a transport failure.
+func isTransientError(err error) bool {
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, ErrTransportBroken) || errors.As(err, &netErr) ||
+		err == io.EOF || err == io.ErrUnexpectedEOF
+}
+
+// retry reports whether a failed attempt should be retried and waits for the
+// backoff delay if so.
+func (p *RetryPolicy) retry(ctx context.Context, attempt int, methods []RPCMethod, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+	if !retryable(methods, err) {
+		return false
+	}
+
+	backoff := p.Backoff
+	if backoff == nil {
+		backoff = defaultBackoff
+	}
+	timer := time.NewTimer(backoff(attempt))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
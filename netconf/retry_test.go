@@ -0,0 +1,102 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, 300*time.Millisecond)
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, want := range expected {
+		if got := backoff(i + 1); got != want {
+			t.Errorf("backoff(%d) = %s, expected %s", i+1, got, want)
+		}
+	}
+}
+
+func TestDefaultRetryable(t *testing.T) {
+	tt := []struct {
+		name     string
+		methods  []RPCMethod
+		err      error
+		expected bool
+	}{
+		{"readeof", []RPCMethod{MethodGetConfig("running")}, io.EOF, true},
+		{"writeeof", []RPCMethod{MethodEditConfig("running", "<a/>")}, io.EOF, false},
+		{"mixed", []RPCMethod{MethodGetConfig("running"), MethodCommit()}, io.EOF, false},
+		{"rpcerror", []RPCMethod{MethodGetConfig("running")}, &RPCError{Severity: "error"}, false},
+	}
+
+	for _, tc := range tt {
+		if got := DefaultRetryable(tc.methods, tc.err); got != tc.expected {
+			t.Errorf("%s: got %v, expected %v", tc.name, got, tc.expected)
+		}
+	}
+}
+
+func TestSessionRetry(t *testing.T) {
+	// The first Receive fails with io.EOF as the script holds no reply, the
+	// retry takes place on a redialled session.
+	s, first := newScriptedSession(nil)
+	s.RetryPolicy = &RetryPolicy{MaxAttempts: 3, Backoff: func(int) time.Duration { return 0 }}
+	if _, err := s.ExecContext(context.Background(), MethodGetConfig("running")); !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("expected no retry without redial, got %v", err)
+	}
+	if len(first.sent) != 1 {
+		t.Errorf("expected a single attempt without redial, got %d", len(first.sent))
+	}
+
+	s, first = newScriptedSession(nil)
+	s.RetryPolicy = &RetryPolicy{MaxAttempts: 3, Backoff: func(int) time.Duration { return 0 }}
+	var redialled []*scriptedTransport
+	s.redial = func(ctx context.Context) (*Session, error) {
+		ns, trans := newScriptedSession([]string{CapabilityCandidate}, replyOK)
+		ns.SessionID = 7
+		redialled = append(redialled, trans)
+		return ns, nil
+	}
+	if _, err := s.ExecContext(context.Background(), MethodGetConfig("running")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !first.closed || len(first.sent) != 1 || len(redialled) != 1 || len(redialled[0].sent) != 1 {
+		t.Errorf("expected the retry on a new session, got %d redials", len(redialled))
+	}
+	if s.SessionID != 7 || !s.HasCapability(CapabilityCandidate) {
+		t.Errorf("got session %d with %v, expected the redialled session", s.SessionID, s.ServerCapabilities)
+	}
+
+	if _, err := s.ExecContext(context.Background(), MethodCommit()); !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("expected commit not to be retried, got %v", err)
+	}
+	if len(redialled) != 1 || len(redialled[0].sent) != 2 {
+		t.Errorf("expected a single commit attempt, got %d redials", len(redialled)-1)
+	}
+}
+
+func TestSessionRetryTimeout(t *testing.T) {
+	// The late reply of a timed out attempt must not be read by the retry,
+	// which therefore takes place on a new session.
+	trans := &silentTransport{done: make(chan struct{})}
+	s := &Session{Transport: trans, Deadlines: Deadlines{Read: 20 * time.Millisecond}}
+	s.RetryPolicy = &RetryPolicy{MaxAttempts: 2, Backoff: func(int) time.Duration { return 0 }}
+	s.redial = func(ctx context.Context) (*Session, error) {
+		ns, _ := newScriptedSession(nil, replyOK)
+		return ns, nil
+	}
+	if _, err := s.ExecContext(context.Background(), MethodGetConfig("running")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trans.sent) != 1 || s.Transport == Transport(trans) {
+		t.Errorf("expected the retry on a new session, got %d attempts on the first", len(trans.sent))
+	}
+}
@@ -9,10 +9,14 @@ package netconf
 import (
 	"bytes"
 	"crypto/rand"
+	"encoding"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"strings"
+	"time"
 )
 
 const (
@@ -28,6 +32,9 @@ const (
 type RPCMessage struct {
 	MessageID string
 	Methods   []RPCMethod
+	// Attrs are added to the <rpc> element.  The Space of an attribute name is written as its prefix,
+	// e.g. {Space: "xmlns", Local: "jnx"} declares the jnx namespace.
+	Attrs []xml.Attr
 }
 
 // NewRPCMessage generates a new RPC Message structure with the provided methods
@@ -38,6 +45,57 @@ func NewRPCMessage(methods []RPCMethod) *RPCMessage {
 	}
 }
 
+// WriteTo writes the message, including the XML declaration, to w.  Methods
+// implementing MethodWriter are streamed, so the message is never held in
+// memory as a whole.
+func (m *RPCMessage) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+
+	io.WriteString(cw, xml.Header)
+	io.WriteString(cw, `<rpc message-id="`)
+	xml.EscapeText(cw, []byte(m.MessageID))
+	io.WriteString(cw, `" xmlns="`+BaseNamespace+`"`)
+	for _, attr := range m.Attrs {
+		io.WriteString(cw, " "+rpcAttrName(attr)+`="`)
+		xml.EscapeText(cw, []byte(attr.Value))
+		io.WriteString(cw, `"`)
+	}
+	io.WriteString(cw, ">")
+	for _, method := range m.Methods {
+		if cw.err != nil {
+			break
+		}
+		if mw, ok := method.(MethodWriter); ok {
+			if err := mw.WriteMethod(cw); err != nil && cw.err == nil {
+				cw.err = err
+			}
+			continue
+		}
+		io.WriteString(cw, method.MarshalMethod())
+	}
+	io.WriteString(cw, "</rpc>")
+
+	return cw.n, cw.err
+}
+
+// countingWriter counts the bytes written and keeps the first error, after
+// which writes are discarded.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
+
 // MarshalXML marshals the NETCONF XML data
 func (m *RPCMessage) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	var buf bytes.Buffer
@@ -51,47 +109,64 @@ func (m *RPCMessage) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		Methods   []byte `xml:",innerxml"`
 	}{
 		m.MessageID,
-		"urn:ietf:params:xml:ns:netconf:base:1.0",
+		BaseNamespace,
 		buf.Bytes(),
 	}
 
 	// Wrap the raw XML (data) into <rpc>...</rpc> tags
 	start.Name.Local = "rpc"
+	for _, attr := range m.Attrs {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: rpcAttrName(attr)}, Value: attr.Value})
+	}
 	return e.EncodeElement(data, start)
 }
 
-// RPCReply defines a reply to a RPC request
+// rpcAttrName returns the qualified name of an attribute of the <rpc>
+// element.
+func rpcAttrName(attr xml.Attr) string {
+	if attr.Name.Space == "" {
+		return attr.Name.Local
+	}
+	return attr.Name.Space + ":" + attr.Name.Local
+}
+
+// RawXML holds XML as received from the server.
+type RawXML []byte
+
+// String returns the XML as a string.  The conversion copies the XML, so it
+// is only done on demand.
+func (r RawXML) String() string {
+	return string(r)
+}
+
+// RPCReply defines a reply to a RPC request.  Data and RawReply share the
+// memory of the received message.
 type RPCReply struct {
 	XMLName   xml.Name   `xml:"rpc-reply"`
 	Errors    []RPCError `xml:"rpc-error,omitempty"`
-	Data      string     `xml:",innerxml"`
+	Data      RawXML     `xml:"-"`
 	Ok        bool       `xml:",omitempty"`
-	RawReply  string     `xml:"-"`
+	RawReply  RawXML     `xml:"-"`
 	MessageID string     `xml:"-"`
 }
 
+// ParseRPCReply parses an rpc-reply message, for example one captured from a
+// device.  If the reply holds an rpc-error of severity error, the reply is
+// returned along with the first such error.
+func ParseRPCReply(data []byte) (*RPCReply, error) {
+	return newRPCReply(data, false, "")
+}
+
 func newRPCReply(rawXML []byte, ErrOnWarning bool, messageID string) (*RPCReply, error) {
 	reply := &RPCReply{}
-	// reply.RawReply = string(rawXML)
-	/*
-		2025/02/06 修改源代码，
-		分帧机制：
-		NETCONF 1.1 引入了分帧机制，以解决大数据传输时的性能问题。
-		NETCONF 1.0 使用 ]]>]]> 作为消息结束标记，而 NETCONF 1.1 使用 # 分帧机制来标记消息的长度。
-		这使得 NETCONF 1.1 能够更高效地处理大数据传输，并减少消息解析的复杂性
-		这里新增一个函数，用来将返回的数据去除chunk
-	*/
-	reply.RawReply = ProcessChunkedFraming(string(rawXML))
-	rawXML = []byte(reply.RawReply)
+	// Transports remove the framing, including the chunked framing of
+	// NETCONF 1.1, so the reply is kept byte for byte.
+	reply.RawReply = rawXML
 
-	/*
-		下面的xml.Unmarshal()使用的是函数中传递进来的原始数据，包含trunk fram
-		所以上面事先做了处理，不然会报错如下
-		XML syntax error on line 18: expected attribute name in element
-	*/
 	if err := xml.Unmarshal(rawXML, reply); err != nil {
 		return nil, err
 	}
+	reply.Data = innerXML(rawXML)
 
 	// will return a valid reply so setting Requests message id
 	reply.MessageID = messageID
@@ -110,7 +185,7 @@ func newRPCReply(rawXML []byte, ErrOnWarning bool, messageID string) (*RPCReply,
 		在解析xml字符串时，处理<ok/>标签会有问题，这里重新进行判断，
 		方便在主程序中利用该变量
 	*/
-	if strings.Contains(reply.RawReply, "<ok") {
+	if bytes.Contains(reply.RawReply, []byte("<ok")) {
 		reply.Ok = true
 	} else {
 		reply.Ok = false
@@ -119,6 +194,51 @@ func newRPCReply(rawXML []byte, ErrOnWarning bool, messageID string) (*RPCReply,
 	return reply, nil
 }
 
+// Decode unmarshals the data of the reply into v with encoding/xml.  v
+// describes the element holding the data: the <data> element of get and
+// get-config replies, the <rpc-reply> otherwise.
+func (r *RPCReply) Decode(v interface{}) error {
+	d := xml.NewDecoder(bytes.NewReader(r.Data))
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			break
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			if start.Name.Local == "data" {
+				return xml.Unmarshal(r.Data, v)
+			}
+			break
+		}
+	}
+	return xml.Unmarshal(r.RawReply, v)
+}
+
+// innerXML returns the content of the root element of data without copying
+// it.
+func innerXML(data []byte) RawXML {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	depth, start := 0, 0
+	for {
+		offset := int(d.InputOffset())
+		tok, err := d.RawToken()
+		if err != nil {
+			return nil
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				start = int(d.InputOffset())
+			}
+			depth++
+		case xml.EndElement:
+			if depth--; depth == 0 {
+				return RawXML(data[start:offset])
+			}
+		}
+	}
+}
+
 // RPCError defines an error reply to a RPC request
 type RPCError struct {
 	Type     string `xml:"error-type"`
@@ -126,7 +246,12 @@ type RPCError struct {
 	Severity string `xml:"error-severity"`
 	Path     string `xml:"error-path"`
 	Message  string `xml:"error-message"`
-	Info     string `xml:",innerxml"`
+	// SessionID is the session-id of the error-info, e.g. that of the
+	// session holding the lock for lock-denied errors.  It is zero if
+	// absent or if the lock is held by an entity other than a NETCONF
+	// session.
+	SessionID int    `xml:"error-info>session-id"`
+	Info      string `xml:",innerxml"`
 }
 
 // Error generates a string representation of the provided RPC error
@@ -139,6 +264,53 @@ type RPCMethod interface {
 	MarshalMethod() string
 }
 
+// MethodWriter is implemented by RPC methods that can write themselves to a
+// writer.  Such methods are streamed into the outgoing message instead of
+// being marshaled to a string first.
+type MethodWriter interface {
+	RPCMethod
+	WriteMethod(w io.Writer) error
+}
+
+// methodName returns the local name of the first element of the method, e.g.
+// "get-config".
+func methodName(m RPCMethod) string {
+	if n, ok := m.(interface{ methodName() string }); ok {
+		return n.methodName()
+	}
+	d := xml.NewDecoder(strings.NewReader(m.MarshalMethod()))
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			return ""
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local
+		}
+	}
+}
+
+// methodOperations returns the local names of all top-level elements of the
+// method, as a raw method may carry several operations.  Streamed methods
+// are not read, their operation is given by methodName.
+func methodOperations(m RPCMethod) ([]string, error) {
+	if n, ok := m.(interface{ methodName() string }); ok {
+		return []string{n.methodName()}, nil
+	}
+	roots, err := ParseNodes([]byte(m.MarshalMethod()))
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("no operation")
+	}
+	ops := make([]string, len(roots))
+	for i, n := range roots {
+		ops[i] = n.XMLName.Local
+	}
+	return ops, nil
+}
+
 // RawMethod defines how a raw text request will be responded to
 type RawMethod string
 
@@ -147,6 +319,43 @@ func (r RawMethod) MarshalMethod() string {
 	return string(r)
 }
 
+// XMLMethod adapts any value that encoding/xml can marshal, such as a
+// struct or an xml.Marshaler, to an RPCMethod.  Values implementing only
+// encoding.TextMarshaler are sent as the XML text they return.
+type XMLMethod struct {
+	V interface{}
+}
+
+// MethodXML returns an RPCMethod marshaling v when it is sent.
+func MethodXML(v interface{}) *XMLMethod {
+	return &XMLMethod{V: v}
+}
+
+// MarshalMethod marshals the value.  It returns an empty string if the
+// value cannot be marshaled, WriteMethod reports the error when the request
+// is sent.
+func (m *XMLMethod) MarshalMethod() string {
+	var buf bytes.Buffer
+	if err := m.WriteMethod(&buf); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// WriteMethod encodes the value to w.
+func (m *XMLMethod) WriteMethod(w io.Writer) error {
+	_, isXML := m.V.(xml.Marshaler)
+	if t, ok := m.V.(encoding.TextMarshaler); ok && !isXML {
+		text, err := t.MarshalText()
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(text)
+		return err
+	}
+	return xml.NewEncoder(w).Encode(m.V)
+}
+
 // MethodLock files a NETCONF lock target request with the remote host
 func MethodLock(target string) RawMethod {
 	return RawMethod(fmt.Sprintf("<lock><target><%s/></target></lock>", target))
@@ -157,21 +366,230 @@ func MethodUnlock(target string) RawMethod {
 	return RawMethod(fmt.Sprintf("<unlock><target><%s/></target></unlock>", target))
 }
 
+// MethodKillSession files a NETCONF kill-session request for the session
+// with the given id with the remote host.
+func MethodKillSession(id int) RawMethod {
+	return RawMethod(fmt.Sprintf("<kill-session><session-id>%d</session-id></kill-session>", id))
+}
+
+// MethodCloseSession files a NETCONF close-session request with the remote host
+func MethodCloseSession() RawMethod {
+	return RawMethod("<close-session/>")
+}
+
 // MethodGetConfig files a NETCONF get-config source request with the remote host
 func MethodGetConfig(source string) RawMethod {
 	return RawMethod(fmt.Sprintf("<get-config><source><%s/></source></get-config>", source))
 }
 
-// MethodGet files a NETCONF get source request with the remote host
+// MethodGet files a NETCONF get source request with the remote host.  The
+// filter is left out if both filterType and dataXml are empty.
 func MethodGet(filterType string, dataXml string) RawMethod {
+	if filterType == "" && dataXml == "" {
+		return MethodGetFilter(nil)
+	}
 	return RawMethod(fmt.Sprintf("<get><filter type=\"%s\">%s</filter></get>", filterType, dataXml))
 }
 
+// MethodGetFilter files a NETCONF get request with the remote host.  The
+// filter is optional.
+func MethodGetFilter(filter *Filter) RawMethod {
+	if filter == nil {
+		return RawMethod("<get/>")
+	}
+	return RawMethod("<get>" + filter.String() + "</get>")
+}
+
+// MethodGetConfigFilter files a NETCONF get-config source request with the
+// remote host.  The filter is optional.
+func MethodGetConfigFilter(source string, filter *Filter) RawMethod {
+	if filter == nil {
+		return MethodGetConfig(source)
+	}
+	return RawMethod(fmt.Sprintf("<get-config><source><%s/></source>%s</get-config>", source, filter))
+}
+
 // MethodEditConfig files a NETCONF edit-config request with the remote host
 func MethodEditConfig(database string, dataXml string) RawMethod {
 	return RawMethod(fmt.Sprintf(editConfigXml, database, dataXml))
 }
 
+// MethodReplaceConfig files a NETCONF edit-config request replacing the
+// configuration of the target datastore with dataXml.
+func MethodReplaceConfig(database string, dataXml string) RawMethod {
+	return RawMethod(fmt.Sprintf(strings.Replace(editConfigXml, ">merge<", ">replace<", 1), database, dataXml))
+}
+
+// EditConfigReader is an edit-config request whose configuration is read
+// from Config while the request is sent, so large configurations need not be
+// held in memory.  Config is consumed and the request can only be sent once.
+type EditConfigReader struct {
+	Target string
+	Config io.Reader
+}
+
+// MethodEditConfigReader files a NETCONF edit-config request with the remote
+// host, streaming the content of the <config> element from config.
+func MethodEditConfigReader(database string, config io.Reader) *EditConfigReader {
+	return &EditConfigReader{Target: database, Config: config}
+}
+
+// MarshalMethod reads the whole configuration and returns the request as
+// string.  WriteMethod is used instead when the request is sent.
+func (e *EditConfigReader) MarshalMethod() string {
+	config, _ := ioutil.ReadAll(e.Config)
+	return fmt.Sprintf(editConfigXml, e.Target, config)
+}
+
+// WriteMethod writes the request to w, copying the configuration from the
+// reader.
+func (e *EditConfigReader) WriteMethod(w io.Writer) error {
+	head := editConfigXml[:strings.Index(editConfigXml, "%s</config>")]
+	if _, err := fmt.Fprintf(w, head, e.Target); err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, e.Config); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "</config>\n</edit-config>")
+	return err
+}
+
+// methodName returns "edit-config" without reading the configuration.
+func (e *EditConfigReader) methodName() string {
+	return "edit-config"
+}
+
+// MethodCopyConfig files a NETCONF copy-config source to target request with the remote host
+func MethodCopyConfig(source string, target string) RawMethod {
+	return RawMethod(fmt.Sprintf("<copy-config><target><%s/></target><source><%s/></source></copy-config>", target, source))
+}
+
+// MethodCopyConfigData files a NETCONF copy-config request replacing the
+// target datastore with the configuration dataXml.
+func MethodCopyConfigData(dataXml string, target string) RawMethod {
+	return RawMethod(fmt.Sprintf("<copy-config><target><%s/></target><source><config>%s</config></source></copy-config>", target, dataXml))
+}
+
+// MethodValidate files a NETCONF validate source request with the remote host
+func MethodValidate(source string) RawMethod {
+	return RawMethod(fmt.Sprintf("<validate><source><%s/></source></validate>", source))
+}
+
+// MethodCommit files a NETCONF commit request with the remote host
+func MethodCommit() RawMethod {
+	return RawMethod("<commit/>")
+}
+
+// MethodConfirmedCommit files a NETCONF confirmed commit request with the
+// remote host.  The commit is reverted unless confirmed within timeout,
+// rounded up to whole seconds; the server's default of 600 seconds applies
+// if timeout is zero.
+func MethodConfirmedCommit(timeout time.Duration) RawMethod {
+	if timeout <= 0 {
+		return RawMethod("<commit><confirmed/></commit>")
+	}
+	return RawMethod(fmt.Sprintf("<commit><confirmed/><confirm-timeout>%d</confirm-timeout></commit>", confirmTimeout(timeout)))
+}
+
+// MethodPersistConfirmedCommit files a NETCONF confirmed commit request with
+// the remote host that, identified by persist, survives the end of the
+// session and can be confirmed or cancelled from any session
+// (:confirmed-commit:1.1).
+func MethodPersistConfirmedCommit(timeout time.Duration, persist string) RawMethod {
+	m := "<commit><confirmed/>"
+	if timeout > 0 {
+		m += fmt.Sprintf("<confirm-timeout>%d</confirm-timeout>", confirmTimeout(timeout))
+	}
+	return RawMethod(m + "<persist>" + EscapeText(persist) + "</persist></commit>")
+}
+
+// confirmTimeout returns timeout in seconds, rounded up as the
+// confirm-timeout of a commit must be at least 1.
+func confirmTimeout(timeout time.Duration) int64 {
+	return int64((timeout + time.Second - 1) / time.Second)
+}
+
+// MethodConfirmCommit files a NETCONF commit request with the remote host
+// confirming the persistent confirmed commit identified by persistID.
+func MethodConfirmCommit(persistID string) RawMethod {
+	return RawMethod("<commit><persist-id>" + EscapeText(persistID) + "</persist-id></commit>")
+}
+
+// MethodCancelCommit files a NETCONF cancel-commit request with the remote
+// host, reverting the ongoing confirmed commit.  persistID identifies a
+// persistent confirmed commit and is empty for that of the session.
+func MethodCancelCommit(persistID string) RawMethod {
+	if persistID == "" {
+		return RawMethod("<cancel-commit/>")
+	}
+	return RawMethod("<cancel-commit><persist-id>" + EscapeText(persistID) + "</persist-id></cancel-commit>")
+}
+
+// MethodPartialLock files a NETCONF partial-lock request (RFC 5717) for the
+// nodes selected by the XPath expressions with the remote host.
+func MethodPartialLock(selects ...string) RawMethod {
+	var b strings.Builder
+	b.WriteString(`<partial-lock xmlns="urn:ietf:params:xml:ns:netconf:partial-lock:1.0">`)
+	for _, sel := range selects {
+		b.WriteString("<select>")
+		b.WriteString(EscapeText(sel))
+		b.WriteString("</select>")
+	}
+	b.WriteString("</partial-lock>")
+	return RawMethod(b.String())
+}
+
+// MethodPartialUnlock files a NETCONF partial-unlock request for the lock
+// with the given id with the remote host.
+func MethodPartialUnlock(lockID uint32) RawMethod {
+	return RawMethod(fmt.Sprintf(`<partial-unlock xmlns="urn:ietf:params:xml:ns:netconf:partial-lock:1.0"><lock-id>%d</lock-id></partial-unlock>`, lockID))
+}
+
+// MethodDiscardChanges files a NETCONF discard-changes request with the remote host
+func MethodDiscardChanges() RawMethod {
+	return RawMethod("<discard-changes/>")
+}
+
+// CustomRPC builds a vendor or custom RPC named elementName in the given
+// namespace.  body becomes the content of the element: a string or []byte is
+// taken as raw XML, an RPCMethod or *Node is marshaled with its methods and
+// any other value with xml.Marshal.  A nil body gives an empty element.
+func CustomRPC(namespace, elementName string, body interface{}) (RawMethod, error) {
+	var content []byte
+	switch b := body.(type) {
+	case nil:
+	case string:
+		content = []byte(b)
+	case []byte:
+		content = b
+	case RPCMethod:
+		content = []byte(b.MarshalMethod())
+	default:
+		var err error
+		if content, err = xml.Marshal(b); err != nil {
+			return "", err
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('<')
+	buf.WriteString(elementName)
+	if namespace != "" {
+		writeAttr(&buf, xmlnsPrefix, namespace)
+	}
+	if len(content) == 0 {
+		buf.WriteString("/>")
+		return RawMethod(buf.String()), nil
+	}
+	buf.WriteByte('>')
+	buf.Write(content)
+	buf.WriteString("</")
+	buf.WriteString(elementName)
+	buf.WriteByte('>')
+	return RawMethod(buf.String()), nil
+}
+
 var msgID = uuid
 
 // uuid generates a "good enough" uuid without adding external dependencies
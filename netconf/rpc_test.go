@@ -9,7 +9,11 @@ package netconf
 import (
 	"bytes"
 	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -94,6 +98,23 @@ func TestMethodUnlock(t *testing.T) {
 	}
 }
 
+func TestMethodConfirmedCommit(t *testing.T) {
+	tt := []struct {
+		timeout  time.Duration
+		expected string
+	}{
+		{0, "<commit><confirmed/></commit>"},
+		{500 * time.Millisecond, "<commit><confirmed/><confirm-timeout>1</confirm-timeout></commit>"},
+		{90 * time.Second, "<commit><confirmed/><confirm-timeout>90</confirm-timeout></commit>"},
+		{90*time.Second + time.Millisecond, "<commit><confirmed/><confirm-timeout>91</confirm-timeout></commit>"},
+	}
+	for _, tc := range tt {
+		if got := MethodConfirmedCommit(tc.timeout).MarshalMethod(); got != tc.expected {
+			t.Errorf("%v: got %s, expected %s", tc.timeout, got, tc.expected)
+		}
+	}
+}
+
 func TestMethodGetConfig(t *testing.T) {
 	expected := "<get-config><source><what.target/></source></get-config>"
 
@@ -103,6 +124,128 @@ func TestMethodGetConfig(t *testing.T) {
 	}
 }
 
+func TestMethodFilters(t *testing.T) {
+	tt := []struct {
+		name     string
+		method   RawMethod
+		expected string
+	}{
+		{"get", MethodGet("", ""), "<get/>"},
+		{"get legacy", MethodGet("subtree", "<system/>"), `<get><filter type="subtree"><system/></filter></get>`},
+		{"get none", MethodGetFilter(nil), "<get/>"},
+		{"get subtree", MethodGetFilter(SubtreeFilter("<system/>")), `<get><filter type="subtree"><system/></filter></get>`},
+		{
+			"get xpath",
+			MethodGetFilter(XPathFilter("/t:top[t:name='a&b']", map[string]string{"t": "urn:top"})),
+			`<get><filter xmlns:t="urn:top" type="xpath" select="/t:top[t:name=&#39;a&amp;b&#39;]"/></get>`,
+		},
+		{"get-config none", MethodGetConfigFilter("running", nil), "<get-config><source><running/></source></get-config>"},
+		{
+			"get-config subtree",
+			MethodGetConfigFilter("running", SubtreeFilter("<system/>")),
+			`<get-config><source><running/></source><filter type="subtree"><system/></filter></get-config>`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.method.MarshalMethod(); got != tc.expected {
+				t.Errorf("unexpected method: (want %q, got %q)", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCustomRPC(t *testing.T) {
+	type inBody struct {
+		XMLName xml.Name `xml:"in"`
+		Minutes int      `xml:"minutes"`
+	}
+
+	tt := []struct {
+		name      string
+		namespace string
+		element   string
+		body      interface{}
+		expected  string
+	}{
+		{"empty", "", "request-reboot", nil, "<request-reboot/>"},
+		{"namespace", "urn:x&y", "reboot", "", `<reboot xmlns="urn:x&amp;y"/>`},
+		{"raw", "http://xml.juniper.net", "request-reboot", "<in>5</in>", `<request-reboot xmlns="http://xml.juniper.net"><in>5</in></request-reboot>`},
+		{"bytes", "urn:x", "r", []byte("<a/>"), `<r xmlns="urn:x"><a/></r>`},
+		{"method", "urn:x", "r", RawMethod("<a/>"), `<r xmlns="urn:x"><a/></r>`},
+		{"struct", "urn:x", "r", inBody{Minutes: 5}, `<r xmlns="urn:x"><in><minutes>5</minutes></in></r>`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := CustomRPC(tc.namespace, tc.element, tc.body)
+			if err != nil {
+				t.Fatalf("CustomRPC failed: %v", err)
+			}
+			if string(m) != tc.expected {
+				t.Errorf("unexpected method: (want %q, got %q)", tc.expected, m)
+			}
+		})
+	}
+
+	if _, err := CustomRPC("urn:x", "r", make(chan int)); err == nil {
+		t.Error("expected error for unsupported body")
+	}
+}
+
+type textMethod string
+
+func (m textMethod) MarshalText() ([]byte, error) {
+	return []byte(m), nil
+}
+
+type xmlMarshalerMethod struct{}
+
+func (xmlMarshalerMethod) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement("5", xml.StartElement{Name: xml.Name{Local: "request-reboot"}})
+}
+
+func TestMethodXML(t *testing.T) {
+	type getInterfaces struct {
+		XMLName xml.Name `xml:"get-interface-information"`
+		Terse   bool     `xml:"terse,omitempty"`
+	}
+
+	tt := []struct {
+		name     string
+		v        interface{}
+		expected string
+	}{
+		{"struct", getInterfaces{}, "<get-interface-information></get-interface-information>"},
+		{"pointer", &getInterfaces{Terse: true}, "<get-interface-information><terse>true</terse></get-interface-information>"},
+		{"marshaler", xmlMarshalerMethod{}, "<request-reboot>5</request-reboot>"},
+		{"text", textMethod("<commit/>"), "<commit/>"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := &RPCMessage{MessageID: "1", Methods: []RPCMethod{MethodXML(tc.v)}}
+			var buf bytes.Buffer
+			if _, err := msg.WriteTo(&buf); err != nil {
+				t.Fatalf("WriteTo failed: %v", err)
+			}
+			expected := xml.Header + `<rpc message-id="1" xmlns="urn:ietf:params:xml:ns:netconf:base:1.0">` + tc.expected + "</rpc>"
+			if diff := cmp.Diff(expected, buf.String()); diff != "" {
+				t.Errorf("unexpected message (-want +got):\n%s", diff)
+			}
+			if got := MethodXML(tc.v).MarshalMethod(); got != tc.expected {
+				t.Errorf("unexpected method: (want %q, got %q)", tc.expected, got)
+			}
+		})
+	}
+
+	msg := &RPCMessage{MessageID: "1", Methods: []RPCMethod{MethodXML(make(chan int))}}
+	if _, err := msg.WriteTo(ioutil.Discard); err == nil {
+		t.Error("expected error for unsupported value")
+	}
+}
+
 // TestUUIDLength verifies that UUID length is cor([a-zA-Z]|\d|-)rect
 func TestUUIDLength(t *testing.T) {
 	expectedLength := 36
@@ -229,7 +372,7 @@ func TestNewRPCReply(t *testing.T) {
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if reply.RawReply != tc.rawXML {
+		if reply.RawReply.String() != tc.rawXML {
 			t.Errorf("newRPCReply(%q) did not set RawReply to input, got %q", tc.rawXML, reply.RawReply)
 		}
 		if reply.MessageID != "101" {
@@ -237,3 +380,166 @@ func TestNewRPCReply(t *testing.T) {
 		}
 	}
 }
+
+func TestMethodName(t *testing.T) {
+	tt := []struct {
+		method   RPCMethod
+		expected string
+	}{
+		{MethodGetConfig("running"), "get-config"},
+		{RawMethod(`<!-- comment --><junos:get-software-information xmlns:junos="x"/>`), "get-software-information"},
+		{RawMethod("not xml"), ""},
+	}
+
+	for _, tc := range tt {
+		if got := methodName(tc.method); got != tc.expected {
+			t.Errorf("methodName(%q) = %q, expected %q", tc.method.MarshalMethod(), got, tc.expected)
+		}
+	}
+}
+
+func TestRPCReplyData(t *testing.T) {
+	tt := []struct {
+		input string
+		data  string
+	}{
+		{`<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><data><a>1</a></data></rpc-reply>`, `<data><a>1</a></data>`},
+		{"<rpc-reply>\n<ok/>\n</rpc-reply>", "\n<ok/>\n"},
+		{`<rpc-reply/>`, ``},
+	}
+
+	for _, tc := range tt {
+		raw := []byte(tc.input)
+		reply, err := ParseRPCReply(raw)
+		if err != nil {
+			t.Fatalf("ParseRPCReply(%q) failed: %v", tc.input, err)
+		}
+		if reply.Data.String() != tc.data {
+			t.Errorf("ParseRPCReply(%q) data = %q, want %q", tc.input, reply.Data, tc.data)
+		}
+		if len(reply.Data) > 0 && &reply.Data[0] != &raw[bytes.Index(raw, reply.Data)] {
+			t.Errorf("ParseRPCReply(%q) copied the data", tc.input)
+		}
+	}
+}
+
+type writerMethod string
+
+func (m writerMethod) MarshalMethod() string {
+	return "<unused/>"
+}
+
+func (m writerMethod) WriteMethod(w io.Writer) error {
+	_, err := io.WriteString(w, string(m))
+	return err
+}
+
+func TestRPCMessageWriteTo(t *testing.T) {
+	msg := &RPCMessage{
+		MessageID: `a"b`,
+		Methods:   []RPCMethod{MethodLock("running"), writerMethod("<streamed/>")},
+	}
+
+	var buf bytes.Buffer
+	n, err := msg.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("expected %d bytes written, got %d", buf.Len(), n)
+	}
+
+	expected := xml.Header + `<rpc message-id="a&#34;b" xmlns="urn:ietf:params:xml:ns:netconf:base:1.0">` +
+		MethodLock("running").MarshalMethod() + `<streamed/></rpc>`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("unexpected message (-want +got):\n%s", diff)
+	}
+}
+
+func TestMethodEditConfigReader(t *testing.T) {
+	config := "<system><host-name>r1</host-name></system>"
+	expected := MethodEditConfig("candidate", config).MarshalMethod()
+
+	var buf bytes.Buffer
+	m := MethodEditConfigReader("candidate", strings.NewReader(config))
+	if err := m.WriteMethod(&buf); err != nil {
+		t.Fatalf("WriteMethod failed: %v", err)
+	}
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("unexpected method (-want +got):\n%s", diff)
+	}
+
+	m = MethodEditConfigReader("candidate", strings.NewReader(config))
+	if got := methodName(m); got != "edit-config" {
+		t.Errorf("expected method name edit-config, got %q", got)
+	}
+	if diff := cmp.Diff(expected, m.MarshalMethod()); diff != "" {
+		t.Errorf("unexpected marshaled method (-want +got):\n%s", diff)
+	}
+}
+
+func TestRPCMessageAttrs(t *testing.T) {
+	msg := &RPCMessage{
+		MessageID: "101",
+		Methods:   []RPCMethod{MethodCommit()},
+		Attrs: []xml.Attr{
+			{Name: xml.Name{Space: "xmlns", Local: "jnx"}, Value: "http://xml.juniper.net/junos/*/junos"},
+			{Name: xml.Name{Local: "transaction"}, Value: `a<b`},
+		},
+	}
+	expected := `<rpc message-id="101" xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"` +
+		` xmlns:jnx="http://xml.juniper.net/junos/*/junos" transaction="a&lt;b">` + MethodCommit().MarshalMethod() + `</rpc>`
+
+	var buf bytes.Buffer
+	if _, err := msg.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if diff := cmp.Diff(xml.Header+expected, buf.String()); diff != "" {
+		t.Errorf("unexpected WriteTo output (-want +got):\n%s", diff)
+	}
+
+	out, err := xml.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	expected = `<rpc xmlns:jnx="http://xml.juniper.net/junos/*/junos" transaction="a&lt;b"` +
+		` message-id="101" xmlns="urn:ietf:params:xml:ns:netconf:base:1.0">` + MethodCommit().MarshalMethod() + `</rpc>`
+	if diff := cmp.Diff(expected, string(out)); diff != "" {
+		t.Errorf("unexpected Marshal output (-want +got):\n%s", diff)
+	}
+}
+
+func TestSessionRPCAttrs(t *testing.T) {
+	s, trans := newScriptedSession(nil, replyOK)
+	s.RPCAttrs = []xml.Attr{{Name: xml.Name{Local: "vendor"}, Value: "x"}}
+	if _, err := s.Exec(MethodCommit()); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if len(trans.sent) != 1 || !strings.Contains(string(trans.sent[0]), ` vendor="x">`) {
+		t.Errorf("attribute not sent: %q", trans.sent)
+	}
+}
+
+func TestRPCReplyDecode(t *testing.T) {
+	var v struct {
+		Hosts []string `xml:"system>host-name"`
+	}
+	tt := []string{
+		`<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><data><system><host-name>a</host-name><host-name>b</host-name></system></data></rpc-reply>`,
+		`<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0">
+<system><host-name>a</host-name><host-name>b</host-name></system></rpc-reply>`,
+	}
+	for _, raw := range tt {
+		reply, err := ParseRPCReply([]byte(raw))
+		if err != nil {
+			t.Fatalf("ParseRPCReply failed: %v", err)
+		}
+		v.Hosts = nil
+		if err := reply.Decode(&v); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if diff := cmp.Diff([]string{"a", "b"}, v.Hosts); diff != "" {
+			t.Errorf("decoded mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
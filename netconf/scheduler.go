@@ -0,0 +1,268 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrJobRunning is returned by Scheduler.RunJob while the previous run of
+// the job has not finished.
+var ErrJobRunning = errors.New("netconf: job still running")
+
+// Job is a collection job run by a Scheduler.
+type Job struct {
+	Name string
+	// Schedule is parsed with ParseSchedule.
+	Schedule string
+	// Tags select the devices of the inventory the job runs against, every
+	// device if empty.
+	Tags []string
+	// Method is the RPC to send.  If nil, a <get> with Filter is sent.
+	Method RPCMethod
+	Filter *Filter
+	// Output receives the data of every successful run on a device.
+	Output func(ctx context.Context, job, device string, data RawXML) error
+	// Jitter delays every activation by a random duration up to Jitter,
+	// spreading the load of jobs sharing a schedule.
+	Jitter time.Duration
+	// Timeout bounds a run, unlimited if zero.
+	Timeout time.Duration
+}
+
+// JobStats holds the metrics of a scheduled job.
+type JobStats struct {
+	// Runs counts the runs of the job, Skipped the activations dropped
+	// because the previous run was still going on.
+	Runs    uint64
+	Skipped uint64
+	// Successes and Failures count the outcome per device.
+	Successes uint64
+	Failures  uint64
+
+	LastRun      time.Time
+	LastDuration time.Duration
+	LastError    string
+}
+
+type scheduledJob struct {
+	job      Job
+	schedule Schedule
+	cancel   context.CancelFunc
+
+	mu      sync.Mutex
+	running bool
+	stats   JobStats
+}
+
+// Scheduler runs jobs against the devices of a pool on their schedules.
+// Runs of the same job never overlap; an activation during a run is
+// skipped.
+type Scheduler struct {
+	Pool *Pool
+
+	mu   sync.Mutex
+	jobs map[string]*scheduledJob
+	ctx  context.Context
+}
+
+// NewScheduler returns a scheduler using the sessions of pool.
+func NewScheduler(pool *Pool) *Scheduler {
+	return &Scheduler{Pool: pool}
+}
+
+// Add registers a job.  If the scheduler is running the job is started
+// right away.
+func (sc *Scheduler) Add(job Job) error {
+	if job.Name == "" {
+		return fmt.Errorf("netconf: job name required")
+	}
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return err
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if _, ok := sc.jobs[job.Name]; ok {
+		return fmt.Errorf("netconf: duplicate job %q", job.Name)
+	}
+	if sc.jobs == nil {
+		sc.jobs = make(map[string]*scheduledJob)
+	}
+	j := &scheduledJob{job: job, schedule: schedule}
+	sc.jobs[job.Name] = j
+	if sc.ctx != nil {
+		sc.start(j)
+	}
+	return nil
+}
+
+// Remove unregisters a job.  A run in progress is cancelled.
+func (sc *Scheduler) Remove(name string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if j, ok := sc.jobs[name]; ok {
+		if j.cancel != nil {
+			j.cancel()
+		}
+		delete(sc.jobs, name)
+	}
+}
+
+// Run runs the jobs on their schedules until ctx is done.
+func (sc *Scheduler) Run(ctx context.Context) error {
+	sc.mu.Lock()
+	if sc.ctx != nil {
+		sc.mu.Unlock()
+		return fmt.Errorf("netconf: scheduler already running")
+	}
+	sc.ctx = ctx
+	for _, j := range sc.jobs {
+		sc.start(j)
+	}
+	sc.mu.Unlock()
+
+	<-ctx.Done()
+
+	sc.mu.Lock()
+	sc.ctx = nil
+	for _, j := range sc.jobs {
+		j.cancel = nil
+	}
+	sc.mu.Unlock()
+	return ctx.Err()
+}
+
+// start runs the activation loop of j.  sc.mu is held.
+func (sc *Scheduler) start(j *scheduledJob) {
+	ctx, cancel := context.WithCancel(sc.ctx)
+	j.cancel = cancel
+	go func() {
+		for {
+			next := j.schedule.Next(time.Now())
+			if next.IsZero() {
+				return
+			}
+			if j.job.Jitter > 0 {
+				next = next.Add(time.Duration(rand.Int63n(int64(j.job.Jitter))))
+			}
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-timer.C:
+				go sc.run(ctx, j)
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// RunJob runs the named job once, now, and waits for it to finish.
+func (sc *Scheduler) RunJob(ctx context.Context, name string) error {
+	sc.mu.Lock()
+	j, ok := sc.jobs[name]
+	sc.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("netconf: unknown job %q", name)
+	}
+	return sc.run(ctx, j)
+}
+
+// Stats returns the metrics of the named job.
+func (sc *Scheduler) Stats(name string) (JobStats, bool) {
+	sc.mu.Lock()
+	j, ok := sc.jobs[name]
+	sc.mu.Unlock()
+	if !ok {
+		return JobStats{}, false
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.stats, true
+}
+
+func (sc *Scheduler) run(ctx context.Context, j *scheduledJob) error {
+	j.mu.Lock()
+	if j.running {
+		j.stats.Skipped++
+		j.mu.Unlock()
+		return ErrJobRunning
+	}
+	j.running = true
+	j.mu.Unlock()
+
+	if j.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.job.Timeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	var wg sync.WaitGroup
+	errs := make(chan error, len(sc.Pool.Inventory.Devices))
+	for _, d := range sc.Pool.Inventory.Select(j.job.Tags...) {
+		wg.Add(1)
+		go func(device string) {
+			defer wg.Done()
+			if err := sc.collect(ctx, j.job, device); err != nil {
+				errs <- fmt.Errorf("%s: %v", device, err)
+				return
+			}
+			errs <- nil
+		}(d.Name)
+	}
+	wg.Wait()
+	close(errs)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.running = false
+	j.stats.Runs++
+	j.stats.LastRun = started
+	j.stats.LastDuration = time.Since(started)
+	j.stats.LastError = ""
+	var first error
+	for err := range errs {
+		if err == nil {
+			j.stats.Successes++
+			continue
+		}
+		j.stats.Failures++
+		if first == nil {
+			first = err
+			j.stats.LastError = err.Error()
+		}
+	}
+	return first
+}
+
+func (sc *Scheduler) collect(ctx context.Context, job Job, device string) error {
+	method := job.Method
+	if method == nil {
+		method = MethodGetFilter(job.Filter)
+	}
+	var data RawXML
+	err := sc.Pool.Do(ctx, device, func(s *Session) error {
+		reply, err := s.ExecContext(ctx, method)
+		if err != nil {
+			return err
+		}
+		data = append(RawXML(nil), reply.Data...)
+		return nil
+	})
+	if err != nil || job.Output == nil {
+		return err
+	}
+	return job.Output(ctx, job.Name, device, data)
+}
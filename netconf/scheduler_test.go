@@ -0,0 +1,91 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	from := time.Date(2020, 1, 31, 10, 17, 30, 0, time.UTC) // a Friday
+	tt := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2020, 1, 31, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2020, 1, 31, 10, 30, 0, 0, time.UTC)},
+		{"5 9-17 * * *", time.Date(2020, 1, 31, 11, 5, 0, 0, time.UTC)},
+		{"0 0 * * 1", time.Date(2020, 2, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 15 * 5", time.Date(2020, 1, 31, 12, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", time.Date(2020, 1, 31, 10, 19, 0, 0, time.UTC)},
+	}
+	for _, tc := range tt {
+		s, err := ParseSchedule(tc.spec)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.spec, err)
+			continue
+		}
+		if got := s.Next(from); !got.Equal(tc.expected) {
+			t.Errorf("%q: got %v, expected %v", tc.spec, got, tc.expected)
+		}
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "*/0 * * * *", "a * * * *", "@every -1s"} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestSchedulerRunJob(t *testing.T) {
+	pool, _ := newTestPool(`<rpc-reply><data><system/></data></rpc-reply>`, `<rpc-reply><data><system/></data></rpc-reply>`)
+	sc := NewScheduler(pool)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var collected string
+	err := sc.Add(Job{
+		Name:     "system",
+		Schedule: "@hourly",
+		Filter:   SubtreeFilter("<system/>"),
+		Output: func(ctx context.Context, job, device string, data RawXML) error {
+			collected = job + "/" + device + ": " + data.String()
+			close(started)
+			<-release
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := sc.Add(Job{Name: "system", Schedule: "@hourly"}); err == nil {
+		t.Error("expected error for duplicate job")
+	}
+
+	done := make(chan error)
+	go func() { done <- sc.RunJob(context.Background(), "system") }()
+	<-started
+	if err := sc.RunJob(context.Background(), "system"); err != ErrJobRunning {
+		t.Errorf("expected ErrJobRunning for overlapping run, got %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("RunJob failed: %v", err)
+	}
+
+	if expected := "system/r1: <data><system/></data>"; collected != expected {
+		t.Errorf("got output %q, expected %q", collected, expected)
+	}
+	stats, _ := sc.Stats("system")
+	if stats.Runs != 1 || stats.Skipped != 1 || stats.Successes != 1 || stats.Failures != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,540 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SchemaNode is a data node of a Schema: a container, list, leaf or
+// leaf-list.  Choices and cases do not appear in instance data, their nodes
+// are children of the enclosing data node.
+type SchemaNode struct {
+	Name      string
+	Keyword   string
+	Namespace string
+	// Module is the name of the module defining the node.
+	Module string
+	// Keys are the key leaves of a list.
+	Keys []string
+	// Type is the type of a leaf or leaf-list as written in the module.
+	Type string
+	// Config is false for state data.
+	Config   bool
+	Parent   *SchemaNode
+	Children []*SchemaNode
+	// Statement is the YANG statement defining the node.
+	Statement *YANGStatement
+
+	// def is the module Statement is written in, used to resolve the
+	// prefixes of its substatements.
+	def *yangModule
+}
+
+// Child returns the child with the given name, or nil.
+func (n *SchemaNode) Child(name string) *SchemaNode {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Path returns the schema path of the node, e.g. /interfaces/interface/mtu.
+func (n *SchemaNode) Path() string {
+	if n.Parent == nil {
+		return "/"
+	}
+	var elems []string
+	for ; n.Parent != nil; n = n.Parent {
+		elems = append(elems, n.Name)
+	}
+	var b strings.Builder
+	for i := len(elems) - 1; i >= 0; i-- {
+		b.WriteString("/" + elems[i])
+	}
+	return b.String()
+}
+
+// Schema is the data tree defined by a set of YANG modules, for example the
+// modules a device serves with get-schema.  It is used to check the paths
+// and filters given by users before they are sent, and to suggest the
+// children of a path.
+//
+// Groupings, including those of imported modules, and top-level augments
+// are resolved.  Deviations, features and when or must conditions are not.
+type Schema struct {
+	// Root holds the top-level data nodes of all modules as children.
+	Root *SchemaNode
+
+	modules map[string]*yangModule
+}
+
+// SchemaPathError reports an element of a path or filter the schema does
+// not define.
+type SchemaPathError struct {
+	// Path is the schema path of the parent of the element.
+	Path    string
+	Element string
+	// Suggestions are the children of Path with names close to Element.
+	Suggestions []string
+}
+
+func (e *SchemaPathError) Error() string {
+	msg := fmt.Sprintf("netconf: unknown element %q in %s", e.Element, e.Path)
+	if len(e.Suggestions) > 0 {
+		msg += ", did you mean " + strings.Join(e.Suggestions, " or ") + "?"
+	}
+	return msg
+}
+
+// GetSchema retrieves the text of a YANG module from the server with the
+// get-schema operation of RFC 6022.  version may be empty for the revision
+// the server implements.
+func (s *Session) GetSchema(ctx context.Context, identifier, version string) ([]byte, error) {
+	rpc := `<get-schema xmlns="` + MonitoringNamespace + `"><identifier>` + EscapeText(identifier) + `</identifier>`
+	if version != "" {
+		rpc += `<version>` + EscapeText(version) + `</version>`
+	}
+	rpc += `<format>yang</format></get-schema>`
+
+	reply, err := s.ExecContext(ctx, RawMethod(rpc))
+	if err != nil {
+		return nil, err
+	}
+	data, err := ParseNode(reply.Data)
+	if err != nil {
+		return nil, fmt.Errorf("netconf: get-schema %s: %v", identifier, err)
+	}
+	return []byte(data.Text), nil
+}
+
+// LoadSchema parses the .yang files in dir into a Schema.
+func LoadSchema(dir string) (*Schema, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yang"))
+	if err != nil {
+		return nil, err
+	}
+	var modules []*YANGStatement
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		module, err := ParseYANG(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f, err)
+		}
+		modules = append(modules, module)
+	}
+	return NewSchema(modules...)
+}
+
+// yangModule holds what is needed to resolve the statements of a module.
+type yangModule struct {
+	name      string
+	namespace string
+	prefix    string
+	// imports maps the prefixes of imported modules to their names.
+	imports   map[string]string
+	groupings map[string]*YANGStatement
+	typedefs  map[string]*YANGStatement
+	// body holds the statements of the module and its submodules.
+	body []*YANGStatement
+}
+
+type schemaBuilder struct {
+	modules map[string]*yangModule
+}
+
+// NewSchema builds the schema defined by modules, which may include
+// submodules.
+func NewSchema(modules ...*YANGStatement) (*Schema, error) {
+	b := &schemaBuilder{modules: make(map[string]*yangModule)}
+	var submodules []*YANGStatement
+	for _, st := range modules {
+		if st.Keyword == "submodule" {
+			submodules = append(submodules, st)
+			continue
+		}
+		m := &yangModule{
+			name:      st.Argument,
+			imports:   make(map[string]string),
+			groupings: make(map[string]*YANGStatement),
+			typedefs:  make(map[string]*YANGStatement),
+		}
+		if ns := st.Sub("namespace"); ns != nil {
+			m.namespace = ns.Argument
+		}
+		if p := st.Sub("prefix"); p != nil {
+			m.prefix = p.Argument
+		}
+		if _, ok := b.modules[m.name]; ok {
+			return nil, fmt.Errorf("netconf: yang: duplicate module %s", m.name)
+		}
+		b.modules[m.name] = m
+		b.addBody(m, st)
+	}
+	for _, st := range submodules {
+		belongs := st.Sub("belongs-to")
+		if belongs == nil || b.modules[belongs.Argument] == nil {
+			return nil, fmt.Errorf("netconf: yang: module of submodule %s not found", st.Argument)
+		}
+		b.addBody(b.modules[belongs.Argument], st)
+	}
+
+	names := make([]string, 0, len(b.modules))
+	for name := range b.modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	root := &SchemaNode{Config: true}
+	for _, name := range names {
+		m := b.modules[name]
+		b.expand(root, m.body, m, m, 0)
+	}
+
+	// Augments may target nodes added by other augments, so apply them
+	// until no more can be.
+	var pending []pendingAugment
+	for _, name := range names {
+		m := b.modules[name]
+		for _, st := range m.body {
+			if st.Keyword == "augment" {
+				pending = append(pending, pendingAugment{st, m})
+			}
+		}
+	}
+	for len(pending) > 0 {
+		var rest []pendingAugment
+		for _, a := range pending {
+			if target := b.resolve(root, a.st.Argument, a.module); target != nil {
+				b.expand(target, a.st.Substatements, a.module, a.module, 0)
+			} else {
+				rest = append(rest, a)
+			}
+		}
+		if len(rest) == len(pending) {
+			return nil, fmt.Errorf("netconf: yang: target of augment %s of module %s not found", rest[0].st.Argument, rest[0].module.name)
+		}
+		pending = rest
+	}
+	return &Schema{Root: root, modules: b.modules}, nil
+}
+
+type pendingAugment struct {
+	st     *YANGStatement
+	module *yangModule
+}
+
+// addBody adds the statements of a module or submodule to m.
+func (b *schemaBuilder) addBody(m *yangModule, st *YANGStatement) {
+	for _, sub := range st.Substatements {
+		switch sub.Keyword {
+		case "import":
+			if p := sub.Sub("prefix"); p != nil {
+				m.imports[p.Argument] = sub.Argument
+			}
+		case "grouping":
+			m.groupings[sub.Argument] = sub
+		case "typedef":
+			m.typedefs[sub.Argument] = sub
+		case "belongs-to":
+			if p := sub.Sub("prefix"); p != nil {
+				m.imports[p.Argument] = m.name
+			}
+		}
+	}
+	m.body = append(m.body, st.Substatements...)
+}
+
+// module returns the module a prefix used in m refers to.
+func (b *schemaBuilder) module(m *yangModule, prefix string) *yangModule {
+	return prefixModule(b.modules, m, prefix)
+}
+
+func prefixModule(modules map[string]*yangModule, m *yangModule, prefix string) *yangModule {
+	if prefix == "" || prefix == m.prefix {
+		return m
+	}
+	return modules[m.imports[prefix]]
+}
+
+// expand adds the data nodes defined by stmts to parent.  def is the module
+// the statements are defined in, used to resolve prefixes, and ns the
+// module whose namespace the nodes are in.
+func (b *schemaBuilder) expand(parent *SchemaNode, stmts []*YANGStatement, def, ns *yangModule, depth int) {
+	// Recursive groupings are invalid YANG; stop rather than loop.
+	if depth > 64 {
+		return
+	}
+	for _, st := range stmts {
+		switch {
+		case yangSchemaNodes[st.Keyword]:
+			n := &SchemaNode{
+				Name:      st.Argument,
+				Keyword:   st.Keyword,
+				Namespace: ns.namespace,
+				Module:    ns.name,
+				Config:    parent.Config,
+				Parent:    parent,
+				Statement: st,
+				def:       def,
+			}
+			if c := st.Sub("config"); c != nil {
+				n.Config = c.Argument != "false"
+			}
+			if k := st.Sub("key"); k != nil {
+				n.Keys = strings.Fields(k.Argument)
+			}
+			if t := st.Sub("type"); t != nil {
+				n.Type = t.Argument
+			}
+			parent.Children = append(parent.Children, n)
+			b.expand(n, st.Substatements, def, ns, depth+1)
+		case st.Keyword == "choice" || st.Keyword == "case":
+			b.expand(parent, st.Substatements, def, ns, depth+1)
+		case st.Keyword == "uses":
+			prefix, name := splitPrefix(st.Argument)
+			if m := b.module(def, prefix); m != nil {
+				if g, ok := m.groupings[name]; ok {
+					b.expand(parent, g.Substatements, m, ns, depth+1)
+				}
+			}
+		}
+	}
+}
+
+// resolve returns the node of an absolute schema node identifier such as
+// /if:interfaces/if:interface, or nil.
+func (b *schemaBuilder) resolve(root *SchemaNode, path string, m *yangModule) *SchemaNode {
+	n := root
+	for _, elem := range strings.Split(strings.Trim(path, "/ "), "/") {
+		prefix, name := splitPrefix(strings.TrimSpace(elem))
+		target := b.module(m, prefix)
+		if target == nil {
+			return nil
+		}
+		var next *SchemaNode
+		for _, c := range n.Children {
+			if c.Name == name && c.Module == target.name {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		n = next
+	}
+	return n
+}
+
+func splitPrefix(s string) (prefix, name string) {
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return "", s
+}
+
+// Find returns the node of a data path.  The path may use module prefixes,
+// XPath predicates or RESTCONF list keys, which are ignored, e.g.
+// /interfaces/interface[name='ge-0/0/0']/mtu or
+// ietf-interfaces:interfaces/interface=eth0/mtu.
+func (sc *Schema) Find(path string) (*SchemaNode, error) {
+	n := sc.Root
+	for _, elem := range splitSchemaPath(path) {
+		next := n.Child(elem)
+		if next == nil {
+			return nil, &SchemaPathError{Path: n.Path(), Element: elem, Suggestions: suggest(n, elem)}
+		}
+		n = next
+	}
+	return n, nil
+}
+
+// Complete returns the completions of a partially typed path: the paths of
+// the children of its parent whose names start with its last element.  A
+// path ending with / completes to all children.
+func (sc *Schema) Complete(path string) []string {
+	parent, partial := "", path
+	if i := lastSchemaSeparator(path); i >= 0 {
+		parent, partial = path[:i+1], path[i+1:]
+	}
+	n, err := sc.Find(parent)
+	if err != nil {
+		return nil
+	}
+	_, partial = splitPrefix(partial)
+
+	var completions []string
+	for _, c := range n.Children {
+		if strings.HasPrefix(c.Name, partial) {
+			completions = append(completions, parent+c.Name)
+		}
+	}
+	return completions
+}
+
+// CheckFilter checks that the elements of a subtree filter are defined by
+// the schema, returning a *SchemaPathError for the first one that is not.
+// Namespaces are compared only where the filter gives them.
+func (sc *Schema) CheckFilter(filter string) error {
+	nodes, err := ParseNodes([]byte(filter))
+	if err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		if err := checkFilter(sc.Root, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkFilter(parent *SchemaNode, n *Node) error {
+	var s *SchemaNode
+	for _, c := range parent.Children {
+		if c.Name == n.XMLName.Local && (n.XMLName.Space == "" || c.Namespace == n.XMLName.Space) {
+			s = c
+			break
+		}
+	}
+	if s == nil {
+		return &SchemaPathError{Path: parent.Path(), Element: n.XMLName.Local, Suggestions: suggest(parent, n.XMLName.Local)}
+	}
+	for _, c := range n.Children {
+		if err := checkFilter(s, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitSchemaPath returns the names of the elements of a data path without
+// prefixes, predicates and keys.
+func splitSchemaPath(path string) []string {
+	var elems []string
+	var elem strings.Builder
+	depth, quote := 0, byte(0)
+	skip := false
+	flush := func() {
+		if elem.Len() > 0 {
+			_, name := splitPrefix(elem.String())
+			elems = append(elems, name)
+		}
+		elem.Reset()
+		skip = false
+	}
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			if depth > 0 {
+				quote = c
+			}
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case depth > 0:
+		case c == '/':
+			flush()
+		case c == '=':
+			skip = true
+		case !skip:
+			elem.WriteByte(c)
+		}
+	}
+	flush()
+	return elems
+}
+
+// lastSchemaSeparator returns the index of the last / of path outside of
+// predicates, or -1.
+func lastSchemaSeparator(path string) int {
+	last, depth, quote := -1, 0, byte(0)
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '\'' || c == '"') && depth > 0:
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == '/' && depth == 0:
+			last = i
+		}
+	}
+	return last
+}
+
+// suggest returns the names of the children of n close to name.
+func suggest(n *SchemaNode, name string) []string {
+	var suggestions []string
+	for _, c := range n.Children {
+		limit := len(c.Name) / 3
+		if limit < 1 {
+			limit = 1
+		}
+		if editDistance(c.Name, name) <= limit || name != "" && strings.HasPrefix(c.Name, name) {
+			suggestions = append(suggestions, c.Name)
+		}
+	}
+	return suggestions
+}
+
+// editDistance returns the edit distance of a and b, counting insertions,
+// deletions, substitutions and transpositions of adjacent characters.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min3(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && d[i-2][j-2]+1 < d[i][j] {
+				d[i][j] = d[i-2][j-2] + 1
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,205 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testYANGAugment = `module example-ext {
+  namespace "urn:example:ext";
+  prefix ext;
+  import example { prefix ex; }
+
+  augment /ex:interfaces/ex:interface {
+    container ext {
+      uses ex:mtu;
+    }
+    container statistics {
+      config false;
+      leaf in-octets { type uint64; }
+    }
+  }
+}
+`
+
+func newTestSchema(t *testing.T) *Schema {
+	var modules []*YANGStatement
+	for _, src := range []string{testYANGModule, testYANGAugment} {
+		module, err := ParseYANG([]byte(src))
+		if err != nil {
+			t.Fatalf("ParseYANG failed: %v", err)
+		}
+		modules = append(modules, module)
+	}
+	sc, err := NewSchema(modules...)
+	if err != nil {
+		t.Fatalf("NewSchema failed: %v", err)
+	}
+	return sc
+}
+
+func TestSchemaFind(t *testing.T) {
+	sc := newTestSchema(t)
+
+	tt := []struct {
+		path      string
+		keyword   string
+		namespace string
+		config    bool
+		err       string
+	}{
+		{path: "/interfaces", keyword: "container", namespace: "urn:example", config: true},
+		{path: "/ex:interfaces/ex:interface[name='ge-0/0/0']/mtu", keyword: "leaf", namespace: "urn:example", config: true},
+		{path: "example:interfaces/interface=ge-0%2F0%2F0/vlan", keyword: "leaf", namespace: "urn:example", config: true},
+		{path: "/interfaces/interface/statistics/in-octets", keyword: "leaf", namespace: "urn:example:ext"},
+		{path: "/interfaces/interface/tag", keyword: "leaf-list", namespace: "urn:example", config: true},
+		{path: "/interfaces/interface/ext/mtu", keyword: "leaf", namespace: "urn:example:ext", config: true},
+		{
+			path: "/interfaces/interfce", err: `netconf: unknown element "interfce" in /interfaces, did you mean interface?`,
+		},
+		{
+			path: "/interfaces/interface/mode", err: `netconf: unknown element "mode" in /interfaces/interface`,
+		},
+	}
+
+	for _, tc := range tt {
+		n, err := sc.Find(tc.path)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%s: got error %v, expected %q", tc.path, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.path, err)
+			continue
+		}
+		if n.Keyword != tc.keyword || n.Namespace != tc.namespace || n.Config != tc.config {
+			t.Errorf("%s: got %s in %s config %v, expected %s in %s config %v",
+				tc.path, n.Keyword, n.Namespace, n.Config, tc.keyword, tc.namespace, tc.config)
+		}
+	}
+
+	n, _ := sc.Find("/interfaces/interface")
+	if diff := cmp.Diff([]string{"name"}, n.Keys); diff != "" {
+		t.Errorf("keys mismatch (-expected +got):\n%s", diff)
+	}
+	if n.Path() != "/interfaces/interface" {
+		t.Errorf("got path %s", n.Path())
+	}
+}
+
+func TestSchemaComplete(t *testing.T) {
+	sc := newTestSchema(t)
+
+	tt := []struct {
+		path     string
+		expected []string
+	}{
+		{path: "/", expected: []string{"/interfaces"}},
+		{path: "/int", expected: []string{"/interfaces"}},
+		{path: "/interfaces/interface/", expected: []string{
+			"/interfaces/interface/name", "/interfaces/interface/mtu", "/interfaces/interface/vlan",
+			"/interfaces/interface/tag", "/interfaces/interface/ext", "/interfaces/interface/statistics",
+		}},
+		{path: "/interfaces/interface[name='a/b']/st", expected: []string{"/interfaces/interface[name='a/b']/statistics"}},
+		{path: "/interfaces/bogus/", expected: nil},
+	}
+
+	for _, tc := range tt {
+		if diff := cmp.Diff(tc.expected, sc.Complete(tc.path)); diff != "" {
+			t.Errorf("%s: completions mismatch (-expected +got):\n%s", tc.path, diff)
+		}
+	}
+}
+
+func TestSchemaCheckFilter(t *testing.T) {
+	sc := newTestSchema(t)
+
+	tt := []struct {
+		filter string
+		err    string
+	}{
+		{filter: `<interfaces xmlns="urn:example"><interface><name>eth0</name><mtu/></interface></interfaces>`},
+		{filter: `<interfaces><interface><statistics/></interface></interfaces>`},
+		{
+			filter: `<interfaces><interface><nmae>eth0</nmae></interface></interfaces>`,
+			err:    `netconf: unknown element "nmae" in /interfaces/interface, did you mean name?`,
+		},
+		{
+			filter: `<interfaces xmlns="urn:other"/>`,
+			err:    `netconf: unknown element "interfaces" in /, did you mean interfaces?`,
+		},
+	}
+
+	for _, tc := range tt {
+		err := sc.CheckFilter(tc.filter)
+		if tc.err == "" && err != nil || tc.err != "" && (err == nil || err.Error() != tc.err) {
+			t.Errorf("%s: got error %v, expected %q", tc.filter, err, tc.err)
+		}
+	}
+
+	var pathErr *SchemaPathError
+	if err := sc.CheckFilter(`<system/>`); !errors.As(err, &pathErr) || pathErr.Element != "system" {
+		t.Errorf("got %v, expected a SchemaPathError for system", err)
+	}
+}
+
+func TestNewSchemaErrors(t *testing.T) {
+	augment, _ := ParseYANG([]byte(testYANGAugment))
+	if _, err := NewSchema(augment); err == nil {
+		t.Error("expected an error for an augment of a missing module")
+	}
+}
+
+func TestLoadSchema(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "example.yang"), []byte(testYANGModule), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := LoadSchema(dir)
+	if err != nil {
+		t.Fatalf("LoadSchema failed: %v", err)
+	}
+	if _, err := sc.Find("/interfaces/interface/mtu"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestGetSchema(t *testing.T) {
+	s, trans := newScriptedSession(nil,
+		`<rpc-reply message-id="1"><data xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring">module example {
+  namespace "urn:example";
+}</data></rpc-reply>`)
+
+	text, err := s.GetSchema(context.Background(), "example", "2020-01-01")
+	if err != nil {
+		t.Fatalf("GetSchema failed: %v", err)
+	}
+	if expected := "module example {\n  namespace \"urn:example\";\n}"; string(text) != expected {
+		t.Errorf("got %q, expected %q", text, expected)
+	}
+	expected := `<get-schema xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring"><identifier>example</identifier>` +
+		`<version>2020-01-01</version><format>yang</format></get-schema>`
+	if len(trans.sent) != 1 || !strings.Contains(trans.sent[0], expected) {
+		t.Errorf("got %v, expected the get-schema rpc", trans.sent)
+	}
+}
@@ -0,0 +1,558 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrServerClosed is returned by the Serve methods of a Server after Close.
+var ErrServerClosed = errors.New("netconf: server closed")
+
+// Handler handles an operation received by a Server.  It returns the
+// content of the <rpc-reply>, e.g. a <data> element, or nil to reply <ok/>.
+// An *RPCError is sent to the client as <rpc-error>, other errors as an
+// operation-failed error with the error as message.  The context is
+// cancelled when the session ends.
+type Handler interface {
+	ServeRPC(ctx context.Context, req *ServerRequest) ([]byte, error)
+}
+
+// HandlerFunc adapts a function to a Handler.
+type HandlerFunc func(ctx context.Context, req *ServerRequest) ([]byte, error)
+
+// ServeRPC calls f.
+func (f HandlerFunc) ServeRPC(ctx context.Context, req *ServerRequest) ([]byte, error) {
+	return f(ctx, req)
+}
+
+// ServerRequest is an RPC received by a Server.
+type ServerRequest struct {
+	Session   *ServerSession
+	MessageID string
+	// Attrs are the attributes of the <rpc> element other than message-id
+	// and the default namespace, which are returned on the reply.  The
+	// Space of an attribute name is its prefix.
+	Attrs []xml.Attr
+	// Operation is the element of the operation, e.g. <get-config>.
+	Operation *Node
+	// Raw holds the whole <rpc> message.
+	Raw RawXML
+}
+
+// ServerSession is a NETCONF session of a Server.
+type ServerSession struct {
+	ID int
+	// Capabilities are those announced by the client.
+	Capabilities []string
+	// User is the name the client authenticated as over SSH, if any.
+	User       string
+	RemoteAddr net.Addr
+
+	srv    *Server
+	trans  *transportBasicIO
+	ctx    context.Context
+	cancel context.CancelFunc
+	// mu serializes the messages sent, replies and notifications.
+	mu sync.Mutex
+}
+
+// HasCapability reports whether the client announced the capability uri.
+func (ss *ServerSession) HasCapability(uri string) bool {
+	for _, c := range ss.Capabilities {
+		if capabilityBase(c) == capabilityBase(uri) {
+			return true
+		}
+	}
+	return false
+}
+
+// Send sends msg, a complete NETCONF message, to the client.
+func (ss *ServerSession) Send(msg []byte) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.trans.Send(msg)
+}
+
+// Notify sends an event notification (RFC 5277) to the client.  event is
+// the XML of the event, eventTime defaults to the current time.
+func (ss *ServerSession) Notify(eventTime time.Time, event []byte) error {
+	if eventTime.IsZero() {
+		eventTime = time.Now()
+	}
+	var b bytes.Buffer
+	b.WriteString(`<notification xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0"><eventTime>`)
+	b.WriteString(eventTime.Format(time.RFC3339Nano))
+	b.WriteString("</eventTime>")
+	b.Write(event)
+	b.WriteString("</notification>")
+	return ss.Send(b.Bytes())
+}
+
+// Context returns the context of the session, which is cancelled when the
+// session ends.
+func (ss *ServerSession) Context() context.Context {
+	return ss.ctx
+}
+
+// Close terminates the session.
+func (ss *ServerSession) Close() error {
+	ss.cancel()
+	return ss.trans.Close()
+}
+
+// Server is a NETCONF server.  It negotiates the capabilities and the
+// framing with clients, assigns session IDs and dispatches the operations
+// of RPCs to the handlers registered for them.  close-session and
+// kill-session are handled by the server, other operations without a
+// handler are answered with operation-not-supported.
+//
+// RPCs of a session are handled one at a time, in the order received.
+// The fields must not be changed once the server is serving.
+type Server struct {
+	// Capabilities are announced in the hello in addition to base:1.0 and
+	// base:1.1.
+	Capabilities []string
+	// HelloTimeout, if set, bounds the time clients have to send their
+	// hello.
+	HelloTimeout time.Duration
+	// OnSession, if set, is called when a session has been established.
+	OnSession func(ss *ServerSession)
+	// OnSessionEnd, if set, is called when a session has ended.
+	OnSessionEnd func(ss *ServerSession)
+	Logger       Logger
+
+	mu        sync.Mutex
+	handlers  map[string]Handler
+	sessions  map[int]*ServerSession
+	lastID    int
+	listeners map[net.Listener]struct{}
+	closed    bool
+}
+
+// NewServer returns a server announcing capabilities.
+func NewServer(capabilities ...string) *Server {
+	return &Server{Capabilities: capabilities}
+}
+
+// Handle registers h for the operation with the local name op, e.g.
+// "get-config", replacing any previous handler.
+func (srv *Server) Handle(op string, h Handler) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.handlers == nil {
+		srv.handlers = make(map[string]Handler)
+	}
+	srv.handlers[op] = h
+}
+
+// HandleFunc registers fn for the operation with the local name op.
+func (srv *Server) HandleFunc(op string, fn func(ctx context.Context, req *ServerRequest) ([]byte, error)) {
+	srv.Handle(op, HandlerFunc(fn))
+}
+
+// Sessions returns the established sessions, ordered by ID.
+func (srv *Server) Sessions() []*ServerSession {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	sessions := make([]*ServerSession, 0, len(srv.sessions))
+	for _, ss := range srv.sessions {
+		sessions = append(sessions, ss)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID < sessions[j].ID })
+	return sessions
+}
+
+// Session returns the session with the given ID, or nil.
+func (srv *Server) Session(id int) *ServerSession {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return srv.sessions[id]
+}
+
+// Serve accepts connections on l and serves NETCONF on them directly,
+// without SSH, as DialTCP expects.  Such sessions are neither
+// authenticated nor encrypted unless l is a TLS listener (RFC 7589).
+func (srv *Server) Serve(l net.Listener) error {
+	return srv.serve(l, func(conn net.Conn) {
+		srv.ServeConn(conn)
+	})
+}
+
+// ServeSSH accepts SSH connections on l and serves NETCONF on the channels
+// that request the netconf subsystem (RFC 6242).  config authenticates the
+// clients.
+func (srv *Server) ServeSSH(l net.Listener, config *ssh.ServerConfig) error {
+	return srv.serve(l, func(conn net.Conn) {
+		srv.serveSSHConn(conn, config)
+	})
+}
+
+func (srv *Server) serve(l net.Listener, handle func(conn net.Conn)) error {
+	srv.mu.Lock()
+	if srv.closed {
+		srv.mu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	if srv.listeners == nil {
+		srv.listeners = make(map[net.Listener]struct{})
+	}
+	srv.listeners[l] = struct{}{}
+	srv.mu.Unlock()
+
+	defer func() {
+		srv.mu.Lock()
+		delete(srv.listeners, l)
+		srv.mu.Unlock()
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			srv.mu.Lock()
+			closed := srv.closed
+			srv.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		go handle(conn)
+	}
+}
+
+func (srv *Server) serveSSHConn(conn net.Conn, config *ssh.ServerConfig) {
+	sc, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		srv.logf("netconf: ssh handshake with %s failed: %v", conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+	defer sc.Close()
+	go ssh.DiscardRequests(reqs)
+	for nch := range chans {
+		if nch.ChannelType() != "session" {
+			nch.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		ch, reqs, err := nch.Accept()
+		if err != nil {
+			continue
+		}
+		go srv.serveSSHChannel(sc, ch, reqs)
+	}
+}
+
+func (srv *Server) serveSSHChannel(sc *ssh.ServerConn, ch ssh.Channel, reqs <-chan *ssh.Request) {
+	for req := range reqs {
+		var payload struct{ Name string }
+		ok := req.Type == "subsystem" && ssh.Unmarshal(req.Payload, &payload) == nil && payload.Name == "netconf"
+		req.Reply(ok, nil)
+		if ok {
+			go ssh.DiscardRequests(reqs)
+			srv.serveSession(ch, sc.User(), sc.RemoteAddr())
+			return
+		}
+	}
+	ch.Close()
+}
+
+// ServeConn serves a single NETCONF session on conn, which has been
+// established and authenticated by the caller, and closes it when the
+// session ends.  It returns the error that ended the session, nil if the
+// client closed it.
+func (srv *Server) ServeConn(conn io.ReadWriteCloser) error {
+	var addr net.Addr
+	if c, ok := conn.(interface{ RemoteAddr() net.Addr }); ok {
+		addr = c.RemoteAddr()
+	}
+	return srv.serveSession(conn, "", addr)
+}
+
+// Close stops the listeners and terminates all sessions.
+func (srv *Server) Close() error {
+	srv.mu.Lock()
+	srv.closed = true
+	var err error
+	for l := range srv.listeners {
+		if cerr := l.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	sessions := make([]*ServerSession, 0, len(srv.sessions))
+	for _, ss := range srv.sessions {
+		sessions = append(sessions, ss)
+	}
+	srv.mu.Unlock()
+
+	for _, ss := range sessions {
+		ss.Close()
+	}
+	return err
+}
+
+func (srv *Server) serveSession(conn io.ReadWriteCloser, user string, addr net.Addr) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	ss := &ServerSession{
+		User:       user,
+		RemoteAddr: addr,
+		srv:        srv,
+		trans:      &transportBasicIO{ReadWriteCloser: conn},
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	defer ss.Close()
+
+	if err := srv.hello(ss); err != nil {
+		srv.logf("netconf: hello from %v failed: %v", addr, err)
+		return err
+	}
+	if srv.OnSession != nil {
+		srv.OnSession(ss)
+	}
+	defer func() {
+		srv.mu.Lock()
+		delete(srv.sessions, ss.ID)
+		srv.mu.Unlock()
+		if srv.OnSessionEnd != nil {
+			srv.OnSessionEnd(ss)
+		}
+	}()
+
+	for {
+		msg, err := ss.trans.Receive()
+		if err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if done := srv.handle(ss, msg); done {
+			return nil
+		}
+	}
+}
+
+// hello exchanges the hello messages, selects the framing and registers
+// the session.
+func (srv *Server) hello(ss *ServerSession) error {
+	srv.mu.Lock()
+	if srv.closed {
+		srv.mu.Unlock()
+		return ErrServerClosed
+	}
+	if srv.sessions == nil {
+		srv.sessions = make(map[int]*ServerSession)
+	}
+	srv.lastID++
+	ss.ID = srv.lastID
+	srv.mu.Unlock()
+
+	capabilities := append([]string{CapabilityBase10, CapabilityBase11}, srv.Capabilities...)
+	if err := ss.trans.SendHello(&HelloMessage{Capabilities: capabilities, SessionID: ss.ID}); err != nil {
+		return err
+	}
+
+	if srv.HelloTimeout > 0 {
+		timer := time.AfterFunc(srv.HelloTimeout, func() { ss.trans.Close() })
+		defer timer.Stop()
+	}
+	hello, err := ss.trans.ReceiveHello()
+	if err != nil {
+		return err
+	}
+	if hello.SessionID != 0 {
+		return errors.New("netconf: client hello carries a session-id")
+	}
+	ss.Capabilities = hello.Capabilities
+	switch {
+	case ss.HasCapability(CapabilityBase11):
+		ss.trans.SetVersion("v1.1")
+	case !ss.HasCapability(CapabilityBase10):
+		return errors.New("netconf: no common base capability")
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.closed {
+		return ErrServerClosed
+	}
+	srv.sessions[ss.ID] = ss
+	return nil
+}
+
+// handle handles a message of ss and reports whether the session is closed.
+func (srv *Server) handle(ss *ServerSession, msg []byte) bool {
+	req, rerr := parseServerRequest(ss, msg)
+	if rerr != nil {
+		ss.reply(req, nil, rerr)
+		return false
+	}
+
+	op := req.Operation.XMLName.Local
+	switch op {
+	case "close-session":
+		ss.reply(req, nil, nil)
+		return true
+	case "kill-session":
+		ss.reply(req, nil, srv.killSession(ss, req.Operation))
+		return false
+	}
+
+	srv.mu.Lock()
+	h := srv.handlers[op]
+	srv.mu.Unlock()
+	if h == nil {
+		ss.reply(req, nil, &RPCError{Type: "protocol", Tag: "operation-not-supported", Severity: "error",
+			Message: "operation " + op + " is not supported"})
+		return false
+	}
+	data, err := h.ServeRPC(ss.ctx, req)
+	ss.reply(req, data, err)
+	return false
+}
+
+func (srv *Server) killSession(ss *ServerSession, op *Node) error {
+	id, err := strconv.Atoi(childValue(op, "session-id"))
+	if err != nil || id == ss.ID {
+		return &RPCError{Type: "protocol", Tag: "invalid-value", Severity: "error", Message: "invalid session-id"}
+	}
+	target := srv.Session(id)
+	if target == nil {
+		return &RPCError{Type: "protocol", Tag: "invalid-value", Severity: "error",
+			Message: fmt.Sprintf("no session %d", id)}
+	}
+	target.Close()
+	return nil
+}
+
+// parseServerRequest parses an <rpc>.  If it is malformed, the error to
+// reply is returned along with the request carrying the message-id, if
+// any.
+func parseServerRequest(ss *ServerSession, msg []byte) (*ServerRequest, error) {
+	req := &ServerRequest{Session: ss, Raw: msg}
+	d := xml.NewDecoder(bytes.NewReader(msg))
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			return req, &RPCError{Type: "rpc", Tag: "malformed-message", Severity: "error", Message: "malformed message"}
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "rpc" {
+			return req, &RPCError{Type: "rpc", Tag: "unknown-element", Severity: "error",
+				Message: "unexpected element " + start.Name.Local}
+		}
+		for _, attr := range start.Attr {
+			switch {
+			case attr.Name.Space == "" && attr.Name.Local == "message-id":
+				req.MessageID = attr.Value
+			case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+			default:
+				req.Attrs = append(req.Attrs, attr)
+			}
+		}
+		break
+	}
+	if req.MessageID == "" {
+		return req, &RPCError{Type: "rpc", Tag: "missing-attribute", Severity: "error", Message: "missing message-id"}
+	}
+
+	root, err := ParseNode(msg)
+	if err != nil {
+		return req, &RPCError{Type: "rpc", Tag: "malformed-message", Severity: "error", Message: "malformed message"}
+	}
+	if len(root.Children) != 1 {
+		return req, &RPCError{Type: "protocol", Tag: "malformed-message", Severity: "error",
+			Message: "expected a single operation"}
+	}
+	req.Operation = root.Children[0]
+	return req, nil
+}
+
+// reply sends the reply to req: data, <ok/> if data is nil, or err.
+func (ss *ServerSession) reply(req *ServerRequest, data []byte, err error) {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString("<rpc-reply")
+	if req.MessageID != "" {
+		b.WriteString(` message-id="` + EscapeText(req.MessageID) + `"`)
+	}
+	b.WriteString(` xmlns="` + BaseNamespace + `"`)
+	for _, attr := range req.Attrs {
+		b.WriteString(" " + rpcAttrName(attr) + `="` + EscapeText(attr.Value) + `"`)
+	}
+	b.WriteString(">")
+	switch {
+	case err != nil:
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &RPCError{Message: err.Error()}
+		}
+		writeRPCError(&b, rpcErr)
+	case data == nil:
+		b.WriteString("<ok/>")
+	default:
+		b.Write(data)
+	}
+	b.WriteString("</rpc-reply>")
+
+	if err := ss.Send(b.Bytes()); err != nil {
+		ss.srv.logf("netconf: reply to session %d failed: %v", ss.ID, err)
+	}
+}
+
+// writeRPCError writes e as <rpc-error>.  The type, tag and severity
+// default to application, operation-failed and error.  Info is written as
+// XML into the <error-info>, after the session-id if there is one.
+func writeRPCError(b *bytes.Buffer, e *RPCError) {
+	field := func(name, value, def string) {
+		if value == "" {
+			value = def
+		}
+		if value != "" {
+			b.WriteString("<" + name + ">" + EscapeText(value) + "</" + name + ">")
+		}
+	}
+	b.WriteString("<rpc-error>")
+	field("error-type", e.Type, "application")
+	field("error-tag", e.Tag, "operation-failed")
+	field("error-severity", e.Severity, "error")
+	field("error-path", e.Path, "")
+	if e.Message != "" {
+		b.WriteString(`<error-message xml:lang="en">` + EscapeText(strings.TrimSpace(e.Message)) + "</error-message>")
+	}
+	if e.SessionID != 0 || e.Info != "" {
+		b.WriteString("<error-info>")
+		if e.SessionID != 0 {
+			b.WriteString("<session-id>" + strconv.Itoa(e.SessionID) + "</session-id>")
+		}
+		b.WriteString(e.Info + "</error-info>")
+	}
+	b.WriteString("</rpc-error>")
+}
+
+func (srv *Server) logf(format string, v ...interface{}) {
+	if srv.Logger != nil {
+		srv.Logger.Printf(format, v...)
+	}
+}
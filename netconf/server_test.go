@@ -0,0 +1,285 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/xml"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/crypto/ssh"
+)
+
+// newTestServer returns a server with a get-config handler, serving plain
+// TCP on the returned address.
+func newTestServer(t *testing.T) (*Server, string) {
+	srv := NewServer(CapabilityCandidate)
+	srv.HandleFunc("get-config", func(ctx context.Context, req *ServerRequest) ([]byte, error) {
+		if source := req.Operation.Child("source"); source == nil || source.Child("running") == nil {
+			return nil, &RPCError{Type: "protocol", Tag: "invalid-value", Message: "unknown source"}
+		}
+		return []byte(`<data><system xmlns="urn:example:system"><hostname>r1</hostname></system></data>`), nil
+	})
+	srv.HandleFunc("commit", func(ctx context.Context, req *ServerRequest) ([]byte, error) {
+		return nil, errors.New("disk full")
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(l)
+	return srv, l.Addr().String()
+}
+
+func TestServer(t *testing.T) {
+	srv, addr := newTestServer(t)
+	defer srv.Close()
+
+	s, err := DialTCP(addr)
+	if err != nil {
+		t.Fatalf("DialTCP failed: %v", err)
+	}
+	defer s.Close()
+	if s.SessionID != 1 || !s.HasCapability(CapabilityCandidate) || s.Framing() != FramingChunked {
+		t.Errorf("got session %d with %v and %s framing, expected 1 with candidate and chunked framing", s.SessionID, s.ServerCapabilities, s.Framing())
+	}
+
+	reply, err := s.Exec(MethodGetConfig("running"))
+	if err != nil {
+		t.Fatalf("get-config failed: %v", err)
+	}
+	if !strings.Contains(reply.Data.String(), "<hostname>r1</hostname>") {
+		t.Errorf("got data %s, expected the hostname r1", reply.Data)
+	}
+
+	tt := []struct {
+		method RPCMethod
+		err    RPCError
+	}{
+		{MethodGetConfig("startup"), RPCError{Type: "protocol", Tag: "invalid-value", Severity: "error", Message: "unknown source"}},
+		{MethodCommit(), RPCError{Type: "application", Tag: "operation-failed", Severity: "error", Message: "disk full"}},
+		{MethodLock("running"), RPCError{Type: "protocol", Tag: "operation-not-supported", Severity: "error", Message: "operation lock is not supported"}},
+	}
+	for _, tc := range tt {
+		_, err := s.Exec(tc.method)
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			t.Errorf("%s: expected an RPC error, got %v", tc.method.MarshalMethod(), err)
+			continue
+		}
+		got := RPCError{Type: rpcErr.Type, Tag: rpcErr.Tag, Severity: rpcErr.Severity, Message: rpcErr.Message}
+		if diff := cmp.Diff(tc.err, got); diff != "" {
+			t.Errorf("%s: error mismatch (-expected +got):\n%s", tc.method.MarshalMethod(), diff)
+		}
+	}
+
+	if n := len(srv.Sessions()); n != 1 {
+		t.Errorf("got %d sessions, expected 1", n)
+	}
+	if _, err := s.Exec(MethodCloseSession()); err != nil {
+		t.Fatalf("close-session failed: %v", err)
+	}
+	waitForSessions(t, srv, 0)
+}
+
+func TestWriteRPCError(t *testing.T) {
+	tt := []struct {
+		name     string
+		err      *RPCError
+		expected string
+	}{
+		{
+			name:     "defaults",
+			err:      &RPCError{},
+			expected: "<rpc-error><error-type>application</error-type><error-tag>operation-failed</error-tag><error-severity>error</error-severity></rpc-error>",
+		},
+		{
+			name: "info",
+			err:  &RPCError{Type: "protocol", Tag: "missing-element", Info: "<bad-element>target</bad-element>"},
+			expected: "<rpc-error><error-type>protocol</error-type><error-tag>missing-element</error-tag><error-severity>error</error-severity>" +
+				"<error-info><bad-element>target</bad-element></error-info></rpc-error>",
+		},
+		{
+			name: "session-id and info",
+			err:  &RPCError{Type: "protocol", Tag: "lock-denied", SessionID: 4, Info: "<x/>"},
+			expected: "<rpc-error><error-type>protocol</error-type><error-tag>lock-denied</error-tag><error-severity>error</error-severity>" +
+				"<error-info><session-id>4</session-id><x/></error-info></rpc-error>",
+		},
+	}
+
+	for _, tc := range tt {
+		var b bytes.Buffer
+		writeRPCError(&b, tc.err)
+		if got := b.String(); got != tc.expected {
+			t.Errorf("%s: got %s, expected %s", tc.name, got, tc.expected)
+		}
+	}
+}
+
+func TestServerKillSession(t *testing.T) {
+	srv, addr := newTestServer(t)
+	defer srv.Close()
+
+	var sessions []*Session
+	for i := 0; i < 2; i++ {
+		s, err := DialTCP(addr)
+		if err != nil {
+			t.Fatalf("DialTCP failed: %v", err)
+		}
+		defer s.Close()
+		sessions = append(sessions, s)
+	}
+
+	if _, err := sessions[0].Exec(MethodKillSession(sessions[0].SessionID)); err == nil {
+		t.Error("expected killing the own session to fail")
+	}
+	if _, err := sessions[0].Exec(MethodKillSession(42)); err == nil {
+		t.Error("expected killing an unknown session to fail")
+	}
+	if _, err := sessions[0].Exec(MethodKillSession(sessions[1].SessionID)); err != nil {
+		t.Fatalf("kill-session failed: %v", err)
+	}
+	waitForSessions(t, srv, 1)
+	if _, err := sessions[1].Exec(MethodGetConfig("running")); err == nil {
+		t.Error("expected the killed session to fail")
+	}
+}
+
+func TestServerNotify(t *testing.T) {
+	srv, addr := newTestServer(t)
+	defer srv.Close()
+	srv.Capabilities = append(srv.Capabilities, CapabilityNotification)
+	eventTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv.HandleFunc("create-subscription", func(ctx context.Context, req *ServerRequest) ([]byte, error) {
+		go req.Session.Notify(eventTime, []byte(`<link-down xmlns="urn:example:events"/>`))
+		return nil, nil
+	})
+
+	s, err := DialTCP(addr)
+	if err != nil {
+		t.Fatalf("DialTCP failed: %v", err)
+	}
+	defer s.Close()
+	sub, err := s.Subscribe(context.Background(), &SubscriptionOptions{})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	defer sub.Close()
+
+	select {
+	case n := <-sub.C:
+		if !n.EventTime.Equal(eventTime) || !strings.Contains(string(n.Event), "link-down") {
+			t.Errorf("got notification %s at %v", n.Event, n.EventTime)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification received")
+	}
+}
+
+func TestServerSSH(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := make(chan string, 1)
+	srv := NewServer()
+	srv.OnSession = func(ss *ServerSession) { users <- ss.User }
+	done := make(chan error, 1)
+	go func() { done <- srv.ServeSSH(l, config) }()
+
+	s, err := Dial(l.Addr().String(), WithSSHConfig(testSSHConfig()))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer s.Close()
+	if user := <-users; user != "test" {
+		t.Errorf("got user %q, expected test", user)
+	}
+	var rpcErr *RPCError
+	if _, err := s.Exec(MethodGetConfig("running")); !errors.As(err, &rpcErr) || rpcErr.Tag != "operation-not-supported" {
+		t.Errorf("expected operation-not-supported, got %v", err)
+	}
+
+	srv.Close()
+	if err := <-done; err != ErrServerClosed {
+		t.Errorf("got %v, expected ErrServerClosed", err)
+	}
+}
+
+func TestParseServerRequest(t *testing.T) {
+	tt := []struct {
+		name  string
+		msg   string
+		id    string
+		attrs []xml.Attr
+		op    string
+		tag   string
+	}{
+		{
+			name:  "rpc",
+			msg:   `<rpc message-id="7" xmlns="urn:ietf:params:xml:ns:netconf:base:1.0" xmlns:ex="urn:example" ex:user="a"><get/></rpc>`,
+			id:    "7",
+			attrs: []xml.Attr{{Name: xml.Name{Space: "xmlns", Local: "ex"}, Value: "urn:example"}, {Name: xml.Name{Space: "ex", Local: "user"}, Value: "a"}},
+			op:    "get",
+		},
+		{name: "prefixed", msg: `<nc:rpc message-id="1" xmlns:nc="urn:ietf:params:xml:ns:netconf:base:1.0"><nc:get/></nc:rpc>`, id: "1",
+			attrs: []xml.Attr{{Name: xml.Name{Space: "xmlns", Local: "nc"}, Value: BaseNamespace}}, op: "get"},
+		{name: "no message-id", msg: `<rpc><get/></rpc>`, tag: "missing-attribute"},
+		{name: "not rpc", msg: `<hello/>`, tag: "unknown-element"},
+		{name: "no operation", msg: `<rpc message-id="2"/>`, id: "2", tag: "malformed-message"},
+		{name: "truncated", msg: `<rpc message-id="3"><get>`, id: "3", tag: "malformed-message"},
+		{name: "garbage", msg: `junk`, tag: "malformed-message"},
+	}
+	for _, tc := range tt {
+		req, err := parseServerRequest(nil, []byte(tc.msg))
+		var tag, op string
+		if rpcErr, ok := err.(*RPCError); ok {
+			tag = rpcErr.Tag
+		} else if err == nil {
+			op = req.Operation.XMLName.Local
+		}
+		if tag != tc.tag || op != tc.op || req.MessageID != tc.id {
+			t.Errorf("%s: got id %q, op %q, error %v, expected id %q, op %q, tag %q", tc.name, req.MessageID, op, err, tc.id, tc.op, tc.tag)
+		}
+		if tc.tag == "" {
+			if diff := cmp.Diff(tc.attrs, req.Attrs); diff != "" {
+				t.Errorf("%s: attrs mismatch (-expected +got):\n%s", tc.name, diff)
+			}
+		}
+	}
+}
+
+func waitForSessions(t *testing.T, srv *Server, n int) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if len(srv.Sessions()) == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("got %d sessions, expected %d", len(srv.Sessions()), n)
+}
@@ -0,0 +1,100 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"fmt"
+)
+
+// Service exposes NETCONF operations on the devices of a Pool for use by
+// RPC frontends such as the gRPC server of the netconfgrpc module or the
+// HTTP gateway.  Its methods map one
+// to one to the RPCs of the service.
+type Service struct {
+	Pool *Pool
+}
+
+// GetConfig returns the configuration of source on device, optionally
+// filtered.
+func (svc *Service) GetConfig(ctx context.Context, device, source string, filter *Filter) (RawXML, error) {
+	if source == "" {
+		source = "running"
+	}
+	var data RawXML
+	err := svc.Pool.Do(ctx, device, func(s *Session) error {
+		reply, err := s.ExecContext(ctx, MethodGetConfigFilter(source, filter))
+		if err != nil {
+			return err
+		}
+		data = append(RawXML(nil), reply.Data...)
+		return nil
+	})
+	return data, err
+}
+
+// EditConfig merges config into target on device.
+func (svc *Service) EditConfig(ctx context.Context, device, target, config string) error {
+	if target == "" {
+		return fmt.Errorf("netconf: edit-config target required")
+	}
+	return svc.Pool.Do(ctx, device, func(s *Session) error {
+		_, err := s.ExecContext(ctx, MethodEditConfig(target, config))
+		return err
+	})
+}
+
+// Exec sends the raw RPC, the XML of the operation without the <rpc>
+// element, to device and returns the raw reply.  RPC errors reported by
+// the device are returned as *RPCError.
+func (svc *Service) Exec(ctx context.Context, device, rpc string) (RawXML, error) {
+	var raw RawXML
+	err := svc.Pool.Do(ctx, device, func(s *Session) error {
+		reply, err := s.ExecContext(ctx, RawMethod(rpc))
+		if err != nil {
+			return err
+		}
+		raw = append(RawXML(nil), reply.RawReply...)
+		return nil
+	})
+	return raw, err
+}
+
+// Subscribe subscribes to an event stream of device and passes every
+// notification to send until ctx is done, the subscription ends or send
+// fails.  The subscription uses a session of its own, which is closed
+// afterwards.
+func (svc *Service) Subscribe(ctx context.Context, device string, opts *SubscriptionOptions, send func(*Notification) error) error {
+	d := svc.Pool.Inventory.Device(device)
+	if d == nil {
+		return fmt.Errorf("%w %q", ErrUnknownDevice, device)
+	}
+	s, err := svc.Pool.Dial(ctx, d)
+	if err != nil {
+		return err
+	}
+	sub, err := s.Subscribe(ctx, opts)
+	if err != nil {
+		s.Close()
+		return err
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case n, ok := <-sub.C:
+			if !ok {
+				return sub.Err()
+			}
+			if err := send(n); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestService(t *testing.T) {
+	p, _ := newTestPool(
+		`<rpc-reply><data><system/></data></rpc-reply>`,
+		replyOK,
+		replyOK,
+		replyError("invalid-value"),
+	)
+	svc := &Service{Pool: p}
+	ctx := context.Background()
+
+	data, err := svc.GetConfig(ctx, "r1", "", SubtreeFilter("<system/>"))
+	if err != nil || data.String() != "<data><system/></data>" {
+		t.Errorf("unexpected GetConfig result %q, %v", data, err)
+	}
+	if err := svc.EditConfig(ctx, "r1", "candidate", "<system/>"); err != nil {
+		t.Errorf("EditConfig failed: %v", err)
+	}
+
+	raw, err := svc.Exec(ctx, "r1", "<get-system-information/>")
+	if err != nil || !strings.Contains(raw.String(), "<ok/>") {
+		t.Errorf("unexpected Exec result %q, %v", raw, err)
+	}
+	var rpcErr *RPCError
+	if _, err := svc.Exec(ctx, "r1", "<get-system-information/>"); !errors.As(err, &rpcErr) {
+		t.Errorf("expected RPC error, got %v", err)
+	}
+}
+
+func TestServiceSubscribe(t *testing.T) {
+	p, _ := newTestPool(replyOK, testNotification(1), testNotification(2))
+	svc := &Service{Pool: p}
+
+	var events []string
+	err := svc.Subscribe(context.Background(), "r1", nil, func(n *Notification) error {
+		events = append(events, n.Event.String())
+		return nil
+	})
+	if !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("expected subscription to end with ErrSessionClosed, got %v", err)
+	}
+	if len(events) != 2 {
+		t.Errorf("expected 2 events, got %v", events)
+	}
+}
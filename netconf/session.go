@@ -11,8 +11,15 @@ Package netconf provides support for a a simple NETCONF client based on RFC6241
 package netconf
 
 import (
+	"bytes"
+	"context"
 	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Session defines the necessary components for a NETCONF session
@@ -21,64 +28,454 @@ type Session struct {
 	SessionID          int
 	ServerCapabilities []string
 	ErrOnWarning       bool
+	// Address is the host:port the session was dialled to by a
+	// SessionConfig, empty for sessions created over a given transport.
+	Address string
+	// Limiter, if set, throttles the RPCs issued on this session.
+	Limiter *RateLimiter
+	// ReadOnly, if set, rejects every operation not known to be read-only,
+	// see IsReadOnly, with ErrReadOnlySession before it is sent.  Requests
+	// that cannot be parsed are rejected too.
+	ReadOnly bool
+	// Queue, if set, serializes the RPCs of goroutines sharing the session
+	// by their priority, see WithPriority.
+	Queue *RPCQueue
+	// Profile, if set, selects vendor specific behaviour.
+	Profile *Profile
+	// RetryPolicy, if set, retries failed RPCs.  Failures of the transport
+	// and timeouts are only retried on sessions dialled by a SessionConfig,
+	// which are redialled first.
+	RetryPolicy *RetryPolicy
+	// Deadlines bounds the time spent writing each request and reading each
+	// reply.  It can be overridden per call with WithDeadlines.
+	Deadlines Deadlines
+	// CloseOnCancel, if set, sends close-session once the reply of a
+	// cancelled RPC has been read, before the transport is closed.
+	CloseOnCancel bool
+	// TolerantXML, if set, repairs malformed replies with RepairXML before
+	// they are parsed.
+	TolerantXML bool
+	// CharsetReader, if set, converts replies in charsets other than UTF-8,
+	// Latin-1 and ASCII.
+	CharsetReader CharsetReader
+	// Charset is assumed for replies that are not valid UTF-8 and do not
+	// declare their encoding.
+	Charset string
+	// Compression, if set, decompresses <data> payloads compressed by the
+	// server.
+	Compression *Compression
+	// Logger, if set, receives diagnostic messages such as retries.
+	Logger Logger
+	// Interceptors wrap every RPC, the first being the outermost.
+	Interceptors []Interceptor
+	// RPCAttrs are added to the <rpc> element of every request, see
+	// RPCMessage.Attrs.
+	RPCAttrs []xml.Attr
+
+	// abandoned is set once an RPC was cancelled or its request cut short.
+	abandoned bool
+	// redial, if set, opens a new session to the same address, used to
+	// retry RPCs that failed the session.
+	redial func(ctx context.Context) (*Session, error)
+	// version is the negotiated protocol version.
+	version string
+	// capabilities are derived from ServerCapabilities on first use.
+	capabilities *Capabilities
+	// closeHooks run when Close is called, before the transport is closed.
+	hooksMu    sync.Mutex
+	closeHooks []*closeHook
 }
 
+type closeHook struct{ fn func() }
+
+// ErrSessionAbandoned is returned for RPCs on a session whose earlier RPC was
+// cancelled before its reply was read, or whose request failed to stream
+// after part of it was sent.  The framing state of such a session is
+// unknown, so it cannot be used any more.
+var ErrSessionAbandoned = errors.New("netconf: session abandoned after cancelled RPC")
+
+// ErrReadOnlySession is returned for state changing operations on a session
+// with ReadOnly set.
+var ErrReadOnlySession = errors.New("netconf: state changing operation on read-only session")
+
+// cancelGrace bounds how long the reply of a cancelled RPC is awaited before
+// close-session is sent.
+const cancelGrace = 5 * time.Second
+
 // Close is used to close and end a transport session
 func (s *Session) Close() error {
+	s.hooksMu.Lock()
+	hooks := s.closeHooks
+	s.closeHooks = nil
+	s.hooksMu.Unlock()
+	for _, h := range hooks {
+		h.fn()
+	}
 	return s.Transport.Close()
 }
 
+// onClose registers fn to run when the session is closed and returns a
+// function removing it again.
+func (s *Session) onClose(fn func()) (remove func()) {
+	h := &closeHook{fn}
+	s.hooksMu.Lock()
+	s.closeHooks = append(s.closeHooks, h)
+	s.hooksMu.Unlock()
+	return func() {
+		s.hooksMu.Lock()
+		defer s.hooksMu.Unlock()
+		for i, other := range s.closeHooks {
+			if other == h {
+				s.closeHooks = append(s.closeHooks[:i], s.closeHooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
 // Exec is used to execute an RPC method or methods
 func (s *Session) Exec(methods ...RPCMethod) (*RPCReply, error) {
-	rpc := NewRPCMessage(methods)
+	return s.ExecContext(context.Background(), methods...)
+}
 
-	request, err := xml.Marshal(rpc)
+// ExecContext is used to execute an RPC method or methods.  Failed RPCs are
+// retried according to the session's RetryPolicy.  If ctx is cancelled
+// before the reply arrived, the reply is abandoned, the session is closed and
+// all further RPCs fail with ErrSessionAbandoned.
+func (s *Session) ExecContext(ctx context.Context, methods ...RPCMethod) (*RPCReply, error) {
+	if len(s.Interceptors) > 0 {
+		return s.intercept(ctx, methods, s.execRetry)
+	}
+	return s.execRetry(ctx, methods)
+}
+
+// execRetry executes the RPC, retrying as set out by the RetryPolicy.  An
+// attempt that left the session unusable is only retried if the session can
+// be redialled, see reopen.
+func (s *Session) execRetry(ctx context.Context, methods []RPCMethod) (*RPCReply, error) {
+	for attempt := 1; ; attempt++ {
+		reply, err := s.exec(ctx, methods)
+		if err == nil || !s.RetryPolicy.retry(ctx, attempt, methods, err) {
+			return reply, err
+		}
+		if !reusable(s, err) {
+			if s.redial == nil {
+				return reply, err
+			}
+			if reopenErr := s.reopen(ctx); reopenErr != nil {
+				s.logf("netconf: redial for retry failed: %v", reopenErr)
+				return reply, err
+			}
+		}
+		s.logf("netconf: retrying rpc after attempt %d: %v", attempt, err)
+	}
+}
+
+// reopen closes the transport of the session and replaces it with that of
+// a new session opened by redial, so that the session can be used again.
+func (s *Session) reopen(ctx context.Context) error {
+	if err := s.Queue.Acquire(ctx, priority(ctx)); err != nil {
+		return err
+	}
+	defer s.Queue.Release()
+
+	s.Transport.Close()
+	ns, err := s.redial(ctx)
 	if err != nil {
+		return err
+	}
+	s.Transport = ns.Transport
+	s.SessionID = ns.SessionID
+	s.ServerCapabilities = ns.ServerCapabilities
+	s.version = ns.version
+	s.capabilities = nil
+	s.abandoned = false
+	return nil
+}
+
+func (s *Session) exec(ctx context.Context, methods []RPCMethod) (*RPCReply, error) {
+	if s.abandoned {
+		return nil, ErrSessionAbandoned
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.checkReadOnly(methods); err != nil {
 		return nil, err
 	}
 
-	header := []byte(xml.Header)
-	request = append(header, request...)
+	rpc := NewRPCMessage(methods)
+	rpc.Attrs = s.RPCAttrs
 
-	err = s.Transport.Send(request)
+	if err := s.Queue.Acquire(ctx, priority(ctx)); err != nil {
+		return nil, err
+	}
+	defer s.Queue.Release()
+
+	if err := s.Limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	defer s.Limiter.Done()
+
+	rawXML, err := s.roundTrip(ctx, rpc)
 	if err != nil {
 		return nil, err
 	}
 
-	rawXML, err := s.Transport.Receive()
+	rawXML, err = toUTF8(rawXML, s.Charset, s.CharsetReader)
 	if err != nil {
 		return nil, err
 	}
+	rawXML = stripDeclarations(rawXML)
+	if s.TolerantXML {
+		rawXML = RepairXML(rawXML)
+	}
 
 	reply, err := newRPCReply(rawXML, s.ErrOnWarning, rpc.MessageID)
 	if err != nil {
 		return nil, err
 	}
 
+	if s.Compression.enabled(s) {
+		if reply.Data, err = decompressData(reply.Data); err != nil {
+			return nil, err
+		}
+	}
+
 	return reply, nil
 }
 
+// checkReadOnly rejects state changing methods on a read-only session.
+func (s *Session) checkReadOnly(methods []RPCMethod) error {
+	if !s.ReadOnly {
+		return nil
+	}
+	for _, m := range methods {
+		ops, err := methodOperations(m)
+		if err != nil {
+			return fmt.Errorf("%w: unreadable request: %v", ErrReadOnlySession, err)
+		}
+		for _, op := range ops {
+			if !IsReadOnly(op) {
+				return fmt.Errorf("%w: %s", ErrReadOnlySession, op)
+			}
+		}
+	}
+	return nil
+}
+
+// messageWriter is implemented by transports able to stream outgoing
+// messages.
+type messageWriter interface {
+	MessageWriter() io.WriteCloser
+}
+
+// send writes the request to the transport, streaming it if the transport
+// supports it.  Failures of the transport are returned as TransportError.
+// A message cut short leaves the peer waiting for its end, so the session
+// is closed once anything was written: after a failure of the transport,
+// and after an error streaming a method, which abandons the session.
+func (s *Session) send(rpc *RPCMessage) error {
+	if mw, ok := s.Transport.(messageWriter); ok {
+		w := &failedWriter{w: mw.MessageWriter()}
+		_, err := rpc.WriteTo(w)
+		if err == nil {
+			w.failed = w.w.Close()
+		}
+		if w.failed != nil {
+			s.Transport.Close()
+			return transportError("write", false, w.failed)
+		}
+		if err != nil {
+			s.abandoned = true
+			s.Transport.Close()
+			return fmt.Errorf("%w: request cut short: %v", ErrSessionAbandoned, err)
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if _, err := rpc.WriteTo(&buf); err != nil {
+		return err
+	}
+	if err := s.Transport.Send(buf.Bytes()); err != nil {
+		s.Transport.Close()
+		return transportError("write", false, err)
+	}
+	return nil
+}
+
+// failedWriter records the error of the message writer, to tell it from
+// errors marshaling the request.
+type failedWriter struct {
+	w      io.WriteCloser
+	failed error
+}
+
+func (f *failedWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err != nil {
+		f.failed = err
+	}
+	return n, err
+}
+
+// roundTrip sends the request and waits for its reply or the cancellation
+// of ctx, whichever comes first.
+func (s *Session) roundTrip(ctx context.Context, rpc *RPCMessage) ([]byte, error) {
+	if ctx.Done() == nil {
+		return s.sendReceive(ctx, rpc)
+	}
+
+	done := make(chan error, 1)
+	var rawXML []byte
+	go func() {
+		var err error
+		rawXML, err = s.sendReceive(ctx, rpc)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return rawXML, err
+	case <-ctx.Done():
+		s.abandon(done)
+		return nil, ctx.Err()
+	}
+}
+
+func (s *Session) sendReceive(ctx context.Context, rpc *RPCMessage) ([]byte, error) {
+	deadlines := s.deadlines(ctx)
+	err := s.withDeadline(ctx, "write", deadlines.Write, func() error {
+		return s.send(rpc)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rawXML []byte
+	err = s.withDeadline(ctx, "read", deadlines.Read, func() error {
+		var err error
+		rawXML, err = s.Transport.Receive()
+		return err
+	})
+	return rawXML, transportError("read", true, err)
+}
+
+// abandon marks the session unusable after an RPC was cancelled and shuts
+// it down in the background once the pending exchange completed.
+func (s *Session) abandon(pending <-chan error) {
+	s.abandoned = true
+	if !s.CloseOnCancel {
+		s.Transport.Close()
+		return
+	}
+
+	go func() {
+		defer s.Transport.Close()
+		select {
+		case err := <-pending:
+			if err != nil {
+				return
+			}
+		case <-time.After(cancelGrace):
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), cancelGrace)
+		defer cancel()
+		s.sendReceive(ctx, NewRPCMessage([]RPCMethod{MethodCloseSession()}))
+	}()
+}
+
+// pingMethod selects no data, making it the cheapest RPC every server must
+// answer.
+var pingMethod = RawMethod(`<get><filter type="subtree"/></get>`)
+
+// Ping checks the health of the session by issuing a minimal RPC and returns
+// its round-trip time.  An rpc-error reply still proves the session is alive
+// and is not reported as an error.
+func (s *Session) Ping(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	_, err := s.exec(ctx, []RPCMethod{pingMethod})
+	rtt := time.Since(start)
+	if _, ok := err.(*RPCError); ok {
+		err = nil
+	}
+	return rtt, err
+}
+
+// SaveConfig persists the running configuration so it survives a reboot.
+// The vendor specific save mechanism of the session's Profile is used if it
+// has one, otherwise running is copied to startup.
+func (s *Session) SaveConfig() error {
+	if s.Profile != nil && s.Profile.SaveConfig != nil {
+		_, err := s.Exec(s.Profile.SaveConfig)
+		return err
+	}
+
+	if !s.HasCapability(CapabilityStartup) {
+		return fmt.Errorf("netconf: server does not support the startup datastore")
+	}
+	_, err := s.Exec(MethodCopyConfig("running", "startup"))
+	return err
+}
+
 // NewSession creates a new NETCONF session using the provided transport layer.
 func NewSession(t Transport) *Session {
+	s, _ := newSession(t)
+	return s
+}
+
+// NewStrictSession creates a new NETCONF session like NewSession, but fails
+// unless the server hello passes ValidateHello with the required
+// capabilities.  The transport is closed if the session cannot be created.
+func NewStrictSession(t Transport, required ...string) (*Session, error) {
+	s, err := newSession(t)
+	if err == nil {
+		err = s.ValidateHello(required...)
+	}
+	if err != nil {
+		t.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// newSession exchanges hello messages and returns the session along with any
+// error receiving the server hello.
+func newSession(t Transport) (*Session, error) {
+	return newSessionHello(t, DefaultCapabilities)
+}
+
+// newSessionHello is newSession announcing the given capabilities.
+func newSessionHello(t Transport, capabilities []string) (*Session, error) {
 	s := new(Session)
 	s.Transport = t
 
 	// Receive Servers Hello message
-	serverHello, _ := t.ReceiveHello()
+	serverHello, err := t.ReceiveHello()
 	s.SessionID = serverHello.SessionID
 	s.ServerCapabilities = serverHello.Capabilities
 
 	// Send our hello using default capabilities.
-	t.SendHello(&HelloMessage{Capabilities: DefaultCapabilities})
+	t.SendHello(&HelloMessage{Capabilities: capabilities})
 
-	// Set Transport version
-	t.SetVersion("v1.0")
-	for _, capability := range s.ServerCapabilities {
+	// Set Transport version; 1.1 requires both sides to announce it.
+	s.version = "1.0"
+	if hasBase11(capabilities) && hasBase11(s.ServerCapabilities) {
+		s.version = "1.1"
+	}
+	t.SetVersion("v" + s.version)
+
+	return s, err
+}
+
+func hasBase11(capabilities []string) bool {
+	for _, capability := range capabilities {
 		if strings.Contains(capability, "urn:ietf:params:netconf:base:1.1") {
-			t.SetVersion("v1.1")
-			break
+			return true
 		}
 	}
-
-	return s
+	return false
 }
@@ -0,0 +1,350 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// scriptedTransport answers each request with the next canned reply and
+// records the requests it was sent.
+type scriptedTransport struct {
+	replies []string
+	sent    []string
+	closed  bool
+	hello   *HelloMessage
+}
+
+const replyOK = `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><ok/></rpc-reply>`
+
+func replyError(tag string) string {
+	return fmt.Sprintf(`<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><rpc-error>
+<error-type>protocol</error-type><error-tag>%s</error-tag><error-severity>error</error-severity>
+<error-message>%s failed</error-message></rpc-error></rpc-reply>`, tag, tag)
+}
+
+func newScriptedSession(capabilities []string, replies ...string) (*Session, *scriptedTransport) {
+	t := &scriptedTransport{replies: replies}
+	return &Session{Transport: t, ServerCapabilities: capabilities}, t
+}
+
+func (t *scriptedTransport) Send(b []byte) error {
+	if t.closed {
+		return io.ErrClosedPipe
+	}
+	t.sent = append(t.sent, string(b))
+	return nil
+}
+
+func (t *scriptedTransport) Receive() ([]byte, error) {
+	if len(t.replies) == 0 {
+		return nil, io.EOF
+	}
+	r := t.replies[0]
+	t.replies = t.replies[1:]
+	return []byte(r), nil
+}
+
+func (t *scriptedTransport) Close() error {
+	t.closed = true
+	return nil
+}
+
+func (t *scriptedTransport) ReceiveHello() (*HelloMessage, error) {
+	if t.hello != nil {
+		return t.hello, nil
+	}
+	return &HelloMessage{}, nil
+}
+
+func (t *scriptedTransport) SendHello(*HelloMessage) error { return nil }
+
+func (t *scriptedTransport) SetVersion(version string) {}
+
+// operations returns the name of the first element of each request sent.
+func (t *scriptedTransport) operations() []string {
+	var ops []string
+	for _, req := range t.sent {
+		i := strings.Index(req, "<rpc ")
+		j := strings.IndexByte(req[i:], '>')
+		op := req[i+j+1:]
+		op = op[1:strings.IndexAny(op, " />")]
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+func TestSessionHasCapability(t *testing.T) {
+	s := &Session{ServerCapabilities: []string{
+		"urn:ietf:params:netconf:base:1.1",
+		"urn:ietf:params:netconf:capability:validate:1.0",
+		"urn:ietf:params:netconf:capability:url:1.0?scheme=http,ftp",
+	}}
+
+	tt := []struct {
+		uri      string
+		expected bool
+	}{
+		{CapabilityBase11, true},
+		{CapabilityValidate, true},
+		{CapabilityURL, true},
+		{CapabilityCandidate, false},
+		{CapabilityBase10, false},
+	}
+	for _, tc := range tt {
+		if got := s.HasCapability(tc.uri); got != tc.expected {
+			t.Errorf("HasCapability(%q) = %v, expected %v", tc.uri, got, tc.expected)
+		}
+	}
+}
+
+func TestSessionSaveConfig(t *testing.T) {
+	tt := []struct {
+		name     string
+		caps     []string
+		profile  *Profile
+		expected string
+		err      bool
+	}{
+		{name: "startup", caps: []string{CapabilityStartup}, expected: "copy-config"},
+		{name: "nostartup", err: true},
+		{name: "iosxe", profile: ProfileIOSXE, expected: "save-config"},
+		{name: "sros", profile: ProfileSROS, expected: "action"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s, trans := newScriptedSession(tc.caps, replyOK)
+			s.Profile = tc.profile
+
+			err := s.SaveConfig()
+			if tc.err {
+				if err == nil {
+					t.Errorf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ops := trans.operations(); len(ops) != 1 || ops[0] != tc.expected {
+				t.Errorf("unexpected operations %v, expected %s", ops, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSessionPing(t *testing.T) {
+	s, trans := newScriptedSession(nil, replyOK, replyError("operation-not-supported"))
+	for i := 0; i < 2; i++ {
+		if _, err := s.Ping(context.Background()); err != nil {
+			t.Errorf("ping %d: unexpected error: %v", i, err)
+		}
+	}
+	if _, err := s.Ping(context.Background()); err == nil {
+		t.Errorf("expected error once the transport failed")
+	}
+	if ops := trans.operations(); len(ops) != 3 || ops[0] != "get" {
+		t.Errorf("unexpected operations: %v", ops)
+	}
+}
+
+// stalledTransport holds back its replies until release is closed.
+type stalledTransport struct {
+	silentTransport
+	release chan struct{}
+	sent    chan string
+}
+
+func (t *stalledTransport) Send(b []byte) error {
+	t.sent <- string(b)
+	return nil
+}
+
+func (t *stalledTransport) Receive() ([]byte, error) {
+	select {
+	case <-t.release:
+		return []byte(replyOK), nil
+	case <-t.done:
+		return nil, io.EOF
+	}
+}
+
+func TestExecCancel(t *testing.T) {
+	trans := &silentTransport{done: make(chan struct{})}
+	s := &Session{Transport: trans}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := s.ExecContext(ctx, MethodGetConfig("running")); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	select {
+	case <-trans.done:
+	default:
+		t.Errorf("transport not closed")
+	}
+	if _, err := s.Exec(MethodGetConfig("running")); err != ErrSessionAbandoned {
+		t.Errorf("expected ErrSessionAbandoned, got %v", err)
+	}
+}
+
+func TestExecCancelCloseSession(t *testing.T) {
+	trans := &stalledTransport{
+		silentTransport: silentTransport{done: make(chan struct{})},
+		release:         make(chan struct{}),
+		sent:            make(chan string, 2),
+	}
+	s := &Session{Transport: trans, CloseOnCancel: true}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := s.ExecContext(ctx, MethodGetConfig("running")); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	<-trans.sent
+	close(trans.release)
+
+	select {
+	case req := <-trans.sent:
+		if !strings.Contains(req, "<close-session/>") {
+			t.Errorf("expected close-session, got %s", req)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("close-session not sent")
+	}
+	select {
+	case <-trans.done:
+	case <-time.After(time.Second):
+		t.Errorf("transport not closed")
+	}
+}
+
+func TestValidateHello(t *testing.T) {
+	tt := []struct {
+		name     string
+		hello    *HelloMessage
+		required []string
+		problems []string
+	}{
+		{
+			name:     "valid",
+			hello:    &HelloMessage{SessionID: 4, Capabilities: []string{CapabilityBase10, CapabilityBase11, CapabilityCandidate}},
+			required: []string{CapabilityCandidate},
+		},
+		{
+			name:     "problems",
+			hello:    &HelloMessage{Capabilities: []string{CapabilityBase11, CapabilityBase11}},
+			required: []string{CapabilityCandidate},
+			problems: []string{
+				"missing session-id",
+				"duplicate capability urn:ietf:params:netconf:base:1.1",
+				"missing capability urn:ietf:params:netconf:capability:candidate:1.0",
+			},
+		},
+		{
+			name:     "noBase",
+			hello:    &HelloMessage{SessionID: 1, Capabilities: []string{CapabilityCandidate}},
+			problems: []string{"no supported base capability"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			trans := &scriptedTransport{hello: tc.hello}
+			s, err := NewStrictSession(trans, tc.required...)
+
+			if tc.problems == nil {
+				if err != nil || s == nil {
+					t.Fatalf("NewStrictSession failed: %v", err)
+				}
+				return
+			}
+			he, ok := err.(*HelloError)
+			if !ok {
+				t.Fatalf("expected *HelloError, got %v", err)
+			}
+			if diff := cmp.Diff(tc.problems, he.Problems); diff != "" {
+				t.Errorf("problems mismatch (-want +got):\n%s", diff)
+			}
+			if !trans.closed {
+				t.Errorf("transport not closed")
+			}
+		})
+	}
+}
+
+// errReader fails every read.
+type errReader struct{ err error }
+
+func (r errReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
+func TestSendCutShort(t *testing.T) {
+	trans, out := newTransportTest("")
+	trans.SetVersion("v1.1")
+	trans.SetChunkSize(16)
+	s := &Session{Transport: trans}
+	readErr := errors.New("disk error")
+	_, err := s.Exec(MethodEditConfigReader("candidate", errReader{readErr}))
+	if !errors.Is(err, ErrSessionAbandoned) || !strings.Contains(err.Error(), readErr.Error()) {
+		t.Errorf("got %v, expected the session to be abandoned", err)
+	}
+	if out.Len() == 0 {
+		t.Fatal("expected part of the request to be written")
+	}
+	if _, err := s.Exec(MethodGetConfig("running")); !errors.Is(err, ErrSessionAbandoned) {
+		t.Errorf("got %v for the next RPC, expected ErrSessionAbandoned", err)
+	}
+}
+
+func TestReadOnlySession(t *testing.T) {
+	s, trans := newScriptedSession(nil, replyOK, replyOK, replyOK)
+	s.ReadOnly = true
+	for _, m := range []RPCMethod{
+		MethodEditConfig("candidate", "<system/>"),
+		MethodCopyConfig("running", "startup"),
+		RawMethod(`<delete-config><target><startup/></target></delete-config>`),
+		MethodCommit(),
+		RawMethod(`<kill-session><session-id>4</session-id></kill-session>`),
+		MethodEditConfigReader("candidate", strings.NewReader("<system/>")),
+		RawMethod(`<get/><edit-config><target><running/></target><config/></edit-config>`),
+		RawMethod(`<action xmlns="urn:ietf:params:xml:ns:yang:1"><reset/></action>`),
+		RawMethod(`<command>request system reboot</command>`),
+		RawMethod(`<get><filter>`),
+		RawMethod(``),
+	} {
+		if _, err := s.Exec(MethodGetConfig("running"), m); !errors.Is(err, ErrReadOnlySession) {
+			t.Errorf("%s: expected ErrReadOnlySession, got %v", methodName(m), err)
+		}
+		if _, err := s.SendRPC(context.Background(), m); !errors.Is(err, ErrReadOnlySession) {
+			t.Errorf("%s: expected ErrReadOnlySession from SendRPC, got %v", methodName(m), err)
+		}
+	}
+	if len(trans.sent) != 0 {
+		t.Fatalf("state changing requests sent: %q", trans.sent)
+	}
+	if _, err := s.Exec(MethodGetConfig("running")); err != nil {
+		t.Errorf("unexpected error for get-config: %v", err)
+	}
+	if _, err := s.Exec(MethodLock("running")); err != nil {
+		t.Errorf("unexpected error for lock: %v", err)
+	}
+	if _, err := s.Exec(RawMethod("<get-interface-information/>")); err != nil {
+		t.Errorf("unexpected error for get-interface-information: %v", err)
+	}
+}
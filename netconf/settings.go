@@ -0,0 +1,201 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// SettingsFile is the name of the settings file looked up in the home
+// directory by LoadSettings.
+const SettingsFile = ".netconf.yaml"
+
+// Settings holds connection defaults read from a settings file and the
+// environment.
+type Settings struct {
+	Host     string `yaml:"host,omitempty"`
+	Port     int    `yaml:"port,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	// Password is best taken from the environment rather than a file.
+	Password      string `yaml:"password,omitempty"`
+	KeyFile       string `yaml:"key_file,omitempty"`
+	KeyPassphrase string `yaml:"key_passphrase,omitempty"`
+	// KnownHosts is an OpenSSH known_hosts file used to check host keys,
+	// ~/.ssh/known_hosts if empty.  InsecureIgnoreHostKey disables the
+	// check, for lab devices only.
+	KnownHosts            string `yaml:"known_hosts,omitempty"`
+	InsecureIgnoreHostKey bool   `yaml:"insecure_ignore_host_key,omitempty"`
+	Profile               string `yaml:"profile,omitempty"`
+	// Timeout bounds the establishment of a session, RPCTimeout the
+	// reading of each reply.
+	Timeout    time.Duration `yaml:"timeout,omitempty"`
+	RPCTimeout time.Duration `yaml:"rpc_timeout,omitempty"`
+}
+
+// Environment variables read by LoadSettings, overriding the settings file.
+// NETCONF_CONFIG names a settings file to use instead of ~/.netconf.yaml.
+const (
+	EnvConfig                = "NETCONF_CONFIG"
+	EnvHost                  = "NETCONF_HOST"
+	EnvPort                  = "NETCONF_PORT"
+	EnvUsername              = "NETCONF_USERNAME"
+	EnvPassword              = "NETCONF_PASSWORD"
+	EnvKeyFile               = "NETCONF_KEY_FILE"
+	EnvKeyPassphrase         = "NETCONF_KEY_PASSPHRASE"
+	EnvKnownHosts            = "NETCONF_KNOWN_HOSTS"
+	EnvInsecureIgnoreHostKey = "NETCONF_INSECURE_IGNORE_HOST_KEY"
+	EnvProfile               = "NETCONF_PROFILE"
+	EnvTimeout               = "NETCONF_TIMEOUT"
+	EnvRPCTimeout            = "NETCONF_RPC_TIMEOUT"
+)
+
+// LoadSettings reads the settings file named by NETCONF_CONFIG, or
+// ~/.netconf.yaml if it exists, and applies the NETCONF_* environment
+// variables on top.
+func LoadSettings() (*Settings, error) {
+	s := &Settings{}
+	path, explicit := os.LookupEnv(EnvConfig)
+	if !explicit {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, SettingsFile)
+		}
+	}
+	if path != "" {
+		fs, err := ReadSettingsFile(path)
+		switch {
+		case err == nil:
+			s = fs
+		case explicit || !os.IsNotExist(err):
+			return nil, err
+		}
+	}
+	if err := s.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ReadSettingsFile reads settings from a YAML file.
+func ReadSettingsFile(path string) (*Settings, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Settings{}
+	if err := yaml.UnmarshalStrict(buf, s); err != nil {
+		return nil, fmt.Errorf("netconf: %s: %v", path, err)
+	}
+	return s, nil
+}
+
+// ApplyEnv overrides the settings with the NETCONF_* variables found by
+// lookup, typically os.LookupEnv.
+func (s *Settings) ApplyEnv(lookup func(key string) (string, bool)) error {
+	strs := map[string]*string{
+		EnvHost:          &s.Host,
+		EnvUsername:      &s.Username,
+		EnvPassword:      &s.Password,
+		EnvKeyFile:       &s.KeyFile,
+		EnvKeyPassphrase: &s.KeyPassphrase,
+		EnvKnownHosts:    &s.KnownHosts,
+		EnvProfile:       &s.Profile,
+	}
+	for key, p := range strs {
+		if v, ok := lookup(key); ok {
+			*p = v
+		}
+	}
+
+	if v, ok := lookup(EnvPort); ok {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("netconf: invalid %s %q", EnvPort, v)
+		}
+		s.Port = port
+	}
+	if v, ok := lookup(EnvInsecureIgnoreHostKey); ok {
+		insecure, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("netconf: invalid %s %q", EnvInsecureIgnoreHostKey, v)
+		}
+		s.InsecureIgnoreHostKey = insecure
+	}
+	durations := map[string]*time.Duration{EnvTimeout: &s.Timeout, EnvRPCTimeout: &s.RPCTimeout}
+	for key, p := range durations {
+		if v, ok := lookup(key); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("netconf: invalid %s %q", key, v)
+			}
+			*p = d
+		}
+	}
+	return nil
+}
+
+// Target returns the host and port to dial, the port being left out if it
+// is not set.
+func (s *Settings) Target() string {
+	if s.Port == 0 {
+		return s.Host
+	}
+	host, _ := splitTarget(s.Host)
+	return net.JoinHostPort(host, strconv.Itoa(s.Port))
+}
+
+// Options returns the Dial options described by the settings.
+func (s *Settings) Options() ([]Option, error) {
+	config := &ssh.ClientConfig{User: s.Username}
+	if s.KeyFile != "" {
+		key, err := SSHConfigPubKeyFile(s.Username, s.KeyFile, s.KeyPassphrase)
+		if err != nil {
+			return nil, err
+		}
+		config.Auth = append(config.Auth, key.Auth...)
+	}
+	if s.Password != "" {
+		config.Auth = append(config.Auth, ssh.Password(s.Password))
+	}
+	switch {
+	case s.InsecureIgnoreHostKey:
+		config.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+	case s.KnownHosts != "":
+		callback, err := knownhosts.New(s.KnownHosts)
+		if err != nil {
+			return nil, err
+		}
+		config.HostKeyCallback = callback
+	default:
+		config.HostKeyCallback = checkKnownHosts
+	}
+
+	opts := []Option{WithSSHConfig(config)}
+	if s.Profile != "" {
+		p := LookupProfile(s.Profile)
+		if p == nil {
+			return nil, fmt.Errorf("netconf: unknown profile %q", s.Profile)
+		}
+		opts = append(opts, WithProfile(p))
+	}
+	if s.Timeout > 0 {
+		opts = append(opts, WithTimeout(s.Timeout))
+	}
+	if s.RPCTimeout > 0 {
+		opts = append(opts, WithRPCDeadlines(Deadlines{Read: s.RPCTimeout}))
+	}
+	return opts, nil
+}
@@ -0,0 +1,126 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadSettings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "netconf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "netconf.yaml")
+	err = ioutil.WriteFile(path, []byte("host: r1\nusername: ops\nprofile: junos\ntimeout: 10s\nrpc_timeout: 1m\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv(EnvConfig, path)
+	os.Setenv(EnvPort, "2022")
+	os.Setenv(EnvPassword, "secret")
+	defer func() {
+		os.Unsetenv(EnvConfig)
+		os.Unsetenv(EnvPort)
+		os.Unsetenv(EnvPassword)
+	}()
+
+	s, err := LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings failed: %v", err)
+	}
+	expected := &Settings{
+		Host:       "r1",
+		Port:       2022,
+		Username:   "ops",
+		Password:   "secret",
+		Profile:    "junos",
+		Timeout:    10 * time.Second,
+		RPCTimeout: time.Minute,
+	}
+	if diff := cmp.Diff(expected, s); diff != "" {
+		t.Errorf("settings mismatch (-want +got):\n%s", diff)
+	}
+	if s.Target() != "r1:2022" {
+		t.Errorf("unexpected target %s", s.Target())
+	}
+
+	config := NewSessionConfig(mustOptions(t, s)...)
+	if config.SSHConfig.User != "ops" || len(config.SSHConfig.Auth) != 1 || config.Profile != ProfileJunos ||
+		config.Timeout != 10*time.Second || config.Deadlines.Read != time.Minute {
+		t.Errorf("unexpected session config %+v", config)
+	}
+
+	os.Setenv(EnvConfig, filepath.Join(dir, "missing.yaml"))
+	if _, err := LoadSettings(); err == nil {
+		t.Error("expected error for missing explicit settings file")
+	}
+}
+
+func TestSettingsApplyEnv(t *testing.T) {
+	env := map[string]string{EnvTimeout: "soon"}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+	if err := (&Settings{}).ApplyEnv(lookup); err == nil {
+		t.Error("expected error for invalid timeout")
+	}
+	if _, err := (&Settings{Profile: "unknown"}).Options(); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+
+	env = map[string]string{EnvInsecureIgnoreHostKey: "maybe"}
+	if err := (&Settings{}).ApplyEnv(lookup); err == nil {
+		t.Error("expected error for invalid insecure_ignore_host_key")
+	}
+	env = map[string]string{EnvInsecureIgnoreHostKey: "true"}
+	s := &Settings{}
+	if err := s.ApplyEnv(lookup); err != nil || !s.InsecureIgnoreHostKey {
+		t.Errorf("got %v, %v, expected host keys to be ignored", s.InsecureIgnoreHostKey, err)
+	}
+}
+
+func TestSettingsHostKeys(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+	home, err := ioutil.TempDir("", "netconf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+
+	if _, err := Dial(srv.Addr(), mustOptions(t, &Settings{Username: "ops", Timeout: 5 * time.Second})...); err == nil {
+		t.Error("expected error without known_hosts")
+	}
+	if _, err := (&Settings{KnownHosts: filepath.Join(home, "missing")}).Options(); err == nil {
+		t.Error("expected error for missing known_hosts file")
+	}
+	s, err := Dial(srv.Addr(), mustOptions(t, &Settings{Username: "ops", Timeout: 5 * time.Second, InsecureIgnoreHostKey: true})...)
+	if err != nil {
+		t.Fatalf("Dial ignoring host keys failed: %v", err)
+	}
+	s.Close()
+}
+
+func mustOptions(t *testing.T, s *Settings) []Option {
+	opts, err := s.Options()
+	if err != nil {
+		t.Fatalf("Options failed: %v", err)
+	}
+	return opts
+}
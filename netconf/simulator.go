@@ -0,0 +1,714 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Scenario describes a simulated device: the initial content of its
+// datastores, its operational data and the replies, errors and
+// notifications scripted for CI workflows.
+//
+//	name: r1
+//	running: |
+//	  <system xmlns="urn:example:system"><hostname>r1</hostname></system>
+//	state: |
+//	  <system-state xmlns="urn:example:system"><uptime>42</uptime></system-state>
+//	replies:
+//	  - operation: get-software-information
+//	    reply: <software-information><version>1.0</version></software-information>
+//	errors:
+//	  - operation: commit
+//	    after: 1
+//	    tag: resource-denied
+//	    message: commit database busy
+//	notifications:
+//	  - on: commit
+//	    event: <config-change xmlns="urn:example:events"/>
+type Scenario struct {
+	Name string `yaml:"name,omitempty"`
+	// Capabilities are announced instead of the default ones:
+	// writable-running, candidate, validate, notification and interleave,
+	// plus startup if the scenario has a startup datastore.  They select
+	// the datastores that can be used.
+	Capabilities []string `yaml:"capabilities,omitempty"`
+	// Running, Candidate and Startup hold the content of the datastores.
+	// The candidate starts as a copy of running if not set.
+	Running   string `yaml:"running,omitempty"`
+	Candidate string `yaml:"candidate,omitempty"`
+	Startup   string `yaml:"startup,omitempty"`
+	// State is the operational data returned by get along with running.
+	State string `yaml:"state,omitempty"`
+	// ListKeys names the key leaves of list entries by element name, used
+	// to match the entries of edits.  Entries are matched by their name
+	// leaf otherwise.
+	ListKeys      map[string][]string    `yaml:"list-keys,omitempty"`
+	Replies       []ScenarioReply        `yaml:"replies,omitempty"`
+	Errors        []ScenarioError        `yaml:"errors,omitempty"`
+	Notifications []ScenarioNotification `yaml:"notifications,omitempty"`
+}
+
+// ScenarioReply is a canned reply to an operation.
+type ScenarioReply struct {
+	Operation string `yaml:"operation"`
+	// Reply is the content of the <rpc-reply>, <ok/> if empty.
+	Reply string `yaml:"reply,omitempty"`
+}
+
+// ScenarioError makes calls of an operation fail.  The first After calls
+// succeed, then Times calls fail, all of them if Times is zero.
+type ScenarioError struct {
+	Operation string `yaml:"operation"`
+	After     int    `yaml:"after,omitempty"`
+	Times     int    `yaml:"times,omitempty"`
+	// Type, Tag and Severity default to application, operation-failed and
+	// error.
+	Type     string `yaml:"type,omitempty"`
+	Tag      string `yaml:"tag,omitempty"`
+	Severity string `yaml:"severity,omitempty"`
+	Message  string `yaml:"message,omitempty"`
+	// Info is the XML content of the <error-info>, e.g.
+	// <bad-element>target</bad-element>.
+	Info string `yaml:"info,omitempty"`
+}
+
+// ScenarioNotification is an event sent to the subscribed sessions each
+// time the operation On succeeds.
+type ScenarioNotification struct {
+	On    string `yaml:"on"`
+	Event string `yaml:"event"`
+}
+
+// LoadScenario reads a scenario from a YAML or JSON file.
+func LoadScenario(path string) (*Scenario, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sc, err := ParseScenario(buf)
+	if err != nil {
+		return nil, fmt.Errorf("scenario %s: %v", path, err)
+	}
+	return sc, nil
+}
+
+// ParseScenario parses a scenario document.  As JSON is a subset of YAML
+// both formats are accepted.
+func ParseScenario(data []byte) (*Scenario, error) {
+	sc := new(Scenario)
+	if err := yaml.UnmarshalStrict(data, sc); err != nil {
+		return nil, err
+	}
+	if _, err := sc.datastores(); err != nil {
+		return nil, err
+	}
+	for i, r := range sc.Replies {
+		if r.Operation == "" {
+			return nil, fmt.Errorf("reply #%d has no operation", i)
+		}
+	}
+	for i, e := range sc.Errors {
+		if e.Operation == "" {
+			return nil, fmt.Errorf("error #%d has no operation", i)
+		}
+		if e.After < 0 || e.Times < 0 {
+			return nil, fmt.Errorf("error #%d of %s has a negative count", i, e.Operation)
+		}
+	}
+	for i, n := range sc.Notifications {
+		if n.On == "" || n.Event == "" {
+			return nil, fmt.Errorf("notification #%d needs an operation and an event", i)
+		}
+	}
+	return sc, nil
+}
+
+// datastores parses the content of the datastores and the state.
+func (sc *Scenario) datastores() (map[string][]*Node, error) {
+	stores := make(map[string][]*Node)
+	for name, content := range map[string]string{
+		"running": sc.Running, "candidate": sc.Candidate, "startup": sc.Startup, "state": sc.State,
+	} {
+		nodes, err := ParseNodes([]byte(content))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", name, err)
+		}
+		stores[name] = nodes
+	}
+	if sc.Candidate == "" {
+		stores["candidate"] = cloneNodes(stores["running"])
+	}
+	return stores, nil
+}
+
+// simulatorCapabilities are announced by simulators whose scenario sets
+// none.
+var simulatorCapabilities = []string{
+	CapabilityWritableRunning,
+	CapabilityCandidate,
+	CapabilityValidate,
+	CapabilityNotification,
+	CapabilityInterleave,
+}
+
+// Simulator is a NETCONF server simulating a device described by a
+// Scenario.  It implements get, get-config, edit-config, copy-config,
+// delete-config, lock, unlock, validate, commit, discard-changes and
+// create-subscription on in-memory datastores, so that workflows can be
+// tested without devices.  Edits are applied without a schema: list
+// entries are matched by the keys of the scenario.
+//
+// The simulator serves with the methods of its Server, whose OnSessionEnd
+// hook it uses to release the locks and subscriptions of sessions.
+type Simulator struct {
+	*Server
+	scenario *Scenario
+
+	mu         sync.Mutex
+	stores     map[string][]*Node
+	locks      map[string]int
+	subscribed map[int]*ServerSession
+	calls      map[string]int
+	// events queues the notifications, sent in order by a single
+	// goroutine until done is closed.
+	events    chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewSimulator returns a simulator of sc.
+func NewSimulator(sc *Scenario) (*Simulator, error) {
+	stores, err := sc.datastores()
+	if err != nil {
+		return nil, err
+	}
+	capabilities := sc.Capabilities
+	if len(capabilities) == 0 {
+		capabilities = append([]string(nil), simulatorCapabilities...)
+		if sc.Startup != "" {
+			capabilities = append(capabilities, CapabilityStartup)
+		}
+	}
+
+	sim := &Simulator{
+		Server:     NewServer(capabilities...),
+		scenario:   sc,
+		stores:     stores,
+		locks:      make(map[string]int),
+		subscribed: make(map[int]*ServerSession),
+		calls:      make(map[string]int),
+		events:     make(chan []byte, 64),
+		done:       make(chan struct{}),
+	}
+	sim.OnSessionEnd = sim.endSession
+	ops := []string{"get", "get-config", "edit-config", "copy-config", "delete-config", "lock", "unlock",
+		"validate", "commit", "discard-changes", "create-subscription"}
+	for _, r := range sc.Replies {
+		ops = append(ops, r.Operation)
+	}
+	for _, e := range sc.Errors {
+		ops = append(ops, e.Operation)
+	}
+	for _, op := range ops {
+		sim.HandleFunc(op, sim.serveRPC)
+	}
+	go sim.sendEvents()
+	return sim, nil
+}
+
+// Datastore returns the content of the datastore running, candidate or
+// startup, e.g. to check the outcome of a workflow.
+func (sim *Simulator) Datastore(name string) string {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	return nodesString(sim.stores[name])
+}
+
+// Calls returns the number of RPCs received for the operation op.
+func (sim *Simulator) Calls(op string) int {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	return sim.calls[op]
+}
+
+// Notify sends event, the XML of an event, to the subscribed sessions.
+func (sim *Simulator) Notify(event string) {
+	select {
+	case sim.events <- []byte(event):
+	case <-sim.done:
+	}
+}
+
+// Close stops the simulator and its server.
+func (sim *Simulator) Close() error {
+	sim.closeOnce.Do(func() { close(sim.done) })
+	return sim.Server.Close()
+}
+
+func (sim *Simulator) sendEvents() {
+	for {
+		var event []byte
+		select {
+		case event = <-sim.events:
+		case <-sim.done:
+			return
+		}
+		sim.mu.Lock()
+		sessions := make([]*ServerSession, 0, len(sim.subscribed))
+		for _, ss := range sim.subscribed {
+			sessions = append(sessions, ss)
+		}
+		sim.mu.Unlock()
+		for _, ss := range sessions {
+			if err := ss.Notify(time.Now(), event); err != nil {
+				sim.logf("netconf: notification to session %d failed: %v", ss.ID, err)
+			}
+		}
+	}
+}
+
+func (sim *Simulator) endSession(ss *ServerSession) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	for store, id := range sim.locks {
+		if id == ss.ID {
+			delete(sim.locks, store)
+		}
+	}
+	delete(sim.subscribed, ss.ID)
+}
+
+func (sim *Simulator) serveRPC(ctx context.Context, req *ServerRequest) ([]byte, error) {
+	op := req.Operation.XMLName.Local
+	sim.mu.Lock()
+	sim.calls[op]++
+	n := sim.calls[op]
+	for _, e := range sim.scenario.Errors {
+		if e.Operation == op && n > e.After && (e.Times == 0 || n <= e.After+e.Times) {
+			sim.mu.Unlock()
+			return nil, &RPCError{Type: e.Type, Tag: e.Tag, Severity: e.Severity, Message: e.Message, Info: e.Info}
+		}
+	}
+	data, err := sim.operation(req)
+	sim.mu.Unlock()
+
+	if err == nil {
+		for _, n := range sim.scenario.Notifications {
+			if n.On == op {
+				sim.Notify(n.Event)
+			}
+		}
+	}
+	return data, err
+}
+
+// operation performs an operation with sim.mu held.
+func (sim *Simulator) operation(req *ServerRequest) ([]byte, error) {
+	op := req.Operation
+	for _, r := range sim.scenario.Replies {
+		if r.Operation == op.XMLName.Local {
+			if r.Reply == "" {
+				return nil, nil
+			}
+			return []byte(r.Reply), nil
+		}
+	}
+
+	id := req.Session.ID
+	switch op.XMLName.Local {
+	case "get":
+		data := cloneNodes(sim.stores["running"])
+		data, err := sim.merge(data, sim.stores["state"], "merge")
+		if err != nil {
+			return nil, err
+		}
+		return filteredData(data, op)
+	case "get-config":
+		store, err := sim.datastore(op, "source", false)
+		if err != nil {
+			return nil, err
+		}
+		return filteredData(sim.stores[store], op)
+	case "edit-config":
+		store, err := sim.writable(op, "target", id)
+		if err != nil {
+			return nil, err
+		}
+		config := op.Child("config")
+		if config == nil {
+			return nil, missingElement("config")
+		}
+		defaultOp := childValue(op, "default-operation")
+		if defaultOp == "" {
+			defaultOp = "merge"
+		}
+		data := cloneNodes(sim.stores[store])
+		if defaultOp == "replace" {
+			data = nil
+		}
+		if data, err = sim.merge(data, config.Children, defaultOp); err != nil {
+			return nil, err
+		}
+		sim.stores[store] = data
+	case "copy-config":
+		target, err := sim.writable(op, "target", id)
+		if err != nil {
+			return nil, err
+		}
+		var data []*Node
+		if source := op.Child("source"); source != nil && source.Child("config") != nil {
+			data = cloneNodes(source.Child("config").Children)
+		} else {
+			store, err := sim.datastore(op, "source", false)
+			if err != nil {
+				return nil, err
+			}
+			data = cloneNodes(sim.stores[store])
+		}
+		sim.stores[target] = data
+	case "delete-config":
+		target, err := sim.writable(op, "target", id)
+		if err != nil {
+			return nil, err
+		}
+		if target == "running" {
+			return nil, invalidValue("running cannot be deleted")
+		}
+		sim.stores[target] = nil
+	case "lock", "unlock":
+		store, err := sim.datastore(op, "target", false)
+		if err != nil {
+			return nil, err
+		}
+		holder, locked := sim.locks[store]
+		switch {
+		case op.XMLName.Local == "lock" && locked:
+			return nil, &RPCError{Type: "protocol", Tag: "lock-denied", Severity: "error",
+				Message: "lock held by session " + fmt.Sprint(holder), SessionID: holder}
+		case op.XMLName.Local == "lock":
+			sim.locks[store] = id
+		case !locked || holder != id:
+			return nil, &RPCError{Type: "protocol", Tag: "operation-failed", Severity: "error",
+				Message: store + " is not locked by this session"}
+		default:
+			delete(sim.locks, store)
+		}
+	case "validate":
+		if source := op.Child("source"); source == nil || source.Child("config") == nil {
+			if _, err := sim.datastore(op, "source", false); err != nil {
+				return nil, err
+			}
+		}
+	case "commit":
+		if err := sim.available("candidate"); err != nil {
+			return nil, err
+		}
+		if err := sim.unlocked("running", id); err != nil {
+			return nil, err
+		}
+		sim.stores["running"] = cloneNodes(sim.stores["candidate"])
+	case "discard-changes":
+		if err := sim.available("candidate"); err != nil {
+			return nil, err
+		}
+		sim.stores["candidate"] = cloneNodes(sim.stores["running"])
+	case "create-subscription":
+		if !sim.hasCapability(CapabilityNotification) {
+			return nil, &RPCError{Type: "protocol", Tag: "operation-not-supported", Severity: "error",
+				Message: "notifications are not supported"}
+		}
+		if _, ok := sim.subscribed[id]; ok {
+			return nil, &RPCError{Type: "protocol", Tag: "operation-failed", Severity: "error",
+				Message: "session already has a subscription"}
+		}
+		sim.subscribed[id] = req.Session
+	}
+	return nil, nil
+}
+
+// datastore returns the datastore named by the element child of op, e.g.
+// <target><candidate/></target>.
+func (sim *Simulator) datastore(op *Node, child string, write bool) (string, error) {
+	c := op.Child(child)
+	if c == nil || len(c.Children) != 1 {
+		return "", missingElement(child)
+	}
+	store := c.Children[0].XMLName.Local
+	if err := sim.available(store); err != nil {
+		return "", err
+	}
+	if write && store == "running" && !sim.hasCapability(CapabilityWritableRunning) {
+		return "", &RPCError{Type: "protocol", Tag: "operation-not-supported", Severity: "error",
+			Message: "running is not writable"}
+	}
+	return store, nil
+}
+
+// writable returns the target datastore of op if session id may write it.
+func (sim *Simulator) writable(op *Node, child string, id int) (string, error) {
+	store, err := sim.datastore(op, child, true)
+	if err != nil {
+		return "", err
+	}
+	return store, sim.unlocked(store, id)
+}
+
+func (sim *Simulator) available(store string) error {
+	ok := store == "running" ||
+		store == "candidate" && sim.hasCapability(CapabilityCandidate) ||
+		store == "startup" && sim.hasCapability(CapabilityStartup)
+	if !ok {
+		return invalidValue("unknown datastore " + store)
+	}
+	return nil
+}
+
+// unlocked fails if store is locked by a session other than id.
+func (sim *Simulator) unlocked(store string, id int) error {
+	if holder, ok := sim.locks[store]; ok && holder != id {
+		return &RPCError{Type: "protocol", Tag: "in-use", Severity: "error",
+			Message: fmt.Sprintf("%s is locked by session %d", store, holder), SessionID: holder}
+	}
+	return nil
+}
+
+func (sim *Simulator) hasCapability(uri string) bool {
+	for _, c := range sim.Capabilities {
+		if capabilityBase(c) == capabilityBase(uri) {
+			return true
+		}
+	}
+	return false
+}
+
+// merge applies edits to data with the operation defaultOp, honouring the
+// operation attributes of RFC 6241 on the edits.
+func (sim *Simulator) merge(data, edits []*Node, defaultOp string) ([]*Node, error) {
+	for _, e := range edits {
+		op := defaultOp
+		if v, ok := e.Attr(BaseNamespace, "operation"); ok {
+			op = v
+		}
+		i := sim.find(data, e)
+		switch op {
+		case "merge", "none":
+			switch {
+			case i < 0 && op == "none" && e.IsLeaf():
+			case i < 0:
+				n := stripOperations(e)
+				if op == "none" {
+					children, err := sim.merge(nil, e.Children, op)
+					if err != nil {
+						return nil, err
+					}
+					if len(children) == 0 {
+						continue
+					}
+					n.Children = children
+				}
+				data = append(data, n)
+			case e.IsLeaf():
+				if op == "merge" {
+					data[i].Text = e.Text
+					data[i].Children = nil
+				}
+			default:
+				children, err := sim.merge(data[i].Children, e.Children, op)
+				if err != nil {
+					return nil, err
+				}
+				data[i].Children = children
+			}
+		case "replace":
+			if i < 0 {
+				data = append(data, stripOperations(e))
+			} else {
+				data[i] = stripOperations(e)
+			}
+		case "create":
+			if i >= 0 {
+				return nil, &RPCError{Type: "application", Tag: "data-exists", Severity: "error",
+					Message: e.XMLName.Local + " already exists"}
+			}
+			data = append(data, stripOperations(e))
+		case "delete", "remove":
+			if i < 0 {
+				if op == "delete" {
+					return nil, &RPCError{Type: "application", Tag: "data-missing", Severity: "error",
+						Message: e.XMLName.Local + " does not exist"}
+				}
+				continue
+			}
+			data = append(data[:i], data[i+1:]...)
+		default:
+			return nil, &RPCError{Type: "protocol", Tag: "bad-attribute", Severity: "error",
+				Message: "invalid operation " + op}
+		}
+	}
+	return data, nil
+}
+
+// find returns the index of the node of data edit e refers to, or -1.
+// List entries are matched by their keys, leaves of a leaf-list by value.
+func (sim *Simulator) find(data []*Node, e *Node) int {
+	keys, ok := sim.scenario.ListKeys[e.XMLName.Local]
+	if !ok && e.Child("name") != nil && e.Child("name").IsLeaf() {
+		keys = []string{"name"}
+	}
+	first, count := -1, 0
+	for i, d := range data {
+		if d.XMLName.Local != e.XMLName.Local || (e.XMLName.Space != "" && d.XMLName.Space != e.XMLName.Space) {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		count++
+		switch {
+		case len(keys) > 0:
+			if sameKeyValues(d, e, keys) {
+				return i
+			}
+		case e.IsLeaf() && d.Value() == e.Value():
+			return i
+		}
+	}
+	if len(keys) > 0 || (e.IsLeaf() && count > 1) {
+		return -1
+	}
+	return first
+}
+
+func sameKeyValues(a, b *Node, keys []string) bool {
+	for _, k := range keys {
+		if childValue(a, k) != childValue(b, k) {
+			return false
+		}
+	}
+	return true
+}
+
+// stripOperations returns a copy of n without operation attributes.
+func stripOperations(n *Node) *Node {
+	c := n.Clone()
+	var strip func(n *Node)
+	strip = func(n *Node) {
+		n.RemoveAttr(BaseNamespace, "operation")
+		for _, child := range n.Children {
+			strip(child)
+		}
+	}
+	strip(c)
+	return c
+}
+
+// filteredData returns the <data> reply holding the nodes selected by the
+// subtree filter of op, if any.
+func filteredData(data []*Node, op *Node) ([]byte, error) {
+	if f := op.Child("filter"); f != nil {
+		if t, ok := f.Attr("", "type"); ok && t != "subtree" {
+			return nil, &RPCError{Type: "protocol", Tag: "operation-not-supported", Severity: "error",
+				Message: "filter type " + t + " is not supported"}
+		}
+		var selected []*Node
+		for _, d := range data {
+			for _, fn := range f.Children {
+				if n := filterSubtree(d, fn); n != nil {
+					selected = append(selected, n)
+				}
+			}
+		}
+		data = selected
+	}
+	return []byte("<data>" + nodesString(data) + "</data>"), nil
+}
+
+// filterSubtree applies the subtree filter f (RFC 6241, section 6) to n.
+// It returns the selected part of n, or nil.
+func filterSubtree(n, f *Node) *Node {
+	if n.XMLName.Local != f.XMLName.Local || (f.XMLName.Space != "" && n.XMLName.Space != f.XMLName.Space) {
+		return nil
+	}
+	if f.IsLeaf() {
+		if f.Value() != "" && n.Value() != f.Value() {
+			return nil
+		}
+		return n.Clone()
+	}
+
+	// Content match nodes select siblings only if all of them match.
+	var selectors []*Node
+	for _, fc := range f.Children {
+		if fc.IsLeaf() && fc.Value() != "" {
+			if !containsSubtree(n.Children, fc) {
+				return nil
+			}
+			continue
+		}
+		selectors = append(selectors, fc)
+	}
+	if len(selectors) == 0 {
+		return n.Clone()
+	}
+
+	out := &Node{XMLName: n.XMLName, Attrs: n.Attrs, Text: n.Text}
+	for _, c := range n.Children {
+		for _, fc := range f.Children {
+			if fc.IsLeaf() && fc.Value() != "" {
+				if containsSubtree([]*Node{c}, fc) {
+					out.Children = append(out.Children, c.Clone())
+					break
+				}
+				continue
+			}
+			if sel := filterSubtree(c, fc); sel != nil {
+				out.Children = append(out.Children, sel)
+				break
+			}
+		}
+	}
+	if len(out.Children) == 0 {
+		return nil
+	}
+	return out
+}
+
+func cloneNodes(nodes []*Node) []*Node {
+	if nodes == nil {
+		return nil
+	}
+	c := make([]*Node, len(nodes))
+	for i, n := range nodes {
+		c[i] = n.Clone()
+	}
+	return c
+}
+
+func nodesString(nodes []*Node) string {
+	var b bytes.Buffer
+	for _, n := range nodes {
+		b.WriteString(n.String())
+	}
+	return b.String()
+}
+
+func missingElement(name string) error {
+	return &RPCError{Type: "protocol", Tag: "missing-element", Severity: "error",
+		Message: "missing element " + name, Info: "<bad-element>" + EscapeText(name) + "</bad-element>"}
+}
+
+func invalidValue(msg string) error {
+	return &RPCError{Type: "protocol", Tag: "invalid-value", Severity: "error", Message: msg}
+}
@@ -0,0 +1,313 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testScenario = `
+name: r1
+running: |
+  <interfaces xmlns="urn:example:if">
+    <interface><name>ge-0/0/0</name><mtu>1500</mtu></interface>
+    <interface><name>ge-0/0/1</name><mtu>1500</mtu></interface>
+  </interfaces>
+state: |
+  <interfaces xmlns="urn:example:if">
+    <interface><name>ge-0/0/0</name><oper-status>up</oper-status></interface>
+  </interfaces>
+replies:
+  - operation: get-software-information
+    reply: <software-information><version>1.0</version></software-information>
+errors:
+  - operation: commit
+    after: 1
+    times: 1
+    tag: resource-denied
+    message: commit database busy
+notifications:
+  - on: commit
+    event: <config-change xmlns="urn:example:events"/>
+`
+
+func newTestSimulator(t *testing.T, scenario string) (*Simulator, string) {
+	sc, err := ParseScenario([]byte(scenario))
+	if err != nil {
+		t.Fatalf("ParseScenario failed: %v", err)
+	}
+	sim, err := NewSimulator(sc)
+	if err != nil {
+		t.Fatalf("NewSimulator failed: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go sim.Serve(l)
+	return sim, l.Addr().String()
+}
+
+func TestSimulatorWorkflow(t *testing.T) {
+	sim, addr := newTestSimulator(t, testScenario)
+	defer sim.Close()
+
+	listener, err := DialTCP(addr)
+	if err != nil {
+		t.Fatalf("DialTCP failed: %v", err)
+	}
+	defer listener.Close()
+	sub, err := listener.Subscribe(context.Background(), &SubscriptionOptions{})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	defer sub.Close()
+
+	s, err := DialTCP(addr)
+	if err != nil {
+		t.Fatalf("DialTCP failed: %v", err)
+	}
+	defer s.Close()
+	other, err := DialTCP(addr)
+	if err != nil {
+		t.Fatalf("DialTCP failed: %v", err)
+	}
+	defer other.Close()
+
+	edit := `<interfaces xmlns="urn:example:if"><interface><name>ge-0/0/1</name><mtu>9000</mtu></interface></interfaces>`
+	steps := []struct {
+		session *Session
+		method  RPCMethod
+		tag     string
+	}{
+		{session: s, method: MethodLock("candidate")},
+		{session: other, method: MethodLock("candidate"), tag: "lock-denied"},
+		{session: other, method: MethodEditConfig("candidate", edit), tag: "in-use"},
+		{session: s, method: MethodEditConfig("candidate", edit)},
+		{session: s, method: MethodCommit()},
+		{session: s, method: MethodCommit(), tag: "resource-denied"},
+		{session: s, method: MethodUnlock("candidate")},
+		{session: other, method: MethodUnlock("candidate"), tag: "operation-failed"},
+		{session: s, method: MethodGetConfig("startup"), tag: "invalid-value"},
+	}
+	for i, step := range steps {
+		_, err := step.session.Exec(step.method)
+		var tag string
+		if rpcErr, ok := err.(*RPCError); ok {
+			tag = rpcErr.Tag
+		} else if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if tag != step.tag {
+			t.Errorf("step %d: %s: got error %v, expected tag %q", i, step.method.MarshalMethod(), err, step.tag)
+		}
+	}
+
+	if running := sim.Datastore("running"); !strings.Contains(running, "<mtu>9000</mtu>") || strings.Count(running, "<interface>") != 2 {
+		t.Errorf("unexpected running datastore %s", running)
+	}
+	if n := sim.Calls("commit"); n != 2 {
+		t.Errorf("got %d commits, expected 2", n)
+	}
+
+	select {
+	case n := <-sub.C:
+		if !strings.Contains(string(n.Event), "config-change") {
+			t.Errorf("unexpected notification %s", n.Event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification received")
+	}
+
+	reply, err := s.Exec(MethodGetFilter(SubtreeFilter(`<interfaces xmlns="urn:example:if"><interface><name>ge-0/0/0</name></interface></interfaces>`)))
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if data := reply.Data.String(); !strings.Contains(data, "<oper-status>up</oper-status>") || strings.Contains(data, "ge-0/0/1") {
+		t.Errorf("unexpected get data %s", data)
+	}
+
+	reply, err = s.Exec(RawMethod("<get-software-information/>"))
+	if err != nil || !strings.Contains(reply.Data.String(), "<version>1.0</version>") {
+		t.Errorf("unexpected canned reply %v, error %v", reply, err)
+	}
+
+	_, err = s.Exec(RawMethod("<edit-config><target><candidate/></target></edit-config>"))
+	if rpcErr, ok := err.(*RPCError); !ok || !strings.Contains(rpcErr.Info, "<error-info><bad-element>config</bad-element></error-info>") {
+		t.Errorf("got %v, expected the missing config as bad-element", err)
+	}
+}
+
+func TestSimulatorReleasesLocks(t *testing.T) {
+	sim, addr := newTestSimulator(t, testScenario)
+	defer sim.Close()
+
+	s, err := DialTCP(addr)
+	if err != nil {
+		t.Fatalf("DialTCP failed: %v", err)
+	}
+	if _, err := s.Exec(MethodLock("running")); err != nil {
+		t.Fatalf("lock failed: %v", err)
+	}
+	s.Close()
+	waitForSessions(t, sim.Server, 0)
+
+	s, err = DialTCP(addr)
+	if err != nil {
+		t.Fatalf("DialTCP failed: %v", err)
+	}
+	defer s.Close()
+	if _, err := s.Exec(MethodLock("running")); err != nil {
+		t.Errorf("lock after the holder ended failed: %v", err)
+	}
+}
+
+func TestSimulatorMerge(t *testing.T) {
+	const running = `<system xmlns="urn:example:sys"><hostname>r1</hostname><dns><server>10.0.0.1</server><server>10.0.0.2</server></dns>` +
+		`<user><name>alice</name><class>admin</class></user></system>`
+	tt := []struct {
+		name     string
+		edit     string
+		defOp    string
+		expected string
+		tag      string
+	}{
+		{
+			name:     "merge leaf and list entry",
+			edit:     `<system xmlns="urn:example:sys"><hostname>r2</hostname><user><name>bob</name><class>ops</class></user></system>`,
+			expected: `<system xmlns="urn:example:sys"><hostname>r2</hostname><dns><server>10.0.0.1</server><server>10.0.0.2</server></dns><user><name>alice</name><class>admin</class></user><user><name>bob</name><class>ops</class></user></system>`,
+		},
+		{
+			name:     "delete leaf-list entry",
+			edit:     `<system xmlns="urn:example:sys" xmlns:nc="urn:ietf:params:xml:ns:netconf:base:1.0"><dns><server nc:operation="delete">10.0.0.1</server></dns></system>`,
+			expected: `<system xmlns="urn:example:sys"><hostname>r1</hostname><dns><server>10.0.0.2</server></dns><user><name>alice</name><class>admin</class></user></system>`,
+		},
+		{
+			name:     "replace list entry",
+			edit:     `<system xmlns="urn:example:sys" xmlns:nc="urn:ietf:params:xml:ns:netconf:base:1.0"><user nc:operation="replace"><name>alice</name></user></system>`,
+			expected: `<system xmlns="urn:example:sys"><hostname>r1</hostname><dns><server>10.0.0.1</server><server>10.0.0.2</server></dns><user><name>alice</name></user></system>`,
+		},
+		{
+			name: "create existing",
+			edit: `<system xmlns="urn:example:sys" xmlns:nc="urn:ietf:params:xml:ns:netconf:base:1.0"><hostname nc:operation="create">r3</hostname></system>`,
+			tag:  "data-exists",
+		},
+		{
+			name: "delete missing",
+			edit: `<system xmlns="urn:example:sys" xmlns:nc="urn:ietf:params:xml:ns:netconf:base:1.0"><user nc:operation="delete"><name>eve</name></user></system>`,
+			tag:  "data-missing",
+		},
+		{
+			name:     "remove missing",
+			edit:     `<system xmlns="urn:example:sys" xmlns:nc="urn:ietf:params:xml:ns:netconf:base:1.0"><user nc:operation="remove"><name>eve</name></user></system>`,
+			expected: running,
+		},
+		{
+			name:     "none skips absent leaves",
+			edit:     `<system xmlns="urn:example:sys"><location>lab</location></system>`,
+			defOp:    "none",
+			expected: running,
+		},
+	}
+	sim := &Simulator{scenario: &Scenario{}}
+	for _, tc := range tt {
+		data, _ := ParseNodes([]byte(running))
+		edits, _ := ParseNodes([]byte(tc.edit))
+		defOp := tc.defOp
+		if defOp == "" {
+			defOp = "merge"
+		}
+		data, err := sim.merge(data, edits, defOp)
+		var tag string
+		if rpcErr, ok := err.(*RPCError); ok {
+			tag = rpcErr.Tag
+		}
+		if tag != tc.tag {
+			t.Errorf("%s: got error %v, expected tag %q", tc.name, err, tc.tag)
+			continue
+		}
+		if got := nodesString(data); err == nil && got != tc.expected {
+			t.Errorf("%s: got\n%s\nexpected\n%s", tc.name, got, tc.expected)
+		}
+	}
+}
+
+func TestFilterSubtree(t *testing.T) {
+	const data = `<interfaces xmlns="urn:example:if"><interface><name>a</name><mtu>1500</mtu><enabled>true</enabled></interface>` +
+		`<interface><name>b</name><mtu>9000</mtu><enabled>false</enabled></interface></interfaces>`
+	tt := []struct {
+		filter   string
+		expected string
+	}{
+		{`<interfaces/>`, data},
+		{`<interfaces xmlns="urn:example:other"/>`, ""},
+		{`<interfaces><interface><name>b</name></interface></interfaces>`,
+			`<interfaces xmlns="urn:example:if"><interface><name>b</name><mtu>9000</mtu><enabled>false</enabled></interface></interfaces>`},
+		{`<interfaces><interface><name>a</name><mtu/></interface></interfaces>`,
+			`<interfaces xmlns="urn:example:if"><interface><name>a</name><mtu>1500</mtu></interface></interfaces>`},
+		{`<interfaces><interface><name/><enabled>false</enabled></interface></interfaces>`,
+			`<interfaces xmlns="urn:example:if"><interface><name>b</name><enabled>false</enabled></interface></interfaces>`},
+		{`<interfaces><interface><name>c</name></interface></interfaces>`, ""},
+	}
+	for _, tc := range tt {
+		n, err := ParseNode([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := ParseNode([]byte(tc.filter))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		if sel := filterSubtree(n, f); sel != nil {
+			got = sel.String()
+		}
+		if got != tc.expected {
+			t.Errorf("%s: got\n%s\nexpected\n%s", tc.filter, got, tc.expected)
+		}
+	}
+}
+
+func TestParseScenarioErrors(t *testing.T) {
+	tt := []string{
+		"running: <system>",
+		"errors: [{tag: in-use}]",
+		"errors: [{operation: commit, after: -1}]",
+		"replies: [{reply: <ok/>}]",
+		"notifications: [{on: commit}]",
+		"unknown: field",
+	}
+	for _, tc := range tt {
+		if _, err := ParseScenario([]byte(tc)); err == nil {
+			t.Errorf("%q: expected an error", tc)
+		}
+	}
+}
+
+func TestSimulatorCapabilities(t *testing.T) {
+	sim, addr := newTestSimulator(t, "capabilities: [urn:ietf:params:netconf:capability:candidate:1.0]")
+	defer sim.Close()
+
+	s, err := DialTCP(addr)
+	if err != nil {
+		t.Fatalf("DialTCP failed: %v", err)
+	}
+	defer s.Close()
+
+	var rpcErr *RPCError
+	if _, err := s.Exec(MethodEditConfig("running", "<system/>")); !errors.As(err, &rpcErr) || rpcErr.Tag != "operation-not-supported" {
+		t.Errorf("expected running to be read-only, got %v", err)
+	}
+	if _, err := s.Subscribe(context.Background(), &SubscriptionOptions{}); err == nil {
+		t.Error("expected Subscribe to fail without the notification capability")
+	}
+}
@@ -0,0 +1,134 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// NotificationSink receives notifications, e.g. to publish them on a
+// message bus.
+type NotificationSink interface {
+	Publish(ctx context.Context, n *Notification) error
+}
+
+// NotificationSinkFunc adapts a function to a NotificationSink.
+type NotificationSinkFunc func(ctx context.Context, n *Notification) error
+
+// Publish calls f.
+func (f NotificationSinkFunc) Publish(ctx context.Context, n *Notification) error {
+	return f(ctx, n)
+}
+
+// MultiSink returns a sink publishing every notification to all sinks.  All
+// sinks are tried; the first error is returned.
+func MultiSink(sinks ...NotificationSink) NotificationSink {
+	return NotificationSinkFunc(func(ctx context.Context, n *Notification) error {
+		var first error
+		for _, s := range sinks {
+			if err := s.Publish(ctx, n); err != nil && first == nil {
+				first = err
+			}
+		}
+		return first
+	})
+}
+
+// Forward publishes the notifications received from c, such as the channel
+// of a Subscription, to sink until c is closed or ctx is done.  Errors of
+// the sink are passed to onError if it is not nil and do not stop
+// forwarding.
+func Forward(ctx context.Context, c <-chan *Notification, sink NotificationSink, onError func(*Notification, error)) error {
+	for {
+		select {
+		case n, ok := <-c:
+			if !ok {
+				return nil
+			}
+			if err := sink.Publish(ctx, n); err != nil && onError != nil {
+				onError(n, err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// jsonNotification is the JSON encoding of notifications used by the
+// webhook and bus adapters.
+type jsonNotification struct {
+	Device    string    `json:"device,omitempty"`
+	EventTime time.Time `json:"eventTime"`
+	Event     string    `json:"event"`
+}
+
+func marshalNotification(device string, n *Notification) ([]byte, error) {
+	return json.Marshal(jsonNotification{Device: device, EventTime: n.EventTime, Event: n.Event.String()})
+}
+
+// KafkaProducer is the minimal interface of a Kafka client needed by
+// KafkaSink.  Clients such as sarama or kafka-go are adapted with a few
+// lines.
+type KafkaProducer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) error
+}
+
+// KafkaSink publishes notifications as JSON to a Kafka topic, keyed by
+// device so that the events of a device stay in order.
+type KafkaSink struct {
+	Producer KafkaProducer
+	Topic    string
+	Device   string
+}
+
+// NewKafkaSink returns a sink publishing the notifications of device to
+// topic.
+func NewKafkaSink(p KafkaProducer, topic, device string) *KafkaSink {
+	return &KafkaSink{Producer: p, Topic: topic, Device: device}
+}
+
+// Publish implements NotificationSink.
+func (k *KafkaSink) Publish(ctx context.Context, n *Notification) error {
+	value, err := marshalNotification(k.Device, n)
+	if err != nil {
+		return err
+	}
+	return k.Producer.Produce(ctx, k.Topic, []byte(k.Device), value)
+}
+
+// NATSPublisher is the minimal interface of a NATS connection needed by
+// NATSSink.  It is implemented by *nats.Conn.
+type NATSPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSSink publishes notifications as JSON to a NATS subject.
+type NATSSink struct {
+	Conn    NATSPublisher
+	Subject string
+	Device  string
+}
+
+// NewNATSSink returns a sink publishing the notifications of device to
+// subject.
+func NewNATSSink(conn NATSPublisher, subject, device string) *NATSSink {
+	return &NATSSink{Conn: conn, Subject: subject, Device: device}
+}
+
+// Publish implements NotificationSink.
+func (s *NATSSink) Publish(ctx context.Context, n *Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := marshalNotification(s.Device, n)
+	if err != nil {
+		return err
+	}
+	return s.Conn.Publish(s.Subject, data)
+}
@@ -0,0 +1,85 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testKafka struct {
+	topics, keys, values []string
+}
+
+func (k *testKafka) Produce(ctx context.Context, topic string, key, value []byte) error {
+	k.topics = append(k.topics, topic)
+	k.keys = append(k.keys, string(key))
+	k.values = append(k.values, string(value))
+	return nil
+}
+
+type testNATS struct {
+	subjects, data []string
+}
+
+func (n *testNATS) Publish(subject string, data []byte) error {
+	n.subjects = append(n.subjects, subject)
+	n.data = append(n.data, string(data))
+	return nil
+}
+
+func TestNotificationSinks(t *testing.T) {
+	kafka := &testKafka{}
+	nats := &testNATS{}
+	failing := NotificationSinkFunc(func(context.Context, *Notification) error {
+		return errors.New("bus down")
+	})
+
+	c := make(chan *Notification, 2)
+	for i := 1; i <= 2; i++ {
+		n, err := ParseNotification([]byte(testNotification(i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c <- n
+	}
+	close(c)
+
+	var errs int
+	sink := MultiSink(NewKafkaSink(kafka, "events", "r1"), failing, NewNATSSink(nats, "netconf.r1", "r1"))
+	err := Forward(context.Background(), c, sink, func(n *Notification, err error) {
+		errs++
+	})
+	if err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	if errs != 2 {
+		t.Errorf("expected 2 errors, got %d", errs)
+	}
+
+	if diff := cmp.Diff([]string{"events", "events"}, kafka.topics); diff != "" {
+		t.Errorf("topics mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"r1", "r1"}, kafka.keys); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(kafka.values, nats.data); diff != "" {
+		t.Errorf("expected the same payloads on both buses (-kafka +nats):\n%s", diff)
+	}
+
+	var got jsonNotification
+	if err := json.Unmarshal([]byte(nats.data[1]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Device != "r1" || got.Event != `<event xmlns="urn:x"><seq>2</seq></event>` {
+		t.Errorf("unexpected payload %+v", got)
+	}
+}
@@ -0,0 +1,245 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// snapshotTimeFormat is used to name snapshots.  It sorts lexically in
+// chronological order.
+const snapshotTimeFormat = "20060102T150405.000000000Z"
+
+// Snapshot is a configuration retrieved from a device at a point in time.
+type Snapshot struct {
+	Device string
+	Time   time.Time
+	Config []byte
+}
+
+// Hash returns the hex encoded SHA-256 of the snapshot's configuration.
+func (s *Snapshot) Hash() string {
+	sum := sha256.Sum256(s.Config)
+	return hex.EncodeToString(sum[:])
+}
+
+// SnapshotStore persists configuration snapshots.
+type SnapshotStore interface {
+	// Latest returns the most recent snapshot of the device, or nil if the
+	// store holds no snapshot for it.
+	Latest(ctx context.Context, device string) (*Snapshot, error)
+	// Put stores a new snapshot.
+	Put(ctx context.Context, s *Snapshot) error
+}
+
+// ObjectStore is the minimal interface of an S3 style blob store needed to
+// keep snapshots.
+type ObjectStore interface {
+	PutObject(ctx context.Context, key string, data []byte) error
+	GetObject(ctx context.Context, key string) ([]byte, error)
+	// ListObjects returns the keys starting with prefix.
+	ListObjects(ctx context.Context, prefix string) ([]string, error)
+}
+
+// NewObjectSnapshotStore returns a SnapshotStore keeping snapshots in an
+// ObjectStore under keys of the form <device>/<timestamp>.xml.
+func NewObjectSnapshotStore(objects ObjectStore) SnapshotStore {
+	return &objectSnapshotStore{objects}
+}
+
+// NewDirSnapshotStore returns a SnapshotStore keeping snapshots as files
+// below dir, one sub-directory per device.
+func NewDirSnapshotStore(dir string) SnapshotStore {
+	return &objectSnapshotStore{dirObjectStore(dir)}
+}
+
+type objectSnapshotStore struct {
+	objects ObjectStore
+}
+
+// snapshotPrefix returns the key prefix of the snapshots of device, a single
+// path segment.  PathEscape leaves "." and ".." alone, so their dots are
+// encoded to keep the snapshots within the store.
+func snapshotPrefix(device string) string {
+	segment := url.PathEscape(device)
+	if segment == "." || segment == ".." {
+		segment = strings.Replace(segment, ".", "%2E", -1)
+	}
+	return segment + "/"
+}
+
+func (st *objectSnapshotStore) Latest(ctx context.Context, device string) (*Snapshot, error) {
+	prefix := snapshotPrefix(device)
+	keys, err := st.objects.ListObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest string
+	for _, k := range keys {
+		if strings.HasSuffix(k, ".xml") && k > latest {
+			latest = k
+		}
+	}
+	if latest == "" {
+		return nil, nil
+	}
+
+	ts, err := time.Parse(snapshotTimeFormat, strings.TrimSuffix(strings.TrimPrefix(latest, prefix), ".xml"))
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s: %v", latest, err)
+	}
+	data, err := st.objects.GetObject(ctx, latest)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Device: device, Time: ts, Config: data}, nil
+}
+
+func (st *objectSnapshotStore) Put(ctx context.Context, s *Snapshot) error {
+	key := snapshotPrefix(s.Device) + s.Time.UTC().Format(snapshotTimeFormat) + ".xml"
+	return st.objects.PutObject(ctx, key, s.Config)
+}
+
+// dirObjectStore is an ObjectStore backed by a local directory.
+type dirObjectStore string
+
+func (d dirObjectStore) PutObject(ctx context.Context, key string, data []byte) error {
+	path := filepath.Join(string(d), filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see partial snapshots.
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (d dirObjectStore) GetObject(ctx context.Context, key string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(string(d), filepath.FromSlash(key)))
+}
+
+func (d dirObjectStore) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	dir, base := filepath.Split(filepath.Join(string(d), filepath.FromSlash(prefix)))
+	if strings.HasSuffix(prefix, "/") {
+		dir, base = filepath.Join(string(d), filepath.FromSlash(prefix)), ""
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rel, err := filepath.Rel(string(d), dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), base) {
+			continue
+		}
+		keys = append(keys, filepath.ToSlash(filepath.Join(rel, e.Name())))
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Collector periodically retrieves the configuration of a fleet of devices
+// and archives it in a SnapshotStore.  A snapshot is only written when the
+// configuration differs from the latest stored one.
+type Collector struct {
+	Fleet *Fleet
+	Store SnapshotStore
+	// Source is the datastore to retrieve, "running" if empty.
+	Source string
+	// Interval between collection runs.
+	Interval time.Duration
+	// OnSnapshot, if set, is called for each retrieved snapshot along with
+	// whether it was stored.
+	OnSnapshot func(s *Snapshot, stored bool)
+}
+
+// Collect runs a single collection pass over the fleet.
+func (c *Collector) Collect(ctx context.Context) *Report {
+	source := c.Source
+	if source == "" {
+		source = "running"
+	}
+
+	return c.Fleet.Run(ctx, func(ctx context.Context, s *Session, r *DeviceResult) error {
+		reply, err := s.ExecContext(ctx, MethodGetConfig(source))
+		if err != nil {
+			return err
+		}
+
+		snap := &Snapshot{
+			Device: r.Device,
+			Time:   time.Now(),
+			Config: bytes.TrimSpace(reply.Data),
+		}
+
+		prev, err := c.Store.Latest(ctx, r.Device)
+		if err != nil {
+			return err
+		}
+
+		stored := false
+		if prev == nil || !bytes.Equal(prev.Config, snap.Config) {
+			if err := c.Store.Put(ctx, snap); err != nil {
+				return err
+			}
+			stored = true
+		}
+
+		if c.OnSnapshot != nil {
+			c.OnSnapshot(snap, stored)
+		}
+		return nil
+	})
+}
+
+// Run collects snapshots every Interval until ctx is cancelled.  The report
+// of each pass is passed to fn if it is not nil.
+func (c *Collector) Run(ctx context.Context, fn func(*Report)) error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("collector interval must be positive")
+	}
+
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		report := c.Collect(ctx)
+		if fn != nil {
+			fn(report)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDirSnapshotStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "netconf-snapshots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	store := NewDirSnapshotStore(dir)
+
+	if snap, err := store.Latest(ctx, "core1"); err != nil || snap != nil {
+		t.Fatalf("expected no snapshot, got %v, %v", snap, err)
+	}
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, cfg := range []string{"<a/>", "<b/>"} {
+		snap := &Snapshot{Device: "core1", Time: now.Add(time.Duration(i) * time.Hour), Config: []byte(cfg)}
+		if err := store.Put(ctx, snap); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	latest, err := store.Latest(ctx, "core1")
+	if err != nil {
+		t.Fatalf("Latest failed: %v", err)
+	}
+	if string(latest.Config) != "<b/>" || !latest.Time.Equal(now.Add(time.Hour)) {
+		t.Errorf("unexpected latest snapshot: %+v", latest)
+	}
+}
+
+func TestSnapshotPrefix(t *testing.T) {
+	tt := []struct {
+		device   string
+		expected string
+	}{
+		{"core1", "core1/"},
+		{"core/1", "core%2F1/"},
+		{".", "%2E/"},
+		{"..", "%2E%2E/"},
+		{"...", ".../"},
+	}
+	for _, tc := range tt {
+		if got := snapshotPrefix(tc.device); got != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.device, got, tc.expected)
+		}
+	}
+}
+
+func TestCollectorDedup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "netconf-snapshots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	reply := `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><data><system/></data></rpc-reply>]]>]]>`
+	fleet := &Fleet{
+		Devices: []*Device{{Name: "core1", Address: "core1"}},
+		Dial: func(ctx context.Context, d *Device) (*Session, error) {
+			trans, _ := newTransportTest(reply)
+			return &Session{Transport: trans}, nil
+		},
+	}
+
+	var stored []bool
+	c := &Collector{
+		Fleet: fleet,
+		Store: NewDirSnapshotStore(dir),
+		OnSnapshot: func(s *Snapshot, ok bool) {
+			stored = append(stored, ok)
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		if report := c.Collect(context.Background()); !report.OK() {
+			t.Fatalf("collection failed: %+v", report.Results[0])
+		}
+	}
+
+	if len(stored) != 2 || !stored[0] || stored[1] {
+		t.Errorf("expected only the first snapshot to be stored, got %v", stored)
+	}
+}
@@ -0,0 +1,94 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// SocketOptions configures the TCP connections of sessions.
+type SocketOptions struct {
+	// SourceAddress, if set, is the local IP address connections are made
+	// from, e.g. that of a loopback interface.
+	SourceAddress string
+	// KeepAlive is the interval of TCP keepalive probes.  Zero uses the
+	// default of the net package, a negative value disables keepalives.
+	KeepAlive time.Duration
+	// UserTimeout, if set, is the time transmitted data may remain
+	// unacknowledged before the connection is closed (TCP_USER_TIMEOUT,
+	// RFC 5482).  It is supported on Linux only.
+	UserTimeout time.Duration
+	// DSCP, if set, marks the packets with this differentiated services
+	// code point (0 to 63), in the TOS byte of IPv4 and the traffic class
+	// of IPv6.  It is not supported on Windows.
+	DSCP int
+}
+
+// WithSocketOptions opens the TCP connections of NETCONF over SSH and TLS
+// with o applied.  It is overridden by WithDialFunc and WithDialer.
+func WithSocketOptions(o SocketOptions) Option {
+	return func(c *SessionConfig) { c.SocketOptions = &o }
+}
+
+// Dialer returns a dialer applying the options.
+func (o *SocketOptions) Dialer() (*net.Dialer, error) {
+	if o.DSCP < 0 || o.DSCP > 63 {
+		return nil, fmt.Errorf("netconf: invalid DSCP %d", o.DSCP)
+	}
+	d := &net.Dialer{KeepAlive: o.KeepAlive}
+	if o.SourceAddress != "" {
+		host := strings.TrimSuffix(strings.TrimPrefix(o.SourceAddress, "["), "]")
+		ip, err := net.ResolveIPAddr("ip", host)
+		if err != nil || ip.IP == nil {
+			return nil, fmt.Errorf("netconf: invalid source address %q", o.SourceAddress)
+		}
+		d.LocalAddr = &net.TCPAddr{IP: ip.IP, Zone: ip.Zone}
+	}
+	if o.UserTimeout > 0 || o.DSCP > 0 {
+		d.Control = o.control
+	}
+	return d, nil
+}
+
+// control sets the socket options of a connection before it connects.
+func (o *SocketOptions) control(network, address string, c syscall.RawConn) error {
+	var err error
+	cerr := c.Control(func(fd uintptr) {
+		if o.DSCP > 0 {
+			if err = setTrafficClass(fd, strings.HasSuffix(network, "6"), o.DSCP<<2); err != nil {
+				err = fmt.Errorf("netconf: set DSCP of %s: %v", address, err)
+				return
+			}
+		}
+		if o.UserTimeout > 0 {
+			if err = setUserTimeout(fd, o.UserTimeout); err != nil {
+				err = fmt.Errorf("netconf: set user timeout of %s: %v", address, err)
+			}
+		}
+	})
+	if cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// socketDialFunc returns the dial function applying o, which fails if the
+// options are invalid.
+func socketDialFunc(o *SocketOptions) DialFunc {
+	d, err := o.Dialer()
+	if err != nil {
+		return func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, err
+		}
+	}
+	return d.DialContext
+}
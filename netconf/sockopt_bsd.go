@@ -0,0 +1,19 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+// +build darwin dragonfly freebsd netbsd openbsd
+
+package netconf
+
+import (
+	"errors"
+	"time"
+)
+
+func setUserTimeout(fd uintptr, d time.Duration) error {
+	return errors.New("TCP user timeout not supported")
+}
@@ -0,0 +1,46 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSocketOptionsLinux(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	o := SocketOptions{SourceAddress: "127.0.0.1", DSCP: 46, UserTimeout: 1500 * time.Millisecond}
+	d, err := o.Dialer()
+	if err != nil {
+		t.Fatalf("Dialer failed: %v", err)
+	}
+	conn, err := d.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	raw, err := conn.(*net.TCPConn).SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tos, timeout int
+	raw.Control(func(fd uintptr) {
+		tos, _ = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TOS)
+		timeout, _ = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_TCP, tcpUserTimeout)
+	})
+	if tos != 46<<2 || timeout != 1500 {
+		t.Errorf("got TOS %#x, user timeout %d, expected %#x, 1500", tos, timeout, 46<<2)
+	}
+}
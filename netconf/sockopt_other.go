@@ -0,0 +1,23 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package netconf
+
+import (
+	"errors"
+	"time"
+)
+
+func setTrafficClass(fd uintptr, ipv6 bool, tos int) error {
+	return errors.New("DSCP marking not supported")
+}
+
+func setUserTimeout(fd uintptr, d time.Duration) error {
+	return errors.New("TCP user timeout not supported")
+}
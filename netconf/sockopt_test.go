@@ -0,0 +1,50 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"testing"
+)
+
+func TestSocketOptionsDialer(t *testing.T) {
+	tt := []struct {
+		opts SocketOptions
+		err  bool
+	}{
+		{opts: SocketOptions{SourceAddress: "127.0.0.1", KeepAlive: -1}},
+		{opts: SocketOptions{SourceAddress: "[::1]"}},
+		{opts: SocketOptions{SourceAddress: "fe80::1%lo"}},
+		{opts: SocketOptions{SourceAddress: "not an address"}, err: true},
+		{opts: SocketOptions{DSCP: 64}, err: true},
+	}
+	for _, tc := range tt {
+		d, err := tc.opts.Dialer()
+		if tc.err != (err != nil) {
+			t.Errorf("%+v: got error %v, expected error %v", tc.opts, err, tc.err)
+			continue
+		}
+		if err == nil && tc.opts.KeepAlive != d.KeepAlive {
+			t.Errorf("%+v: keepalive not applied", tc.opts)
+		}
+	}
+}
+
+func TestWithSocketOptions(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+
+	s, err := Dial(srv.Addr(), WithSSHConfig(testSSHConfig()), WithSocketOptions(SocketOptions{SourceAddress: "127.0.0.1"}))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	s.Close()
+
+	_, err = Dial(srv.Addr(), WithSSHConfig(testSSHConfig()), WithSocketOptions(SocketOptions{DSCP: 99}))
+	if err == nil {
+		t.Error("expected an error for an invalid DSCP")
+	}
+}
@@ -0,0 +1,313 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// notificationNamespace is the namespace of <notification> and
+// <create-subscription> (RFC 5277).
+const notificationNamespace = "urn:ietf:params:xml:ns:netconf:notification:1.0"
+
+// Notification is an event notification received from the server.
+type Notification struct {
+	EventTime time.Time
+	// Event holds the XML of the event, the content of the <notification>
+	// element without <eventTime>.
+	Event RawXML
+	// Raw holds the whole <notification> message.
+	Raw RawXML
+}
+
+// ParseNotification parses a <notification> message (RFC 5277), such as one
+// sent on a session with a subscription.  The eventTime must be a valid
+// RFC 3339 timestamp.
+func ParseNotification(data []byte) (*Notification, error) {
+	var n struct {
+		XMLName   xml.Name `xml:"notification"`
+		EventTime string   `xml:"eventTime"`
+	}
+	if err := xml.Unmarshal(data, &n); err != nil {
+		return nil, err
+	}
+	eventTime, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(n.EventTime))
+	if err != nil {
+		return nil, fmt.Errorf("netconf: invalid notification eventTime %q", n.EventTime)
+	}
+
+	event := innerXML(data)
+	if i := bytes.Index(event, []byte("</eventTime>")); i >= 0 {
+		event = event[i+len("</eventTime>"):]
+	}
+	return &Notification{EventTime: eventTime, Event: RawXML(bytes.TrimSpace(event)), Raw: data}, nil
+}
+
+// MethodCreateSubscription files a NETCONF create-subscription request
+// (RFC 5277) with the remote host.  An empty stream selects the default
+// NETCONF stream; filter, startTime and stopTime are optional.
+func MethodCreateSubscription(stream string, filter *Filter, startTime, stopTime time.Time) RawMethod {
+	var b strings.Builder
+	b.WriteString(`<create-subscription xmlns="` + notificationNamespace + `">`)
+	if stream != "" {
+		b.WriteString("<stream>" + EscapeText(stream) + "</stream>")
+	}
+	if filter != nil {
+		b.WriteString(filter.String())
+	}
+	if !startTime.IsZero() {
+		b.WriteString("<startTime>" + startTime.Format(time.RFC3339Nano) + "</startTime>")
+	}
+	if !stopTime.IsZero() {
+		b.WriteString("<stopTime>" + stopTime.Format(time.RFC3339Nano) + "</stopTime>")
+	}
+	b.WriteString("</create-subscription>")
+	return RawMethod(b.String())
+}
+
+// OverflowPolicy selects what happens to notifications arriving while the
+// buffer of a subscription is full.
+type OverflowPolicy int
+
+// Overflow policies.
+const (
+	// OverflowDropOldest discards the oldest buffered notification to make
+	// room for the new one.
+	OverflowDropOldest OverflowPolicy = iota
+	// OverflowDropNewest discards the notification that just arrived.
+	OverflowDropNewest
+	// OverflowBlock stops reading from the server until there is room, for
+	// at most BlockTimeout, after which the notification is discarded.
+	OverflowBlock
+)
+
+// DefaultSubscriptionBuffer is the number of notifications buffered if
+// SubscriptionOptions.BufferSize is not set.
+const DefaultSubscriptionBuffer = 64
+
+// SubscriptionOptions tunes Subscribe.
+type SubscriptionOptions struct {
+	// Stream is the event stream, the default NETCONF stream if empty.
+	Stream string
+	Filter *Filter
+	// StartTime, if set, replays events since then.
+	StartTime time.Time
+	// StopTime, if set, ends the subscription at that time.
+	StopTime time.Time
+
+	// BufferSize is the number of notifications buffered for a slow
+	// consumer, DefaultSubscriptionBuffer if zero.
+	BufferSize int
+	// Overflow selects what happens when the buffer is full.
+	Overflow OverflowPolicy
+	// BlockTimeout bounds the wait of OverflowBlock, zero waits forever.
+	BlockTimeout time.Duration
+}
+
+// Subscription delivers the notifications of an event stream.  The session
+// it was created on receives nothing but notifications and must not be used
+// for other RPCs.
+type Subscription struct {
+	// C delivers the notifications.  It is closed when the subscription
+	// ends.
+	C <-chan *Notification
+
+	c       chan *Notification
+	session *Session
+	opts    SubscriptionOptions
+	dropped uint64
+
+	mu     sync.Mutex
+	err    error
+	closed bool
+	// done is closed by Close, ending a delivery blocked on a full buffer.
+	done chan struct{}
+}
+
+// Subscribe creates a subscription on s.  opts may be nil.
+func (s *Session) Subscribe(ctx context.Context, opts *SubscriptionOptions) (*Subscription, error) {
+	if opts == nil {
+		opts = &SubscriptionOptions{}
+	}
+	if err := s.requireCapability("create-subscription", CapabilityNotification); err != nil {
+		return nil, err
+	}
+	method := MethodCreateSubscription(opts.Stream, opts.Filter, opts.StartTime, opts.StopTime)
+	if _, err := s.ExecContext(ctx, method); err != nil {
+		return nil, err
+	}
+
+	return newSubscription(s, opts), nil
+}
+
+// newSubscription starts delivering the notifications received on s, for
+// a subscription already established.
+func newSubscription(s *Session, opts *SubscriptionOptions) *Subscription {
+	size := opts.BufferSize
+	if size <= 0 {
+		size = DefaultSubscriptionBuffer
+	}
+	sub := &Subscription{c: make(chan *Notification, size), session: s, opts: *opts, done: make(chan struct{})}
+	sub.C = sub.c
+	go sub.run()
+	return sub
+}
+
+// Dropped returns the number of notifications discarded because the
+// buffer was full.
+func (sub *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&sub.dropped)
+}
+
+// Err returns the error that ended the subscription, or nil if it is still
+// running or was closed.
+func (sub *Subscription) Err() error {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	return sub.err
+}
+
+// Close ends the subscription and closes its session.
+func (sub *Subscription) Close() error {
+	sub.mu.Lock()
+	if !sub.closed {
+		sub.closed = true
+		close(sub.done)
+	}
+	sub.mu.Unlock()
+	return sub.session.Close()
+}
+
+func (sub *Subscription) run() {
+	defer close(sub.c)
+	for {
+		data, err := sub.session.Transport.Receive()
+		if err != nil {
+			sub.mu.Lock()
+			if !sub.closed {
+				sub.err = transportError("read", false, err)
+			}
+			sub.mu.Unlock()
+			return
+		}
+
+		data = stripDeclarations(data)
+		if !isNotification(data) {
+			// Stray replies, e.g. to a keepalive, are of no interest.
+			continue
+		}
+		n, err := ParseNotification(data)
+		if err != nil {
+			continue
+		}
+		if isSubscriptionEnd(n) {
+			return
+		}
+		sub.deliver(n)
+	}
+}
+
+// deliver passes n to the consumer, applying the overflow policy if the
+// buffer is full.  A blocked delivery ends when the subscription is closed.
+func (sub *Subscription) deliver(n *Notification) {
+	select {
+	case sub.c <- n:
+		return
+	default:
+	}
+
+	switch sub.opts.Overflow {
+	case OverflowDropNewest:
+		atomic.AddUint64(&sub.dropped, 1)
+	case OverflowBlock:
+		var timeout <-chan time.Time
+		if sub.opts.BlockTimeout > 0 {
+			timer := time.NewTimer(sub.opts.BlockTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case sub.c <- n:
+		case <-timeout:
+			atomic.AddUint64(&sub.dropped, 1)
+		case <-sub.done:
+		}
+	default:
+		for {
+			select {
+			case sub.c <- n:
+				return
+			default:
+			}
+			select {
+			case <-sub.c:
+				atomic.AddUint64(&sub.dropped, 1)
+			default:
+			}
+		}
+	}
+}
+
+// isNotification reports whether the message is a <notification>.
+func isNotification(data []byte) bool {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			return false
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local == "notification"
+		}
+	}
+}
+
+// isSubscriptionEnd reports whether n is the notificationComplete event
+// sent once a subscription with a stop time ended.
+func isSubscriptionEnd(n *Notification) bool {
+	return isStreamEvent(n, "notificationComplete")
+}
+
+// isStreamEvent reports whether the event of n is the RFC 5277 event local,
+// e.g. replayComplete.  Devices qualify these events with the namespace of
+// the notification module or leave them in the one of <notification>.
+func isStreamEvent(n *Notification, local string) bool {
+	if !bytes.Contains(n.Event, []byte(local)) {
+		return false
+	}
+	name := eventName(n)
+	return name.Local == local && (name.Space == NotificationStreamsNamespace || name.Space == notificationNamespace)
+}
+
+// eventName returns the name of the event element of n, resolving the
+// namespace inherited from <notification>.
+func eventName(n *Notification) xml.Name {
+	if len(n.Raw) == 0 {
+		roots, err := ParseNodes(n.Event)
+		if err != nil || len(roots) == 0 {
+			return xml.Name{}
+		}
+		return roots[0].XMLName
+	}
+	root, err := ParseNode(n.Raw)
+	if err != nil {
+		return xml.Name{}
+	}
+	for _, c := range root.Children {
+		if c.XMLName.Local != "eventTime" {
+			return c.XMLName
+		}
+	}
+	return xml.Name{}
+}
@@ -0,0 +1,176 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testNotification(i int) string {
+	return fmt.Sprintf(`<notification xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0"><eventTime>2020-01-02T03:04:0%dZ</eventTime><event xmlns="urn:x"><seq>%d</seq></event></notification>`, i, i)
+}
+
+func TestParseNotificationEvent(t *testing.T) {
+	n, err := ParseNotification([]byte(testNotification(5)))
+	if err != nil {
+		t.Fatalf("ParseNotification failed: %v", err)
+	}
+	if !n.EventTime.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("unexpected event time %s", n.EventTime)
+	}
+	if got, want := n.Event.String(), `<event xmlns="urn:x"><seq>5</seq></event>`; got != want {
+		t.Errorf("unexpected event: (want %q, got %q)", want, got)
+	}
+
+	for _, data := range []string{
+		`<notification><eventTime>yesterday</eventTime></notification>`,
+		`<notification xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0"><event/></notification>`,
+		`<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><ok/></rpc-reply>`,
+		`<notification><eventTime>2020-01-02T03:04:05Z</eventTime>`,
+	} {
+		if _, err := ParseNotification([]byte(data)); err == nil {
+			t.Errorf("expected error for %s", data)
+		}
+	}
+
+	data := "<notification xmlns=\"urn:ietf:params:xml:ns:netconf:notification:1.0\">\n" +
+		"  <eventTime> 2020-01-02T03:04:05.25+01:00 </eventTime>\n  <netconf-session-start/>\n</notification>"
+	n, err = ParseNotification([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseNotification failed: %v", err)
+	}
+	if !n.EventTime.Equal(time.Date(2020, 1, 2, 2, 4, 5, 250e6, time.UTC)) || n.Event.String() != "<netconf-session-start/>" {
+		t.Errorf("unexpected notification %s %q", n.EventTime, n.Event)
+	}
+	if n.Raw.String() != data {
+		t.Errorf("unexpected raw notification %q", n.Raw)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	s, trans := newScriptedSession([]string{CapabilityNotification},
+		replyOK,
+		testNotification(1),
+		replyOK,
+		testNotification(2),
+	)
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	sub, err := s.Subscribe(context.Background(), &SubscriptionOptions{Stream: "NETCONF", StartTime: start})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	var events []string
+	for n := range sub.C {
+		events = append(events, n.Event.String())
+	}
+	expected := []string{`<event xmlns="urn:x"><seq>1</seq></event>`, `<event xmlns="urn:x"><seq>2</seq></event>`}
+	if diff := cmp.Diff(expected, events); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+	if !errors.Is(sub.Err(), ErrSessionClosed) {
+		t.Errorf("expected subscription to end with ErrSessionClosed, got %v", sub.Err())
+	}
+	if !strings.Contains(trans.sent[0], "<stream>NETCONF</stream><startTime>2020-01-01T00:00:00Z</startTime>") {
+		t.Errorf("unexpected request: %s", trans.sent[0])
+	}
+}
+
+func TestSubscribeUnsupported(t *testing.T) {
+	s, _ := newScriptedSession(nil)
+	if _, err := s.Subscribe(context.Background(), nil); !errors.Is(err, ErrCapabilityUnsupported) {
+		t.Errorf("expected ErrCapabilityUnsupported, got %v", err)
+	}
+}
+
+func TestSubscriptionOverflow(t *testing.T) {
+	tt := []struct {
+		name     string
+		opts     SubscriptionOptions
+		expected []int
+		dropped  uint64
+	}{
+		{"drop oldest", SubscriptionOptions{Overflow: OverflowDropOldest}, []int{3, 4}, 2},
+		{"drop newest", SubscriptionOptions{Overflow: OverflowDropNewest}, []int{1, 2}, 2},
+		{"block timeout", SubscriptionOptions{Overflow: OverflowBlock, BlockTimeout: time.Millisecond}, []int{1, 2}, 2},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := make(chan *Notification, 2)
+			sub := &Subscription{C: c, c: c, opts: tc.opts}
+			for i := 1; i <= 4; i++ {
+				sub.deliver(&Notification{Event: RawXML(fmt.Sprint(i))})
+			}
+			close(c)
+
+			var got []int
+			for n := range sub.C {
+				var i int
+				fmt.Sscan(n.Event.String(), &i)
+				got = append(got, i)
+			}
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("delivered mismatch (-want +got):\n%s", diff)
+			}
+			if sub.Dropped() != tc.dropped {
+				t.Errorf("expected %d dropped, got %d", tc.dropped, sub.Dropped())
+			}
+		})
+	}
+}
+
+func TestSubscriptionCloseUnblocks(t *testing.T) {
+	s, _ := newScriptedSession(nil)
+	c := make(chan *Notification)
+	sub := &Subscription{C: c, c: c, session: s, opts: SubscriptionOptions{Overflow: OverflowBlock}, done: make(chan struct{})}
+
+	delivered := make(chan struct{})
+	go func() {
+		sub.deliver(&Notification{Event: RawXML("<event/>")})
+		close(delivered)
+	}()
+	sub.Close()
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("delivery still blocked after Close")
+	}
+	sub.Close()
+}
+
+func TestIsSubscriptionEnd(t *testing.T) {
+	tt := []struct {
+		msg string
+		end bool
+	}{
+		{`<notification xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0"><eventTime>2020-01-02T03:04:09Z</eventTime><notificationComplete/></notification>`, true},
+		{`<notification xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0"><eventTime>2020-01-02T03:04:09Z</eventTime>` +
+			`<notificationComplete xmlns="urn:ietf:params:xml:ns:netmod:notification"/></notification>`, true},
+		{`<notification xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0"><eventTime>2020-01-02T03:04:09Z</eventTime>` +
+			`<notificationCompleteness xmlns="urn:ietf:params:xml:ns:netmod:notification"/></notification>`, false},
+		{`<notification xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0"><eventTime>2020-01-02T03:04:09Z</eventTime>` +
+			`<notificationComplete xmlns="urn:example:events"/></notification>`, false},
+	}
+	for _, tc := range tt {
+		n, err := ParseNotification([]byte(tc.msg))
+		if err != nil {
+			t.Fatalf("ParseNotification failed: %v", err)
+		}
+		if end := isSubscriptionEnd(n); end != tc.end {
+			t.Errorf("%s: got end %v, expected %v", tc.msg, end, tc.end)
+		}
+	}
+}
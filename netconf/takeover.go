@@ -0,0 +1,183 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// LockHolderID returns the id of the session holding the lock reported by a
+// lock-denied error.  ok is false for other errors and for locks not held by
+// a NETCONF session.
+func LockHolderID(err error) (id int, ok bool) {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Tag != "lock-denied" || rpcErr.SessionID == 0 {
+		return 0, false
+	}
+	return rpcErr.SessionID, true
+}
+
+// LockHolder describes the session holding the lock of a datastore, from the
+// ietf-netconf-monitoring state of the server.
+type LockHolder struct {
+	SessionID  int
+	Username   string
+	SourceHost string
+	Transport  string
+	LoginTime  time.Time
+	// LockedTime is the time the lock was acquired.
+	LockedTime time.Time
+	// InRPCs is the number of RPCs the session received.
+	InRPCs uint64
+}
+
+// LockHolder returns the session holding the global lock of target, or nil
+// if target is not locked.
+func (s *Session) LockHolder(ctx context.Context, target string) (*LockHolder, error) {
+	filter := `<netconf-state xmlns="` + MonitoringNamespace + `"><datastores><datastore><name>` + EscapeText(target) +
+		`</name><locks/></datastore></datastores><sessions/></netconf-state>`
+	reply, err := s.ExecContext(ctx, MethodGetFilter(SubtreeFilter(filter)))
+	if err != nil {
+		return nil, err
+	}
+	root, err := configRoot(reply.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	locks, _ := root.Select("/netconf-state/datastores/datastore[name=" + xpathLiteral(target) + "]/locks/global-lock")
+	if len(locks) == 0 {
+		return nil, nil
+	}
+	lock := locks[0]
+	id, err := strconv.Atoi(childValue(lock, "locked-by-session"))
+	if err != nil {
+		return nil, fmt.Errorf("netconf: invalid locked-by-session of %s: %v", target, err)
+	}
+
+	h := &LockHolder{SessionID: id, LockedTime: parseStreamTime(childValue(lock, "locked-time"))}
+	sessions, _ := root.Select("/netconf-state/sessions/session[session-id='" + strconv.Itoa(id) + "']")
+	if len(sessions) > 0 {
+		sess := sessions[0]
+		h.Username = childValue(sess, "username")
+		h.SourceHost = childValue(sess, "source-host")
+		h.Transport = childValue(sess, "transport")
+		h.LoginTime = parseStreamTime(childValue(sess, "login-time"))
+		h.InRPCs, _ = strconv.ParseUint(childValue(sess, "in-rpcs"), 10, 64)
+	}
+	return h, nil
+}
+
+// KillSession terminates the session with the given id, releasing its locks.
+func (s *Session) KillSession(ctx context.Context, id int) error {
+	_, err := s.ExecContext(ctx, MethodKillSession(id))
+	return err
+}
+
+// LockTakeover decides when the lock of another session is stale enough to
+// be reclaimed by killing the session, see Session.LockTakeover.
+type LockTakeover struct {
+	// MinLockAge, if set, is the time the lock must have been held.
+	MinLockAge time.Duration
+	// IdleWindow, if set, is a time the holder must not send any RPC in.
+	// The monitoring state has no idle time, so the RPC counter of the
+	// holder is sampled at the start and the end of the window.
+	IdleWindow time.Duration
+	// Allow, if set, is asked last whether the holder may be killed, e.g.
+	// to spare sessions of certain users.  It cannot make a lock stale on
+	// its own, MinLockAge or IdleWindow must be set too.
+	Allow func(h *LockHolder) bool
+}
+
+// LockTakeoverError is returned by Session.LockTakeover if the lock is held
+// by a session that was not considered stale.  It unwraps to the
+// lock-denied error.
+type LockTakeoverError struct {
+	Holder *LockHolder
+	Reason string
+	Err    error
+}
+
+func (e *LockTakeoverError) Error() string {
+	if e.Holder == nil {
+		return fmt.Sprintf("netconf: lock not taken over, %s: %v", e.Reason, e.Err)
+	}
+	return fmt.Sprintf("netconf: lock held by session %d of %q not taken over, %s: %v",
+		e.Holder.SessionID, e.Holder.Username, e.Reason, e.Err)
+}
+
+func (e *LockTakeoverError) Unwrap() error {
+	return e.Err
+}
+
+// LockTakeover locks target.  If the lock is held by a stale session as
+// decided by t, that session is killed and the lock acquired.  It returns
+// the killed session, or nil if target was not locked.  Killing a session
+// discards its uncommitted changes, so this is meant for locks stranded
+// by crashed clients.  Unless t sets MinLockAge or IdleWindow, no session
+// is deemed stale and a held lock is reported as LockTakeoverError.
+func (s *Session) LockTakeover(ctx context.Context, target string, t *LockTakeover) (*LockHolder, error) {
+	_, lockErr := s.ExecContext(ctx, MethodLock(target))
+	var rpcErr *RPCError
+	if lockErr == nil || !errors.As(lockErr, &rpcErr) || rpcErr.Tag != "lock-denied" {
+		return nil, lockErr
+	}
+	if t == nil || (t.MinLockAge <= 0 && t.IdleWindow <= 0) {
+		return nil, &LockTakeoverError{Reason: "no staleness criteria set", Err: lockErr}
+	}
+
+	h, err := s.LockHolder(ctx, target)
+	if err != nil {
+		return nil, &LockTakeoverError{Reason: "holder unknown: " + err.Error(), Err: lockErr}
+	}
+	if h == nil {
+		// The lock was released in the meantime.
+		_, err := s.ExecContext(ctx, MethodLock(target))
+		return nil, err
+	}
+	if id, ok := LockHolderID(lockErr); ok && id != h.SessionID {
+		return nil, &LockTakeoverError{Holder: h, Reason: "lock changed hands", Err: lockErr}
+	}
+	if h.SessionID == 0 || h.SessionID == s.SessionID {
+		return nil, &LockTakeoverError{Holder: h, Reason: "not held by another session", Err: lockErr}
+	}
+	if t.MinLockAge > 0 && (h.LockedTime.IsZero() || time.Since(h.LockedTime) < t.MinLockAge) {
+		return nil, &LockTakeoverError{Holder: h, Reason: fmt.Sprintf("lock younger than %s", t.MinLockAge), Err: lockErr}
+	}
+	if t.IdleWindow > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(t.IdleWindow):
+		}
+		later, err := s.LockHolder(ctx, target)
+		if err != nil {
+			return nil, &LockTakeoverError{Holder: h, Reason: "holder unknown: " + err.Error(), Err: lockErr}
+		}
+		if later == nil || later.SessionID != h.SessionID {
+			return nil, &LockTakeoverError{Holder: h, Reason: "lock changed hands", Err: lockErr}
+		}
+		if later.InRPCs != h.InRPCs {
+			return nil, &LockTakeoverError{Holder: h, Reason: fmt.Sprintf("holder active within %s", t.IdleWindow), Err: lockErr}
+		}
+	}
+	if t.Allow != nil && !t.Allow(h) {
+		return nil, &LockTakeoverError{Holder: h, Reason: "not allowed", Err: lockErr}
+	}
+
+	if err := s.KillSession(ctx, h.SessionID); err != nil {
+		return nil, err
+	}
+	if _, err := s.ExecContext(ctx, MethodLock(target)); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
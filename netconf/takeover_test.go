@@ -0,0 +1,162 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const lockDenied = `<rpc-reply><rpc-error><error-type>protocol</error-type><error-tag>lock-denied</error-tag>
+<error-severity>error</error-severity><error-info><session-id>42</session-id></error-info>
+<error-message>Lock failed, lock is already held</error-message></rpc-error></rpc-reply>`
+
+func lockState(lockedTime string, inRPCs int) string {
+	return fmt.Sprintf(`<rpc-reply><data><netconf-state xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring">
+<datastores><datastore><name>candidate</name><locks><global-lock><locked-by-session>42</locked-by-session>
+<locked-time>%s</locked-time></global-lock></locks></datastore></datastores>
+<sessions><session><session-id>7</session-id><username>me</username></session>
+<session><session-id>42</session-id><transport>netconf-ssh</transport><username>bob</username>
+<source-host>192.0.2.1</source-host><login-time>2020-01-01T00:00:00Z</login-time><in-rpcs>%d</in-rpcs></session></sessions>
+</netconf-state></data></rpc-reply>`, lockedTime, inRPCs)
+}
+
+func TestLockHolderID(t *testing.T) {
+	_, err := ParseRPCReply([]byte(lockDenied))
+	if id, ok := LockHolderID(err); !ok || id != 42 {
+		t.Errorf("got %d, %v, expected session 42", id, ok)
+	}
+	_, err = ParseRPCReply([]byte(replyError("lock-denied")))
+	if _, ok := LockHolderID(err); ok {
+		t.Error("expected no holder without session-id")
+	}
+	if _, ok := LockHolderID(errors.New("other")); ok {
+		t.Error("expected no holder for other errors")
+	}
+}
+
+func TestLockHolder(t *testing.T) {
+	s, _ := newScriptedSession(nil, lockState("2020-01-01T10:00:00Z", 3))
+	h, err := s.LockHolder(context.Background(), "candidate")
+	if err != nil {
+		t.Fatalf("LockHolder failed: %v", err)
+	}
+	expected := &LockHolder{
+		SessionID:  42,
+		Username:   "bob",
+		SourceHost: "192.0.2.1",
+		Transport:  "netconf-ssh",
+		LoginTime:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		LockedTime: time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC),
+		InRPCs:     3,
+	}
+	if diff := cmp.Diff(expected, h); diff != "" {
+		t.Errorf("holder mismatch (-expected +got):\n%s", diff)
+	}
+
+	s, _ = newScriptedSession(nil, `<rpc-reply><data><netconf-state xmlns="urn:ietf:params:xml:ns:yang:ietf-netconf-monitoring"/></data></rpc-reply>`)
+	if h, err := s.LockHolder(context.Background(), "candidate"); h != nil || err != nil {
+		t.Errorf("got %v, %v, expected no holder", h, err)
+	}
+}
+
+func TestLockTakeover(t *testing.T) {
+	old := "2020-01-01T10:00:00Z"
+	recent := time.Now().UTC().Format(time.RFC3339)
+
+	tt := []struct {
+		name     string
+		replies  []string
+		takeover LockTakeover
+		ops      []string
+		killed   bool
+		reason   string
+	}{
+		{
+			name:    "not locked",
+			replies: []string{replyOK},
+			ops:     []string{"lock"},
+		},
+		{
+			name:     "stale",
+			replies:  []string{lockDenied, lockState(old, 3), replyOK, replyOK},
+			takeover: LockTakeover{MinLockAge: time.Hour},
+			ops:      []string{"lock", "get", "kill-session", "lock"},
+			killed:   true,
+		},
+		{
+			name:     "young lock",
+			replies:  []string{lockDenied, lockState(recent, 3)},
+			takeover: LockTakeover{MinLockAge: time.Hour},
+			ops:      []string{"lock", "get"},
+			reason:   "lock younger than 1h0m0s",
+		},
+		{
+			name:     "idle",
+			replies:  []string{lockDenied, lockState(old, 3), lockState(old, 3), replyOK, replyOK},
+			takeover: LockTakeover{IdleWindow: time.Millisecond},
+			ops:      []string{"lock", "get", "get", "kill-session", "lock"},
+			killed:   true,
+		},
+		{
+			name:     "active",
+			replies:  []string{lockDenied, lockState(old, 3), lockState(old, 4)},
+			takeover: LockTakeover{IdleWindow: time.Millisecond},
+			ops:      []string{"lock", "get", "get"},
+			reason:   "holder active within 1ms",
+		},
+		{
+			name:     "not allowed",
+			replies:  []string{lockDenied, lockState(old, 3)},
+			takeover: LockTakeover{MinLockAge: time.Hour, Allow: func(h *LockHolder) bool { return h.Username != "bob" }},
+			ops:      []string{"lock", "get"},
+			reason:   "not allowed",
+		},
+		{
+			name:     "no criteria",
+			replies:  []string{lockDenied},
+			takeover: LockTakeover{Allow: func(h *LockHolder) bool { return true }},
+			ops:      []string{"lock"},
+			reason:   "no staleness criteria set",
+		},
+	}
+
+	for _, tc := range tt {
+		s, trans := newScriptedSession(nil, tc.replies...)
+		s.SessionID = 7
+		h, err := s.LockTakeover(context.Background(), "candidate", &tc.takeover)
+
+		if diff := cmp.Diff(tc.ops, trans.operations()); diff != "" {
+			t.Errorf("%s: operations mismatch (-expected +got):\n%s", tc.name, diff)
+		}
+		if tc.killed != (h != nil) {
+			t.Errorf("%s: got killed holder %v, expected killed %v", tc.name, h, tc.killed)
+		}
+		var takeoverErr *LockTakeoverError
+		switch {
+		case tc.reason == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		case tc.reason != "" && (!errors.As(err, &takeoverErr) || takeoverErr.Reason != tc.reason):
+			t.Errorf("%s: got %v, expected a LockTakeoverError for %q", tc.name, err, tc.reason)
+		case tc.reason != "":
+			if _, ok := LockHolderID(err); !ok {
+				t.Errorf("%s: expected %v to unwrap to the lock-denied error", tc.name, err)
+			}
+		}
+	}
+
+	s, trans := newScriptedSession(nil, lockDenied)
+	var takeoverErr *LockTakeoverError
+	if _, err := s.LockTakeover(context.Background(), "candidate", nil); !errors.As(err, &takeoverErr) || len(trans.sent) != 1 {
+		t.Errorf("got %v after %d requests, expected a LockTakeoverError after the lock", err, len(trans.sent))
+	}
+}
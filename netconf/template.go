@@ -0,0 +1,161 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"text/template"
+	"text/template/parse"
+)
+
+// ConfigTemplate renders configuration payloads from text/template
+// templates.  The output of every action is XML escaped unless it is passed
+// through the raw function, so values cannot inject markup.  The cdata
+// function embeds a value as CDATA section:
+//
+//	<host-name>{{.Device.Name}}</host-name>
+//	<description>{{.Vars.description}}</description>
+//	<message>{{cdata .Vars.banner}}</message>
+//	{{raw .Vars.snippet}}
+type ConfigTemplate struct {
+	t *template.Template
+}
+
+// TemplateData is passed to the template when it is rendered.
+type TemplateData struct {
+	Device *Device
+	Vars   map[string]interface{}
+}
+
+// trustedXML marks template output that must not be escaped.
+type trustedXML string
+
+// templateFuncs are available in every configuration template.
+var templateFuncs = template.FuncMap{
+	"xml": escapeTemplateValue,
+	"cdata": func(v interface{}) trustedXML {
+		return trustedXML(CDATA(fmt.Sprint(v)))
+	},
+	"raw": func(v interface{}) trustedXML {
+		if s, ok := v.(trustedXML); ok {
+			return s
+		}
+		return trustedXML(fmt.Sprint(v))
+	},
+}
+
+// escapeTemplateValue XML escapes v, leaving output of raw alone.
+func escapeTemplateValue(v interface{}) trustedXML {
+	if s, ok := v.(trustedXML); ok {
+		return s
+	}
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(fmt.Sprint(v)))
+	return trustedXML(buf.String())
+}
+
+// NewConfigTemplate parses text as a configuration template.
+func NewConfigTemplate(name, text string) (*ConfigTemplate, error) {
+	t, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return newConfigTemplate(t)
+}
+
+// ParseConfigTemplateFiles parses the named files as configuration
+// templates.  The first file is the one rendered, the others may define
+// templates it includes.
+func ParseConfigTemplateFiles(filenames ...string) (*ConfigTemplate, error) {
+	if len(filenames) == 0 {
+		return nil, fmt.Errorf("no template files given")
+	}
+	t, err := template.New(filepath.Base(filenames[0])).Funcs(templateFuncs).ParseFiles(filenames...)
+	if err != nil {
+		return nil, err
+	}
+	return newConfigTemplate(t)
+}
+
+func newConfigTemplate(t *template.Template) (*ConfigTemplate, error) {
+	escape, err := escapeCommand()
+	if err != nil {
+		return nil, err
+	}
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree != nil {
+			escapeList(tmpl.Tree.Root, escape)
+		}
+	}
+	return &ConfigTemplate{t: t}, nil
+}
+
+// Render executes the template for device with the given variables.
+func (ct *ConfigTemplate) Render(device *Device, vars map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := ct.t.Execute(&buf, &TemplateData{Device: device, Vars: vars}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EditConfig renders the template and returns an edit-config request of
+// the target datastore carrying it.
+func (ct *ConfigTemplate) EditConfig(target string, device *Device, vars map[string]interface{}) (RawMethod, error) {
+	config, err := ct.Render(device, vars)
+	if err != nil {
+		return "", err
+	}
+	return MethodEditConfig(target, string(config)), nil
+}
+
+// escapeCommand returns a pipeline command calling the xml function.
+func escapeCommand() (*parse.CommandNode, error) {
+	t, err := template.New("escape").Funcs(templateFuncs).Parse("{{. | xml}}")
+	if err != nil {
+		return nil, err
+	}
+	action := t.Tree.Root.Nodes[0].(*parse.ActionNode)
+	return action.Pipe.Cmds[1], nil
+}
+
+// escapeList appends the xml function to every action in the list that
+// produces output and does not already end in xml or raw.
+func escapeList(list *parse.ListNode, escape *parse.CommandNode) {
+	if list == nil {
+		return
+	}
+	for _, n := range list.Nodes {
+		switch n := n.(type) {
+		case *parse.ActionNode:
+			escapePipe(n.Pipe, escape)
+		case *parse.IfNode:
+			escapeList(n.List, escape)
+			escapeList(n.ElseList, escape)
+		case *parse.RangeNode:
+			escapeList(n.List, escape)
+			escapeList(n.ElseList, escape)
+		case *parse.WithNode:
+			escapeList(n.List, escape)
+			escapeList(n.ElseList, escape)
+		}
+	}
+}
+
+func escapePipe(pipe *parse.PipeNode, escape *parse.CommandNode) {
+	if len(pipe.Decl) > 0 || len(pipe.Cmds) == 0 {
+		return
+	}
+	last := pipe.Cmds[len(pipe.Cmds)-1]
+	if id, ok := last.Args[0].(*parse.IdentifierNode); ok && (id.Ident == "xml" || id.Ident == "raw" || id.Ident == "cdata") {
+		return
+	}
+	pipe.Cmds = append(pipe.Cmds, escape.Copy().(*parse.CommandNode))
+}
@@ -0,0 +1,107 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigTemplateRender(t *testing.T) {
+	tt := []struct {
+		name     string
+		text     string
+		vars     map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "device",
+			text:     "<host-name>{{.Device.Name}}</host-name>",
+			expected: "<host-name>r1&amp;2</host-name>",
+		},
+		{
+			name:     "escaped",
+			text:     `<description>{{.Vars.d}}</description><x a="{{.Vars.d}}"/>`,
+			vars:     map[string]interface{}{"d": `</description><evil a="1">`},
+			expected: `<description>&lt;/description&gt;&lt;evil a=&#34;1&#34;&gt;</description><x a="&lt;/description&gt;&lt;evil a=&#34;1&#34;&gt;"/>`,
+		},
+		{
+			name:     "explicit",
+			text:     "{{xml .Vars.d}}{{.Vars.d | xml}}",
+			vars:     map[string]interface{}{"d": "<"},
+			expected: "&lt;&lt;",
+		},
+		{
+			name:     "raw",
+			text:     "{{raw .Vars.d}}{{.Vars.d | raw}}",
+			vars:     map[string]interface{}{"d": "<a/>"},
+			expected: "<a/><a/>",
+		},
+		{
+			name:     "cdata",
+			text:     "<message>{{cdata .Vars.d}}</message>",
+			vars:     map[string]interface{}{"d": "<b>]]>"},
+			expected: "<message><![CDATA[<b>]]]]><![CDATA[>]]></message>",
+		},
+		{
+			name:     "nested",
+			text:     `{{range .Vars.l}}<v>{{.}}</v>{{end}}{{if .Vars.d}}{{.Vars.d}}{{else}}none{{end}}{{with $x := .Vars.d}}{{$x}}{{end}}`,
+			vars:     map[string]interface{}{"l": []string{"<", "&"}, "d": ">"},
+			expected: "<v>&lt;</v><v>&amp;</v>&gt;&gt;",
+		},
+		{
+			name:     "define",
+			text:     `{{define "unit"}}<unit>{{.}}</unit>{{end}}{{template "unit" .Vars.d}}`,
+			vars:     map[string]interface{}{"d": "<"},
+			expected: "<unit>&lt;</unit>",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ct, err := NewConfigTemplate(tc.name, tc.text)
+			if err != nil {
+				t.Fatalf("NewConfigTemplate failed: %v", err)
+			}
+			out, err := ct.Render(&Device{Name: "r1&2"}, tc.vars)
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if string(out) != tc.expected {
+				t.Errorf("unexpected result: (want %q, got %q)", tc.expected, out)
+			}
+		})
+	}
+}
+
+func TestParseConfigTemplateFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "netconf-templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	main := filepath.Join(dir, "main.xml")
+	unit := filepath.Join(dir, "unit.xml")
+	ioutil.WriteFile(main, []byte(`<system>{{template "unit.xml" .Device.Name}}</system>`), 0644)
+	ioutil.WriteFile(unit, []byte(`<host-name>{{.}}</host-name>`), 0644)
+
+	ct, err := ParseConfigTemplateFiles(main, unit)
+	if err != nil {
+		t.Fatalf("ParseConfigTemplateFiles failed: %v", err)
+	}
+	m, err := ct.EditConfig("candidate", &Device{Name: "<r1>"}, nil)
+	if err != nil {
+		t.Fatalf("EditConfig failed: %v", err)
+	}
+	expected := MethodEditConfig("candidate", "<system><host-name>&lt;r1&gt;</host-name></system>")
+	if m != expected {
+		t.Errorf("unexpected method: (want %q, got %q)", expected, m)
+	}
+}
@@ -0,0 +1,128 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"fmt"
+)
+
+// TransactionStage names a step of an edit transaction.
+type TransactionStage string
+
+// Stages of an edit transaction, in order.
+const (
+	StageLock     TransactionStage = "lock"
+	StageEdit     TransactionStage = "edit"
+	StageValidate TransactionStage = "validate"
+	StageCommit   TransactionStage = "commit"
+	StageUnlock   TransactionStage = "unlock"
+)
+
+// TransactionError is returned by EditTransaction and reports the stage at
+// which the transaction failed.
+type TransactionError struct {
+	Stage TransactionStage
+	Err   error
+	// CleanupErr holds the first error hit while discarding changes or
+	// unlocking after the failure, if any.
+	CleanupErr error
+}
+
+func (e *TransactionError) Error() string {
+	msg := fmt.Sprintf("netconf transaction failed at %s: %v", e.Stage, e.Err)
+	if e.CleanupErr != nil {
+		msg += fmt.Sprintf(" (cleanup: %v)", e.CleanupErr)
+	}
+	return msg
+}
+
+// Unwrap returns the error of the failed stage.
+func (e *TransactionError) Unwrap() error {
+	return e.Err
+}
+
+// TransactionOptions tunes EditTransaction.
+type TransactionOptions struct {
+	// SkipValidate disables the validate stage.  Validation is also skipped
+	// when the server does not announce :validate.
+	SkipValidate bool
+	// NoLock disables locking the target, for devices without lock support.
+	NoLock bool
+	// Replace replaces the configuration of the target with config rather
+	// than merging config into it.
+	Replace bool
+}
+
+// EditTransaction applies config to the target datastore in a single safe
+// sequence: lock, edit-config, validate and, for the candidate datastore,
+// commit, followed by unlock.  On failure any uncommitted candidate changes
+// are discarded and the lock is released before returning a
+// *TransactionError naming the failed stage.  opts may be nil.
+func (s *Session) EditTransaction(ctx context.Context, target string, config string, opts *TransactionOptions) error {
+	if opts == nil {
+		opts = &TransactionOptions{}
+	}
+	edit := MethodEditConfig(target, config)
+	if opts.Replace {
+		edit = MethodReplaceConfig(target, config)
+	}
+	return s.transaction(ctx, target, edit, opts)
+}
+
+// transaction runs the sequence of EditTransaction with edit as the request
+// changing the target.
+func (s *Session) transaction(ctx context.Context, target string, edit RPCMethod, opts *TransactionOptions) error {
+
+	// Cleanup must run even once ctx is done, otherwise locks are stranded.
+	cleanup := context.Background()
+	locked := false
+	fail := func(stage TransactionStage, err error) error {
+		txErr := &TransactionError{Stage: stage, Err: err}
+		if target == "candidate" && stage != StageLock {
+			if _, err := s.ExecContext(cleanup, MethodDiscardChanges()); err != nil {
+				txErr.CleanupErr = err
+			}
+		}
+		if locked {
+			if _, err := s.ExecContext(cleanup, MethodUnlock(target)); err != nil && txErr.CleanupErr == nil {
+				txErr.CleanupErr = err
+			}
+		}
+		return txErr
+	}
+
+	if !opts.NoLock {
+		if _, err := s.ExecContext(ctx, MethodLock(target)); err != nil {
+			return fail(StageLock, err)
+		}
+		locked = true
+	}
+
+	if _, err := s.ExecContext(ctx, edit); err != nil {
+		return fail(StageEdit, err)
+	}
+
+	if !opts.SkipValidate && s.HasCapability(CapabilityValidate) {
+		if _, err := s.ExecContext(ctx, MethodValidate(target)); err != nil {
+			return fail(StageValidate, err)
+		}
+	}
+
+	if target == "candidate" {
+		if _, err := s.ExecContext(ctx, MethodCommit()); err != nil {
+			return fail(StageCommit, err)
+		}
+	}
+
+	if locked {
+		if _, err := s.ExecContext(cleanup, MethodUnlock(target)); err != nil {
+			return &TransactionError{Stage: StageUnlock, Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEditTransaction(t *testing.T) {
+	caps := []string{CapabilityCandidate, CapabilityValidate}
+
+	tt := []struct {
+		name     string
+		target   string
+		replies  []string
+		stage    TransactionStage
+		expected []string
+	}{
+		{
+			name:     "candidate",
+			target:   "candidate",
+			replies:  []string{replyOK, replyOK, replyOK, replyOK, replyOK},
+			expected: []string{"lock", "edit-config", "validate", "commit", "unlock"},
+		},
+		{
+			name:     "running",
+			target:   "running",
+			replies:  []string{replyOK, replyOK, replyOK, replyOK},
+			expected: []string{"lock", "edit-config", "validate", "unlock"},
+		},
+		{
+			name:     "lockdenied",
+			target:   "candidate",
+			replies:  []string{replyError("lock-denied")},
+			stage:    StageLock,
+			expected: []string{"lock"},
+		},
+		{
+			name:     "validatefailed",
+			target:   "candidate",
+			replies:  []string{replyOK, replyOK, replyError("invalid-value"), replyOK, replyOK},
+			stage:    StageValidate,
+			expected: []string{"lock", "edit-config", "validate", "discard-changes", "unlock"},
+		},
+		{
+			name:     "commitfailed",
+			target:   "candidate",
+			replies:  []string{replyOK, replyOK, replyOK, replyError("operation-failed"), replyOK, replyOK},
+			stage:    StageCommit,
+			expected: []string{"lock", "edit-config", "validate", "commit", "discard-changes", "unlock"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s, trans := newScriptedSession(caps, tc.replies...)
+			err := s.EditTransaction(context.Background(), tc.target, "<system/>", nil)
+
+			if tc.stage == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			} else {
+				txErr, ok := err.(*TransactionError)
+				if !ok {
+					t.Fatalf("expected *TransactionError, got %v", err)
+				}
+				if txErr.Stage != tc.stage || txErr.CleanupErr != nil {
+					t.Errorf("unexpected error: %v", txErr)
+				}
+			}
+
+			if got := trans.operations(); !cmp.Equal(got, tc.expected) {
+				t.Errorf("unexpected operations:\n%s", cmp.Diff(tc.expected, got))
+			}
+		})
+	}
+}
@@ -7,17 +7,22 @@
 package netconf
 
 import (
-	"bytes"
 	"encoding/xml"
-	"fmt"
 	"io"
 	"regexp"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
 	// msgSeperator is used to separate sent messages via NETCONF
 	msgSeperator     = "]]>]]>"
 	msgSeperator_v11 = "\n##\n"
+
+	// DefaultChunkSize is the default maximum size of outgoing chunks with
+	// chunked framing.
+	DefaultChunkSize = 64 * 1024
 )
 
 // DefaultCapabilities sets the default capabilities of the client library
@@ -46,47 +51,78 @@ type Transport interface {
 
 type transportBasicIO struct {
 	io.ReadWriteCloser
-	//new add
-	version string
+	// framer frames the messages, created on first use so that the
+	// ReadWriteCloser can be set after construction.
+	framer     *Framer
+	framerOnce sync.Once
+}
+
+// frames returns the framer of the transport.  It reads and writes through
+// t, so it follows changes to the ReadWriteCloser.  The reader and writer
+// of a session may call it concurrently.
+func (t *transportBasicIO) frames() *Framer {
+	t.framerOnce.Do(func() {
+		t.framer = NewFramer(t, t)
+	})
+	return t.framer
 }
 
-func (t *transportBasicIO) SetVersion(version string) {
-	t.version = version
+// basicIO gives access to the byte stream of the transports embedding
+// transportBasicIO, see Chaos.Transport.
+func (t *transportBasicIO) basicIO() *transportBasicIO {
+	return t
 }
 
-// Sends a well formated NETCONF rpc message as a slice of bytes adding on the
-// nessisary framining messages.
-func (t *transportBasicIO) Send(data []byte) error {
-	var seperator []byte
-	var dataInfo []byte
-	//headlen := 0
-	if t.version == "v1.1" {
-		seperator = append(seperator, []byte(msgSeperator_v11)...)
+func (t *transportBasicIO) SetVersion(version string) {
+	if version == "v1.1" {
+		t.frames().SetFraming(FramingChunked)
 	} else {
-		seperator = append(seperator, []byte(msgSeperator)...)
+		t.frames().SetFraming(FramingEOM)
 	}
+}
 
-	if t.version == "v1.1" {
-		header := fmt.Sprintf("\n#%d\n", len(string(data)))
-		dataInfo = append(dataInfo, header...)
-		//t.Write([]byte(header))
-		//headlen = len([]byte(header))
+// SetReadDeadline sets the read deadline of the underlying connection if it
+// supports deadlines.
+func (t *transportBasicIO) SetReadDeadline(d time.Time) error {
+	if c, ok := t.ReadWriteCloser.(interface{ SetReadDeadline(time.Time) error }); ok {
+		return c.SetReadDeadline(d)
 	}
-	dataInfo = append(dataInfo, data...)
-	dataInfo = append(dataInfo, seperator...)
-	_, err := t.Write(dataInfo)
+	return errDeadlineUnsupported
+}
 
-	return err
+// SetWriteDeadline sets the write deadline of the underlying connection if it
+// supports deadlines.
+func (t *transportBasicIO) SetWriteDeadline(d time.Time) error {
+	if c, ok := t.ReadWriteCloser.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		return c.SetWriteDeadline(d)
+	}
+	return errDeadlineUnsupported
 }
 
+// Sends a well formated NETCONF rpc message as a slice of bytes adding on the
+// nessisary framining messages.  With chunked framing the message is split
+// into chunks of at most the transport's chunk size.
+func (t *transportBasicIO) Send(data []byte) error {
+	return t.frames().WriteFrame(data)
+}
+
+// MessageWriter returns a writer for a single outgoing message.  Data is
+// framed as it is written and Close completes the message, so messages
+// need not be held in memory as a whole.
+func (t *transportBasicIO) MessageWriter() io.WriteCloser {
+	return t.frames().FrameWriter()
+}
+
+// SetChunkSize sets the maximum size of the chunks messages are split into
+// with chunked framing.  Sizes below 1 select DefaultChunkSize.
+func (t *transportBasicIO) SetChunkSize(size int) {
+	t.frames().SetChunkSize(size)
+}
+
+// Receive reads the next message.  Chunked messages are returned without
+// their framing.
 func (t *transportBasicIO) Receive() ([]byte, error) {
-	var seperator []byte
-	if t.version == "v1.1" {
-		seperator = append(seperator, []byte(msgSeperator_v11)...)
-	} else {
-		seperator = append(seperator, []byte(msgSeperator)...)
-	}
-	return t.WaitForBytes([]byte(seperator))
+	return t.frames().ReadFrame()
 }
 
 func (t *transportBasicIO) SendHello(hello *HelloMessage) error {
@@ -102,14 +138,23 @@ func (t *transportBasicIO) SendHello(hello *HelloMessage) error {
 }
 
 func (t *transportBasicIO) ReceiveHello() (*HelloMessage, error) {
-	hello := new(HelloMessage)
-
 	val, err := t.Receive()
 	if err != nil {
-		return hello, err
+		return new(HelloMessage), err
 	}
 
-	err = xml.Unmarshal(val, hello)
+	return ParseHello(val)
+}
+
+// ParseHello parses a hello message, with or without the end-of-message
+// delimiter.  Whitespace around capabilities is removed.  The message is
+// returned even if it could not be parsed completely.
+func ParseHello(data []byte) (*HelloMessage, error) {
+	hello := new(HelloMessage)
+	err := xml.Unmarshal(data, hello)
+	for i, c := range hello.Capabilities {
+		hello.Capabilities[i] = strings.TrimSpace(c)
+	}
 	return hello, err
 }
 
@@ -119,47 +164,17 @@ func (t *transportBasicIO) Writeln(b []byte) (int, error) {
 	return 0, nil
 }
 
+// WaitForFunc reads until f reports the end of the output.  Data read beyond
+// the end is kept for the next read.
 func (t *transportBasicIO) WaitForFunc(f func([]byte) (int, error)) ([]byte, error) {
-	var out bytes.Buffer
-	buf := make([]byte, 8192)
-
-	pos := 0
-	for {
-		n, err := t.Read(buf[pos : pos+(len(buf)/2)])
-		if err != nil {
-			if err != io.EOF {
-				return nil, err
-			}
-			break
-		}
-
-		if n > 0 {
-			end, err := f(buf[0 : pos+n])
-			if err != nil {
-				return nil, err
-			}
-
-			if end > -1 {
-				out.Write(buf[0:end])
-				return out.Bytes(), nil
-			}
-
-			if pos > 0 {
-				out.Write(buf[0:pos])
-				copy(buf, buf[pos:pos+n])
-			}
-
-			pos = n
-		}
-	}
-
-	return nil, fmt.Errorf("WaitForFunc failed")
+	return t.frames().waitFor(func(buf []byte) (int, int, error) {
+		end, err := f(buf)
+		return end, end, err
+	})
 }
 
 func (t *transportBasicIO) WaitForBytes(b []byte) ([]byte, error) {
-	return t.WaitForFunc(func(buf []byte) (int, error) {
-		return bytes.Index(buf, b), nil
-	})
+	return t.frames().waitForBytes(b)
 }
 
 func (t *transportBasicIO) WaitForString(s string) (string, error) {
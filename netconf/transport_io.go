@@ -0,0 +1,83 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"io"
+	"os"
+	"os/exec"
+)
+
+// TransportIO maintains the information necessary to communicate over an
+// arbitrary reader and writer pair, such as the standard input and output of
+// the process or of a child process.
+type TransportIO struct {
+	transportBasicIO
+	r   io.Reader
+	w   io.Writer
+	cmd *exec.Cmd
+}
+
+// NewTransportIO returns a transport reading from r and writing to w.
+// Closing the transport closes r and w if they implement io.Closer.
+func NewTransportIO(r io.Reader, w io.Writer) *TransportIO {
+	t := &TransportIO{r: r, w: w}
+	t.ReadWriteCloser = struct {
+		io.Reader
+		io.Writer
+		io.Closer
+	}{r, w, t}
+	return t
+}
+
+// NewStdioTransport returns a transport speaking NETCONF over the standard
+// input and output of the process, for use in "ssh -s netconf" style
+// wrappers and relays.
+func NewStdioTransport() *TransportIO {
+	return NewTransportIO(os.Stdin, os.Stdout)
+}
+
+// Close closes the reader and writer and waits for the child process if
+// the transport was created by DialCommand.
+func (t *TransportIO) Close() error {
+	var err error
+	if c, ok := t.w.(io.Closer); ok {
+		err = c.Close()
+	}
+	if c, ok := t.r.(io.Closer); ok && interface{}(t.r) != interface{}(t.w) {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if t.cmd != nil {
+		if werr := t.cmd.Wait(); err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+// DialCommand creates a new NETCONF session over the standard input and
+// output of a child process, e.g. "ssh -s router netconf".
+func DialCommand(name string, args ...string) (*Session, error) {
+	cmd := exec.Command(name, args...)
+	w, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	r, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	t := NewTransportIO(r, w)
+	t.cmd = cmd
+	return NewSession(t), nil
+}
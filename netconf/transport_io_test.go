@@ -0,0 +1,65 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"net"
+	"os"
+	"testing"
+)
+
+func TestTransportIO(t *testing.T) {
+	client, server := net.Pipe()
+	go serveTestNETCONF(server)
+
+	s := NewSession(NewTransportIO(client, client))
+	if _, err := s.Exec(MethodGetConfig("running")); err != nil {
+		t.Errorf("Exec failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+}
+
+// TestHelperServer is not a real test.  It is run as child process by
+// TestDialCommand to serve NETCONF on its standard input and output.
+func TestHelperServer(t *testing.T) {
+	if os.Getenv("NETCONF_HELPER_SERVER") != "1" {
+		return
+	}
+
+	tr := NewStdioTransport()
+	tr.SendHello(&HelloMessage{Capabilities: []string{CapabilityBase10}, SessionID: 3})
+	if _, err := tr.ReceiveHello(); err != nil {
+		os.Exit(1)
+	}
+	for {
+		if _, err := tr.Receive(); err != nil {
+			os.Exit(0)
+		}
+		tr.Send([]byte(replyOK))
+	}
+}
+
+func TestDialCommand(t *testing.T) {
+	os.Setenv("NETCONF_HELPER_SERVER", "1")
+	defer os.Unsetenv("NETCONF_HELPER_SERVER")
+
+	s, err := DialCommand(os.Args[0], "-test.run=^TestHelperServer$")
+	if err != nil {
+		t.Fatalf("DialCommand failed: %v", err)
+	}
+	if s.SessionID != 3 {
+		t.Errorf("expected session id 3, got %d", s.SessionID)
+	}
+	if _, err := s.Exec(MethodGetConfig("running")); err != nil {
+		t.Errorf("Exec failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+}
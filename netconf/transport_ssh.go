@@ -7,13 +7,14 @@
 package netconf
 
 import (
+	"context"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
-	"os"
-	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -32,8 +33,44 @@ const (
 // remote device over SSH
 type TransportSSH struct {
 	transportBasicIO
+	// Command, if set, is executed on the device to start NETCONF instead of
+	// requesting the netconf subsystem, for devices that do not register
+	// the subsystem.  E.g. "xml-mode netconf need-trailer" on Junos.
+	Command string
+	// Subsystem is the name of the SSH subsystem requested, "netconf" if
+	// empty.
+	Subsystem string
+	// DialFunc, if set, opens the TCP connection of Dial.
+	DialFunc DialFunc
+
 	sshClient  *ssh.Client
 	sshSession *ssh.Session
+	// conn is the shared connection the transport was opened on, if any.
+	conn *SSHConnection
+	// banner holds the messages sent by the server before authentication.
+	banner string
+}
+
+// Banner returns the banner the server sent before authentication, often a
+// legal notice, or an empty string if there was none.
+func (t *TransportSSH) Banner() string {
+	return t.banner
+}
+
+// recordBanner returns a copy of config that appends the banner messages of
+// the server to banner before passing them on to the BannerCallback of
+// config, if any.
+func recordBanner(config *ssh.ClientConfig, banner *string) *ssh.ClientConfig {
+	c := *config
+	next := config.BannerCallback
+	c.BannerCallback = func(message string) error {
+		*banner += message
+		if next != nil {
+			return next(message)
+		}
+		return nil
+	}
+	return &c
 }
 
 // Close closes an existing SSH session and socket if they exist.
@@ -43,6 +80,11 @@ func (t *TransportSSH) Close() error {
 		return nil
 	}
 
+	// Leave a shared connection open for its other sessions
+	if t.conn != nil {
+		return t.conn.release(t)
+	}
+
 	// Close the SSH Session if we have one
 	if t.sshSession != nil {
 		if err := t.sshSession.Close(); err != nil {
@@ -64,19 +106,29 @@ func (t *TransportSSH) Close() error {
 //
 // target can be an IP address (e.g.) 172.16.1.1 which utlizes the default
 // NETCONF over SSH port of 830.  Target can also specify a port with the
-// following format <host>:<port (e.g 172.16.1.1:22)
+// following format <host>:<port (e.g 172.16.1.1:22).  IPv6 literals are
+// given as [fe80::1%mgmt]:830, the brackets being optional without a port.
 //
 // config takes a ssh.ClientConfig connection. See documentation for
 // go.crypto/ssh for documenation.  There is a helper function SSHConfigPassword
 // thar returns a ssh.ClientConfig for simple username/password authentication
 func (t *TransportSSH) Dial(target string, config *ssh.ClientConfig) error {
-	if !strings.Contains(target, ":") {
-		target = fmt.Sprintf("%s:%d", target, sshDefaultPort)
+	target = withDefaultPort(target, sshDefaultPort)
+	if t.DialFunc != nil {
+		conn, err := t.DialFunc(context.Background(), "tcp", target)
+		if err != nil {
+			return err
+		}
+		if err := t.handshake(conn, config); err != nil {
+			conn.Close()
+			return err
+		}
+		return nil
 	}
 
 	var err error
 
-	t.sshClient, err = ssh.Dial("tcp", target, config)
+	t.sshClient, err = ssh.Dial("tcp", target, recordBanner(config, &t.banner))
 	if err != nil {
 		return err
 	}
@@ -104,7 +156,108 @@ func (t *TransportSSH) setupSession() error {
 	}
 
 	t.ReadWriteCloser = NewReadWriteCloser(reader, writer)
-	return t.sshSession.RequestSubsystem(sshNetconfSubsystem)
+	if t.Command != "" {
+		return t.sshSession.Start(t.Command)
+	}
+	subsystem := t.Subsystem
+	if subsystem == "" {
+		subsystem = sshNetconfSubsystem
+	}
+	return t.sshSession.RequestSubsystem(subsystem)
+}
+
+// SSHConnection owns an SSH connection over which several NETCONF sessions
+// can be opened, each on its own channel.  Closing a session only closes its
+// channel; the connection stays open until it is closed itself.
+type SSHConnection struct {
+	// Subsystem is the name of the SSH subsystem requested for new
+	// sessions, "netconf" if empty.
+	Subsystem string
+
+	client *ssh.Client
+	banner string
+
+	mu       sync.Mutex
+	sessions map[*TransportSSH]struct{}
+	closed   bool
+}
+
+// NewSSHConnection returns an SSHConnection owning client.
+func NewSSHConnection(client *ssh.Client) *SSHConnection {
+	return &SSHConnection{client: client, sessions: make(map[*TransportSSH]struct{})}
+}
+
+// DialSSHConnection connects to target, see TransportSSH.Dial for the
+// arguments, and returns the connection without opening a session.
+func DialSSHConnection(target string, config *ssh.ClientConfig) (*SSHConnection, error) {
+	target = withDefaultPort(target, sshDefaultPort)
+
+	var banner string
+	client, err := ssh.Dial("tcp", target, recordBanner(config, &banner))
+	if err != nil {
+		return nil, err
+	}
+	c := NewSSHConnection(client)
+	c.banner = banner
+	return c, nil
+}
+
+// Banner returns the banner the server sent before authentication when the
+// connection was dialed.
+func (c *SSHConnection) Banner() string {
+	return c.banner
+}
+
+// NewSession opens a new NETCONF session on its own channel of the
+// connection.
+func (c *SSHConnection) NewSession() (*Session, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("netconf: ssh connection closed")
+	}
+	t := &TransportSSH{Subsystem: c.Subsystem, sshClient: c.client, conn: c, banner: c.banner}
+	c.sessions[t] = struct{}{}
+	c.mu.Unlock()
+
+	if err := t.setupSession(); err != nil {
+		t.Close()
+		return nil, err
+	}
+	return NewSession(t), nil
+}
+
+// Sessions returns the number of open sessions on the connection.
+func (c *SSHConnection) Sessions() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.sessions)
+}
+
+// Close closes the connection along with all sessions opened on it.
+func (c *SSHConnection) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.sessions = make(map[*TransportSSH]struct{})
+	c.mu.Unlock()
+	return c.client.Close()
+}
+
+// release closes the channel of a session opened on the connection.
+func (c *SSHConnection) release(t *TransportSSH) error {
+	c.mu.Lock()
+	delete(c.sessions, t)
+	c.mu.Unlock()
+
+	if t.sshSession == nil {
+		return nil
+	}
+	err := t.sshSession.Close()
+	if err == io.EOF {
+		// The server closed the channel already.
+		err = nil
+	}
+	return err
 }
 
 // NewSSHSession creates a new NETCONF session using an existing net.Conn.
@@ -120,7 +273,14 @@ func NewSSHSession(conn net.Conn, config *ssh.ClientConfig) (*Session, error) {
 // DialSSH creates a new NETCONF session using a SSH Transport.
 // See TransportSSH.Dial for arguments.
 func DialSSH(target string, config *ssh.ClientConfig) (*Session, error) {
-	var t TransportSSH
+	return Dial(target, WithSSHConfig(config))
+}
+
+// DialSSHCommand creates a new NETCONF session using a SSH Transport that
+// executes command instead of requesting the netconf subsystem.
+// See TransportSSH.Dial for the other arguments.
+func DialSSHCommand(target string, config *ssh.ClientConfig, command string) (*Session, error) {
+	t := TransportSSH{Command: command}
 	err := t.Dial(target, config)
 	if err != nil {
 		t.Close()
@@ -133,7 +293,7 @@ func DialSSH(target string, config *ssh.ClientConfig) (*Session, error) {
 // See TransportSSH.Dial for arguments.
 // The timeout value is used for both connection establishment and Read/Write operations.
 func DialSSHTimeout(target string, config *ssh.ClientConfig, timeout time.Duration) (*Session, error) {
-	bareConn, err := net.DialTimeout("tcp", target, timeout)
+	bareConn, err := net.DialTimeout("tcp", withDefaultPort(target, sshDefaultPort), timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -213,9 +373,9 @@ func SSHConfigPubKeyFile(user string, file string, passphrase string) (*ssh.Clie
 
 // SSHConfigPubKeyAgent is a convience function that takes a username and
 // returns a new ssh.Clientconfig setup to pass credentials received from
-// an ssh agent
+// an ssh agent, see DialSSHAgent
 func SSHConfigPubKeyAgent(user string) (*ssh.ClientConfig, error) {
-	c, err := net.Dial("unix", os.Getenv("SSH_AUTH_SOCK"))
+	c, err := DialSSHAgent()
 	if err != nil {
 		return nil, err
 	}
@@ -228,20 +388,25 @@ func SSHConfigPubKeyAgent(user string) (*ssh.ClientConfig, error) {
 }
 
 func connToTransport(conn net.Conn, config *ssh.ClientConfig) (*TransportSSH, error) {
-	c, chans, reqs, err := ssh.NewClientConn(conn, conn.RemoteAddr().String(), config)
-	if err != nil {
-		return nil, err
-	}
-
 	t := &TransportSSH{}
-	t.sshClient = ssh.NewClient(c, chans, reqs)
+	if err := t.handshake(conn, config); err != nil {
+		if t.sshClient == nil {
+			return nil, err
+		}
+		return t, err
+	}
+	return t, nil
+}
 
-	err = t.setupSession()
+// handshake establishes the SSH connection over conn and opens the NETCONF
+// channel.
+func (t *TransportSSH) handshake(conn net.Conn, config *ssh.ClientConfig) error {
+	c, chans, reqs, err := ssh.NewClientConn(conn, conn.RemoteAddr().String(), recordBanner(config, &t.banner))
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	return t, nil
+	t.sshClient = ssh.NewClient(c, chans, reqs)
+	return t.setupSession()
 }
 
 type deadlineConn struct {
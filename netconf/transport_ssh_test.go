@@ -7,10 +7,18 @@
 package netconf
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestSSHConfigPassword(t *testing.T) {
@@ -44,3 +52,262 @@ func TestSSHConfigPassword(t *testing.T) {
 		t.Errorf("host key method of %s does not contain expected InsecureIgnoreHostKey", hostKeyMethod)
 	}
 }
+
+// testSSHServer is an SSH server answering every RPC on the netconf
+// subsystem with <ok/>.
+type testSSHServer struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+
+	mu       sync.Mutex
+	conns    int
+	channels int
+	requests []string
+	banner   string
+	// password, if set, is required to authenticate.
+	password string
+}
+
+func newTestSSHServer(t *testing.T) *testSSHServer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := &testSSHServer{listener: l, config: &ssh.ServerConfig{NoClientAuth: true}}
+	srv.config.AddHostKey(signer)
+	srv.config.BannerCallback = func(ssh.ConnMetadata) string {
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		return srv.banner
+	}
+	srv.config.NoClientAuthCallback = func(ssh.ConnMetadata) (*ssh.Permissions, error) {
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		if srv.password != "" {
+			return nil, errors.New("password required")
+		}
+		return nil, nil
+	}
+	srv.config.PasswordCallback = func(_ ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		if string(password) != srv.password {
+			return nil, errors.New("wrong password")
+		}
+		return nil, nil
+	}
+	go srv.serve()
+	return srv
+}
+
+func (srv *testSSHServer) Addr() string {
+	return srv.listener.Addr().String()
+}
+
+func (srv *testSSHServer) Close() {
+	srv.listener.Close()
+}
+
+func (srv *testSSHServer) serve() {
+	for {
+		nc, err := srv.listener.Accept()
+		if err != nil {
+			return
+		}
+		go srv.serveConn(nc)
+	}
+}
+
+func (srv *testSSHServer) serveConn(nc net.Conn) {
+	_, chans, reqs, err := ssh.NewServerConn(nc, srv.config)
+	if err != nil {
+		nc.Close()
+		return
+	}
+	srv.mu.Lock()
+	srv.conns++
+	srv.mu.Unlock()
+
+	go ssh.DiscardRequests(reqs)
+	for nch := range chans {
+		ch, reqs, err := nch.Accept()
+		if err != nil {
+			continue
+		}
+		srv.mu.Lock()
+		srv.channels++
+		srv.mu.Unlock()
+		go srv.serveChannel(ch, reqs)
+	}
+}
+
+func (srv *testSSHServer) serveChannel(ch ssh.Channel, reqs <-chan *ssh.Request) {
+	defer ch.Close()
+	for req := range reqs {
+		var payload struct{ Value string }
+		ssh.Unmarshal(req.Payload, &payload)
+
+		srv.mu.Lock()
+		srv.requests = append(srv.requests, req.Type+" "+payload.Value)
+		srv.mu.Unlock()
+
+		ok := req.Type == "subsystem" || req.Type == "exec"
+		req.Reply(ok, nil)
+		if ok {
+			go ssh.DiscardRequests(reqs)
+			break
+		}
+	}
+
+	tr := &transportBasicIO{ReadWriteCloser: ch}
+	tr.SendHello(&HelloMessage{Capabilities: []string{CapabilityBase10}, SessionID: 1})
+	if _, err := tr.ReceiveHello(); err != nil {
+		return
+	}
+	for {
+		if _, err := tr.Receive(); err != nil {
+			return
+		}
+		tr.Send([]byte(replyOK))
+	}
+}
+
+func testSSHConfig() *ssh.ClientConfig {
+	return &ssh.ClientConfig{User: "test", HostKeyCallback: ssh.InsecureIgnoreHostKey()}
+}
+
+func TestSSHConnectionSessions(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+
+	conn, err := DialSSHConnection(srv.Addr(), testSSHConfig())
+	if err != nil {
+		t.Fatalf("DialSSHConnection failed: %v", err)
+	}
+	defer conn.Close()
+
+	var sessions []*Session
+	for i := 0; i < 2; i++ {
+		s, err := conn.NewSession()
+		if err != nil {
+			t.Fatalf("NewSession failed: %v", err)
+		}
+		sessions = append(sessions, s)
+	}
+
+	for _, s := range sessions {
+		if _, err := s.Exec(MethodGetConfig("running")); err != nil {
+			t.Errorf("Exec failed: %v", err)
+		}
+	}
+
+	if err := sessions[0].Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if _, err := sessions[1].Exec(MethodGetConfig("running")); err != nil {
+		t.Errorf("Exec after closing other session failed: %v", err)
+	}
+	if n := conn.Sessions(); n != 1 {
+		t.Errorf("expected 1 open session, got %d", n)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.conns != 1 || srv.channels != 2 {
+		t.Errorf("expected 2 channels on 1 connection, got %d on %d", srv.channels, srv.conns)
+	}
+}
+
+func TestDialSSHCommand(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+
+	s, err := DialSSHCommand(srv.Addr(), testSSHConfig(), "xml-mode netconf need-trailer")
+	if err != nil {
+		t.Fatalf("DialSSHCommand failed: %v", err)
+	}
+	defer s.Close()
+
+	if _, err := s.Exec(MethodGetConfig("running")); err != nil {
+		t.Errorf("Exec failed: %v", err)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if diff := cmp.Diff([]string{"exec xml-mode netconf need-trailer"}, srv.requests); diff != "" {
+		t.Errorf("requests mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSSHSubsystem(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+
+	trans := &TransportSSH{Subsystem: "netconf-xml"}
+	if err := trans.Dial(srv.Addr(), testSSHConfig()); err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	s := NewSession(trans)
+	defer s.Close()
+
+	if _, err := s.Exec(MethodGetConfig("running")); err != nil {
+		t.Errorf("Exec failed: %v", err)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if diff := cmp.Diff([]string{"subsystem netconf-xml"}, srv.requests); diff != "" {
+		t.Errorf("requests mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSSHBanner(t *testing.T) {
+	srv := newTestSSHServer(t)
+	defer srv.Close()
+	banner := "Change freeze until Monday\n"
+	srv.mu.Lock()
+	srv.banner = banner
+	srv.mu.Unlock()
+
+	var seen []string
+	config := testSSHConfig()
+	config.BannerCallback = func(message string) error {
+		seen = append(seen, message)
+		return nil
+	}
+
+	s, err := DialSSH(srv.Addr(), config)
+	if err != nil {
+		t.Fatalf("DialSSH failed: %v", err)
+	}
+	defer s.Close()
+	if s.Banner() != banner {
+		t.Errorf("unexpected session banner: (want %q, got %q)", banner, s.Banner())
+	}
+	if diff := cmp.Diff([]string{banner}, seen); diff != "" {
+		t.Errorf("callback mismatch (-want +got):\n%s", diff)
+	}
+
+	conn, err := DialSSHConnection(srv.Addr(), testSSHConfig())
+	if err != nil {
+		t.Fatalf("DialSSHConnection failed: %v", err)
+	}
+	defer conn.Close()
+	shared, err := conn.NewSession()
+	if err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+	if shared.Banner() != banner {
+		t.Errorf("unexpected shared session banner: (want %q, got %q)", banner, shared.Banner())
+	}
+}
@@ -0,0 +1,59 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"fmt"
+	"net"
+)
+
+// TransportConn maintains the information necessary to communicate with a
+// NETCONF server that speaks the framing directly on a connection, without
+// SSH or TLS.
+//
+// Such connections are neither authenticated nor encrypted.  They are meant
+// for simulators, test servers and lab use only.
+type TransportConn struct {
+	transportBasicIO
+	conn net.Conn
+}
+
+// NewTransportConn returns a transport using an established connection.
+func NewTransportConn(conn net.Conn) *TransportConn {
+	t := &TransportConn{conn: conn}
+	t.ReadWriteCloser = conn
+	return t
+}
+
+// Close closes the connection.
+func (t *TransportConn) Close() error {
+	if t.conn == nil {
+		return fmt.Errorf("No connection to close")
+	}
+	return t.conn.Close()
+}
+
+// RemoteAddr returns the address of the server.
+func (t *TransportConn) RemoteAddr() net.Addr {
+	return t.conn.RemoteAddr()
+}
+
+// DialTCP creates a new NETCONF session over a plain TCP connection to
+// target, given as host:port.
+//
+// The connection is insecure: it is neither authenticated nor encrypted.
+// Use it for simulators and lab setups only.
+func DialTCP(target string) (*Session, error) {
+	if host, port := splitTarget(target); port != "" {
+		target = net.JoinHostPort(host, port)
+	}
+	conn, err := net.Dial("tcp", target)
+	if err != nil {
+		return nil, err
+	}
+	return NewSession(NewTransportConn(conn)), nil
+}
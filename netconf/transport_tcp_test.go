@@ -0,0 +1,89 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// serveTestNETCONF answers every RPC received on conn with <ok/>.
+func serveTestNETCONF(conn net.Conn) {
+	defer conn.Close()
+	tr := &transportBasicIO{ReadWriteCloser: conn}
+	tr.SendHello(&HelloMessage{Capabilities: []string{CapabilityBase10, CapabilityBase11}, SessionID: 7})
+	if _, err := tr.ReceiveHello(); err != nil {
+		return
+	}
+	tr.SetVersion("v1.1")
+	for {
+		if _, err := tr.Receive(); err != nil {
+			return
+		}
+		tr.Send([]byte(replyOK))
+	}
+}
+
+// listenTestNETCONF serves NETCONF on l until it is closed.
+func listenTestNETCONF(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go serveTestNETCONF(conn)
+	}
+}
+
+func TestDialTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go listenTestNETCONF(l)
+
+	s, err := DialTCP(l.Addr().String())
+	if err != nil {
+		t.Fatalf("DialTCP failed: %v", err)
+	}
+	defer s.Close()
+
+	if s.SessionID != 7 {
+		t.Errorf("expected session id 7, got %d", s.SessionID)
+	}
+	reply, err := s.Exec(MethodGetConfig("running"))
+	if err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if !reply.Ok {
+		t.Errorf("expected ok reply, got %q", reply.RawReply)
+	}
+}
+
+func TestSessionInfo(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go listenTestNETCONF(l)
+
+	s, err := DialTCP(l.Addr().String())
+	if err != nil {
+		t.Fatalf("DialTCP failed: %v", err)
+	}
+	defer s.Close()
+
+	got := []string{s.Version(), string(s.Framing()), s.TransportType(), s.RemoteAddr().String()}
+	want := []string{"1.1", "chunked", "tcp", l.Addr().String()}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("session info mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -206,3 +206,188 @@ func TestWaitForBytesEmpty(t *testing.T) {
 		t.Errorf("WaitForBytes should error on empty input!")
 	}
 }
+
+func TestReceiveMultipleMessages(t *testing.T) {
+	tt := []struct {
+		name    string
+		version string
+		input   string
+		want    []string
+	}{
+		{
+			name:    "eom",
+			version: "v1.0",
+			input:   "<rpc-reply>one</rpc-reply>]]>]]><rpc-reply>two</rpc-reply>]]>]]><notification>three</notification>]]>]]>",
+			want:    []string{"<rpc-reply>one</rpc-reply>", "<rpc-reply>two</rpc-reply>", "<notification>three</notification>"},
+		},
+		{
+			name:    "chunked",
+			version: "v1.1",
+			input:   "\n#4\nabcd\n##\n\n#3\nefg\n##\n",
+			want:    []string{"abcd", "efg"},
+		},
+		{
+			name:    "binarySafe",
+			version: "v1.1",
+			input:   "\n#9\na\r\n##\n#1\n\n#2\nbc\n##\n",
+			want:    []string{"a\r\n##\n#1\nbc"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, _ := newTransportTest(tc.input)
+			tr.SetVersion(tc.version)
+
+			var got []string
+			for range tc.want {
+				msg, err := tr.Receive()
+				if err != nil {
+					t.Fatalf("Receive failed: %v", err)
+				}
+				got = append(got, string(msg))
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("messages mismatch (-want +got):\n%s", diff)
+			}
+			if _, err := tr.Receive(); err == nil {
+				t.Errorf("expected error after last message")
+			}
+		})
+	}
+}
+
+// trickleReader returns its data one byte per read.
+type trickleReader struct {
+	data []byte
+}
+
+func (r *trickleReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	p[0] = r.data[0]
+	r.data = r.data[1:]
+	return 1, nil
+}
+
+func TestReceiveSplitDelimiter(t *testing.T) {
+	var tr transportTest
+	tr.ReadWriteCloser = newNilCloser(&trickleReader{[]byte("<a/>]]>]]><b/>]]>]]>")}, new(bytes.Buffer))
+
+	for _, want := range []string{"<a/>", "<b/>"} {
+		msg, err := tr.Receive()
+		if err != nil {
+			t.Fatalf("Receive failed: %v", err)
+		}
+		if string(msg) != want {
+			t.Errorf("expected %q, got %q", want, msg)
+		}
+	}
+}
+
+func TestSendChunked(t *testing.T) {
+	tt := []struct {
+		name      string
+		chunkSize int
+		input     string
+		expected  string
+	}{
+		{
+			name:     "single",
+			input:    "<rpc/>",
+			expected: "\n#6\n<rpc/>\n##\n",
+		},
+		{
+			name:      "split",
+			chunkSize: 4,
+			input:     "<rpc/>abcd",
+			expected:  "\n#4\n<rpc\n#4\n/>ab\n#2\ncd\n##\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			trans, out := newTransportTest("")
+			trans.SetVersion("v1.1")
+			trans.SetChunkSize(tc.chunkSize)
+			if err := trans.Send([]byte(tc.input)); err != nil {
+				t.Fatalf("Send failed: %v", err)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("unexpected result: (want %q, got %q)", tc.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestMessageWriterChunked(t *testing.T) {
+	trans, out := newTransportTest("")
+	trans.SetVersion("v1.1")
+	trans.SetChunkSize(4)
+
+	w := trans.MessageWriter()
+	for _, s := range []string{"<r", "pc/>", "abcdefghij", "k"} {
+		if _, err := io.WriteString(w, s); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	expected := "\n#4\n<rpc\n#4\n/>ab\n#4\ncdef\n#4\nghij\n#1\nk\n##\n"
+	if out.String() != expected {
+		t.Errorf("unexpected result: (want %q, got %q)", expected, out.String())
+	}
+}
+
+func TestParseHello(t *testing.T) {
+	tt := []struct {
+		name     string
+		input    string
+		expected *HelloMessage
+		err      bool
+	}{
+		{
+			name: "server",
+			input: xml.Header + `<hello xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><capabilities>
+<capability>
+  urn:ietf:params:netconf:base:1.1
+</capability>
+</capabilities><session-id>42</session-id></hello>]]>]]>`,
+			expected: &HelloMessage{
+				XMLName:      xml.Name{Space: "urn:ietf:params:xml:ns:netconf:base:1.0", Local: "hello"},
+				Capabilities: []string{"urn:ietf:params:netconf:base:1.1"},
+				SessionID:    42,
+			},
+		},
+		{
+			name:  "client",
+			input: `<hello xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><capabilities><capability>urn:ietf:params:netconf:base:1.0</capability></capabilities></hello>`,
+			expected: &HelloMessage{
+				XMLName:      xml.Name{Space: "urn:ietf:params:xml:ns:netconf:base:1.0", Local: "hello"},
+				Capabilities: []string{"urn:ietf:params:netconf:base:1.0"},
+			},
+		},
+		{name: "wrong namespace", input: `<hello xmlns="urn:x"/>`, err: true},
+		{name: "not a hello", input: `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"/>`, err: true},
+		{name: "truncated", input: `<hello xmlns="urn:ietf:params:xml:ns:netconf:base:1.0"><capabilities>`, err: true},
+	}
+	for _, tc := range tt {
+		hello, err := ParseHello([]byte(tc.input))
+		if tc.err {
+			if err == nil {
+				t.Errorf("%s: expected error", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if diff := cmp.Diff(tc.expected, hello); diff != "" {
+			t.Errorf("%s: hello mismatch (-want +got):\n%s", tc.name, diff)
+		}
+	}
+}
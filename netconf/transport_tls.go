@@ -0,0 +1,113 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+)
+
+// tlsDefaultPort is the default port of NETCONF over TLS (RFC 7589).
+const tlsDefaultPort = 6513
+
+// DialTLS creates a new NETCONF session over TLS (RFC 7589).
+//
+// target can be an IP address (e.g.) 172.16.1.1 which utlizes the default
+// NETCONF over TLS port of 6513.  Target can also specify a port with the
+// following format <host>:<port (e.g 172.16.1.1:6514)
+//
+// The client certificate is taken from config, typically through its
+// GetClientCertificate callback, see CertificateReloader.
+func DialTLS(target string, config *tls.Config) (*Session, error) {
+	return Dial(target, WithTLSConfig(config))
+}
+
+// CertificateReloader provides a client certificate read from files and
+// reloads it once the files change, so that certificates can be rotated
+// without restarting the application.  Sessions established afterwards
+// present the renewed certificate, while established sessions keep running
+// with the certificate they were set up with until they are closed.
+type CertificateReloader struct {
+	CertFile string
+	KeyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// NewCertificateReloader loads the certificate and key from the given PEM
+// files.
+func NewCertificateReloader(certFile, keyFile string) (*CertificateReloader, error) {
+	r := &CertificateReloader{CertFile: certFile, KeyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the certificate and key files.  The current certificate is
+// kept if they cannot be loaded.
+func (r *CertificateReloader) Reload() error {
+	modTime, err := r.filesModTime()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.CertFile, r.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mu.Unlock()
+	return nil
+}
+
+// GetClientCertificate returns the current certificate, reloading it first
+// if the files changed.  It is meant for tls.Config.GetClientCertificate.
+func (r *CertificateReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	current, loaded := r.cert, r.modTime
+	r.mu.Unlock()
+
+	if modTime, err := r.filesModTime(); err == nil && !modTime.Equal(loaded) {
+		// Files may be replaced one after the other; keep serving the
+		// current certificate until both are consistent again.
+		if err := r.Reload(); err != nil && current != nil {
+			return current, nil
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cert, nil
+}
+
+// TLSConfig returns a client configuration presenting the reloader's
+// certificate.
+func (r *CertificateReloader) TLSConfig() *tls.Config {
+	return &tls.Config{GetClientCertificate: r.GetClientCertificate}
+}
+
+// filesModTime returns the latest modification time of the files.
+func (r *CertificateReloader) filesModTime() (time.Time, error) {
+	var latest time.Time
+	for _, f := range []string{r.CertFile, r.KeyFile} {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest, nil
+}
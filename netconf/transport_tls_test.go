@@ -0,0 +1,166 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA issues certificates for tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// issue returns a PEM encoded certificate and key for name.
+func (ca *testCA) issue(t *testing.T, name string, serial int64) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// tlsCert returns a tls.Certificate for name.
+func (ca *testCA) tlsCert(t *testing.T, name string, serial int64) tls.Certificate {
+	certPEM, keyPEM := ca.issue(t, name, serial)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestDialTLSCertificateRotation(t *testing.T) {
+	ca := newTestCA(t)
+	dir, err := ioutil.TempDir("", "netconf-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	writeCert := func(serial int64, mtime time.Time) {
+		certPEM, keyPEM := ca.issue(t, "client", serial)
+		for f, data := range map[string][]byte{certFile: certPEM, keyFile: keyPEM} {
+			if err := ioutil.WriteFile(f, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+			os.Chtimes(f, mtime, mtime)
+		}
+	}
+	writeCert(10, time.Now().Add(-time.Minute))
+
+	serials := make(chan int64, 2)
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{ca.tlsCert(t, "server", 2)},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    ca.pool,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			tc := conn.(*tls.Conn)
+			if err := tc.Handshake(); err == nil {
+				serials <- tc.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+			}
+			go serveTestNETCONF(conn)
+		}
+	}()
+
+	reloader, err := NewCertificateReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewCertificateReloader failed: %v", err)
+	}
+	config := reloader.TLSConfig()
+	config.RootCAs = ca.pool
+
+	first, err := DialTLS(l.Addr().String(), config)
+	if err != nil {
+		t.Fatalf("DialTLS failed: %v", err)
+	}
+	defer first.Close()
+
+	writeCert(11, time.Now())
+	second, err := DialTLS(l.Addr().String(), config)
+	if err != nil {
+		t.Fatalf("DialTLS failed: %v", err)
+	}
+	defer second.Close()
+
+	if got := []int64{<-serials, <-serials}; got[0] != 10 || got[1] != 11 {
+		t.Errorf("expected certificates 10 and 11, got %v", got)
+	}
+	for _, s := range []*Session{first, second} {
+		if _, err := s.Exec(MethodGetConfig("running")); err != nil {
+			t.Errorf("Exec failed: %v", err)
+		}
+	}
+}
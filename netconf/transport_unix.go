@@ -0,0 +1,20 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import "net"
+
+// DialUnix creates a new NETCONF session over the unix domain socket at
+// path, as exposed locally by several containerized network OS images.
+// Access is controlled by the permissions of the socket.
+func DialUnix(path string) (*Session, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return NewSession(NewTransportConn(conn)), nil
+}
@@ -0,0 +1,233 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Webhook payload formats.
+const (
+	// WebhookJSON posts a JSON array of objects with the eventTime and the
+	// event XML of each notification.
+	WebhookJSON = "json"
+	// WebhookXML posts the notifications as received, wrapped in a
+	// <notifications> element.
+	WebhookXML = "xml"
+)
+
+// WebhookForwarder posts notifications to an HTTP endpoint, in batches and
+// with retries, bridging NETCONF events to systems that only speak HTTP.
+type WebhookForwarder struct {
+	URL string
+	// Client is used for the requests, http.DefaultClient if nil.
+	Client *http.Client
+	// Format is WebhookJSON or WebhookXML, WebhookJSON if empty.
+	Format string
+	// Header holds additional request headers, e.g. for authentication.
+	Header http.Header
+
+	// BatchSize is the maximum number of notifications per request, 1 if
+	// zero.
+	BatchSize int
+	// BatchInterval bounds the time a notification waits for its batch to
+	// fill up.  Zero sends incomplete batches as soon as no further
+	// notification is pending.
+	BatchInterval time.Duration
+
+	// MaxAttempts is the number of attempts per batch, including the first,
+	// 1 if zero.  Network errors and 5xx responses are retried.
+	MaxAttempts int
+	// Backoff returns the delay before the given retry, see RetryPolicy.
+	Backoff func(retry int) time.Duration
+	// OnError, if set, is called with batches that could not be delivered.
+	// Run does not stop on delivery errors.
+	OnError func(batch []*Notification, err error)
+	// FlushTimeout bounds the delivery of the partial batch sent once the
+	// context of Run is done, DefaultWebhookFlushTimeout if zero.
+	FlushTimeout time.Duration
+}
+
+// DefaultWebhookFlushTimeout is the default of WebhookForwarder.FlushTimeout.
+const DefaultWebhookFlushTimeout = 10 * time.Second
+
+// WebhookError is returned for requests the endpoint answered with an
+// unexpected status.
+type WebhookError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *WebhookError) Error() string {
+	return fmt.Sprintf("netconf: webhook responded with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Run forwards the notifications received from c, such as the channel of a
+// Subscription, until c is closed or ctx is done.  A partial batch is sent
+// before Run returns.
+func (f *WebhookForwarder) Run(ctx context.Context, c <-chan *Notification) error {
+	size := f.BatchSize
+	if size <= 0 {
+		size = 1
+	}
+
+	var batch []*Notification
+	var timeout <-chan time.Time
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := f.Send(ctx, batch); err != nil && f.OnError != nil {
+			f.OnError(batch, err)
+		}
+		batch, timeout = nil, nil
+	}
+
+	for {
+		// Without an interval, take what is pending and send it.
+		if len(batch) > 0 && f.BatchInterval <= 0 {
+			select {
+			case n, ok := <-c:
+				if !ok {
+					flush(ctx)
+					return nil
+				}
+				batch = append(batch, n)
+			default:
+				flush(ctx)
+			}
+			if len(batch) >= size {
+				flush(ctx)
+			}
+			continue
+		}
+
+		select {
+		case n, ok := <-c:
+			if !ok {
+				flush(ctx)
+				return nil
+			}
+			batch = append(batch, n)
+			if len(batch) == 1 && f.BatchInterval > 0 {
+				timeout = time.After(f.BatchInterval)
+			}
+			if len(batch) >= size {
+				flush(ctx)
+			}
+		case <-timeout:
+			flush(ctx)
+		case <-ctx.Done():
+			// ctx can no longer carry the last batch.
+			timeout := f.FlushTimeout
+			if timeout <= 0 {
+				timeout = DefaultWebhookFlushTimeout
+			}
+			fctx, cancel := context.WithTimeout(context.Background(), timeout)
+			flush(fctx)
+			cancel()
+			return ctx.Err()
+		}
+	}
+}
+
+// Publish posts a single notification, making the forwarder a
+// NotificationSink.  Batching only applies to Run.
+func (f *WebhookForwarder) Publish(ctx context.Context, n *Notification) error {
+	return f.Send(ctx, []*Notification{n})
+}
+
+// Send posts a batch of notifications, retrying as configured.
+func (f *WebhookForwarder) Send(ctx context.Context, batch []*Notification) error {
+	body, contentType, err := f.encode(batch)
+	if err != nil {
+		return err
+	}
+
+	attempts := f.MaxAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	backoff := f.Backoff
+	if backoff == nil {
+		backoff = defaultBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		var retry bool
+		retry, err = f.post(ctx, body, contentType)
+		if err == nil || !retry || attempt >= attempts {
+			return err
+		}
+
+		timer := time.NewTimer(backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// post sends a single request and reports whether a failure may be retried.
+func (f *WebhookForwarder) post(ctx context.Context, body []byte, contentType string) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, f.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range f.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(ioutil.Discard, resp.Body)
+		return false, nil
+	}
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+	return resp.StatusCode >= 500, &WebhookError{StatusCode: resp.StatusCode, Body: string(msg)}
+}
+
+func (f *WebhookForwarder) encode(batch []*Notification) ([]byte, string, error) {
+	switch f.Format {
+	case "", WebhookJSON:
+		events := make([]jsonNotification, 0, len(batch))
+		for _, n := range batch {
+			events = append(events, jsonNotification{EventTime: n.EventTime, Event: n.Event.String()})
+		}
+		body, err := json.Marshal(events)
+		return body, "application/json", err
+	case WebhookXML:
+		var buf bytes.Buffer
+		buf.WriteString("<notifications>")
+		for _, n := range batch {
+			buf.Write(n.Raw)
+		}
+		buf.WriteString("</notifications>")
+		return buf.Bytes(), "application/xml", nil
+	}
+	return nil, "", fmt.Errorf("netconf: unknown webhook format %q", f.Format)
+}
@@ -0,0 +1,154 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWebhookForwarder(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	failures := 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures > 0 {
+			failures--
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer x" || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "bad headers", http.StatusBadRequest)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+	}))
+	defer srv.Close()
+
+	f := &WebhookForwarder{
+		URL:         srv.URL,
+		Header:      http.Header{"Authorization": {"Bearer x"}},
+		BatchSize:   2,
+		MaxAttempts: 2,
+		Backoff:     func(int) time.Duration { return 0 },
+		OnError: func(batch []*Notification, err error) {
+			t.Errorf("unexpected delivery error: %v", err)
+		},
+	}
+
+	c := make(chan *Notification, 3)
+	for i := 1; i <= 3; i++ {
+		n, err := ParseNotification([]byte(testNotification(i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c <- n
+	}
+	close(c)
+
+	if err := f.Run(context.Background(), c); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var got [][]jsonNotification
+	for _, b := range bodies {
+		var events []jsonNotification
+		if err := json.Unmarshal([]byte(b), &events); err != nil {
+			t.Fatalf("invalid body %s: %v", b, err)
+		}
+		got = append(got, events)
+	}
+	event := func(i int) jsonNotification {
+		n, _ := ParseNotification([]byte(testNotification(i)))
+		return jsonNotification{EventTime: n.EventTime, Event: n.Event.String()}
+	}
+	expected := [][]jsonNotification{{event(1), event(2)}, {event(3)}}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("batches mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWebhookForwarderXML(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer srv.Close()
+
+	n, _ := ParseNotification([]byte(testNotification(1)))
+	f := &WebhookForwarder{URL: srv.URL, Format: WebhookXML}
+	if err := f.Send(context.Background(), []*Notification{n}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if want := "<notifications>" + testNotification(1) + "</notifications>"; body != want {
+		t.Errorf("unexpected body: (want %q, got %q)", want, body)
+	}
+}
+
+func TestWebhookForwarderClientError(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "nope", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	f := &WebhookForwarder{URL: srv.URL, MaxAttempts: 3, Backoff: func(int) time.Duration { return 0 }}
+	err := f.Send(context.Background(), []*Notification{{}})
+	if werr, ok := err.(*WebhookError); !ok || werr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected WebhookError with status 400, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected client errors not to be retried, got %d requests", requests)
+	}
+}
+
+func TestWebhookForwarderFlushOnCancel(t *testing.T) {
+	received := make(chan int, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var events []json.RawMessage
+		json.NewDecoder(r.Body).Decode(&events)
+		received <- len(events)
+	}))
+	defer srv.Close()
+
+	f := &WebhookForwarder{URL: srv.URL, BatchSize: 10, BatchInterval: time.Hour}
+	c := make(chan *Notification, 2)
+	c <- &Notification{EventTime: time.Now(), Event: RawXML("<a/>")}
+	c <- &Notification{EventTime: time.Now(), Event: RawXML("<b/>")}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Run(ctx, c) }()
+	for len(c) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	if err := <-done; err != context.Canceled {
+		t.Errorf("got %v, expected context.Canceled", err)
+	}
+	select {
+	case n := <-received:
+		if n != 2 {
+			t.Errorf("got a batch of %d, expected 2", n)
+		}
+	default:
+		t.Error("partial batch not sent after cancellation")
+	}
+}
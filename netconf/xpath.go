@@ -0,0 +1,256 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// xpathStep is a location step of a path expression.
+type xpathStep struct {
+	// descendant selects descendants rather than children.
+	descendant bool
+	// name is a local name, "*" for any element or "." for the context
+	// node.
+	name  string
+	preds []xpathPred
+}
+
+// xpathPred is a predicate of a location step.
+type xpathPred struct {
+	// index, if positive, selects the node at that position.
+	index int
+	// path is evaluated relative to the node, "." if empty.
+	path []xpathStep
+	// op is "", "=" or "!=".  Without an operator path must select a node.
+	op    string
+	value string
+}
+
+// Select returns the nodes selected by the path expression expr, which is
+// evaluated with n as the document root, so that /a selects the children of
+// n named a.  A subset of XPath is supported: child and descendant (//)
+// steps, * and . , and predicates of the form [2], [name], [name='x'],
+// [.='x'] and [name!='x'].  Prefixes are ignored and names match by local
+// name.
+func (n *Node) Select(expr string) ([]*Node, error) {
+	steps, err := parseXPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return evalXPath([]*Node{n}, steps), nil
+}
+
+func parseXPath(expr string) ([]xpathStep, error) {
+	p := &xpathParser{s: expr}
+	steps, err := p.steps(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.i < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.i:])
+	}
+	if len(steps) == 0 {
+		return nil, p.errorf("empty path")
+	}
+	return steps, nil
+}
+
+type xpathParser struct {
+	s string
+	i int
+}
+
+func (p *xpathParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("xpath %q: offset %d: %s", p.s, p.i, fmt.Sprintf(format, args...))
+}
+
+func (p *xpathParser) skipSpace() {
+	for p.i < len(p.s) && p.s[p.i] == ' ' {
+		p.i++
+	}
+}
+
+// steps parses a location path.  Within a predicate the path is relative
+// and ends at an operator or the closing bracket.
+func (p *xpathParser) steps(relative bool) ([]xpathStep, error) {
+	var steps []xpathStep
+	for p.i < len(p.s) {
+		var st xpathStep
+		switch {
+		case strings.HasPrefix(p.s[p.i:], "//"):
+			st.descendant = true
+			p.i += 2
+		case p.s[p.i] == '/':
+			if relative && len(steps) == 0 {
+				return nil, p.errorf("absolute path in predicate")
+			}
+			p.i++
+		case len(steps) > 0:
+			return steps, nil
+		}
+
+		start := p.i
+		for p.i < len(p.s) && !strings.ContainsRune("/[]=! ", rune(p.s[p.i])) {
+			p.i++
+		}
+		st.name = p.s[start:p.i]
+		if j := strings.LastIndexByte(st.name, ':'); j >= 0 {
+			st.name = st.name[j+1:]
+		}
+		if st.name == "" {
+			return nil, p.errorf("missing name")
+		}
+
+		for p.i < len(p.s) && p.s[p.i] == '[' {
+			p.i++
+			pred, err := p.predicate()
+			if err != nil {
+				return nil, err
+			}
+			st.preds = append(st.preds, pred)
+		}
+		steps = append(steps, st)
+	}
+	return steps, nil
+}
+
+func (p *xpathParser) predicate() (xpathPred, error) {
+	var pred xpathPred
+	p.skipSpace()
+	if end := strings.IndexByte(p.s[p.i:], ']'); end > 0 {
+		if n, err := strconv.Atoi(strings.TrimSpace(p.s[p.i : p.i+end])); err == nil {
+			if n < 1 {
+				return pred, p.errorf("invalid position %d", n)
+			}
+			pred.index = n
+			p.i += end + 1
+			return pred, nil
+		}
+	}
+
+	path, err := p.steps(true)
+	if err != nil {
+		return pred, err
+	}
+	if len(path) != 1 || path[0].name != "." || path[0].descendant || len(path[0].preds) > 0 {
+		pred.path = path
+	}
+	p.skipSpace()
+	switch {
+	case strings.HasPrefix(p.s[p.i:], "!="):
+		pred.op = "!="
+		p.i += 2
+	case strings.HasPrefix(p.s[p.i:], "="):
+		pred.op = "="
+		p.i++
+	}
+	if pred.op != "" {
+		p.skipSpace()
+		if pred.value, err = p.literal(); err != nil {
+			return pred, err
+		}
+		p.skipSpace()
+	}
+	if p.i >= len(p.s) || p.s[p.i] != ']' {
+		return pred, p.errorf("expected ]")
+	}
+	p.i++
+	return pred, nil
+}
+
+// literal parses a quoted string or a number.
+func (p *xpathParser) literal() (string, error) {
+	if p.i < len(p.s) && (p.s[p.i] == '\'' || p.s[p.i] == '"') {
+		end := strings.IndexByte(p.s[p.i+1:], p.s[p.i])
+		if end < 0 {
+			return "", p.errorf("unterminated string")
+		}
+		v := p.s[p.i+1 : p.i+1+end]
+		p.i += end + 2
+		return v, nil
+	}
+	start := p.i
+	for p.i < len(p.s) && p.s[p.i] != ']' && p.s[p.i] != ' ' {
+		p.i++
+	}
+	if _, err := strconv.ParseFloat(p.s[start:p.i], 64); err != nil {
+		return "", p.errorf("expected a string or number")
+	}
+	return p.s[start:p.i], nil
+}
+
+func evalXPath(context []*Node, steps []xpathStep) []*Node {
+	for _, st := range steps {
+		var next []*Node
+		seen := make(map[*Node]bool)
+		for _, n := range context {
+			var candidates []*Node
+			switch {
+			case st.name == ".":
+				candidates = []*Node{n}
+			case st.descendant:
+				candidates = descendants(n, st.name, nil)
+			default:
+				for _, c := range n.Children {
+					if st.name == "*" || c.XMLName.Local == st.name {
+						candidates = append(candidates, c)
+					}
+				}
+			}
+			for _, pred := range st.preds {
+				candidates = pred.filter(candidates)
+			}
+			for _, c := range candidates {
+				if !seen[c] {
+					seen[c] = true
+					next = append(next, c)
+				}
+			}
+		}
+		context = next
+	}
+	return context
+}
+
+// descendants appends the descendants of n with the given local name, or
+// all of them for "*", in document order.
+func descendants(n *Node, name string, nodes []*Node) []*Node {
+	for _, c := range n.Children {
+		if name == "*" || c.XMLName.Local == name {
+			nodes = append(nodes, c)
+		}
+		nodes = descendants(c, name, nodes)
+	}
+	return nodes
+}
+
+func (pred xpathPred) filter(nodes []*Node) []*Node {
+	if pred.index > 0 {
+		if pred.index > len(nodes) {
+			return nil
+		}
+		return nodes[pred.index-1 : pred.index]
+	}
+
+	var matched []*Node
+	for _, n := range nodes {
+		selected := []*Node{n}
+		if pred.path != nil {
+			selected = evalXPath(selected, pred.path)
+		}
+		for _, s := range selected {
+			if pred.op == "" || (pred.op == "=") == (s.Value() == pred.value) {
+				matched = append(matched, n)
+				break
+			}
+		}
+	}
+	return matched
+}
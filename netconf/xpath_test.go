@@ -0,0 +1,71 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNodeSelect(t *testing.T) {
+	root, err := configRoot([]byte(`<data><system xmlns="urn:example:system">
+<host-name>r1</host-name>
+<ntp><server><name>a</name><prefer>true</prefer></server><server><name>b</name></server></ntp>
+<user><name>admin</name><class>super-user</class></user>
+</system></data>`))
+	if err != nil {
+		t.Fatalf("configRoot failed: %v", err)
+	}
+
+	tt := []struct {
+		expr     string
+		expected []string
+		err      bool
+	}{
+		{expr: "/system/host-name", expected: []string{"r1"}},
+		{expr: "/sys:system/sys:host-name", expected: []string{"r1"}},
+		{expr: "system/ntp/server/name", expected: []string{"a", "b"}},
+		{expr: "//name", expected: []string{"a", "b", "admin"}},
+		{expr: "/system/ntp/server[2]/name", expected: []string{"b"}},
+		{expr: "/system/ntp/server[name='b']/name", expected: []string{"b"}},
+		{expr: `/system/ntp/server[name!="b"]/name`, expected: []string{"a"}},
+		{expr: "/system/ntp/server[prefer]/name", expected: []string{"a"}},
+		{expr: "//server/name[.='a']", expected: []string{"a"}},
+		{expr: "/system/*/server[ name = 'a' ][1]/name", expected: []string{"a"}},
+		{expr: "/system/user[class='operator']"},
+		{expr: "/system/missing"},
+		{expr: "", err: true},
+		{expr: "/system[", err: true},
+		{expr: "/system[name='a]", err: true},
+		{expr: "/system[0]", err: true},
+		{expr: "/system[/a]", err: true},
+		{expr: "/system[name=a]", err: true},
+		{expr: "/system/", err: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.expr, func(t *testing.T) {
+			nodes, err := root.Select(tc.expr)
+			if tc.err {
+				if err == nil {
+					t.Errorf("expected error, got %d nodes", len(nodes))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Select failed: %v", err)
+			}
+			var values []string
+			for _, n := range nodes {
+				values = append(values, n.Value())
+			}
+			if diff := cmp.Diff(tc.expected, values); diff != "" {
+				t.Errorf("values mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
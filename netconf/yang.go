@@ -0,0 +1,276 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// YANGStatement is a statement of a YANG module: a keyword, an optional
+// argument and substatements.  ParseYANG checks the syntax of a module but
+// not its semantics.
+type YANGStatement struct {
+	Keyword       string
+	Argument      string
+	Substatements []*YANGStatement
+}
+
+// Sub returns the first substatement with the given keyword, or nil.
+func (st *YANGStatement) Sub(keyword string) *YANGStatement {
+	for _, sub := range st.Substatements {
+		if sub.Keyword == keyword {
+			return sub
+		}
+	}
+	return nil
+}
+
+// ParseYANG parses the text of a YANG module or submodule.
+func ParseYANG(data []byte) (*YANGStatement, error) {
+	p := &yangParser{src: string(data), line: 1}
+	stmts, err := p.statements()
+	if err != nil {
+		return nil, err
+	}
+	if tok, err := p.next(); err != nil {
+		return nil, err
+	} else if tok != "" {
+		return nil, p.errorf("unexpected %q", tok)
+	}
+	if len(stmts) != 1 || (stmts[0].Keyword != "module" && stmts[0].Keyword != "submodule") {
+		return nil, fmt.Errorf("netconf: yang: expected a single module or submodule")
+	}
+	return stmts[0], nil
+}
+
+type yangParser struct {
+	src    string
+	pos    int
+	line   int
+	quoted bool
+}
+
+func (p *yangParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("netconf: yang: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+// statements parses statements up to a closing brace or the end of input.
+func (p *yangParser) statements() ([]*YANGStatement, error) {
+	var stmts []*YANGStatement
+	for {
+		keyword, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if keyword == "" || (keyword == "}" && !p.quoted) {
+			if keyword == "}" {
+				p.pos--
+			}
+			return stmts, nil
+		}
+		if !p.quoted && (keyword == "{" || keyword == ";") {
+			return nil, p.errorf("unexpected %q", keyword)
+		}
+
+		st := &YANGStatement{Keyword: keyword}
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if tok == "}" && !p.quoted {
+			return nil, p.errorf("expected ; or { after %s", keyword)
+		}
+		if tok != ";" && tok != "{" || p.quoted {
+			st.Argument = tok
+			if tok, err = p.next(); err != nil {
+				return nil, err
+			}
+		}
+		switch tok {
+		case ";":
+		case "{":
+			if st.Substatements, err = p.statements(); err != nil {
+				return nil, err
+			}
+			if tok, err = p.next(); err != nil {
+				return nil, err
+			}
+			if tok != "}" {
+				return nil, p.errorf("missing } for %s", keyword)
+			}
+		default:
+			return nil, p.errorf("expected ; or { after %s", keyword)
+		}
+		stmts = append(stmts, st)
+	}
+}
+
+// next returns the next token, "" at the end of input.  Quoted strings,
+// including concatenations with +, are returned unquoted with p.quoted set.
+func (p *yangParser) next() (string, error) {
+	p.quoted = false
+	if err := p.skip(); err != nil {
+		return "", err
+	}
+	if p.pos >= len(p.src) {
+		return "", nil
+	}
+
+	switch c := p.src[p.pos]; c {
+	case ';', '{', '}':
+		p.pos++
+		return string(c), nil
+	case '"', '\'':
+		var b strings.Builder
+		for {
+			s, err := p.quotedString()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(s)
+			// Strings may be concatenated with +.
+			save, saveLine := p.pos, p.line
+			if err := p.skip(); err != nil {
+				return "", err
+			}
+			if p.pos < len(p.src) && p.src[p.pos] == '+' {
+				p.pos++
+				if err := p.skip(); err != nil {
+					return "", err
+				}
+				if p.pos < len(p.src) && (p.src[p.pos] == '"' || p.src[p.pos] == '\'') {
+					continue
+				}
+				return "", p.errorf("expected string after +")
+			}
+			p.pos, p.line = save, saveLine
+			p.quoted = true
+			return b.String(), nil
+		}
+	}
+
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ';' || c == '{' || c == '}' {
+			break
+		}
+		if strings.HasPrefix(p.src[p.pos:], "//") || strings.HasPrefix(p.src[p.pos:], "/*") {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos], nil
+}
+
+func (p *yangParser) quotedString() (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		p.pos++
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\n':
+			p.line++
+		case c == '\\' && quote == '"' && p.pos < len(p.src):
+			switch e := p.src[p.pos]; e {
+			case 'n':
+				c = '\n'
+			case 't':
+				c = '\t'
+			default:
+				c = e
+			}
+			p.pos++
+		}
+		b.WriteByte(c)
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// skip skips whitespace and comments.
+func (p *yangParser) skip() error {
+	for p.pos < len(p.src) {
+		switch {
+		case p.src[p.pos] == '\n':
+			p.line++
+			p.pos++
+		case p.src[p.pos] == ' ' || p.src[p.pos] == '\t' || p.src[p.pos] == '\r':
+			p.pos++
+		case strings.HasPrefix(p.src[p.pos:], "//"):
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		case strings.HasPrefix(p.src[p.pos:], "/*"):
+			end := strings.Index(p.src[p.pos+2:], "*/")
+			if end < 0 {
+				return p.errorf("unterminated comment")
+			}
+			p.line += strings.Count(p.src[p.pos:p.pos+2+end], "\n")
+			p.pos += end + 4
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// yangSchemaNodes are the statements defining data nodes, as opposed to
+// choice and case, which do not appear in instance data.
+var yangSchemaNodes = map[string]bool{
+	"container": true,
+	"list":      true,
+	"leaf":      true,
+	"leaf-list": true,
+}
+
+// YANGDefaults returns the default values of the leaves defined by module,
+// keyed by schema path such as /interfaces/interface/mtu, for use as
+// DiffOptions.Defaults.  Groupings are expanded where they are used within
+// the same module; augments and imported groupings are not resolved.
+func YANGDefaults(module *YANGStatement) map[string]string {
+	groupings := make(map[string]*YANGStatement)
+	for _, st := range module.Substatements {
+		if st.Keyword == "grouping" {
+			groupings[st.Argument] = st
+		}
+	}
+	defaults := make(map[string]string)
+	yangDefaults(module, "", groupings, defaults, 0)
+	return defaults
+}
+
+func yangDefaults(st *YANGStatement, path string, groupings map[string]*YANGStatement, defaults map[string]string, depth int) {
+	// Recursive groupings are invalid YANG; stop rather than loop.
+	if depth > 64 {
+		return
+	}
+	for _, sub := range st.Substatements {
+		switch {
+		case sub.Keyword == "leaf":
+			if d := sub.Sub("default"); d != nil {
+				defaults[path+"/"+sub.Argument] = d.Argument
+			}
+		case yangSchemaNodes[sub.Keyword]:
+			yangDefaults(sub, path+"/"+sub.Argument, groupings, defaults, depth+1)
+		case sub.Keyword == "choice" || sub.Keyword == "case":
+			yangDefaults(sub, path, groupings, defaults, depth+1)
+		case sub.Keyword == "uses":
+			name := sub.Argument
+			if i := strings.IndexByte(name, ':'); i >= 0 {
+				name = name[i+1:]
+			}
+			if g, ok := groupings[name]; ok {
+				yangDefaults(g, path, groupings, defaults, depth+1)
+			}
+		}
+	}
+}
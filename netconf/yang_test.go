@@ -0,0 +1,71 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconf
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testYANGModule = `module example {
+  namespace "urn:example";
+  prefix ex;
+  description "An example " +
+    'module'; // with a comment
+
+  /* groupings are expanded */
+  grouping mtu {
+    leaf mtu { type uint16; default 1500; }
+  }
+
+  container interfaces {
+    list interface {
+      key name;
+      leaf name { type string; }
+      uses ex:mtu;
+      choice mode {
+        case access { leaf vlan { type uint16; default "1"; } }
+      }
+      leaf-list tag { type string; }
+    }
+  }
+}
+`
+
+func TestParseYANG(t *testing.T) {
+	module, err := ParseYANG([]byte(testYANGModule))
+	if err != nil {
+		t.Fatalf("ParseYANG failed: %v", err)
+	}
+	if module.Argument != "example" || module.Sub("namespace").Argument != "urn:example" {
+		t.Errorf("unexpected module %s %+v", module.Argument, module.Sub("namespace"))
+	}
+	if d := module.Sub("description").Argument; d != "An example module" {
+		t.Errorf("got description %q", d)
+	}
+
+	expected := map[string]string{
+		"/interfaces/interface/mtu":  "1500",
+		"/interfaces/interface/vlan": "1",
+	}
+	if diff := cmp.Diff(expected, YANGDefaults(module)); diff != "" {
+		t.Errorf("defaults mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, bad := range []string{
+		"module a { leaf b; ",
+		"module a { } }",
+		`module a { description "open; }`,
+		"module a { leaf }",
+		"container a;",
+	} {
+		if _, err := ParseYANG([]byte(bad)); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
@@ -0,0 +1,653 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: proto/netconf/v1/netconf.proto
+
+package netconfv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Filter struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// type is "subtree" or "xpath".
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// content is the XML of a subtree filter.
+	Content string `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	// select is the expression of an XPath filter.
+	Select        string            `protobuf:"bytes,3,opt,name=select,proto3" json:"select,omitempty"`
+	Namespaces    map[string]string `protobuf:"bytes,4,rep,name=namespaces,proto3" json:"namespaces,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Filter) Reset() {
+	*x = Filter{}
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Filter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Filter) ProtoMessage() {}
+
+func (x *Filter) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Filter.ProtoReflect.Descriptor instead.
+func (*Filter) Descriptor() ([]byte, []int) {
+	return file_proto_netconf_v1_netconf_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Filter) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Filter) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Filter) GetSelect() string {
+	if x != nil {
+		return x.Select
+	}
+	return ""
+}
+
+func (x *Filter) GetNamespaces() map[string]string {
+	if x != nil {
+		return x.Namespaces
+	}
+	return nil
+}
+
+type GetConfigRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Device string                 `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	// source is the datastore, "running" if empty.
+	Source        string  `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Filter        *Filter `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConfigRequest) Reset() {
+	*x = GetConfigRequest{}
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConfigRequest) ProtoMessage() {}
+
+func (x *GetConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetConfigRequest) Descriptor() ([]byte, []int) {
+	return file_proto_netconf_v1_netconf_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetConfigRequest) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *GetConfigRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *GetConfigRequest) GetFilter() *Filter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type GetConfigResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// data holds the XML of the <data> element.
+	Data          string `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConfigResponse) Reset() {
+	*x = GetConfigResponse{}
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConfigResponse) ProtoMessage() {}
+
+func (x *GetConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConfigResponse.ProtoReflect.Descriptor instead.
+func (*GetConfigResponse) Descriptor() ([]byte, []int) {
+	return file_proto_netconf_v1_netconf_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetConfigResponse) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+type EditConfigRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Device string                 `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	Target string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	// config is the content of the <config> element.
+	Config        string `protobuf:"bytes,3,opt,name=config,proto3" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EditConfigRequest) Reset() {
+	*x = EditConfigRequest{}
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditConfigRequest) ProtoMessage() {}
+
+func (x *EditConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditConfigRequest.ProtoReflect.Descriptor instead.
+func (*EditConfigRequest) Descriptor() ([]byte, []int) {
+	return file_proto_netconf_v1_netconf_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *EditConfigRequest) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *EditConfigRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *EditConfigRequest) GetConfig() string {
+	if x != nil {
+		return x.Config
+	}
+	return ""
+}
+
+type EditConfigResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EditConfigResponse) Reset() {
+	*x = EditConfigResponse{}
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditConfigResponse) ProtoMessage() {}
+
+func (x *EditConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditConfigResponse.ProtoReflect.Descriptor instead.
+func (*EditConfigResponse) Descriptor() ([]byte, []int) {
+	return file_proto_netconf_v1_netconf_proto_rawDescGZIP(), []int{4}
+}
+
+type ExecRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Device string                 `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	// rpc is the XML of the operation, without the <rpc> element.
+	Rpc           string `protobuf:"bytes,2,opt,name=rpc,proto3" json:"rpc,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecRequest) Reset() {
+	*x = ExecRequest{}
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecRequest) ProtoMessage() {}
+
+func (x *ExecRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecRequest.ProtoReflect.Descriptor instead.
+func (*ExecRequest) Descriptor() ([]byte, []int) {
+	return file_proto_netconf_v1_netconf_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ExecRequest) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *ExecRequest) GetRpc() string {
+	if x != nil {
+		return x.Rpc
+	}
+	return ""
+}
+
+type ExecResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// reply is the raw <rpc-reply>.  RPC errors reported by the device are
+	// returned as gRPC errors.
+	Reply         string `protobuf:"bytes,1,opt,name=reply,proto3" json:"reply,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecResponse) Reset() {
+	*x = ExecResponse{}
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecResponse) ProtoMessage() {}
+
+func (x *ExecResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecResponse.ProtoReflect.Descriptor instead.
+func (*ExecResponse) Descriptor() ([]byte, []int) {
+	return file_proto_netconf_v1_netconf_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ExecResponse) GetReply() string {
+	if x != nil {
+		return x.Reply
+	}
+	return ""
+}
+
+type SubscribeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Device        string                 `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	Stream        string                 `protobuf:"bytes,2,opt,name=stream,proto3" json:"stream,omitempty"`
+	Filter        *Filter                `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	StopTime      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=stop_time,json=stopTime,proto3" json:"stop_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_netconf_v1_netconf_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SubscribeRequest) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *SubscribeRequest) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *SubscribeRequest) GetFilter() *Filter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *SubscribeRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *SubscribeRequest) GetStopTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StopTime
+	}
+	return nil
+}
+
+type Notification struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	EventTime *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=event_time,json=eventTime,proto3" json:"event_time,omitempty"`
+	// event is the XML of the event.
+	Event         string `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Notification) Reset() {
+	*x = Notification{}
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Notification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Notification) ProtoMessage() {}
+
+func (x *Notification) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_netconf_v1_netconf_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Notification.ProtoReflect.Descriptor instead.
+func (*Notification) Descriptor() ([]byte, []int) {
+	return file_proto_netconf_v1_netconf_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Notification) GetEventTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EventTime
+	}
+	return nil
+}
+
+func (x *Notification) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+var File_proto_netconf_v1_netconf_proto protoreflect.FileDescriptor
+
+const file_proto_netconf_v1_netconf_proto_rawDesc = "" +
+	"\n" +
+	"\x1eproto/netconf/v1/netconf.proto\x12\n" +
+	"netconf.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd1\x01\n" +
+	"\x06Filter\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x16\n" +
+	"\x06select\x18\x03 \x01(\tR\x06select\x12B\n" +
+	"\n" +
+	"namespaces\x18\x04 \x03(\v2\".netconf.v1.Filter.NamespacesEntryR\n" +
+	"namespaces\x1a=\n" +
+	"\x0fNamespacesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"n\n" +
+	"\x10GetConfigRequest\x12\x16\n" +
+	"\x06device\x18\x01 \x01(\tR\x06device\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12*\n" +
+	"\x06filter\x18\x03 \x01(\v2\x12.netconf.v1.FilterR\x06filter\"'\n" +
+	"\x11GetConfigResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\tR\x04data\"[\n" +
+	"\x11EditConfigRequest\x12\x16\n" +
+	"\x06device\x18\x01 \x01(\tR\x06device\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\x12\x16\n" +
+	"\x06config\x18\x03 \x01(\tR\x06config\"\x14\n" +
+	"\x12EditConfigResponse\"7\n" +
+	"\vExecRequest\x12\x16\n" +
+	"\x06device\x18\x01 \x01(\tR\x06device\x12\x10\n" +
+	"\x03rpc\x18\x02 \x01(\tR\x03rpc\"$\n" +
+	"\fExecResponse\x12\x14\n" +
+	"\x05reply\x18\x01 \x01(\tR\x05reply\"\xe2\x01\n" +
+	"\x10SubscribeRequest\x12\x16\n" +
+	"\x06device\x18\x01 \x01(\tR\x06device\x12\x16\n" +
+	"\x06stream\x18\x02 \x01(\tR\x06stream\x12*\n" +
+	"\x06filter\x18\x03 \x01(\v2\x12.netconf.v1.FilterR\x06filter\x129\n" +
+	"\n" +
+	"start_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x127\n" +
+	"\tstop_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bstopTime\"_\n" +
+	"\fNotification\x129\n" +
+	"\n" +
+	"event_time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\teventTime\x12\x14\n" +
+	"\x05event\x18\x02 \x01(\tR\x05event2\xa2\x02\n" +
+	"\aNetconf\x12H\n" +
+	"\tGetConfig\x12\x1c.netconf.v1.GetConfigRequest\x1a\x1d.netconf.v1.GetConfigResponse\x12K\n" +
+	"\n" +
+	"EditConfig\x12\x1d.netconf.v1.EditConfigRequest\x1a\x1e.netconf.v1.EditConfigResponse\x129\n" +
+	"\x04Exec\x12\x17.netconf.v1.ExecRequest\x1a\x18.netconf.v1.ExecResponse\x12E\n" +
+	"\tSubscribe\x12\x1c.netconf.v1.SubscribeRequest\x1a\x18.netconf.v1.Notification0\x01BFZDgithub.com/Juniper/go-netconf/netconfgrpc/proto/netconf/v1;netconfv1b\x06proto3"
+
+var (
+	file_proto_netconf_v1_netconf_proto_rawDescOnce sync.Once
+	file_proto_netconf_v1_netconf_proto_rawDescData []byte
+)
+
+func file_proto_netconf_v1_netconf_proto_rawDescGZIP() []byte {
+	file_proto_netconf_v1_netconf_proto_rawDescOnce.Do(func() {
+		file_proto_netconf_v1_netconf_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_netconf_v1_netconf_proto_rawDesc), len(file_proto_netconf_v1_netconf_proto_rawDesc)))
+	})
+	return file_proto_netconf_v1_netconf_proto_rawDescData
+}
+
+var file_proto_netconf_v1_netconf_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_proto_netconf_v1_netconf_proto_goTypes = []any{
+	(*Filter)(nil),                // 0: netconf.v1.Filter
+	(*GetConfigRequest)(nil),      // 1: netconf.v1.GetConfigRequest
+	(*GetConfigResponse)(nil),     // 2: netconf.v1.GetConfigResponse
+	(*EditConfigRequest)(nil),     // 3: netconf.v1.EditConfigRequest
+	(*EditConfigResponse)(nil),    // 4: netconf.v1.EditConfigResponse
+	(*ExecRequest)(nil),           // 5: netconf.v1.ExecRequest
+	(*ExecResponse)(nil),          // 6: netconf.v1.ExecResponse
+	(*SubscribeRequest)(nil),      // 7: netconf.v1.SubscribeRequest
+	(*Notification)(nil),          // 8: netconf.v1.Notification
+	nil,                           // 9: netconf.v1.Filter.NamespacesEntry
+	(*timestamppb.Timestamp)(nil), // 10: google.protobuf.Timestamp
+}
+var file_proto_netconf_v1_netconf_proto_depIdxs = []int32{
+	9,  // 0: netconf.v1.Filter.namespaces:type_name -> netconf.v1.Filter.NamespacesEntry
+	0,  // 1: netconf.v1.GetConfigRequest.filter:type_name -> netconf.v1.Filter
+	0,  // 2: netconf.v1.SubscribeRequest.filter:type_name -> netconf.v1.Filter
+	10, // 3: netconf.v1.SubscribeRequest.start_time:type_name -> google.protobuf.Timestamp
+	10, // 4: netconf.v1.SubscribeRequest.stop_time:type_name -> google.protobuf.Timestamp
+	10, // 5: netconf.v1.Notification.event_time:type_name -> google.protobuf.Timestamp
+	1,  // 6: netconf.v1.Netconf.GetConfig:input_type -> netconf.v1.GetConfigRequest
+	3,  // 7: netconf.v1.Netconf.EditConfig:input_type -> netconf.v1.EditConfigRequest
+	5,  // 8: netconf.v1.Netconf.Exec:input_type -> netconf.v1.ExecRequest
+	7,  // 9: netconf.v1.Netconf.Subscribe:input_type -> netconf.v1.SubscribeRequest
+	2,  // 10: netconf.v1.Netconf.GetConfig:output_type -> netconf.v1.GetConfigResponse
+	4,  // 11: netconf.v1.Netconf.EditConfig:output_type -> netconf.v1.EditConfigResponse
+	6,  // 12: netconf.v1.Netconf.Exec:output_type -> netconf.v1.ExecResponse
+	8,  // 13: netconf.v1.Netconf.Subscribe:output_type -> netconf.v1.Notification
+	10, // [10:14] is the sub-list for method output_type
+	6,  // [6:10] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_proto_netconf_v1_netconf_proto_init() }
+func file_proto_netconf_v1_netconf_proto_init() {
+	if File_proto_netconf_v1_netconf_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_netconf_v1_netconf_proto_rawDesc), len(file_proto_netconf_v1_netconf_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_netconf_v1_netconf_proto_goTypes,
+		DependencyIndexes: file_proto_netconf_v1_netconf_proto_depIdxs,
+		MessageInfos:      file_proto_netconf_v1_netconf_proto_msgTypes,
+	}.Build()
+	File_proto_netconf_v1_netconf_proto = out.File
+	file_proto_netconf_v1_netconf_proto_goTypes = nil
+	file_proto_netconf_v1_netconf_proto_depIdxs = nil
+}
@@ -0,0 +1,251 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: proto/netconf/v1/netconf.proto
+
+package netconfv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Netconf_GetConfig_FullMethodName  = "/netconf.v1.Netconf/GetConfig"
+	Netconf_EditConfig_FullMethodName = "/netconf.v1.Netconf/EditConfig"
+	Netconf_Exec_FullMethodName       = "/netconf.v1.Netconf/Exec"
+	Netconf_Subscribe_FullMethodName  = "/netconf.v1.Netconf/Subscribe"
+)
+
+// NetconfClient is the client API for Netconf service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Netconf fronts a pool of NETCONF sessions.  Each RPC corresponds to a
+// method of netconf.Service.
+type NetconfClient interface {
+	GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error)
+	EditConfig(ctx context.Context, in *EditConfigRequest, opts ...grpc.CallOption) (*EditConfigResponse, error)
+	Exec(ctx context.Context, in *ExecRequest, opts ...grpc.CallOption) (*ExecResponse, error)
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Notification], error)
+}
+
+type netconfClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNetconfClient(cc grpc.ClientConnInterface) NetconfClient {
+	return &netconfClient{cc}
+}
+
+func (c *netconfClient) GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetConfigResponse)
+	err := c.cc.Invoke(ctx, Netconf_GetConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *netconfClient) EditConfig(ctx context.Context, in *EditConfigRequest, opts ...grpc.CallOption) (*EditConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EditConfigResponse)
+	err := c.cc.Invoke(ctx, Netconf_EditConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *netconfClient) Exec(ctx context.Context, in *ExecRequest, opts ...grpc.CallOption) (*ExecResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExecResponse)
+	err := c.cc.Invoke(ctx, Netconf_Exec_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *netconfClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Notification], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Netconf_ServiceDesc.Streams[0], Netconf_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, Notification]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Netconf_SubscribeClient = grpc.ServerStreamingClient[Notification]
+
+// NetconfServer is the server API for Netconf service.
+// All implementations must embed UnimplementedNetconfServer
+// for forward compatibility.
+//
+// Netconf fronts a pool of NETCONF sessions.  Each RPC corresponds to a
+// method of netconf.Service.
+type NetconfServer interface {
+	GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error)
+	EditConfig(context.Context, *EditConfigRequest) (*EditConfigResponse, error)
+	Exec(context.Context, *ExecRequest) (*ExecResponse, error)
+	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Notification]) error
+	mustEmbedUnimplementedNetconfServer()
+}
+
+// UnimplementedNetconfServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedNetconfServer struct{}
+
+func (UnimplementedNetconfServer) GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetConfig not implemented")
+}
+func (UnimplementedNetconfServer) EditConfig(context.Context, *EditConfigRequest) (*EditConfigResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EditConfig not implemented")
+}
+func (UnimplementedNetconfServer) Exec(context.Context, *ExecRequest) (*ExecResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Exec not implemented")
+}
+func (UnimplementedNetconfServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Notification]) error {
+	return status.Error(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedNetconfServer) mustEmbedUnimplementedNetconfServer() {}
+func (UnimplementedNetconfServer) testEmbeddedByValue()                 {}
+
+// UnsafeNetconfServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NetconfServer will
+// result in compilation errors.
+type UnsafeNetconfServer interface {
+	mustEmbedUnimplementedNetconfServer()
+}
+
+func RegisterNetconfServer(s grpc.ServiceRegistrar, srv NetconfServer) {
+	// If the following call panics, it indicates UnimplementedNetconfServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Netconf_ServiceDesc, srv)
+}
+
+func _Netconf_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetconfServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Netconf_GetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetconfServer).GetConfig(ctx, req.(*GetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Netconf_EditConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetconfServer).EditConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Netconf_EditConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetconfServer).EditConfig(ctx, req.(*EditConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Netconf_Exec_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetconfServer).Exec(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Netconf_Exec_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetconfServer).Exec(ctx, req.(*ExecRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Netconf_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NetconfServer).Subscribe(m, &grpc.GenericServerStream[SubscribeRequest, Notification]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Netconf_SubscribeServer = grpc.ServerStreamingServer[Notification]
+
+// Netconf_ServiceDesc is the grpc.ServiceDesc for Netconf service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Netconf_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "netconf.v1.Netconf",
+	HandlerType: (*NetconfServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetConfig",
+			Handler:    _Netconf_GetConfig_Handler,
+		},
+		{
+			MethodName: "EditConfig",
+			Handler:    _Netconf_EditConfig_Handler,
+		},
+		{
+			MethodName: "Exec",
+			Handler:    _Netconf_Exec_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Netconf_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/netconf/v1/netconf.proto",
+}
@@ -0,0 +1,162 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package netconfgrpc serves the NETCONF operations of a netconf.Service
+// over gRPC, so that services written in other languages can use the
+// sessions of a pool.  It is a module of its own to keep the gRPC
+// dependencies out of the netconf package.
+package netconfgrpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative proto/netconf/v1/netconf.proto
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Juniper/go-netconf/netconf"
+	netconfv1 "github.com/Juniper/go-netconf/netconfgrpc/proto/netconf/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements the Netconf service of proto/netconf/v1/netconf.proto
+// on top of a netconf.Service.
+type Server struct {
+	netconfv1.UnimplementedNetconfServer
+
+	Service *netconf.Service
+}
+
+// NewServer returns a server for the devices of pool.
+func NewServer(pool *netconf.Pool) *Server {
+	return &Server{Service: &netconf.Service{Pool: pool}}
+}
+
+// Register registers the server with s.
+func (srv *Server) Register(s grpc.ServiceRegistrar) {
+	netconfv1.RegisterNetconfServer(s, srv)
+}
+
+// GetConfig returns the configuration of a device.
+func (srv *Server) GetConfig(ctx context.Context, req *netconfv1.GetConfigRequest) (*netconfv1.GetConfigResponse, error) {
+	data, err := srv.Service.GetConfig(ctx, req.GetDevice(), req.GetSource(), filter(req.GetFilter()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &netconfv1.GetConfigResponse{Data: string(data)}, nil
+}
+
+// EditConfig merges a configuration into a datastore of a device.
+func (srv *Server) EditConfig(ctx context.Context, req *netconfv1.EditConfigRequest) (*netconfv1.EditConfigResponse, error) {
+	if req.GetTarget() == "" {
+		return nil, status.Error(codes.InvalidArgument, "edit-config target required")
+	}
+	if err := srv.Service.EditConfig(ctx, req.GetDevice(), req.GetTarget(), req.GetConfig()); err != nil {
+		return nil, statusError(err)
+	}
+	return &netconfv1.EditConfigResponse{}, nil
+}
+
+// Exec sends a raw RPC to a device.
+func (srv *Server) Exec(ctx context.Context, req *netconfv1.ExecRequest) (*netconfv1.ExecResponse, error) {
+	reply, err := srv.Service.Exec(ctx, req.GetDevice(), req.GetRpc())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &netconfv1.ExecResponse{Reply: string(reply)}, nil
+}
+
+// Subscribe streams the notifications of a device until the client goes
+// away or the subscription ends.
+func (srv *Server) Subscribe(req *netconfv1.SubscribeRequest, stream netconfv1.Netconf_SubscribeServer) error {
+	opts := &netconf.SubscriptionOptions{
+		Stream: req.GetStream(),
+		Filter: filter(req.GetFilter()),
+	}
+	if req.StartTime != nil {
+		opts.StartTime = req.GetStartTime().AsTime()
+	}
+	if req.StopTime != nil {
+		opts.StopTime = req.GetStopTime().AsTime()
+	}
+	err := srv.Service.Subscribe(stream.Context(), req.GetDevice(), opts, func(n *netconf.Notification) error {
+		return stream.Send(&netconfv1.Notification{
+			EventTime: timestamp(n.EventTime),
+			Event:     string(n.Event),
+		})
+	})
+	if err != nil {
+		return statusError(err)
+	}
+	return nil
+}
+
+// filter converts f, subtree filters being the default.
+func filter(f *netconfv1.Filter) *netconf.Filter {
+	if f == nil {
+		return nil
+	}
+	if f.GetType() == netconf.FilterXPath {
+		return netconf.XPathFilter(f.GetSelect(), f.GetNamespaces())
+	}
+	return netconf.SubtreeFilter(f.GetContent())
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// statusError maps err to a gRPC status.  RPC errors of the device are
+// mapped by their tag, keeping the message.
+func statusError(err error) error {
+	var rpcErr *netconf.RPCError
+	switch {
+	case errors.As(err, &rpcErr):
+		return status.Error(rpcCode(rpcErr.Tag), rpcErr.Error())
+	case errors.Is(err, netconf.ErrUnknownDevice):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, netconf.ErrCapabilityUnsupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, netconf.ErrPolicyDenied), errors.Is(err, netconf.ErrReadOnlySession):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Unavailable, err.Error())
+	}
+}
+
+// rpcCode returns the gRPC code of an <rpc-error> tag (RFC 6241,
+// appendix A).
+func rpcCode(tag string) codes.Code {
+	switch tag {
+	case "in-use", "lock-denied", "resource-denied", "rollback-failed":
+		return codes.Aborted
+	case "access-denied":
+		return codes.PermissionDenied
+	case "data-exists":
+		return codes.AlreadyExists
+	case "data-missing":
+		return codes.NotFound
+	case "operation-not-supported":
+		return codes.Unimplemented
+	case "too-big":
+		return codes.ResourceExhausted
+	case "invalid-value", "missing-attribute", "bad-attribute", "unknown-attribute",
+		"missing-element", "bad-element", "unknown-element", "unknown-namespace", "malformed-message":
+		return codes.InvalidArgument
+	default:
+		return codes.FailedPrecondition
+	}
+}
@@ -0,0 +1,136 @@
+// Go NETCONF Client
+//
+// Copyright (c) 2013-2018, Juniper Networks, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netconfgrpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Juniper/go-netconf/netconf"
+	netconfv1 "github.com/Juniper/go-netconf/netconfgrpc/proto/netconf/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves a NETCONF device r1 and returns a client of a gRPC
+// server fronting it.
+func newTestClient(t *testing.T) (netconfv1.NetconfClient, func()) {
+	device := netconf.NewServer(netconf.CapabilityCandidate, netconf.CapabilityNotification)
+	device.HandleFunc("get-config", func(ctx context.Context, req *netconf.ServerRequest) ([]byte, error) {
+		return []byte(`<data><system xmlns="urn:example:system"><hostname>r1</hostname></system></data>`), nil
+	})
+	device.HandleFunc("edit-config", func(ctx context.Context, req *netconf.ServerRequest) ([]byte, error) {
+		if req.Operation.Child("target").Child("running") != nil {
+			return nil, &netconf.RPCError{Type: "protocol", Tag: "access-denied", Message: "running is read-only"}
+		}
+		return nil, nil
+	})
+	device.HandleFunc("create-subscription", func(ctx context.Context, req *netconf.ServerRequest) ([]byte, error) {
+		go req.Session.Notify(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), []byte(`<link-down xmlns="urn:example:events"/>`))
+		return nil, nil
+	})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go device.Serve(l)
+
+	inv := &netconf.Inventory{Devices: []*netconf.Device{{Name: "r1", Address: l.Addr().String()}}}
+	pool := netconf.NewPool(inv, func(ctx context.Context, d *netconf.Device) (*netconf.Session, error) {
+		return netconf.DialTCP(d.Address)
+	})
+
+	bl := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	NewServer(pool).Register(gs)
+	go gs.Serve(bl)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return bl.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return netconfv1.NewNetconfClient(conn), func() {
+		conn.Close()
+		gs.Stop()
+		pool.Close()
+		device.Close()
+	}
+}
+
+func TestServer(t *testing.T) {
+	client, stop := newTestClient(t)
+	defer stop()
+	ctx := context.Background()
+
+	cfg, err := client.GetConfig(ctx, &netconfv1.GetConfigRequest{Device: "r1"})
+	if err != nil {
+		t.Fatalf("GetConfig failed: %v", err)
+	}
+	if !strings.Contains(cfg.GetData(), "<hostname>r1</hostname>") {
+		t.Errorf("unexpected data %s", cfg.GetData())
+	}
+
+	reply, err := client.Exec(ctx, &netconfv1.ExecRequest{Device: "r1", Rpc: "<get-config><source><running/></source></get-config>"})
+	if err != nil || !strings.Contains(reply.GetReply(), "<hostname>r1</hostname>") {
+		t.Errorf("unexpected Exec reply %v, error %v", reply, err)
+	}
+
+	if _, err := client.EditConfig(ctx, &netconfv1.EditConfigRequest{Device: "r1", Target: "candidate", Config: "<system/>"}); err != nil {
+		t.Errorf("EditConfig failed: %v", err)
+	}
+
+	tt := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{name: "rpc error", code: codes.PermissionDenied},
+		{name: "unknown device", code: codes.NotFound},
+		{name: "unsupported operation", code: codes.Unimplemented},
+		{name: "no target", code: codes.InvalidArgument},
+	}
+	tt[0].err = errOf(client.EditConfig(ctx, &netconfv1.EditConfigRequest{Device: "r1", Target: "running", Config: "<system/>"}))
+	tt[1].err = errOf(client.GetConfig(ctx, &netconfv1.GetConfigRequest{Device: "r2"}))
+	tt[2].err = errOf(client.Exec(ctx, &netconfv1.ExecRequest{Device: "r1", Rpc: "<bogus/>"}))
+	tt[3].err = errOf(client.EditConfig(ctx, &netconfv1.EditConfigRequest{Device: "r1", Config: "<system/>"}))
+	for _, tc := range tt {
+		if code := status.Code(tc.err); code != tc.code {
+			t.Errorf("%s: got code %s (%v), expected %s", tc.name, code, tc.err, tc.code)
+		}
+	}
+}
+
+func TestServerSubscribe(t *testing.T) {
+	client, stop := newTestClient(t)
+	defer stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.Subscribe(ctx, &netconfv1.SubscribeRequest{Device: "r1"})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	n, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	if !strings.Contains(n.GetEvent(), "link-down") || !n.GetEventTime().AsTime().Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("unexpected notification %v", n)
+	}
+}
+
+func errOf(_ interface{}, err error) error {
+	return err
+}